@@ -0,0 +1,97 @@
+package bbs
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// aidTable is the alphabet of AID, each character encodes 6 bits.
+const aidTable = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz-_"
+
+// aidLength is the length of AID, which encodes 48 bits.
+const aidLength = 8
+
+// ArticleAID returns the AID of article filename, such as "1Vo_V0Qb" for
+// "M.1607202752.A.6A5". AID encodes the type, timestamp and random part of
+// filename, see aids.c in pttbbs. It returns false if filename does not match
+// "M.<ts>.A.<rnd>" or "G.<ts>.A.<rnd>".
+func ArticleAID(filename string) (aid string, ok bool) {
+	seg := strings.Split(filename, ".")
+	if len(seg) != 3 && len(seg) != 4 {
+		return "", false
+	}
+
+	var typ uint64
+	switch seg[0] {
+	case "M":
+		typ = 0
+	case "G":
+		typ = 1
+	default:
+		return "", false
+	}
+	ts, err := strconv.ParseUint(seg[1], 10, 32)
+	if err != nil || seg[2] != "A" {
+		return "", false
+	}
+	var rnd uint64
+	if len(seg) == 4 {
+		rnd, err = strconv.ParseUint(seg[3], 16, 12)
+		if err != nil {
+			return "", false
+		}
+	}
+
+	u := typ<<44 | ts<<12 | rnd
+	b := make([]byte, aidLength)
+	for i := aidLength - 1; i >= 0; i-- {
+		b[i] = aidTable[u&0x3f]
+		u >>= 6
+	}
+	return string(b), true
+}
+
+// ParseArticleAID returns the article filename of aid, a leading "#" is
+// allowed. It returns false if aid is malformed.
+func ParseArticleAID(aid string) (filename string, ok bool) {
+	aid = strings.TrimPrefix(aid, "#")
+	if len(aid) != aidLength {
+		return "", false
+	}
+
+	var u uint64
+	for i := 0; i < len(aid); i++ {
+		v := strings.IndexByte(aidTable, aid[i])
+		if v < 0 {
+			return "", false
+		}
+		u = u<<6 | uint64(v)
+	}
+
+	var typ byte
+	switch u >> 44 {
+	case 0:
+		typ = 'M'
+	case 1:
+		typ = 'G'
+	default:
+		return "", false
+	}
+	ts := (u >> 12) & 0xffffffff
+	rnd := u & 0xfff
+	return fmt.Sprintf("%c.%d.A.%03X", typ, ts, rnd), true
+}
+
+// ArticleURL returns the shareable web URL of article in boardID, which is
+// baseURL followed by board id and AID of article, such as
+// "https://example.com/bbs/SYSOP/1Vo_V0Qb". It returns false if filename of
+// article has no AID.
+func ArticleURL(baseURL, boardID string, ar ArticleRecord) (string, bool) {
+	aid, ok := ArticleAID(ar.Filename())
+	if !ok {
+		return "", false
+	}
+	return strings.TrimSuffix(baseURL, "/") + "/" + url.PathEscape(boardID) + "/" + aid, true
+}
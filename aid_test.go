@@ -0,0 +1,59 @@
+package bbs
+
+import (
+	"testing"
+)
+
+func TestArticleAID(t *testing.T) {
+	tests := []struct {
+		filename string
+		aid      string
+		ok       bool
+	}{
+		{"M.1607202752.A.6A5", "1Vo_V0Qb", true},
+		{"G.1607202752.A.6A5", "5Vo_V0Qb", true},
+		{"M.1119222611.A.7A9", "12jVjJUf", true},
+		{"M.1607202752.A", "1Vo_V000", true},
+		{"M.1607202752.B.6A5", "", false},
+		{"X.1607202752.A.6A5", "", false},
+		{"M.99999999999.A.6A5", "", false},
+		{"M.1607202752.A.1000", "", false},
+		{".DIR", "", false},
+	}
+	for _, tt := range tests {
+		aid, ok := ArticleAID(tt.filename)
+		if aid != tt.aid || ok != tt.ok {
+			t.Errorf("ArticleAID(%q) expected: %q, %v, got: %q, %v", tt.filename, tt.aid, tt.ok, aid, ok)
+		}
+		if !ok {
+			continue
+		}
+		filename, ok := ParseArticleAID("#" + aid)
+		if !ok {
+			t.Errorf("ParseArticleAID(%q) should be ok", aid)
+		}
+		if expected, _ := ArticleAID(filename); expected != aid {
+			t.Errorf("ParseArticleAID(%q) round trip got: %q", aid, filename)
+		}
+	}
+
+	if filename, ok := ParseArticleAID("1Vo_V0Qb"); filename != "M.1607202752.A.6A5" || !ok {
+		t.Errorf("ParseArticleAID expected: M.1607202752.A.6A5, true, got: %q, %v", filename, ok)
+	}
+	for _, aid := range []string{"", "1Vo_V0Q", "1Vo_V0Q!", "_Vo_V0Qb"} {
+		if _, ok := ParseArticleAID(aid); ok {
+			t.Errorf("ParseArticleAID(%q) should not be ok", aid)
+		}
+	}
+}
+
+func TestArticleURL(t *testing.T) {
+	u, ok := ArticleURL("https://example.com/bbs/", "SYSOP", &fakeArticleRecord{filename: "M.1607202752.A.6A5"})
+	if u != "https://example.com/bbs/SYSOP/1Vo_V0Qb" || !ok {
+		t.Errorf("ArticleURL expected: https://example.com/bbs/SYSOP/1Vo_V0Qb, true, got: %q, %v", u, ok)
+	}
+	u, ok = ArticleURL("https://example.com/bbs", "SYSOP", &fakeArticleRecord{filename: "D690"})
+	if u != "" || ok {
+		t.Errorf("ArticleURL of filename without AID expected: \"\", false, got: %q, %v", u, ok)
+	}
+}
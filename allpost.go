@@ -0,0 +1,80 @@
+package bbs
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// AllPostBoardID is the board id of ALLPOST, which aggregates new posts of
+// all boards.
+const AllPostBoardID = "ALLPOST"
+
+// ReadAllPostRecords returns at most limit article records of ALLPOST
+// starting from offset, oldest first. limit 0 means no limit. Only the
+// requested records are read if driver supports ranged reads, since ALLPOST
+// can be enormous.
+// Each entry points back to the article in its origin board, the origin
+// board id is usually appended to the title, eg: "[問卦] 標題 (Gossiping)".
+func (db *DB) ReadAllPostRecords(offset, limit int) ([]ArticleRecord, error) {
+	recs, err := db.ReadBoardArticleRecordsRange(AllPostBoardID, offset, limit)
+	if err != nil {
+		log.Println("bbs: ReadBoardArticleRecordsRange error:", err)
+		return nil, err
+	}
+	return recs, nil
+}
+
+// AllPostOriginBoard returns the origin board id of ALLPOST entry title,
+// which is appended to the title as "標題 (Gossiping)" or "標題.Gossiping板"
+// by pttbbs. ok is false if title has no origin board.
+func AllPostOriginBoard(title string) (boardID string, ok bool) {
+	title = strings.TrimSpace(title)
+	switch {
+	case strings.HasSuffix(title, ")"):
+		i := strings.LastIndex(title, "(")
+		if i < 0 {
+			return "", false
+		}
+		boardID = title[i+1 : len(title)-1]
+	case strings.HasSuffix(title, "板"):
+		i := strings.LastIndex(title, ".")
+		if i < 0 {
+			return "", false
+		}
+		boardID = strings.TrimSuffix(title[i+1:], "板")
+	default:
+		return "", false
+	}
+	if boardID == "" || strings.ContainsAny(boardID, " \t") || checkNames(boardID) != nil {
+		return "", false
+	}
+	return boardID, true
+}
+
+// ResolveAllPost returns the origin board id of ALLPOST entry ar, and the
+// article record of the same filename in .DIR of the origin board. If the
+// origin post is deleted, boardID is returned with ErrRecordNotFound. It
+// returns ErrInvalidName if title of ar has no origin board, see
+// AllPostOriginBoard.
+func (db *DB) ResolveAllPost(ar ArticleRecord) (boardID string, full ArticleRecord, err error) {
+	if ar == nil {
+		return "", nil, fmt.Errorf("%w: nil ALLPOST record", ErrInvalidName)
+	}
+	boardID, ok := AllPostOriginBoard(ar.Title())
+	if !ok {
+		return "", nil, fmt.Errorf("%w: no origin board in ALLPOST title %q", ErrInvalidName, ar.Title())
+	}
+
+	recs, err := db.ReadBoardArticleRecordsFile(boardID)
+	if err != nil {
+		log.Println("bbs: ReadBoardArticleRecordsFile error:", err)
+		return boardID, nil, err
+	}
+	for _, r := range recs {
+		if r.Filename() == ar.Filename() && !isDeletedArticle(r) {
+			return boardID, r, nil
+		}
+	}
+	return boardID, nil, fmt.Errorf("%w: article %q in board %q", ErrRecordNotFound, ar.Filename(), boardID)
+}
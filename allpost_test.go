@@ -0,0 +1,104 @@
+package bbs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReadAllPostRecords(t *testing.T) {
+	db := &DB{connector: &fakeConnector{
+		fakeGetBoardArticleRecordsPath: func() (string, error) { return "boards/A/ALLPOST/.DIR", nil },
+		fakeReadArticleRecordsFile: func() ([]ArticleRecord, error) {
+			return []ArticleRecord{
+				&fakeArticleRecord{filename: "M.1.A.001"},
+				&fakeArticleRecord{filename: "M.2.A.002"},
+				&fakeArticleRecord{filename: "M.3.A.003"},
+			}, nil
+		},
+	}}
+
+	tests := []struct {
+		offset    int
+		limit     int
+		filenames []string
+		wantErr   bool
+	}{
+		{0, 0, []string{"M.1.A.001", "M.2.A.002", "M.3.A.003"}, false},
+		{1, 1, []string{"M.2.A.002"}, false},
+		{2, 5, []string{"M.3.A.003"}, false},
+		{3, 1, []string{}, false},
+		{-1, 1, nil, true},
+	}
+	for _, tt := range tests {
+		recs, err := db.ReadAllPostRecords(tt.offset, tt.limit)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ReadAllPostRecords(%v, %v) error: %v", tt.offset, tt.limit, err)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if len(recs) != len(tt.filenames) {
+			t.Errorf("ReadAllPostRecords(%v, %v) len expected: %v, got: %v", tt.offset, tt.limit, len(tt.filenames), len(recs))
+			continue
+		}
+		for i, r := range recs {
+			if r.Filename() != tt.filenames[i] {
+				t.Errorf("ReadAllPostRecords(%v, %v)[%v] expected: %v, got: %v", tt.offset, tt.limit, i, tt.filenames[i], r.Filename())
+			}
+		}
+	}
+}
+
+func TestAllPostOriginBoard(t *testing.T) {
+	tests := []struct {
+		title    string
+		boardID  string
+		expected bool
+	}{
+		{"[問卦] 標題 (Gossiping)", "Gossiping", true},
+		{"[問卦] 有沒有八卦                 .Gossiping板", "Gossiping", true},
+		{"[閒聊] 沒有看板", "", false},
+		{"[閒聊] 括號 (a b)", "", false},
+		{"[閒聊] 穿越 (..)", "", false},
+		{"[閒聊] 空的 ()", "", false},
+	}
+	for _, tt := range tests {
+		boardID, ok := AllPostOriginBoard(tt.title)
+		if boardID != tt.boardID || ok != tt.expected {
+			t.Errorf("AllPostOriginBoard(%q) expected: %q, %v, got: %q, %v", tt.title, tt.boardID, tt.expected, boardID, ok)
+		}
+	}
+}
+
+func TestResolveAllPost(t *testing.T) {
+	dirs := map[string][]ArticleRecord{
+		"boards/G/Gossiping/.DIR": {
+			&fakeArticleRecord{filename: "M.1.A.001", title: "[問卦] 標題", owner: "pichu", recommend: 10},
+			&fakeArticleRecord{filename: "", title: "(本文已被刪除) [pichu]"},
+		},
+	}
+	db := &DB{connector: &fakeConnector{
+		fakeGetBoardArticleRecordsPath: func() (string, error) { return "boards/G/Gossiping/.DIR", nil },
+		fakeReadArticleRecordsFile: func() ([]ArticleRecord, error) {
+			return dirs["boards/G/Gossiping/.DIR"], nil
+		},
+	}}
+
+	boardID, full, err := db.ResolveAllPost(&fakeArticleRecord{filename: "M.1.A.001", title: "[問卦] 標題 (Gossiping)"})
+	if err != nil {
+		t.Fatalf("ResolveAllPost error: %v", err)
+	}
+	if boardID != "Gossiping" || full.Owner() != "pichu" || full.Recommend() != 10 {
+		t.Errorf("ResolveAllPost expected: Gossiping pichu 10, got: %v %v %v", boardID, full.Owner(), full.Recommend())
+	}
+
+	boardID, _, err = db.ResolveAllPost(&fakeArticleRecord{filename: "M.2.A.002", title: "[問卦] 被刪了.Gossiping板"})
+	if boardID != "Gossiping" || !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("ResolveAllPost of deleted post expected: Gossiping, ErrRecordNotFound, got: %v, %v", boardID, err)
+	}
+
+	if _, _, err := db.ResolveAllPost(&fakeArticleRecord{filename: "M.1.A.001", title: "[問卦] 標題"}); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("ResolveAllPost without origin board should return ErrInvalidName, got: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package bbs
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"strings"
+	"sync"
+)
+
+// articleCacheConnector keeps article files read by ReadBoardArticleFile in
+// a least recently used cache bounded by the total bytes of files.
+type articleCacheConnector struct {
+	Connector
+	maxBytes int
+
+	mutex   sync.Mutex
+	size    int
+	lru     *list.List // of *articleCacheEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type articleCacheEntry struct {
+	name string
+	data []byte
+}
+
+// WithArticleCache returns a connector which keeps the article files read by
+// ReadBoardArticleFile in memory, at most maxBytes in total. The least
+// recently used files are evicted when a new file does not fit, files larger
+// than maxBytes are never cached. OpenBoardArticleFile of DB streams cached
+// files from memory but does not add files to cache. Cached files do not
+// expire, writes of article files through DB, NewArticleRecord and
+// ImportBoard, invalidate them. Files modified by other processes, such as comments appended by the
+// running mbbsd, are not detected, so it fits read-only mirrors of BBSHome.
+// It is safe for concurrent use.
+func WithArticleCache(c Connector, maxBytes int) Connector {
+	return &articleCacheConnector{
+		Connector: c,
+		maxBytes:  maxBytes,
+		lru:       list.New(),
+		entries:   map[string]*list.Element{},
+	}
+}
+
+// Unwrap returns the connector wrapped by article cache.
+func (c *articleCacheConnector) Unwrap() Connector {
+	return c.Connector
+}
+
+const articleFileCachePrefix = "article:"
+
+func articleFileCacheKey(name string) string { return articleFileCachePrefix + name }
+
+func (c *articleCacheConnector) ReadBoardArticleFile(name string) ([]byte, error) {
+	if data, ok := c.get(name); ok {
+		return data, nil
+	}
+	data, err := c.Connector.ReadBoardArticleFile(name)
+	if err != nil {
+		return nil, err
+	}
+	c.add(name, data)
+	return append([]byte{}, data...), nil
+}
+
+// OpenBoardArticleFile returns cached file name if any, otherwise it opens
+// the file by OpenArticleFileConnector of the wrapped connector without
+// caching it, or returns ErrNotSupported if the wrapped connector does not
+// implement it.
+func (c *articleCacheConnector) OpenBoardArticleFile(name string) (io.ReadCloser, error) {
+	if data, ok := c.get(name); ok {
+		return cachedArticleFile{bytes.NewReader(data)}, nil
+	}
+	var oc OpenArticleFileConnector
+	if !connectorAs(c.Connector, &oc) {
+		return nil, ErrNotSupported
+	}
+	return oc.OpenBoardArticleFile(name)
+}
+
+// cachedArticleFile reads a cached article file, Close does nothing.
+type cachedArticleFile struct {
+	*bytes.Reader
+}
+
+func (cachedArticleFile) Close() error { return nil }
+
+// get returns a copy of cached file name and marks it most recently used.
+func (c *articleCacheConnector) get(name string) ([]byte, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	el, ok := c.entries[name]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	return append([]byte{}, el.Value.(*articleCacheEntry).data...), true
+}
+
+// add caches file name and evicts the least recently used files until the
+// total size fits in maxBytes.
+func (c *articleCacheConnector) add(name string, data []byte) {
+	if len(data) > c.maxBytes {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if el, ok := c.entries[name]; ok {
+		c.remove(el)
+	}
+	c.entries[name] = c.lru.PushFront(&articleCacheEntry{name: name, data: data})
+	c.size += len(data)
+	for c.size > c.maxBytes {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove drops el from cache, mutex must be held.
+func (c *articleCacheConnector) remove(el *list.Element) {
+	e := c.lru.Remove(el).(*articleCacheEntry)
+	delete(c.entries, e.name)
+	c.size -= len(e.data)
+}
+
+// invalidate drops the cached file of key made by articleFileCacheKey,
+// other keys are passed to the wrapped cache.
+func (c *articleCacheConnector) invalidate(key string) {
+	if name := strings.TrimPrefix(key, articleFileCachePrefix); name != key {
+		c.mutex.Lock()
+		if el, ok := c.entries[name]; ok {
+			c.remove(el)
+		}
+		c.mutex.Unlock()
+	}
+	invalidateWrapped(c.Connector, key)
+}
@@ -0,0 +1,179 @@
+package bbs
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeArticleFileConnector struct {
+	fakeConnector
+	mutex sync.Mutex
+	reads map[string]int
+	files map[string][]byte
+}
+
+func (c *fakeArticleFileConnector) ReadBoardArticleFile(name string) ([]byte, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	data, ok := c.files[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	c.reads[name]++
+	return append([]byte{}, data...), nil
+}
+
+func TestWithArticleCache(t *testing.T) {
+	fc := &fakeArticleFileConnector{
+		reads: map[string]int{},
+		files: map[string][]byte{
+			"a": []byte("aaaa"),
+			"b": []byte("bbbb"),
+			"c": []byte("cccc"),
+			"d": []byte("dddddddddddd"),
+		},
+	}
+	c := WithArticleCache(fc, 10)
+
+	read := func(name string) {
+		data, err := c.ReadBoardArticleFile(name)
+		if err != nil || string(data) != string(fc.files[name]) {
+			t.Fatalf("ReadBoardArticleFile(%q) expected: %q, <nil>, got: %q, %v", name, fc.files[name], data, err)
+		}
+		data[0] = 'x'
+	}
+	read("a")
+	read("b")
+	read("a")
+	if fc.reads["a"] != 1 || fc.reads["b"] != 1 {
+		t.Errorf("cached files should be read once, got: %v", fc.reads)
+	}
+
+	// c evicts b, the least recently used one.
+	read("c")
+	read("a")
+	read("b")
+	if fc.reads["a"] != 1 || fc.reads["b"] != 2 || fc.reads["c"] != 1 {
+		t.Errorf("least recently used file should be evicted, got: %v", fc.reads)
+	}
+
+	// d is larger than maxBytes and never cached.
+	read("d")
+	read("d")
+	if fc.reads["d"] != 2 {
+		t.Errorf("file larger than maxBytes should not be cached, got: %v", fc.reads)
+	}
+
+	if _, err := c.ReadBoardArticleFile("notexist"); !os.IsNotExist(err) {
+		t.Errorf("ReadBoardArticleFile should return ErrNotExist, got: %v", err)
+	}
+
+	db := &DB{connector: WithCache(c, time.Hour)}
+	db.invalidateCache(articleFileCacheKey("b"))
+	read("b")
+	if fc.reads["b"] != 3 {
+		t.Errorf("invalidated file should be read again, got: %v", fc.reads)
+	}
+}
+
+func TestWithArticleCacheConcurrent(t *testing.T) {
+	fc := &fakeArticleFileConnector{reads: map[string]int{}, files: map[string][]byte{}}
+	for i := 0; i < 20; i++ {
+		fc.files[fmt.Sprint(i)] = []byte(fmt.Sprintf("%04d", i))
+	}
+	c := WithArticleCache(fc, 40)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				name := fmt.Sprint((g + i) % 20)
+				data, err := c.ReadBoardArticleFile(name)
+				if err != nil || string(data) != string(fc.files[name]) {
+					t.Errorf("ReadBoardArticleFile(%q) expected: %q, <nil>, got: %q, %v", name, fc.files[name], data, err)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	ac := c.(*articleCacheConnector)
+	if ac.size > 40 || ac.lru.Len() != len(ac.entries) {
+		t.Errorf("cache size should be bounded, got size: %v, lru: %v, entries: %v", ac.size, ac.lru.Len(), len(ac.entries))
+	}
+}
+
+type diskArticleFileConnector struct {
+	fakeConnector
+}
+
+func (c *diskArticleFileConnector) ReadBoardArticleFile(name string) ([]byte, error) {
+	return ioutil.ReadFile(name)
+}
+
+func benchmarkReadBoardArticleFile(b *testing.B, c Connector) {
+	dir := b.TempDir()
+	names := make([]string, 10)
+	for i := range names {
+		names[i] = filepath.Join(dir, fmt.Sprintf("M.%d.A.000", 1600000000+i))
+		if err := ioutil.WriteFile(names[i], make([]byte, 8192), 0644); err != nil {
+			b.Fatalf("write file error: %v", err)
+		}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.ReadBoardArticleFile(names[i%len(names)]); err != nil {
+			b.Fatalf("ReadBoardArticleFile error: %v", err)
+		}
+	}
+}
+
+func BenchmarkReadBoardArticleFile(b *testing.B) {
+	benchmarkReadBoardArticleFile(b, &diskArticleFileConnector{})
+}
+
+func BenchmarkReadBoardArticleFileCached(b *testing.B) {
+	benchmarkReadBoardArticleFile(b, WithArticleCache(&diskArticleFileConnector{}, 1<<20))
+}
+
+type fakeWriteArticleFileConnector struct {
+	fakeArticleFileConnector
+}
+
+func (c *fakeWriteArticleFileConnector) NewArticleRecord(args map[string]interface{}) (ArticleRecord, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.files[args["board_id"].(string)+"/M.1.A.001"] = []byte(args["title"].(string))
+	return &fakeArticleRecord{filename: "M.1.A.001"}, nil
+}
+
+func (c *fakeWriteArticleFileConnector) AddArticleRecordFileRecord(name string, article ArticleRecord) error {
+	return nil
+}
+
+func TestWithArticleCacheInvalidatedByWrites(t *testing.T) {
+	fc := &fakeWriteArticleFileConnector{fakeArticleFileConnector{
+		reads: map[string]int{},
+		files: map[string][]byte{"SYSOP/M.1.A.001": []byte("old")},
+	}}
+	fc.fakeGetBoardArticleFilePath = func() (string, error) { return "SYSOP/M.1.A.001", nil }
+	db := &DB{connector: WithArticleCache(fc, 1024)}
+
+	if data, err := db.ReadBoardArticleFile("SYSOP", "M.1.A.001"); err != nil || string(data) != "old" {
+		t.Fatalf("ReadBoardArticleFile expected: old, got: %q, %v", data, err)
+	}
+	if _, err := db.NewArticleRecord(map[string]interface{}{"board_id": "SYSOP", "title": "new"}); err != nil {
+		t.Fatalf("NewArticleRecord error: %v", err)
+	}
+	if data, err := db.ReadBoardArticleFile("SYSOP", "M.1.A.001"); err != nil || string(data) != "new" {
+		t.Errorf("cached file should be invalidated by NewArticleRecord, got: %q, %v", data, err)
+	}
+}
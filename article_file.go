@@ -0,0 +1,110 @@
+package bbs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"strings"
+)
+
+// Driver which implement OpenArticleFileConnector supports streaming article
+// files through connector, so OpenBoardArticleFile goes through wrappers
+// such as WithRetry and WithArticleCache.
+type OpenArticleFileConnector interface {
+	// OpenBoardArticleFile opens article file name for reading, the caller
+	// should close it. It may return ErrNotSupported to have DB open the
+	// file by itself.
+	OpenBoardArticleFile(name string) (io.ReadCloser, error)
+}
+
+// OpenBoardArticleFile opens article file of board for streaming, the caller
+// should close the returned io.ReadCloser. Like ReadBoardArticleFile,
+// gzipped article files are decompressed unless SetRawArticleFile is set.
+// The file is opened by OpenArticleFileConnector of connector, or from disk,
+// or fsys if DB is opened with WithFS, when connector does not implement it.
+// It returns ErrInvalidName if boardID or filename is not a valid name.
+func (db *DB) OpenBoardArticleFile(boardID, filename string) (io.ReadCloser, error) {
+	if err := checkNames(boardID, filename); err != nil {
+		return nil, err
+	}
+
+	path, err := db.boardArticleFilePath(boardID, filename)
+	if err != nil {
+		log.Println("bbs: GetBoardArticleFilePath error:", err)
+		return nil, err
+	}
+	log.Println("path:", path)
+
+	f, err := db.openArticleFile(path)
+	if err != nil {
+		log.Println("bbs: open article file error:", err)
+		return nil, err
+	}
+	db.countRead(articleRecordKind, articleFileSize(f))
+	if db.rawArticleFile {
+		return f, nil
+	}
+
+	br := bufio.NewReader(f)
+	magic, _ := br.Peek(len(gzipMagic))
+	if !bytes.Equal(magic, gzipMagic) && !strings.HasSuffix(path, ".gz") {
+		return &articleFileReader{Reader: br, closer: f}, nil
+	}
+	gr, err := gzip.NewReader(br)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("bbs: gzip article %v error: %w", path, err)
+	}
+	return &articleFileReader{Reader: gr, closer: f, gzip: gr}, nil
+}
+
+// openArticleFile opens article file path by OpenArticleFileConnector of
+// connector, if connector does not implement it or returns ErrNotSupported,
+// the file is opened from disk, or fsys if DB is opened with WithFS.
+func (db *DB) openArticleFile(path string) (io.ReadCloser, error) {
+	var oc OpenArticleFileConnector
+	if db.connectorAs(&oc) {
+		f, err := oc.OpenBoardArticleFile(path)
+		if !errors.Is(err, ErrNotSupported) {
+			return f, err
+		}
+	}
+	return db.openFile(path)
+}
+
+// articleFileSize returns size of opened article file f for read stats, 0 if
+// it is unknown.
+func articleFileSize(f io.ReadCloser) int {
+	switch f := f.(type) {
+	case interface{ Stat() (fs.FileInfo, error) }:
+		if stat, err := f.Stat(); err == nil {
+			return int(stat.Size())
+		}
+	case interface{ Size() int64 }:
+		return int(f.Size())
+	}
+	return 0
+}
+
+// articleFileReader reads article file through Reader, and closes gzip
+// reader if any and the file on Close.
+type articleFileReader struct {
+	io.Reader
+	closer io.Closer
+	gzip   *gzip.Reader
+}
+
+func (r *articleFileReader) Close() error {
+	if r.gzip != nil {
+		if err := r.gzip.Close(); err != nil {
+			r.closer.Close()
+			return err
+		}
+	}
+	return r.closer.Close()
+}
@@ -0,0 +1,131 @@
+package bbs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"syscall"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestOpenBoardArticleFile(t *testing.T) {
+	content := []byte("作者: SYSOP (站長) 看板: SYSOP\n標題: 測試\n")
+
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	if _, err := w.Write(content); err != nil {
+		t.Fatalf("gzip write error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("gzip close error: %v", err)
+	}
+
+	fsys := fstest.MapFS{
+		"boards/SYSOP/M.1.A.001":    &fstest.MapFile{Data: content},
+		"boards/SYSOP/M.2.A.002.gz": &fstest.MapFile{Data: gz.Bytes()},
+	}
+	c := &fakeUserArticleFileConnector{fakeUserArticleConnector: &fakeUserArticleConnector{}}
+	db := &DB{connector: c, fsys: fsys}
+
+	for _, raw := range []bool{false, true} {
+		db.SetRawArticleFile(raw)
+		for _, filename := range []string{"M.1.A.001", "M.2.A.002.gz"} {
+			rc, err := db.OpenBoardArticleFile("SYSOP", filename)
+			if err != nil {
+				t.Fatalf("OpenBoardArticleFile(%v) error: %v", filename, err)
+			}
+			buf, err := ioutil.ReadAll(rc)
+			if err != nil {
+				t.Fatalf("read %v error: %v", filename, err)
+			}
+			if err := rc.Close(); err != nil {
+				t.Errorf("close %v error: %v", filename, err)
+			}
+
+			expected := content
+			if raw {
+				expected = fsys["boards/SYSOP/"+filename].Data
+			}
+			if !bytes.Equal(buf, expected) {
+				t.Errorf("OpenBoardArticleFile(%v) with raw %v expected: %q, got: %q", filename, raw, expected, buf)
+			}
+		}
+	}
+
+	for _, name := range [][2]string{{"SYSOP", "../../.PASSWDS"}, {"../SYSOP", "M.1.A.001"}} {
+		if _, err := db.OpenBoardArticleFile(name[0], name[1]); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("OpenBoardArticleFile(%q, %q) should return ErrInvalidName, got: %v", name[0], name[1], err)
+		}
+	}
+	if _, err := db.OpenBoardArticleFile("SYSOP", "M.404.A.404"); err == nil {
+		t.Errorf("OpenBoardArticleFile should return error for missing file")
+	}
+}
+
+type fakeOpenArticleFileConnector struct {
+	*fakeUserArticleFileConnector
+	files map[string][]byte
+	opens int
+	errs  []error
+}
+
+func (c *fakeOpenArticleFileConnector) OpenBoardArticleFile(name string) (io.ReadCloser, error) {
+	c.opens++
+	if len(c.errs) > 0 {
+		err := c.errs[0]
+		c.errs = c.errs[1:]
+		return nil, err
+	}
+	data, ok := c.files[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (c *fakeOpenArticleFileConnector) ReadBoardArticleFile(name string) ([]byte, error) {
+	data, ok := c.files[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return append([]byte{}, data...), nil
+}
+
+func TestOpenBoardArticleFileByConnector(t *testing.T) {
+	content := []byte("作者: SYSOP (站長) 看板: SYSOP\n標題: 測試\n")
+	fc := &fakeOpenArticleFileConnector{
+		fakeUserArticleFileConnector: &fakeUserArticleFileConnector{fakeUserArticleConnector: &fakeUserArticleConnector{}},
+		files:                        map[string][]byte{"boards/SYSOP/M.1.A.001": content},
+		errs:                         []error{syscall.EBUSY},
+	}
+	db := &DB{connector: WithArticleCache(WithRetry(fc, 2, time.Millisecond), 1024)}
+
+	read := func() {
+		rc, err := db.OpenBoardArticleFile("SYSOP", "M.1.A.001")
+		if err != nil {
+			t.Fatalf("OpenBoardArticleFile error: %v", err)
+		}
+		defer rc.Close()
+		buf, err := ioutil.ReadAll(rc)
+		if err != nil || !bytes.Equal(buf, content) {
+			t.Errorf("OpenBoardArticleFile expected: %q, <nil>, got: %q, %v", content, buf, err)
+		}
+	}
+	read()
+	if fc.opens != 2 {
+		t.Errorf("open should be retried once, got: %d opens", fc.opens)
+	}
+
+	if _, err := db.ReadBoardArticleFile("SYSOP", "M.1.A.001"); err != nil {
+		t.Fatalf("ReadBoardArticleFile error: %v", err)
+	}
+	read()
+	if fc.opens != 2 {
+		t.Errorf("cached article file should not be opened, got: %d opens", fc.opens)
+	}
+}
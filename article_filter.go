@@ -0,0 +1,104 @@
+package bbs
+
+import (
+	"log"
+	"strings"
+	"time"
+)
+
+// ArticleFilter is the condition for FilterBoardArticles, zero value field
+// means no limit for that field.
+type ArticleFilter struct {
+	// Owner matches the article owner case-insensitively.
+	Owner string
+	// Since and Until is the range of article time, both are inclusive.
+	Since time.Time
+	Until time.Time
+	// MinRecommend is the minimum recommend (推文數) of article.
+	MinRecommend *int
+	// MinMoney is the minimum money of article.
+	MinMoney *int
+}
+
+// ParseArticleDate parses the `Date()` string of article record such as
+// " 9/02" or "11/23". Date string has no year, so the year of ref is used,
+// or the year before if the date would be after ref, as articles are not
+// posted in the future.
+func ParseArticleDate(date string, ref time.Time) (time.Time, bool) {
+	t, err := time.Parse("1/02", strings.TrimSpace(date))
+	if err != nil {
+		return time.Time{}, false
+	}
+	ret := time.Date(ref.Year(), t.Month(), t.Day(), 0, 0, 0, 0, ref.Location())
+	if ret.After(ref) {
+		ret = time.Date(ref.Year()-1, t.Month(), t.Day(), 0, 0, 0, 0, ref.Location())
+	}
+	return ret, true
+}
+
+// TimedArticleRecord return ArticleRecord interface which support PostedAt
+type TimedArticleRecord interface {
+	// PostedAt returns the time article is posted, which is derived from the
+	// timestamp stored in record rather than the Date string. It returns zero
+	// time if the record has no timestamp.
+	PostedAt() time.Time
+}
+
+// articleTime returns the time of article record, it uses PostedAt of
+// TimedArticleRecord, then Modified, and falls back to Date string if
+// neither is set. Date is parsed in loc.
+func articleTime(r ArticleRecord, loc *time.Location) (time.Time, bool) {
+	if tr, ok := r.(TimedArticleRecord); ok {
+		if t := tr.PostedAt(); !t.IsZero() {
+			return t, true
+		}
+	}
+	if m := r.Modified(); !m.IsZero() && m.Unix() != 0 {
+		return m, true
+	}
+	return ParseArticleDate(r.Date(), time.Now().In(loc))
+}
+
+func (f ArticleFilter) match(r ArticleRecord, loc *time.Location) bool {
+	if f.Owner != "" && !strings.EqualFold(f.Owner, r.Owner()) {
+		return false
+	}
+	if f.MinRecommend != nil && r.Recommend() < *f.MinRecommend {
+		return false
+	}
+	if f.MinMoney != nil && r.Money() < *f.MinMoney {
+		return false
+	}
+	if !f.Since.IsZero() || !f.Until.IsZero() {
+		t, ok := articleTime(r, loc)
+		if !ok {
+			return false
+		}
+		if !f.Since.IsZero() && t.Before(f.Since) {
+			return false
+		}
+		if !f.Until.IsZero() && t.After(f.Until) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterBoardArticles returns the article records in board which match the
+// filter, in .DIR order.
+func (db *DB) FilterBoardArticles(boardID string, filter ArticleFilter) ([]ArticleRecord, error) {
+	recs, err := db.ReadBoardArticleRecordsFile(boardID)
+	if err != nil {
+		log.Println("bbs: ReadBoardArticleRecordsFile error:", err)
+		return nil, err
+	}
+
+	loc := db.Location()
+	ret := []ArticleRecord{}
+	for _, r := range recs {
+		if filter.match(r, loc) {
+			ret = append(ret, r)
+		}
+	}
+	return ret, nil
+}
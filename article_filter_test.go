@@ -0,0 +1,95 @@
+package bbs
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFilterBoardArticles(t *testing.T) {
+	db := newTestArticleRecordsDB([]ArticleRecord{
+		&fakeArticleRecord{filename: "A", owner: "SYSOP", recommend: 100, money: 10,
+			modified: time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)},
+		&fakeArticleRecord{filename: "B", owner: "pichu", recommend: -3, money: 0,
+			modified: time.Date(2021, 5, 10, 0, 0, 0, 0, time.UTC)},
+		&fakeArticleRecord{filename: "C", owner: "Pichu", recommend: 20, money: 200,
+			modified: time.Date(2021, 5, 20, 0, 0, 0, 0, time.UTC)},
+	})
+
+	intPtr := func(i int) *int { return &i }
+
+	tests := []struct {
+		name     string
+		filter   ArticleFilter
+		expected []string
+	}{
+		{"no filter", ArticleFilter{}, []string{"A", "B", "C"}},
+		{"owner", ArticleFilter{Owner: "PICHU"}, []string{"B", "C"}},
+		{"recommend", ArticleFilter{MinRecommend: intPtr(100)}, []string{"A"}},
+		{"negative recommend", ArticleFilter{MinRecommend: intPtr(-5)}, []string{"A", "B", "C"}},
+		{"money", ArticleFilter{MinMoney: intPtr(10)}, []string{"A", "C"}},
+		{"since", ArticleFilter{Since: time.Date(2021, 5, 10, 0, 0, 0, 0, time.UTC)}, []string{"B", "C"}},
+		{"until", ArticleFilter{Until: time.Date(2021, 5, 10, 0, 0, 0, 0, time.UTC)}, []string{"A", "B"}},
+		{"combined", ArticleFilter{Owner: "pichu", Since: time.Date(2021, 5, 15, 0, 0, 0, 0, time.UTC)}, []string{"C"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recs, err := db.FilterBoardArticles("SYSOP", tt.filter)
+			if err != nil {
+				t.Errorf("FilterBoardArticles error: %v", err)
+			}
+			if actual := articleFilenames(recs); !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("filter result not match, expected: %v, got: %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseArticleDate(t *testing.T) {
+	ref := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		input      string
+		expected   time.Time
+		expectedOK bool
+	}{
+		{" 9/02", time.Date(2021, 9, 2, 0, 0, 0, 0, time.UTC), true},
+		{"10/01", time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC), true},
+		{"11/23", time.Date(2020, 11, 23, 0, 0, 0, 0, time.UTC), true},
+		{"", time.Time{}, false},
+		{"xx/yy", time.Time{}, false},
+	}
+	for _, c := range tests {
+		actual, ok := ParseArticleDate(c.input, ref)
+		if ok != c.expectedOK || !actual.Equal(c.expected) {
+			t.Errorf("ParseArticleDate(%q) = %v, %v, expected: %v, %v", c.input, actual, ok, c.expected, c.expectedOK)
+		}
+	}
+}
+
+type fakeTimedArticleRecord struct {
+	fakeArticleRecord
+	postedAt time.Time
+}
+
+func (r *fakeTimedArticleRecord) PostedAt() time.Time { return r.postedAt }
+
+func TestFilterBoardArticlesPostedAt(t *testing.T) {
+	db := newTestArticleRecordsDB([]ArticleRecord{
+		&fakeTimedArticleRecord{
+			fakeArticleRecord: fakeArticleRecord{filename: "A", modified: time.Date(2021, 5, 20, 0, 0, 0, 0, time.UTC)},
+			postedAt:          time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC),
+		},
+		&fakeTimedArticleRecord{
+			fakeArticleRecord: fakeArticleRecord{filename: "B", modified: time.Date(2021, 5, 20, 0, 0, 0, 0, time.UTC)},
+		},
+	})
+
+	recs, err := db.FilterBoardArticles("SYSOP", ArticleFilter{Since: time.Date(2021, 5, 10, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("FilterBoardArticles error: %v", err)
+	}
+	if actual := articleFilenames(recs); !reflect.DeepEqual(actual, []string{"B"}) {
+		t.Errorf("filter should use PostedAt and fall back to Modified, got: %v", actual)
+	}
+}
@@ -0,0 +1,49 @@
+package bbs
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+)
+
+// ResolveArticlePath returns the path of article file of ar in board. The
+// path is absolute if DB reads from disk, or the name in fsys if DB is opened
+// with OpenFS. It returns ErrInvalidName if boardID or filename of ar is
+// empty or not a valid name.
+func (db *DB) ResolveArticlePath(boardID string, ar ArticleRecord) (string, error) {
+	if ar == nil {
+		return "", fmt.Errorf("%w: nil article record", ErrInvalidName)
+	}
+	filename := ar.Filename()
+	if boardID == "" || filename == "" {
+		return "", fmt.Errorf("%w: article %q in board %q", ErrInvalidName, filename, boardID)
+	}
+	if err := checkNames(boardID, filename); err != nil {
+		return "", err
+	}
+
+	path, err := db.boardArticleFilePath(boardID, filename)
+	if err != nil {
+		log.Println("bbs: GetBoardArticleFilePath error:", err)
+		return "", err
+	}
+	if db.fsys != nil {
+		return path, nil
+	}
+	return filepath.Abs(path)
+}
+
+// ResolveArticlePaths returns a map from filename to path of article files
+// of ars in board, see ResolveArticlePath. It fails on the first invalid
+// record.
+func (db *DB) ResolveArticlePaths(boardID string, ars []ArticleRecord) (map[string]string, error) {
+	ret := make(map[string]string, len(ars))
+	for _, ar := range ars {
+		path, err := db.ResolveArticlePath(boardID, ar)
+		if err != nil {
+			return nil, err
+		}
+		ret[ar.Filename()] = path
+	}
+	return ret, nil
+}
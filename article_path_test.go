@@ -0,0 +1,56 @@
+package bbs
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestResolveArticlePath(t *testing.T) {
+	c := &fakeUserArticleFileConnector{fakeUserArticleConnector: &fakeUserArticleConnector{}}
+	db := &DB{connector: c}
+
+	actual, err := db.ResolveArticlePath("SYSOP", &fakeArticleRecord{filename: "M.1599059246.A.CF6"})
+	if err != nil {
+		t.Fatalf("ResolveArticlePath error: %v", err)
+	}
+	expected, _ := filepath.Abs("boards/SYSOP/M.1599059246.A.CF6")
+	if actual != expected || !filepath.IsAbs(actual) {
+		t.Errorf("ResolveArticlePath expected: %v, got: %v", expected, actual)
+	}
+
+	invalid := []struct {
+		boardID string
+		ar      ArticleRecord
+	}{
+		{"SYSOP", nil},
+		{"SYSOP", &fakeArticleRecord{filename: ""}},
+		{"", &fakeArticleRecord{filename: "M.1599059246.A.CF6"}},
+		{"SYSOP", &fakeArticleRecord{filename: "../../.PASSWDS"}},
+		{"../SYSOP", &fakeArticleRecord{filename: "M.1599059246.A.CF6"}},
+	}
+	for _, tt := range invalid {
+		if _, err := db.ResolveArticlePath(tt.boardID, tt.ar); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("ResolveArticlePath(%q, %v) should return ErrInvalidName, got: %v", tt.boardID, tt.ar, err)
+		}
+	}
+
+	db.fsys = fstest.MapFS{}
+	paths, err := db.ResolveArticlePaths("SYSOP", testArticleRecords[:2])
+	if err != nil {
+		t.Fatalf("ResolveArticlePaths error: %v", err)
+	}
+	expectedPaths := map[string]string{
+		testArticleRecords[0].Filename(): "boards/SYSOP/" + testArticleRecords[0].Filename(),
+		testArticleRecords[1].Filename(): "boards/SYSOP/" + testArticleRecords[1].Filename(),
+	}
+	if !reflect.DeepEqual(paths, expectedPaths) {
+		t.Errorf("ResolveArticlePaths expected: %v, got: %v", expectedPaths, paths)
+	}
+
+	if _, err := db.ResolveArticlePaths("SYSOP", []ArticleRecord{testArticleRecords[0], &fakeArticleRecord{}}); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("ResolveArticlePaths should return ErrInvalidName, got: %v", err)
+	}
+}
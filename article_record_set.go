@@ -0,0 +1,73 @@
+package bbs
+
+import (
+	"errors"
+	"io/fs"
+	"log"
+)
+
+// ArticleRecordSet is a read-only view of the article records in a records
+// file, such as .DIR of a board. Records may be decoded on access, so the
+// records file is not copied into memory at once. It must be closed after
+// use, and At must not be called after Close.
+type ArticleRecordSet interface {
+	// Len returns the number of records.
+	Len() int
+	// At returns the i-th record, it panics if i is out of range.
+	At(i int) ArticleRecord
+	// Close releases the resources of set, such as the memory mapping.
+	Close() error
+}
+
+// MmapConnector is a connector for bbs which supports memory-mapped article
+// records files, records are decoded on access instead of read at once.
+type MmapConnector interface {
+
+	// OpenArticleRecordSet should map the records file name and return the
+	// records in it, it should return ErrNotSupported if mmap is not
+	// available, so DB falls back to reading the whole file.
+	OpenArticleRecordSet(name string) (ArticleRecordSet, error)
+}
+
+// sliceArticleRecordSet is ArticleRecordSet of records read at once.
+type sliceArticleRecordSet []ArticleRecord
+
+func (s sliceArticleRecordSet) Len() int               { return len(s) }
+func (s sliceArticleRecordSet) At(i int) ArticleRecord { return s[i] }
+func (s sliceArticleRecordSet) Close() error           { return nil }
+
+// OpenBoardArticleRecordSet returns the article records of board without
+// copying the whole records file if connector implements MmapConnector,
+// which suits boards of tens of thousands of articles. It falls back to
+// the records read by ReadBoardArticleRecordsFile if mmap is not available,
+// DB is opened with WithFS, or deleted articles are skipped by
+// SetSkipDeletedArticles. The returned set must be closed, it may hold a
+// shared lock of the records file which blocks writers until closed.
+func (db *DB) OpenBoardArticleRecordSet(boardID string) (ArticleRecordSet, error) {
+	var mc MmapConnector
+	if db.fsys == nil && !db.skipDeletedArticles && db.connectorAs(&mc) {
+		path, err := db.boardArticleRecordsPath(boardID)
+		if err != nil {
+			log.Println("bbs: GetBoardArticleRecordsPath error:", err)
+			return nil, err
+		}
+		set, err := mc.OpenArticleRecordSet(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return sliceArticleRecordSet{}, nil
+		}
+		if err == nil {
+			db.countRead(articleRecordKind, 0)
+			return set, nil
+		}
+		if !errors.Is(err, ErrNotSupported) {
+			log.Println("bbs: OpenArticleRecordSet error:", err)
+			return nil, err
+		}
+	}
+
+	recs, err := db.ReadBoardArticleRecordsFile(boardID)
+	if err != nil {
+		return nil, err
+	}
+	return sliceArticleRecordSet(recs), nil
+}
@@ -0,0 +1,79 @@
+package bbs
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+type fakeArticleRecordSet struct {
+	recs   []ArticleRecord
+	closed bool
+}
+
+func (s *fakeArticleRecordSet) Len() int               { return len(s.recs) }
+func (s *fakeArticleRecordSet) At(i int) ArticleRecord { return s.recs[i] }
+func (s *fakeArticleRecordSet) Close() error           { s.closed = true; return nil }
+
+type fakeMmapConnector struct {
+	fakeConnector
+	set *fakeArticleRecordSet
+	err error
+}
+
+var _ MmapConnector = &fakeMmapConnector{}
+
+func (c *fakeMmapConnector) OpenArticleRecordSet(name string) (ArticleRecordSet, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.set, nil
+}
+
+func TestOpenBoardArticleRecordSet(t *testing.T) {
+	mapped := []ArticleRecord{&fakeArticleRecord{filename: "M.1.A.000"}, &fakeArticleRecord{filename: "M.2.A.000"}}
+	read := []ArticleRecord{&fakeArticleRecord{filename: "M.3.A.000"}}
+	c := &fakeMmapConnector{
+		fakeConnector: fakeConnector{
+			fakeGetBoardArticleRecordsPath: func() (string, error) { return ".DIR", nil },
+			fakeReadArticleRecordsFile:     func() ([]ArticleRecord, error) { return read, nil },
+		},
+		set: &fakeArticleRecordSet{recs: mapped},
+	}
+	db := &DB{connector: c}
+
+	set, err := db.OpenBoardArticleRecordSet("SYSOP")
+	if err != nil {
+		t.Fatalf("OpenBoardArticleRecordSet error: %v", err)
+	}
+	if set.Len() != 2 || set.At(1).Filename() != "M.2.A.000" {
+		t.Errorf("mapped records not match, got: %v", set)
+	}
+	if err := set.Close(); err != nil || !c.set.closed {
+		t.Errorf("Close should close mapped set, got: %v", err)
+	}
+
+	c.err = ErrNotSupported
+	set, err = db.OpenBoardArticleRecordSet("SYSOP")
+	if err != nil || set.Len() != 1 || set.At(0).Filename() != "M.3.A.000" || set.Close() != nil {
+		t.Errorf("fallback records expected: [M.3.A.000], <nil>, got: %v, %v", set, err)
+	}
+
+	c.err = os.ErrNotExist
+	set, err = db.OpenBoardArticleRecordSet("SYSOP")
+	if err != nil || set.Len() != 0 {
+		t.Errorf("records of missing file expected: [], <nil>, got: %v, %v", set, err)
+	}
+
+	errBroken := errors.New("broken")
+	c.err = errBroken
+	if _, err := db.OpenBoardArticleRecordSet("SYSOP"); !errors.Is(err, errBroken) {
+		t.Errorf("OpenBoardArticleRecordSet should return error of connector, got: %v", err)
+	}
+
+	db = &DB{connector: &c.fakeConnector}
+	set, err = db.OpenBoardArticleRecordSet("SYSOP")
+	if err != nil || set.Len() != 1 {
+		t.Errorf("records without MmapConnector expected: [M.3.A.000], <nil>, got: %v, %v", set, err)
+	}
+}
@@ -0,0 +1,63 @@
+package bbs
+
+import (
+	"log"
+	"strconv"
+	"strings"
+)
+
+// articleFilenameTime returns the unix timestamp embedded in article
+// filename such as "M.1607202752.A.6A5", ok is false if filename has none.
+func articleFilenameTime(filename string) (int64, bool) {
+	seg := strings.Split(filename, ".")
+	if len(seg) < 3 || (seg[0] != "M" && seg[0] != "G") {
+		return 0, false
+	}
+	ts, err := strconv.ParseInt(seg[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return ts, true
+}
+
+// ArticlesSince returns the article records in board added after the record
+// of sinceFilename, in .DIR order. Records are compared by the timestamp
+// embedded in their filenames, records without timestamp or with the same
+// timestamp are compared by their position in .DIR. All records are returned
+// if sinceFilename is not found.
+func (db *DB) ArticlesSince(boardID, sinceFilename string) ([]ArticleRecord, error) {
+	recs, err := db.ReadBoardArticleRecordsFile(boardID)
+	if err != nil {
+		log.Println("bbs: ReadBoardArticleRecordsFile error:", err)
+		return nil, err
+	}
+
+	since := -1
+	for i, r := range recs {
+		if r.Filename() == sinceFilename {
+			since = i
+			break
+		}
+	}
+	if since < 0 || sinceFilename == "" {
+		return recs, nil
+	}
+
+	sinceTime, sinceOK := articleFilenameTime(sinceFilename)
+	ret := []ArticleRecord{}
+	for i, r := range recs {
+		if i == since {
+			continue
+		}
+		t, ok := articleFilenameTime(r.Filename())
+		switch {
+		case ok && sinceOK && t != sinceTime:
+			if t > sinceTime {
+				ret = append(ret, r)
+			}
+		case i > since:
+			ret = append(ret, r)
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,51 @@
+package bbs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestArticlesSince(t *testing.T) {
+	recs := []ArticleRecord{
+		&fakeArticleRecord{filename: "M.1599059246.A.CF6"},
+		&fakeArticleRecord{filename: "M.1599059300.A.001"},
+		&fakeArticleRecord{filename: "M.1599059400.A.002"},
+		&fakeArticleRecord{filename: "M.1599059400.A.003"},
+		&fakeArticleRecord{filename: "M.1599059350.A.004"},
+		&fakeArticleRecord{filename: "M.1599059500.A.005"},
+	}
+	db := &DB{
+		connector: &fakeConnector{
+			fakeGetBoardArticleRecordsPath: func() (string, error) {
+				return "boards/S/SYSOP/.DIR", nil
+			},
+			fakeReadArticleRecordsFile: func() ([]ArticleRecord, error) {
+				return recs, nil
+			},
+		},
+	}
+
+	testCases := []struct {
+		since    string
+		expected []string
+	}{
+		{since: "M.1599059300.A.001", expected: []string{"M.1599059400.A.002", "M.1599059400.A.003", "M.1599059350.A.004", "M.1599059500.A.005"}},
+		{since: "M.1599059400.A.002", expected: []string{"M.1599059400.A.003", "M.1599059500.A.005"}},
+		{since: "M.1599059500.A.005", expected: []string{}},
+		{since: "M.1599059999.A.FFF", expected: []string{"M.1599059246.A.CF6", "M.1599059300.A.001", "M.1599059400.A.002", "M.1599059400.A.003", "M.1599059350.A.004", "M.1599059500.A.005"}},
+		{since: "", expected: []string{"M.1599059246.A.CF6", "M.1599059300.A.001", "M.1599059400.A.002", "M.1599059400.A.003", "M.1599059350.A.004", "M.1599059500.A.005"}},
+	}
+	for _, c := range testCases {
+		actual, err := db.ArticlesSince("SYSOP", c.since)
+		if err != nil {
+			t.Fatalf("ArticlesSince error: %v", err)
+		}
+		filenames := []string{}
+		for _, r := range actual {
+			filenames = append(filenames, r.Filename())
+		}
+		if !reflect.DeepEqual(filenames, c.expected) {
+			t.Errorf("ArticlesSince(%q) expected: %v, got: %v", c.since, c.expected, filenames)
+		}
+	}
+}
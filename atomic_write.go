@@ -0,0 +1,44 @@
+package bbs
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic replaces file name with the data written by write. The
+// data is written to a temporary file in the same directory, which is synced
+// and renamed over name only if write succeeds, so a crash or error in the
+// middle leaves the original file intact. Drivers can use it to implement
+// overwriting methods such as UserArticleConnector.WriteUserArticleRecordFile.
+func WriteFileAtomic(name string, perm os.FileMode, write func(w io.Writer) error) (err error) {
+	dir, base := filepath.Split(name)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := ioutil.TempFile(dir, "."+base+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	if err = write(f); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Chmod(perm); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
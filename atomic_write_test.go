@@ -0,0 +1,59 @@
+package bbs
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atomic_write_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "articles")
+	if err := ioutil.WriteFile(name, []byte("original"), 0600); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+
+	// simulate a write interrupted in the middle.
+	errInterrupted := errors.New("interrupted")
+	err = WriteFileAtomic(name, 0644, func(w io.Writer) error {
+		if _, err := w.Write([]byte("partial")); err != nil {
+			return err
+		}
+		return errInterrupted
+	})
+	if !errors.Is(err, errInterrupted) {
+		t.Errorf("WriteFileAtomic should return write error, got: %v", err)
+	}
+	if data, _ := ioutil.ReadFile(name); string(data) != "original" {
+		t.Errorf("original file should be intact, got: %q", data)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Errorf("temporary file should be removed, got %d files", len(files))
+	}
+
+	err = WriteFileAtomic(name, 0644, func(w io.Writer) error {
+		_, err := w.Write([]byte("replaced"))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WriteFileAtomic error: %v", err)
+	}
+	if data, _ := ioutil.ReadFile(name); string(data) != "replaced" {
+		t.Errorf("file should be replaced, got: %q", data)
+	}
+	fi, err := os.Stat(name)
+	if err != nil {
+		t.Fatalf("stat error: %v", err)
+	}
+	if fi.Mode().Perm() != 0644 {
+		t.Errorf("file mode expected: 0644, got: %v", fi.Mode().Perm())
+	}
+}
@@ -0,0 +1,228 @@
+package bbs
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Attachment is a file embedded in article body, such as a photo encoded by
+// uuencode or base64.
+type Attachment struct {
+	Name     string
+	MIMEType string
+	Data     []byte
+}
+
+// ExtractAttachments returns the decoded attachments embedded in raw article
+// file, which are uuencode blocks (`begin 644 name` ... `end`), base64
+// blocks of `uuencode -m` (`begin-base64 644 name` ... `====`) and base64
+// parts with MIME headers. If some attachments are truncated or corrupt, the
+// ones which decode cleanly are returned with ErrBrokenAttachment describing
+// the broken ones.
+func ExtractAttachments(raw []byte) ([]Attachment, error) {
+	lines := strings.Split(strings.ReplaceAll(string(raw), "\r\n", "\n"), "\n")
+	ret := []Attachment{}
+	warnings := []string{}
+
+	for i := 0; i < len(lines); i++ {
+		var (
+			a    *Attachment
+			next int
+			err  error
+		)
+		switch {
+		case strings.HasPrefix(lines[i], "begin-base64 "):
+			a, next, err = decodeBase64Block(lines, i)
+		case isUuencodeBegin(lines[i]):
+			a, next, err = decodeUuencodeBlock(lines, i)
+		case isMIMEHeader(lines[i]):
+			a, next, err = decodeMIMEPart(lines, i)
+		default:
+			continue
+		}
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("line %d: %v", i+1, err))
+		} else if a != nil {
+			if a.MIMEType == "" {
+				a.MIMEType = attachmentMIMEType(a.Name, a.Data)
+			}
+			ret = append(ret, *a)
+		}
+		i = next
+	}
+
+	if len(warnings) != 0 {
+		return ret, fmt.Errorf("%w: %s", ErrBrokenAttachment, strings.Join(warnings, "; "))
+	}
+	return ret, nil
+}
+
+// attachmentMIMEType guesses MIME type by extension of name, and by content
+// of data if extension is unknown.
+func attachmentMIMEType(name string, data []byte) string {
+	if t := mime.TypeByExtension(strings.ToLower(path.Ext(name))); t != "" {
+		return t
+	}
+	return http.DetectContentType(data)
+}
+
+// beginName returns the file name of begin line such as `begin 644 a.jpg`.
+func beginName(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return ""
+	}
+	return strings.Join(fields[2:], " ")
+}
+
+func isUuencodeBegin(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || fields[0] != "begin" {
+		return false
+	}
+	for _, c := range fields[1] {
+		if c < '0' || c > '7' {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeUuencodeBlock decodes the uuencode block beginning at lines[begin],
+// and returns the index of its end line.
+func decodeUuencodeBlock(lines []string, begin int) (*Attachment, int, error) {
+	name := beginName(lines[begin])
+	data := []byte{}
+	done := false
+	for i := begin + 1; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		if line == "end" {
+			if !done {
+				return nil, i, fmt.Errorf("uuencode %q has no terminating line", name)
+			}
+			return &Attachment{Name: name, Data: data}, i, nil
+		}
+		if done {
+			continue
+		}
+		b, err := uudecodeLine(line)
+		if err != nil {
+			return nil, i, fmt.Errorf("uuencode %q: %v", name, err)
+		}
+		if b == nil {
+			done = true
+			continue
+		}
+		data = append(data, b...)
+	}
+	return nil, len(lines), fmt.Errorf("uuencode %q is truncated", name)
+}
+
+// uudecodeLine decodes one line of uuencode, it returns nil for the zero
+// length line which terminates data.
+func uudecodeLine(line string) ([]byte, error) {
+	if line == "" {
+		return nil, fmt.Errorf("empty line")
+	}
+	n := int(line[0]-' ') & 0x3f
+	if n == 0 {
+		return nil, nil
+	}
+	body := []byte(line[1:])
+	need := (n + 2) / 3 * 4
+	for len(body) < need {
+		// some encoders trim the trailing spaces
+		body = append(body, ' ')
+	}
+
+	out := make([]byte, 0, need/4*3)
+	for i := 0; i < need; i += 4 {
+		var c [4]byte
+		for k := range c {
+			if body[i+k] < ' ' || body[i+k] > '`' {
+				return nil, fmt.Errorf("invalid character %q", body[i+k])
+			}
+			c[k] = (body[i+k] - ' ') & 0x3f
+		}
+		out = append(out, c[0]<<2|c[1]>>4, c[1]<<4|c[2]>>2, c[2]<<6|c[3])
+	}
+	return out[:n], nil
+}
+
+// decodeBase64Block decodes the `uuencode -m` block beginning at
+// lines[begin], and returns the index of its terminating line.
+func decodeBase64Block(lines []string, begin int) (*Attachment, int, error) {
+	name := beginName(lines[begin])
+	var sb strings.Builder
+	for i := begin + 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "====" {
+			data, err := base64.StdEncoding.DecodeString(sb.String())
+			if err != nil {
+				return nil, i, fmt.Errorf("base64 %q: %v", name, err)
+			}
+			return &Attachment{Name: name, Data: data}, i, nil
+		}
+		sb.WriteString(line)
+	}
+	return nil, len(lines), fmt.Errorf("base64 %q is truncated", name)
+}
+
+func isMIMEHeader(line string) bool {
+	return strings.HasPrefix(strings.ToLower(line), "content-type:") ||
+		strings.HasPrefix(strings.ToLower(line), "content-transfer-encoding:") ||
+		strings.HasPrefix(strings.ToLower(line), "content-disposition:")
+}
+
+// decodeMIMEPart decodes the MIME part whose headers begin at lines[begin],
+// parts not encoded in base64 are skipped. It returns the index of the last
+// line of part.
+func decodeMIMEPart(lines []string, begin int) (*Attachment, int, error) {
+	header := map[string]string{}
+	i := begin
+	key := ""
+	for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+		line := lines[i]
+		if (line[0] == ' ' || line[0] == '\t') && key != "" {
+			header[key] += " " + strings.TrimSpace(line)
+			continue
+		}
+		kv := strings.SplitN(line, ":", 2)
+		if len(kv) != 2 {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(kv[0]))
+		header[key] = strings.TrimSpace(kv[1])
+	}
+	if !strings.EqualFold(header["content-transfer-encoding"], "base64") {
+		return nil, i, nil
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(header["content-type"])
+	name := params["name"]
+	if _, dparams, err := mime.ParseMediaType(header["content-disposition"]); err == nil && dparams["filename"] != "" {
+		name = dparams["filename"]
+	}
+
+	var sb strings.Builder
+	for i++; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" && sb.Len() != 0 {
+			break
+		}
+		if strings.HasPrefix(line, "--") {
+			i--
+			break
+		}
+		sb.WriteString(line)
+	}
+	data, err := base64.StdEncoding.DecodeString(sb.String())
+	if err != nil || sb.Len() == 0 {
+		return nil, i, fmt.Errorf("base64 part %q is truncated or corrupt", name)
+	}
+	return &Attachment{Name: name, MIMEType: mediaType, Data: data}, i, nil
+}
@@ -0,0 +1,103 @@
+package bbs
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// uuencode encodes data as `uuencode name` does.
+func uuencode(name string, data []byte) string {
+	enc := func(b byte) byte {
+		if b == 0 {
+			return '`'
+		}
+		return b + ' '
+	}
+	var sb strings.Builder
+	sb.WriteString("begin 644 " + name + "\n")
+	for len(data) > 0 {
+		n := len(data)
+		if n > 45 {
+			n = 45
+		}
+		chunk := append([]byte{}, data[:n]...)
+		for len(chunk)%3 != 0 {
+			chunk = append(chunk, 0)
+		}
+		sb.WriteByte(enc(byte(n)))
+		for i := 0; i < len(chunk); i += 3 {
+			sb.WriteByte(enc(chunk[i] >> 2))
+			sb.WriteByte(enc((chunk[i]<<4 | chunk[i+1]>>4) & 0x3f))
+			sb.WriteByte(enc((chunk[i+1]<<2 | chunk[i+2]>>6) & 0x3f))
+			sb.WriteByte(enc(chunk[i+2] & 0x3f))
+		}
+		sb.WriteByte('\n')
+		data = data[n:]
+	}
+	sb.WriteString("`\nend\n")
+	return sb.String()
+}
+
+func TestExtractAttachments(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0, 1, 2, 3, 0xff}, 30)...)
+	gif := append([]byte("GIF89a"), bytes.Repeat([]byte{7, 8, 9}, 20)...)
+	text := []byte("hello world\n")
+
+	raw := "作者: SYSOP (站長) 看板: Photo\n標題: [分享] 照片\n\n照片如下\n\n" +
+		uuencode("cat.png", png) +
+		"\n中間的文字\n\n" +
+		"begin-base64 644 dog\n" + base64.StdEncoding.EncodeToString(gif) + "\n====\n" +
+		"Content-Type: multipart/mixed; boundary=\"XX\"\n\n--XX\n" +
+		"Content-Type: text/plain; name=\"note.txt\"\nContent-Transfer-Encoding: base64\n\n" +
+		base64.StdEncoding.EncodeToString(text) + "\n--XX--\n" +
+		"--\n※ 發信站: 批踢踢實業坊(ptt.cc)\n"
+
+	atts, err := ExtractAttachments([]byte(raw))
+	if err != nil {
+		t.Fatalf("ExtractAttachments error: %v", err)
+	}
+	expected := []Attachment{
+		{Name: "cat.png", MIMEType: "image/png", Data: png},
+		{Name: "dog", MIMEType: "image/gif", Data: gif},
+		{Name: "note.txt", MIMEType: "text/plain", Data: text},
+	}
+	if len(atts) != len(expected) {
+		t.Fatalf("attachments length expected: %v, got: %v", len(expected), len(atts))
+	}
+	for i, a := range atts {
+		e := expected[i]
+		if a.Name != e.Name || a.MIMEType != e.MIMEType || !bytes.Equal(a.Data, e.Data) {
+			t.Errorf("attachment %d expected: %v %v %d bytes, got: %v %v %d bytes",
+				i, e.Name, e.MIMEType, len(e.Data), a.Name, a.MIMEType, len(a.Data))
+		}
+	}
+}
+
+func TestExtractAttachmentsBroken(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0, 1, 2, 3, 0xff}, 30)...)
+	encoded := uuencode("cat.png", png)
+	truncated := encoded[:len(encoded)/2]
+
+	raw := uuencode("ok.png", png) +
+		"begin-base64 644 bad.gif\n!!!!\n====\n" +
+		"begin 644 broken.png\n" + truncated[strings.Index(truncated, "\n")+1:]
+
+	atts, err := ExtractAttachments([]byte(raw))
+	if !errors.Is(err, ErrBrokenAttachment) {
+		t.Errorf("ExtractAttachments should return ErrBrokenAttachment, got: %v", err)
+	}
+	if err != nil && (!strings.Contains(err.Error(), "bad.gif") || !strings.Contains(err.Error(), "broken.png")) {
+		t.Errorf("error should name the broken attachments, got: %v", err)
+	}
+	if len(atts) != 1 || atts[0].Name != "ok.png" || !bytes.Equal(atts[0].Data, png) {
+		t.Errorf("attachments which decode cleanly should be returned, got: %v", atts)
+	}
+
+	atts, err = ExtractAttachments([]byte("沒有附件的文章\n"))
+	if err != nil || len(atts) != 0 {
+		t.Errorf("ExtractAttachments without attachment expected: [], <nil>, got: %v, %v", atts, err)
+	}
+}
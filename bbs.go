@@ -2,10 +2,15 @@ package bbs
 
 import (
 	"bufio"
+	"container/list"
 	"errors"
 	"fmt"
+	"io/fs"
 	"log"
+	"net"
+	"reflect"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -50,12 +55,51 @@ type BadPostUserRecord interface {
 	NumBadPosts() int
 }
 
+// Over18UserRecord return UserRecord interface which support IsOver18
+type Over18UserRecord interface {
+	// IsOver18 return true if user has declared to be an adult.
+	IsOver18() bool
+}
+
 // LastCountryUserRecord return UserRecord interface which support LastCountry
 type LastCountryUserRecord interface {
 	// LastLoginCountry will return the country with this user's last login IP
 	LastLoginCountry() string
 }
 
+// ParsedHostUserRecord return UserRecord interface which support LastHostIP
+type ParsedHostUserRecord interface {
+	// LastHostIP return LastHost parsed as net.IP, ok will be false when
+	// LastHost is a domain name or a partial host such as `140.112.`, which
+	// is stored by some bbs system for privacy.
+	LastHostIP() (ip net.IP, ok bool)
+}
+
+// LoginHistoryUserRecord return UserRecord interface which support recent
+// login hosts and times.
+type LoginHistoryUserRecord interface {
+	// LoginHosts return recent login hosts of user, most-recent-first. The
+	// first host is LastHost.
+	LoginHosts() []string
+	// LoginTimes return recent login times of user, most-recent-first, and
+	// LoginTimes()[i] is the time of logging in from LoginHosts()[i].
+	LoginTimes() []time.Time
+}
+
+// ContactUserRecord return UserRecord interface which support contact
+// information, methods return empty string if the field is not supported by
+// driver. Methods are prefixed with Contact so that records can keep
+// exported fields such as Email and Address, like pttbbs Userec.
+type ContactUserRecord interface {
+	// ContactEmail return email of user, which is used for registration
+	// verification usually.
+	ContactEmail() string
+	// ContactAddress return postal address of user.
+	ContactAddress() string
+	// ContactPhone return phone number of user.
+	ContactPhone() string
+}
+
 // MailboxUserRecord return UserRecord interface which support MailboxDescription
 type MailboxUserRecord interface {
 	// MailboxDescription will return the mailbox description with this user
@@ -71,9 +115,20 @@ const (
 
 )
 
+// IsSeparator returns true if t is FavoriteTypeLine, which is a dividing line
+// in favorite list. Title and BoardID of separators are meaningless, UI
+// should render a line at its position.
+func (t FavoriteType) IsSeparator() bool {
+	return t == FavoriteTypeLine
+}
+
+// FavoriteRecord is an entry in favorite list of user, records are returned
+// in the order of the list, including separator lines.
 type FavoriteRecord interface {
+	// Title is the title of folder.
 	Title() string
 	Type() FavoriteType
+	// BoardID is FavoriteTypeBoard only.
 	BoardID() string
 
 	// Records is FavoriteTypeFolder only.
@@ -137,11 +192,75 @@ type ArticleRecord interface {
 // DB is whole bbs filesystem, including where file store,
 // how to connect to local cache ( system V shared memory or etc.)
 // how to parse or store it's data to bianry
+//
+// DB is safe for concurrent use by multiple goroutines, a single DB should be
+// shared instead of opening one per request. Set methods such as SetDryRun
+// and SetWriteHook are not synchronized, call them before sharing DB.
 type DB struct {
+	// stats is the first field so its uint64 counters are 64-bit aligned for
+	// atomic operations on 32-bit platforms.
+	stats dbStats
+
 	connector Connector
+
+	// fsys is set when DB is opened with WithFS, paths returned by
+	// connector are names in fsys.
+	fsys fs.FS
+
+	// rawArticleFile disables decompression of gzipped article files.
+	rawArticleFile bool
+
+	// skipDeletedArticles makes ReadBoardArticleRecordsFile skip deleted
+	// article records.
+	skipDeletedArticles bool
+
+	// writeHook is called after board records are written successfully.
+	writeHook WriteHook
+
+	// moveHook is called after board records are moved successfully.
+	moveHook MoveHook
+
+	// dryRun makes board record writes only validate and log the change.
+	dryRun bool
+
+	// boardCollation compares strings for ReadBoardRecordsSorted, nil means
+	// byte order.
+	boardCollation func(a, b string) bool
+
+	// passwordScheme overrides VerifyPassword of user records if not nil.
+	passwordScheme PasswordScheme
+
+	// recordCodec is the codec of fork selected in Open, it decodes user
+	// records instead of connector if it is not Native.
+	recordCodec *RecordCodec
+
+	// pathOverride is consulted before Get*Path methods of connector if not
+	// nil.
+	pathOverride func(kind, id string) (string, bool)
+
+	// boardStatsCachePath is the cache file of PrecomputeBoardStats, empty
+	// means no cache.
+	boardStatsCachePath string
+
+	// location is the time zone of record times, nil means time.Local.
+	location *time.Location
+
+	// totalArticlesSkip is the lower-cased board ids skipped by
+	// TotalArticles, nil means ALLPOST only.
+	totalArticlesSkip map[string]bool
+
+	capabilitiesOnce sync.Once
+	capabilities     Capabilities
+
+	geoIPResolver func(net.IP) string
+	geoIPMutex    sync.Mutex
+	geoIPLRU      *list.List // of *geoIPCacheEntry, most recently used first
+	geoIPCache    map[string]*list.Element
 }
 
-// Driver should implement Connector interface
+// Driver should implement Connector interface. Methods of Connector may be
+// called concurrently, drivers should not share file offsets or other mutable
+// state between calls without locking.
 type Connector interface {
 	// Open provides the driver parameter settings, such as BBSHome parameter and SHM parameters.
 	Open(dataSourceName string) error
@@ -184,6 +303,13 @@ type WriteBoardConnector interface {
 	// file record in that file.
 	AddBoardRecordFileRecord(name string, brd BoardRecord) error
 
+	// AddBoardRecordFileRecords given record file name and new records, should
+	// append all records to that file in a single pass and return the index of
+	// the first appended record, counted under the same lock as the write.
+	// Drivers should document whether a partial failure leaves some records
+	// written.
+	AddBoardRecordFileRecords(name string, brds []BoardRecord) (uint, error)
+
 	// UpdateBoardRecordFileRecord update boardRecord brd on index in record file,
 	// index is start with 0
 	UpdateBoardRecordFileRecord(name string, index uint, brd BoardRecord) error
@@ -193,6 +319,62 @@ type WriteBoardConnector interface {
 
 	// RemoveBoardRecordFileRecord remove boardRecord brd on index in record file.
 	RemoveBoardRecordFileRecord(name string, index uint) error
+
+	// MoveBoardRecordFileRecord moves boardRecord on index from to index to in
+	// record file, records between them are shifted by one position. It
+	// should return ErrRecordNotFound if from or to is out of range.
+	MoveBoardRecordFileRecord(name string, from, to uint) error
+
+	// NumBoardRecordFileRecord returns the number of board records in record
+	// file, it is used to check index before reading or writing by index.
+	NumBoardRecordFileRecord(name string) (uint, error)
+}
+
+// Driver which implement WriteUserConnector supports adding user record.
+type WriteUserConnector interface {
+
+	// NewUserRecord return UserRecord object in this driver with arguments,
+	// password in arguments is plain text and should be hashed by driver.
+	NewUserRecord(args map[string]interface{}) (UserRecord, error)
+
+	// AddUserRecordFileRecord given record file name and new record, should
+	// write record to a free slot of that file or append it. It should check
+	// that the user id is not used and write under the same lock, and return
+	// ErrUserExists without writing otherwise.
+	AddUserRecordFileRecord(name string, u UserRecord) error
+}
+
+// Driver which implement MoneyConnector supports adjusting user money.
+type MoneyConnector interface {
+
+	// AdjustUserRecordFileMoney adds delta to the money of userID in record
+	// file name and returns the new balance. It should read and write the
+	// record under a lock, and return ErrMoneyOverflow without writing if the
+	// new balance overflows.
+	AdjustUserRecordFileMoney(name string, userID string, delta int) (int, error)
+}
+
+// Driver which implement BadPostConnector supports changing the number of
+// bad posts of users.
+type BadPostConnector interface {
+
+	// SetUserRecordFileNumBadPosts sets the number of bad posts of userID in
+	// record file name to n, negative n is set as 0.
+	SetUserRecordFileNumBadPosts(name string, userID string, n int) error
+
+	// AdjustUserRecordFileNumBadPosts adds delta to the number of bad posts
+	// of userID in record file name and returns the new number. It should
+	// read and write the record under a lock, and clamp the new number at 0.
+	AdjustUserRecordFileNumBadPosts(name string, userID string, delta int) (int, error)
+}
+
+// Driver which implement NumPostsConnector supports correcting the number of
+// posts of users.
+type NumPostsConnector interface {
+
+	// SetUserRecordFileNumPosts sets the number of posts of userID in record
+	// file name to n, negative n is set as 0.
+	SetUserRecordFileNumPosts(name string, userID string, n int) error
 }
 
 type WriteArticleConnector interface {
@@ -213,7 +395,9 @@ type UserArticleConnector interface {
 	// ReadUserArticleRecordFile should return the article record in file.
 	ReadUserArticleRecordFile(name string) ([]UserArticleRecord, error)
 
-	// WriteUserArticleRecordFile write user article records into file.
+	// WriteUserArticleRecordFile write user article records into file. It
+	// should replace the file atomically so a crash in the middle does not
+	// leave a truncated file, see WriteFileAtomic.
 	WriteUserArticleRecordFile(name string, records []UserArticleRecord) error
 
 	// AppendUserArticleRecordFile append user article records into file.
@@ -248,35 +432,269 @@ type UserDraftConnector interface {
 	DeleteUserDraft(name string) error
 }
 
-var drivers = make(map[string]Connector)
+// MailConnector is a connector for bbs which supports reading user mailbox.
+type MailConnector interface {
+
+	// GetUserMailboxPath should return the mail index file path of user,
+	// eg: BBSHome/home/{{u}}/{{userID}}/.DIR
+	GetUserMailboxPath(userID string) (string, error)
+
+	// GetUserMailFilePath should return the mail file path of user and filename,
+	// eg: BBSHome/home/{{u}}/{{userID}}/{{filename}}
+	GetUserMailFilePath(userID string, filename string) (string, error)
+
+	// ReadMailIndexFile should return MailRecord list in the file called name.
+	ReadMailIndexFile(name string) ([]MailRecord, error)
+
+	// ReadMailFile should return raw file of specific mail file name.
+	ReadMailFile(name string) ([]byte, error)
+}
+
+// StreamUserRecordsConnector is a connector for bbs which can read user
+// records one by one without reading the whole file into memory.
+type StreamUserRecordsConnector interface {
+	// EachUserRecordsFile should call fn with each UserRecord in file called
+	// name in order, and stop when fn returns false.
+	EachUserRecordsFile(name string, fn func(UserRecord) bool) error
+}
+
+// BoardFileConnector is a connector for bbs to access auxiliary files of
+// board other than articles, such as board description or post templates.
+type BoardFileConnector interface {
+	// GetBoardFilePath should return the file path of relPath in the
+	// directory of board, eg: BBSHome/boards/{{b}}/{{boardID}}/{{relPath}}
+	GetBoardFilePath(boardID string, relPath string) (string, error)
+}
+
+// BMIndexConnector is a connector for bbs which stores the boards moderated
+// by each user as an index.
+type BMIndexConnector interface {
+	// GetUserBMIndexPath should return the file path of the BM index of user.
+	GetUserBMIndexPath(userID string) (string, error)
+
+	// ReadBMIndexFile should return the board ids in BM index file called name.
+	ReadBMIndexFile(name string) ([]string, error)
+}
+
+// UtmpConnector is a connector for bbs which can read the online users
+// (utmp), it is usually stored in shared memory.
+type UtmpConnector interface {
+	// ReadUtmp should return the UtmpRecords of current online sessions.
+	ReadUtmp() ([]UtmpRecord, error)
+}
+
+// SHMBoardConnector is a connector for bbs which keeps the board headers in
+// shared memory indexed by board id (brdshm in pttbbs), so looking up a
+// board does not scan the board records file. The location of SHM is given
+// to driver as an argument of dataSourceName, in the uri format of
+// cache.NewCache, eg: `shmkey:1228` for System V SHM with key 1228 (SHM_KEY
+// of pttbbs), or `file:/tmp/ramdisk/bbs.shm` for a memory mapped file.
+type SHMBoardConnector interface {
+	// LookupBoardSHM should return the BoardRecord of boardID in SHM and
+	// true, or false if the board is not in SHM or SHM is not attached.
+	LookupBoardSHM(boardID string) (BoardRecord, bool, error)
+}
+
+// RecordSizer is implemented by connector whose user, board and article
+// record files are arrays of fixed size records.
+type RecordSizer interface {
+	// UserRecordSize should return the size in bytes of one user record.
+	UserRecordSize() int
+
+	// BoardRecordSize should return the size in bytes of one board record.
+	BoardRecordSize() int
+
+	// ArticleRecordSize should return the size in bytes of one article record.
+	ArticleRecordSize() int
+}
+
+// RecordDecoder is implemented by connector which can decode one fixed size
+// record, DB uses it with RecordSizer to read a range of records without
+// reading the whole file.
+type RecordDecoder interface {
+	// DecodeUserRecord should decode one user record from data.
+	DecodeUserRecord(data []byte) (UserRecord, error)
+
+	// DecodeBoardRecord should decode one board record from data.
+	DecodeBoardRecord(data []byte) (BoardRecord, error)
+
+	// DecodeArticleRecord should decode one article record from data.
+	DecodeArticleRecord(data []byte) (ArticleRecord, error)
+}
+
+// ConnectorWrapper is implemented by connector which wraps another connector,
+// such as the connector returned by WithCache. DB looks up optional connector
+// interfaces through Unwrap when the wrapper does not implement them.
+type ConnectorWrapper interface {
+	// Unwrap returns the wrapped connector.
+	Unwrap() Connector
+}
+
+// ConnectorFactory is implemented by registered connectors which keep the
+// settings of a DB, such as the lock timeout. Open opens a new connector
+// returned by NewConnector for each DB instead of the registered one, so DBs
+// opened with the same driver do not share settings.
+type ConnectorFactory interface {
+	// NewConnector returns a new connector which is not opened yet.
+	NewConnector() Connector
+}
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]Connector)
+)
 
 func Register(drivername string, connector Connector) {
-	// TODO: Mutex
+	driversMu.Lock()
+	defer driversMu.Unlock()
 	drivers[drivername] = connector
 }
 
-// Open opan a
-func Open(drivername string, dataSourceName string) (*DB, error) {
+// Unregister removes the driver registered as drivername, it is primarily
+// for tests which register fake drivers.
+func Unregister(drivername string) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	delete(drivers, drivername)
+}
+
+// UnregisterAll removes all registered drivers, including the drivers
+// registered by importing driver packages. It is primarily for test
+// teardown.
+func UnregisterAll() {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	drivers = make(map[string]Connector)
+}
 
+// driver returns the connector registered as drivername.
+func driver(drivername string) (Connector, bool) {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
 	c, ok := drivers[drivername]
+	return c, ok
+}
+
+// newDriverConnector returns the connector for a new DB of driver registered
+// as drivername, see ConnectorFactory.
+func newDriverConnector(drivername string) (Connector, bool) {
+	c, ok := driver(drivername)
+	if !ok {
+		return nil, false
+	}
+	if f, ok := c.(ConnectorFactory); ok {
+		return f.NewConnector(), true
+	}
+	return c, true
+}
+
+// Open opens DB with driver registered as drivername. If dataSourceName has
+// a "fork" argument, such as `file:///home/bbs?fork=pttbbs`, user records are
+// decoded by the RecordCodec of the fork registered for driver unless it is
+// Native, and ErrNotSupported is returned if there is no such codec. If the
+// registered connector implements ConnectorFactory, DB uses a connector of
+// its own.
+func Open(drivername string, dataSourceName string, opts ...OpenOption) (*DB, error) {
+
+	c, ok := newDriverConnector(drivername)
 	if !ok {
 		return nil, fmt.Errorf("bbs: drivername: %v not found", drivername)
 	}
 
-	err := c.Open(dataSourceName)
+	var codec *RecordCodec
+	if fork := dataSourceFork(dataSourceName); fork != "" {
+		codec, ok = recordCodec(drivername, fork)
+		if !ok {
+			return nil, fmt.Errorf("bbs: drivername: %v fork: %v: %w", drivername, fork, ErrNotSupported)
+		}
+	}
+
+	db, err := OpenConnector(c, dataSourceName, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("bbs: drivername: %v open error: %v", drivername, err)
 	}
+	db.recordCodec = codec
+	return db, nil
+}
+
+// OpenConnector opens c with dataSourceName and returns DB using c, c does
+// not need to be registered. It allows callers to inject a configured or
+// wrapped connector, such as the connector returned by WithCache.
+func OpenConnector(c Connector, dataSourceName string, opts ...OpenOption) (*DB, error) {
+	if c == nil {
+		return nil, fmt.Errorf("bbs: connector is nil")
+	}
+
+	err := c.Open(dataSourceName)
+	if err != nil {
+		return nil, err
+	}
 
-	return &DB{
+	db := &DB{
 		connector: c,
-	}, nil
+	}
+	for _, opt := range opts {
+		if err := opt(db); err != nil {
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// connectorAs finds the first connector in the wrapped connector chain that
+// implements the interface which target points to, and sets target to it.
+// target must be a non-nil pointer to an interface type, like errors.As.
+func (db *DB) connectorAs(target interface{}) bool {
+	return connectorAs(db.connector, target)
+}
+
+// baseConnector returns the last connector of the wrapped connector chain,
+// which reads records from file without cache.
+func (db *DB) baseConnector() Connector {
+	c := db.connector
+	for {
+		w, ok := c.(ConnectorWrapper)
+		if !ok {
+			return c
+		}
+		c = w.Unwrap()
+	}
+}
+
+// connectorAs is DB.connectorAs for the connector chain starting from c.
+func connectorAs(c Connector, target interface{}) bool {
+	val := reflect.ValueOf(target)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Interface {
+		panic("bbs: connectorAs target must be a non-nil pointer to an interface")
+	}
+	targetType := val.Type().Elem()
+
+	for c != nil {
+		if reflect.TypeOf(c).AssignableTo(targetType) {
+			val.Elem().Set(reflect.ValueOf(c))
+			return true
+		}
+		w, ok := c.(ConnectorWrapper)
+		if !ok {
+			return false
+		}
+		c = w.Unwrap()
+	}
+	return false
 }
 
-// ReadUserRecords returns the UserRecords
+// ReadUserRecords returns the UserRecords, they are read by
+// ReadUserRecordsRange if connector implements RecordSizer and
+// RecordDecoder and is not wrapped by WithCache.
 func (db *DB) ReadUserRecords() ([]UserRecord, error) {
+	if db.userCodec() != nil {
+		return db.readUserRecordsWithCodec(0, 0)
+	}
+	if _, _, ok := db.fullReadRangeConnector(); ok {
+		return db.ReadUserRecordsRange(0, 0)
+	}
 
-	path, err := db.connector.GetUserRecordsPath()
+	path, err := db.userRecordsPath()
 	if err != nil {
 		log.Println("bbs: open file error:", err)
 		return nil, err
@@ -288,13 +706,14 @@ func (db *DB) ReadUserRecords() ([]UserRecord, error) {
 		log.Println("bbs: get user rec error:", err)
 		return nil, err
 	}
+	db.countRecordsRead(userRecordKind, len(userRecs))
 	return userRecs, nil
 }
 
 // ReadUserFavoriteRecords returns the FavoriteRecord for specific userID
 func (db *DB) ReadUserFavoriteRecords(userID string) ([]FavoriteRecord, error) {
 
-	path, err := db.connector.GetUserFavoriteRecordsPath(userID)
+	path, err := db.userFavoriteRecordsPath(userID)
 	if err != nil {
 		log.Println("bbs: get user favorite records path error:", err)
 		return nil, err
@@ -310,10 +729,14 @@ func (db *DB) ReadUserFavoriteRecords(userID string) ([]FavoriteRecord, error) {
 
 }
 
-// ReadBoardRecords returns the UserRecords
+// ReadBoardRecords returns the UserRecords, see ReadUserRecords for when
+// they are read by ReadBoardRecordsRange.
 func (db *DB) ReadBoardRecords() ([]BoardRecord, error) {
+	if _, _, ok := db.fullReadRangeConnector(); ok {
+		return db.ReadBoardRecordsRange(0, 0)
+	}
 
-	path, err := db.connector.GetBoardRecordsPath()
+	path, err := db.boardRecordsPath()
 	if err != nil {
 		log.Println("bbs: open file error:", err)
 		return nil, err
@@ -325,12 +748,20 @@ func (db *DB) ReadBoardRecords() ([]BoardRecord, error) {
 		log.Println("bbs: get user rec error:", err)
 		return nil, err
 	}
+	db.countRecordsRead(boardRecordKind, len(recs))
 	return recs, nil
 }
 
 func (db *DB) ReadBoardArticleRecordsFile(boardID string) ([]ArticleRecord, error) {
+	if rs, rd, ok := db.fullReadRangeConnector(); ok {
+		recs, err := db.readBoardArticleRecordsRange(boardID, rs, rd, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		return db.filterDeletedArticles(recs), nil
+	}
 
-	path, err := db.connector.GetBoardArticleRecordsPath(boardID)
+	path, err := db.boardArticleRecordsPath(boardID)
 	if err != nil {
 		log.Println("bbs: open file error:", err)
 		return nil, err
@@ -345,13 +776,17 @@ func (db *DB) ReadBoardArticleRecordsFile(boardID string) ([]ArticleRecord, erro
 		log.Println("bbs: ReadArticleRecordsFile error:", err)
 		return nil, err
 	}
-	return recs, nil
+	db.countRecordsRead(articleRecordKind, len(recs))
+	return db.filterDeletedArticles(recs), nil
 
 }
 
 func (db *DB) ReadBoardTreasureRecordsFile(boardID string, treasureID []string) ([]ArticleRecord, error) {
+	if err := checkNames(treasureID...); err != nil {
+		return nil, err
+	}
 
-	path, err := db.connector.GetBoardTreasureRecordsPath(boardID, treasureID)
+	path, err := db.boardTreasureRecordsPath(boardID, treasureID)
 	if err != nil {
 		log.Println("bbs: open file error:", err)
 		return nil, err
@@ -363,12 +798,16 @@ func (db *DB) ReadBoardTreasureRecordsFile(boardID string, treasureID []string)
 		log.Println("bbs: get user rec error:", err)
 		return nil, err
 	}
+	db.countRecordsRead(articleRecordKind, len(recs))
 	return recs, nil
 }
 
 func (db *DB) ReadBoardArticleFile(boardID string, filename string) ([]byte, error) {
+	if err := checkNames(filename); err != nil {
+		return nil, err
+	}
 
-	path, err := db.connector.GetBoardArticleFilePath(boardID, filename)
+	path, err := db.boardArticleFilePath(boardID, filename)
 	if err != nil {
 		log.Println("bbs: open file error:", err)
 		return nil, err
@@ -380,12 +819,19 @@ func (db *DB) ReadBoardArticleFile(boardID string, filename string) ([]byte, err
 		log.Println("bbs: get user rec error:", err)
 		return nil, err
 	}
-	return recs, nil
+	db.countRead(articleRecordKind, len(recs))
+	return db.decompressArticleFile(path, recs)
 }
 
 func (db *DB) ReadBoardTreasureFile(boardID string, treasuresID []string, filename string) ([]byte, error) {
+	if err := checkNames(treasuresID...); err != nil {
+		return nil, err
+	}
+	if err := checkNames(filename); err != nil {
+		return nil, err
+	}
 
-	path, err := db.connector.GetBoardTreasureFilePath(boardID, treasuresID, filename)
+	path, err := db.boardTreasureFilePath(boardID, treasuresID, filename)
 	if err != nil {
 		log.Println("bbs: open file error:", err)
 		return nil, err
@@ -397,70 +843,290 @@ func (db *DB) ReadBoardTreasureFile(boardID string, treasuresID []string, filena
 		log.Println("bbs: get user rec error:", err)
 		return nil, err
 	}
-	return recs, nil
+	db.countRead(articleRecordKind, len(recs))
+	return db.decompressArticleFile(path, recs)
 }
 
 func (db *DB) NewBoardRecord(args map[string]interface{}) (BoardRecord, error) {
-	return db.connector.(WriteBoardConnector).NewBoardRecord(args)
+	var wbc WriteBoardConnector
+	if !db.connectorAs(&wbc) {
+		return nil, ErrNotSupported
+	}
+	return wbc.NewBoardRecord(args)
 }
 
 func (db *DB) AddBoardRecord(brd BoardRecord) error {
 
-	path, err := db.connector.GetBoardRecordsPath()
+	var wbc WriteBoardConnector
+	if !db.connectorAs(&wbc) {
+		return ErrNotSupported
+	}
+
+	path, err := db.boardRecordsPath()
 	if err != nil {
 		log.Println("bbs: open file error:", err)
 		return err
 	}
 	log.Println("path:", path)
 
-	err = db.connector.(WriteBoardConnector).AddBoardRecordFileRecord(path, brd)
+	if db.dryRun {
+		index, err := wbc.NumBoardRecordFileRecord(path)
+		if err != nil {
+			log.Println("bbs: NumBoardRecordFileRecord error:", err)
+			return err
+		}
+		return db.dryRunBoardRecord(WriteOpAdd, path, index, brd)
+	}
+
+	// Append through AddBoardRecordFileRecords which returns the index
+	// decided by the write itself.
+	index, err := wbc.AddBoardRecordFileRecords(path, []BoardRecord{brd})
 	if err != nil {
-		log.Println("bbs: AddBoardRecordFileRecord error:", err)
+		log.Println("bbs: AddBoardRecordFileRecords error:", err)
 		return err
 	}
+	db.countWrite(boardRecordKind)
+	db.callWriteHook(WriteOpAdd, path, index, brd)
+	return nil
+}
+
+// AddBoardRecords appends brds to the board records file in a single pass,
+// which is much faster than calling AddBoardRecord for each record. Whether
+// a failed write leaves some of brds written depends on driver, pttbbs
+// writes all or nothing.
+func (db *DB) AddBoardRecords(brds []BoardRecord) error {
+	var wbc WriteBoardConnector
+	if !db.connectorAs(&wbc) {
+		return ErrNotSupported
+	}
+
+	path, err := db.boardRecordsPath()
+	if err != nil {
+		log.Println("bbs: open file error:", err)
+		return err
+	}
+
+	if db.dryRun {
+		n, err := wbc.NumBoardRecordFileRecord(path)
+		if err != nil {
+			log.Println("bbs: NumBoardRecordFileRecord error:", err)
+			return err
+		}
+		for i, brd := range brds {
+			if err := db.dryRunBoardRecord(WriteOpAdd, path, n+uint(i), brd); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	n, err := wbc.AddBoardRecordFileRecords(path, brds)
+	if err != nil {
+		log.Println("bbs: AddBoardRecordFileRecords error:", err)
+		return err
+	}
+	db.countWrite(boardRecordKind)
+	for i, brd := range brds {
+		db.callWriteHook(WriteOpAdd, path, n+uint(i), brd)
+	}
 	return nil
 }
 
 // UpdateBoardRecordFileRecord update boardRecord brd on index in record file,
 // index is start with 0
 func (db *DB) UpdateBoardRecord(index uint, brd *BoardRecord) error {
-	return fmt.Errorf("not implement")
+	if brd == nil {
+		return fmt.Errorf("bbs: brd must not be nil")
+	}
+
+	var wbc WriteBoardConnector
+	if !db.connectorAs(&wbc) {
+		return ErrNotSupported
+	}
+
+	path, err := db.boardRecordsPath()
+	if err != nil {
+		log.Println("bbs: open file error:", err)
+		return err
+	}
+
+	if err := db.checkBoardRecordIndex(wbc, path, index); err != nil {
+		return err
+	}
+
+	if db.dryRun {
+		return db.dryRunBoardRecord(WriteOpUpdate, path, index, *brd)
+	}
+
+	err = wbc.UpdateBoardRecordFileRecord(path, index, *brd)
+	if err != nil {
+		log.Println("bbs: UpdateBoardRecordFileRecord error:", err)
+		return err
+	}
+	db.countWrite(boardRecordKind)
+	db.callWriteHook(WriteOpUpdate, path, index, *brd)
+	return nil
 }
 
 // ReadBoardRecordFileRecord return boardRecord brd on index in record file.
 func (db *DB) ReadBoardRecord(index uint) (*BoardRecord, error) {
-	return nil, fmt.Errorf("not implement")
+	var wbc WriteBoardConnector
+	if !db.connectorAs(&wbc) {
+		return nil, ErrNotSupported
+	}
+
+	path, err := db.boardRecordsPath()
+	if err != nil {
+		log.Println("bbs: open file error:", err)
+		return nil, err
+	}
+
+	if err := db.checkBoardRecordIndex(wbc, path, index); err != nil {
+		return nil, err
+	}
+
+	brd, err := wbc.ReadBoardRecordFileRecord(path, index)
+	if err != nil {
+		log.Println("bbs: ReadBoardRecordFileRecord error:", err)
+		return nil, err
+	}
+	db.countRecordsRead(boardRecordKind, 1)
+	return &brd, nil
 }
 
 // RemoveBoardRecordFileRecord remove boardRecord brd on index in record file.
 func (db *DB) RemoveBoardRecord(index uint) error {
-	return fmt.Errorf("not implement")
+	var wbc WriteBoardConnector
+	if !db.connectorAs(&wbc) {
+		return ErrNotSupported
+	}
+
+	path, err := db.boardRecordsPath()
+	if err != nil {
+		log.Println("bbs: open file error:", err)
+		return err
+	}
+
+	if err := db.checkBoardRecordIndex(wbc, path, index); err != nil {
+		return err
+	}
+
+	if db.dryRun {
+		return db.dryRunBoardRecord(WriteOpRemove, path, index, nil)
+	}
+
+	err = wbc.RemoveBoardRecordFileRecord(path, index)
+	if err != nil {
+		log.Println("bbs: RemoveBoardRecordFileRecord error:", err)
+		return err
+	}
+	db.countWrite(boardRecordKind)
+	db.callWriteHook(WriteOpRemove, path, index, nil)
+	return nil
+}
+
+// MoveBoardRecord moves board record on index from to index to, records
+// between them are shifted by one position and other records keep their
+// indices.
+func (db *DB) MoveBoardRecord(from, to uint) error {
+	var wbc WriteBoardConnector
+	if !db.connectorAs(&wbc) {
+		return ErrNotSupported
+	}
+
+	path, err := db.boardRecordsPath()
+	if err != nil {
+		log.Println("bbs: open file error:", err)
+		return err
+	}
+
+	if err := db.checkBoardRecordIndex(wbc, path, from); err != nil {
+		return err
+	}
+	if err := db.checkBoardRecordIndex(wbc, path, to); err != nil {
+		return err
+	}
+
+	if db.dryRun {
+		return db.dryRunMoveBoardRecord(path, from, to)
+	}
+
+	err = wbc.MoveBoardRecordFileRecord(path, from, to)
+	if err != nil {
+		log.Println("bbs: MoveBoardRecordFileRecord error:", err)
+		return err
+	}
+	db.countWrite(boardRecordKind)
+	db.callWriteHook(WriteOpMove, path, to, nil)
+	db.callMoveHook(path, from, to)
+	return nil
+}
+
+// checkBoardRecordIndex returns ErrRecordNotFound if index is out of range of
+// board records in path.
+func (db *DB) checkBoardRecordIndex(wbc WriteBoardConnector, path string, index uint) error {
+	n, err := wbc.NumBoardRecordFileRecord(path)
+	if err != nil {
+		log.Println("bbs: NumBoardRecordFileRecord error:", err)
+		return err
+	}
+	if index >= n {
+		return fmt.Errorf("%w: index %d of %d board records", ErrRecordNotFound, index, n)
+	}
+	return nil
 }
 
+// NewArticleRecord creates the article file with args and returns its
+// ArticleRecord, see WriteArticleConnector. The cached article file of the
+// same path, see WithArticleCache, is invalidated.
 func (db *DB) NewArticleRecord(args map[string]interface{}) (ArticleRecord, error) {
-	return db.connector.(WriteArticleConnector).NewArticleRecord(args)
+	var wac WriteArticleConnector
+	if !db.connectorAs(&wac) {
+		return nil, ErrNotSupported
+	}
+	rec, err := wac.NewArticleRecord(args)
+	if err != nil {
+		return nil, err
+	}
+	if boardID, ok := args["board_id"].(string); ok {
+		if p, err := db.boardArticleFilePath(boardID, rec.Filename()); err == nil {
+			db.invalidateCache(articleFileCacheKey(p))
+		}
+	}
+	return rec, nil
 }
 
 func (db *DB) AddArticleRecordFileRecord(boardID string, article ArticleRecord) error {
 
-	path, err := db.connector.GetBoardArticleRecordsPath(boardID)
+	var wac WriteArticleConnector
+	if !db.connectorAs(&wac) {
+		return ErrNotSupported
+	}
+
+	path, err := db.boardArticleRecordsPath(boardID)
 	if err != nil {
 		log.Println("bbs: open file error:", err)
 		return err
 	}
 	log.Println("path:", path)
 
-	return db.connector.(WriteArticleConnector).AddArticleRecordFileRecord(path, article)
+	err = wac.AddArticleRecordFileRecord(path, article)
+	if err != nil {
+		log.Println("bbs: AddArticleRecordFileRecord error:", err)
+		return err
+	}
+	db.countWrite(articleRecordKind)
+	return nil
 }
 
 // GetUserArticleRecordFile returns aritcle file which user posted.
 func (db *DB) GetUserArticleRecordFile(userID string) ([]UserArticleRecord, error) {
 
 	recs := []UserArticleRecord{}
-	uac, ok := db.connector.(UserArticleConnector)
-	if ok {
+	var uac UserArticleConnector
+	if db.connectorAs(&uac) {
 
-		path, err := uac.GetUserArticleRecordsPath(userID)
+		path, err := db.userArticleRecordsPath(uac, userID)
 		if err != nil {
 			log.Println("bbs: open file error:", err)
 			return nil, err
@@ -478,6 +1144,13 @@ func (db *DB) GetUserArticleRecordFile(userID string) ([]UserArticleRecord, erro
 
 	}
 
+	return db.scanUserArticleRecords(userID)
+}
+
+// scanUserArticleRecords returns the articles of userID by scanning .DIR of
+// all boards except ALLPOST, in .BRD and .DIR order.
+func (db *DB) scanUserArticleRecords(userID string) ([]UserArticleRecord, error) {
+	recs := []UserArticleRecord{}
 	boardRecords, err := db.ReadBoardRecords()
 	if err != nil {
 		log.Println("bbs: ReadBoardRecords error:", err)
@@ -485,7 +1158,7 @@ func (db *DB) GetUserArticleRecordFile(userID string) ([]UserArticleRecord, erro
 	}
 
 	shouldSkip := func(boardID string) bool {
-		if boardID == "ALLPOST" {
+		if boardID == AllPostBoardID {
 			return true
 		}
 		return false
@@ -504,13 +1177,7 @@ func (db *DB) GetUserArticleRecordFile(userID string) ([]UserArticleRecord, erro
 		for _, ar := range ars {
 			if ar.Owner() == userID {
 				log.Println("board: ", r.BoardID(), len(recs))
-				r := userArticleRecord{
-					"board_id":   r.BoardID(),
-					"title":      ar.Title(),
-					"owner":      ar.Owner(),
-					"article_id": ar.Filename(),
-				}
-				recs = append(recs, r)
+				recs = append(recs, NewUserArticleRecord(r.BoardID(), ar.Title(), ar.Owner(), ar.Filename()))
 			}
 		}
 	}
@@ -523,9 +1190,9 @@ func (db *DB) GetUserArticleRecordFile(userID string) ([]UserArticleRecord, erro
 func (db *DB) GetUserCommentRecordFile(userID string) ([]UserCommentRecord, error) {
 
 	recs := []UserCommentRecord{}
-	ucc, ok := db.connector.(UserCommentConnector)
-	if ok {
-		path, err := ucc.GetUserCommentRecordsPath(userID)
+	var ucc UserCommentConnector
+	if db.connectorAs(&ucc) {
+		path, err := db.userCommentRecordsPath(ucc, userID)
 		if err != nil {
 			log.Println("bbs: open file error:", err)
 			return nil, err
@@ -636,15 +1303,23 @@ func (db *DB) GetBoardArticleCommentRecords(boardID, filename string) (crs []Use
 }
 
 func (db *DB) GetUserDrafts(userID, draftID string) (UserDraft, error) {
+	if err := checkNames(draftID); err != nil {
+		return nil, err
+	}
+
+	var udc UserDraftConnector
+	if !db.connectorAs(&udc) {
+		return nil, ErrNotSupported
+	}
 
-	path, err := db.connector.(UserDraftConnector).GetUserDraftPath(userID, draftID)
+	path, err := db.userDraftPath(udc, userID, draftID)
 	if err != nil {
 		log.Println("bbs: GetUserDraftPath error:", err)
 		return nil, err
 	}
 	log.Println("path:", path)
 
-	raw, err := db.connector.(UserDraftConnector).ReadUserDraft(path)
+	raw, err := udc.ReadUserDraft(path)
 	if err != nil {
 		return nil, err
 	}
@@ -653,13 +1328,21 @@ func (db *DB) GetUserDrafts(userID, draftID string) (UserDraft, error) {
 }
 
 func (db *DB) DeleteUserDraft(userID, draftID string) error {
+	if err := checkNames(draftID); err != nil {
+		return err
+	}
+
+	var udc UserDraftConnector
+	if !db.connectorAs(&udc) {
+		return ErrNotSupported
+	}
 
-	path, err := db.connector.(UserDraftConnector).GetUserDraftPath(userID, draftID)
+	path, err := db.userDraftPath(udc, userID, draftID)
 	if err != nil {
 		log.Println("bbs: GetUserDraftPath error:", err)
 		return err
 	}
 	log.Println("path:", path)
 
-	return db.connector.(UserDraftConnector).DeleteUserDraft(path)
+	return udc.DeleteUserDraft(path)
 }
@@ -2,13 +2,17 @@ package bbs
 
 import (
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetBoardArticleCommentRecords(t *testing.T) {
 
-	anyBoardID := ""
+	anyBoardID := "SYSOP"
 	anyFilename := ""
 	articleContent := `
 a740 aacc 3a20 5359 534f 5020 28af ab29
@@ -177,3 +181,158 @@ func hexToByte(input string) []byte {
 	b, _ := hex.DecodeString(s)
 	return b
 }
+
+type fakeUserRecord struct {
+	userID       string
+	password     string
+	nickname     string
+	realName     string
+	numLoginDays int
+	numPosts     int
+	money        int
+	lastLogin    time.Time
+	lastHost     string
+	userFlag     uint32
+}
+
+var _ UserRecord = &fakeUserRecord{}
+
+func (u *fakeUserRecord) UserID() string                       { return u.userID }
+func (u *fakeUserRecord) HashedPassword() string               { return u.password }
+func (u *fakeUserRecord) VerifyPassword(password string) error { return nil }
+func (u *fakeUserRecord) Nickname() string                     { return u.nickname }
+func (u *fakeUserRecord) RealName() string                     { return u.realName }
+func (u *fakeUserRecord) NumLoginDays() int                    { return u.numLoginDays }
+func (u *fakeUserRecord) NumPosts() int                        { return u.numPosts }
+func (u *fakeUserRecord) Money() int                           { return u.money }
+func (u *fakeUserRecord) LastLogin() time.Time                 { return u.lastLogin }
+func (u *fakeUserRecord) LastHost() string                     { return u.lastHost }
+func (u *fakeUserRecord) UserFlag() uint32                     { return u.userFlag }
+
+type fakeBoardRecord struct {
+	boardID string
+	title   string
+	isClass bool
+	classID string
+	bm      []string
+}
+
+var _ BoardRecord = &fakeBoardRecord{}
+
+func (b *fakeBoardRecord) BoardID() string { return b.boardID }
+func (b *fakeBoardRecord) Title() string   { return b.title }
+func (b *fakeBoardRecord) IsClass() bool   { return b.isClass }
+func (b *fakeBoardRecord) ClassID() string { return b.classID }
+func (b *fakeBoardRecord) BM() []string    { return b.bm }
+
+type fakeArticleRecord struct {
+	filename  string
+	modified  time.Time
+	recommend int
+	date      string
+	title     string
+	money     int
+	owner     string
+}
+
+var _ ArticleRecord = &fakeArticleRecord{}
+
+func (a *fakeArticleRecord) Filename() string    { return a.filename }
+func (a *fakeArticleRecord) Modified() time.Time { return a.modified }
+func (a *fakeArticleRecord) Recommend() int      { return a.recommend }
+func (a *fakeArticleRecord) Date() string        { return a.date }
+func (a *fakeArticleRecord) Title() string       { return a.title }
+func (a *fakeArticleRecord) Money() int          { return a.money }
+func (a *fakeArticleRecord) Owner() string       { return a.owner }
+
+func TestMoveBoardRecord(t *testing.T) {
+	reads := 0
+	c := newFakeWriteBoardConnector(&reads)
+	for _, id := range []string{"A0", "A1", "A2", "A3", "A4", "A5"} {
+		c.records = append(c.records, &fakeBoardRecord{boardID: id})
+	}
+	db := &DB{connector: c}
+
+	var events []string
+	db.SetWriteHook(func(op string, path string, index uint, rec interface{}) {
+		events = append(events, fmt.Sprintf("%s %s %d %v", op, path, index, rec))
+	})
+	db.SetMoveHook(func(path string, from, to uint) {
+		events = append(events, fmt.Sprintf("%s %d %d", path, from, to))
+	})
+
+	if err := db.MoveBoardRecord(4, 0); err != nil {
+		t.Fatalf("MoveBoardRecord error: %v", err)
+	}
+	recs, err := db.ReadBoardRecords()
+	if err != nil {
+		t.Fatalf("ReadBoardRecords error: %v", err)
+	}
+	var ids []string
+	for _, r := range recs {
+		ids = append(ids, r.BoardID())
+	}
+	expected := []string{"A4", "A0", "A1", "A2", "A3", "A5"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("board ids expected: %v, got: %v", expected, ids)
+	}
+	if !reflect.DeepEqual(events, []string{"move .BRD 0 <nil>", ".BRD 4 0"}) {
+		t.Errorf("write hook events not match, got: %v", events)
+	}
+
+	db.SetDryRun(true)
+	if err := db.MoveBoardRecord(0, 5); err != nil {
+		t.Errorf("MoveBoardRecord in dry-run error: %v", err)
+	}
+	if err := db.MoveBoardRecord(6, 0); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("MoveBoardRecord in dry-run should return ErrRecordNotFound, got: %v", err)
+	}
+	if c.records[0].BoardID() != "A4" || len(events) != 2 {
+		t.Errorf("dry-run should not move records or call write hook")
+	}
+
+	db = &DB{connector: &fakeConnector{}}
+	if err := db.MoveBoardRecord(0, 1); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("MoveBoardRecord should return ErrNotSupported, got: %v", err)
+	}
+}
+
+func TestBoardRecordIndexBounds(t *testing.T) {
+	newDB := func() *DB {
+		reads := 0
+		c := newFakeWriteBoardConnector(&reads)
+		for _, id := range []string{"A0", "A1", "A2"} {
+			c.records = append(c.records, &fakeBoardRecord{boardID: id})
+		}
+		return &DB{connector: c}
+	}
+
+	tests := []struct {
+		index uint
+		err   error
+	}{
+		{0, nil},
+		{2, nil},
+		{3, ErrRecordNotFound},
+	}
+	for _, tt := range tests {
+		brd, err := newDB().ReadBoardRecord(tt.index)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("ReadBoardRecord(%d) error expected: %v, got: %v", tt.index, tt.err, err)
+		}
+		if err == nil && (*brd).BoardID() != fmt.Sprintf("A%d", tt.index) {
+			t.Errorf("ReadBoardRecord(%d) got: %v", tt.index, (*brd).BoardID())
+		}
+
+		var update BoardRecord = &fakeBoardRecord{boardID: "New"}
+		if err := newDB().UpdateBoardRecord(tt.index, &update); !errors.Is(err, tt.err) {
+			t.Errorf("UpdateBoardRecord(%d) error expected: %v, got: %v", tt.index, tt.err, err)
+		}
+		if err := newDB().RemoveBoardRecord(tt.index); !errors.Is(err, tt.err) {
+			t.Errorf("RemoveBoardRecord(%d) error expected: %v, got: %v", tt.index, tt.err, err)
+		}
+		if err := newDB().MoveBoardRecord(tt.index, 0); !errors.Is(err, tt.err) {
+			t.Errorf("MoveBoardRecord(%d, 0) error expected: %v, got: %v", tt.index, tt.err, err)
+		}
+	}
+}
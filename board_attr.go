@@ -0,0 +1,78 @@
+package bbs
+
+// BoardAttr is the bitmask of board attributes, each driver maps its own
+// attribute bits to BoardAttr.
+type BoardAttr uint32
+
+const (
+	// BoardAttrHidden is for hidden board, only board friends can see it.
+	BoardAttrHidden BoardAttr = 1 << iota
+	// BoardAttrNoCount is for board whose posts are not counted in user's
+	// number of posts.
+	BoardAttrNoCount
+	// BoardAttrRestricted is for board which only board friends can post.
+	BoardAttrRestricted
+	// BoardAttrOver18 is for board which only adults can read.
+	BoardAttrOver18
+	// BoardAttrAnonymous is for board which allows anonymous posts.
+	BoardAttrAnonymous
+	// BoardAttrNoBoo is for board which forbids boo (噓).
+	BoardAttrNoBoo
+)
+
+// AttributedBoardRecord return BoardRecord interface which support Attributes
+type AttributedBoardRecord interface {
+	// Attributes return the attribute bitmask of this board.
+	Attributes() BoardAttr
+}
+
+// Has returns true if all bits of flag are set.
+func (a BoardAttr) Has(flag BoardAttr) bool { return a&flag == flag }
+
+// IsHidden returns true if board is hidden.
+func (a BoardAttr) IsHidden() bool { return a.Has(BoardAttrHidden) }
+
+// IsNoCount returns true if posts of board are not counted.
+func (a BoardAttr) IsNoCount() bool { return a.Has(BoardAttrNoCount) }
+
+// IsRestricted returns true if only board friends can post.
+func (a BoardAttr) IsRestricted() bool { return a.Has(BoardAttrRestricted) }
+
+// Over18 returns true if board is for adults only.
+func (a BoardAttr) Over18() bool { return a.Has(BoardAttrOver18) }
+
+// IsAnonymous returns true if board allows anonymous posts.
+func (a BoardAttr) IsAnonymous() bool { return a.Has(BoardAttrAnonymous) }
+
+// IsNoBoo returns true if board forbids boo.
+func (a BoardAttr) IsNoBoo() bool { return a.Has(BoardAttrNoBoo) }
+
+// BoardAttributes returns the attributes of r, it uses AttributedBoardRecord
+// and falls back to BoardRecordSettings. It returns 0 if r supports neither.
+func BoardAttributes(r BoardRecord) BoardAttr {
+	if ar, ok := r.(AttributedBoardRecord); ok {
+		return ar.Attributes()
+	}
+
+	s, ok := r.(BoardRecordSettings)
+	if !ok {
+		return 0
+	}
+	var a BoardAttr
+	if s.IsHide() {
+		a |= BoardAttrHidden
+	}
+	if s.IsRestrictedPost() {
+		a |= BoardAttrRestricted
+	}
+	if s.IsOver18() {
+		a |= BoardAttrOver18
+	}
+	if s.IsAnonymous() {
+		a |= BoardAttrAnonymous
+	}
+	if s.IsNoBoo() {
+		a |= BoardAttrNoBoo
+	}
+	return a
+}
@@ -0,0 +1,27 @@
+package bbs
+
+import (
+	"testing"
+)
+
+type fakeAttributedBoardRecord struct {
+	fakeBoardRecord
+	attr BoardAttr
+}
+
+func (b *fakeAttributedBoardRecord) Attributes() BoardAttr { return b.attr }
+
+func TestBoardAttributes(t *testing.T) {
+	r := &fakeAttributedBoardRecord{attr: BoardAttrHidden | BoardAttrOver18}
+	a := BoardAttributes(r)
+	if !a.IsHidden() || !a.Over18() {
+		t.Errorf("attributes should be hidden and over18, got: %b", a)
+	}
+	if a.IsRestricted() || a.IsAnonymous() || a.IsNoBoo() || a.IsNoCount() {
+		t.Errorf("attributes should not have other flags, got: %b", a)
+	}
+
+	if a := BoardAttributes(&fakeBoardRecord{}); a != 0 {
+		t.Errorf("attributes of record without support should be 0, got: %b", a)
+	}
+}
@@ -0,0 +1,52 @@
+package bbs
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"strings"
+)
+
+// ReadBoardFile returns raw file of relPath in the directory of board, such
+// as board description or post templates. relPath should be a relative path
+// which does not go outside the board directory.
+// It returns ErrNotSupported if connector does not implement
+// BoardFileConnector.
+func (db *DB) ReadBoardFile(boardID, relPath string) ([]byte, error) {
+	if err := checkRelPath(relPath); err != nil {
+		return nil, err
+	}
+
+	var bfc BoardFileConnector
+	if !db.connectorAs(&bfc) {
+		return nil, ErrNotSupported
+	}
+
+	path, err := db.boardFilePath(bfc, boardID, relPath)
+	if err != nil {
+		log.Println("bbs: GetBoardFilePath error:", err)
+		return nil, err
+	}
+	log.Println("path:", path)
+
+	buf, err := db.connector.ReadBoardArticleFile(path)
+	if err != nil {
+		log.Println("bbs: ReadBoardArticleFile error:", err)
+		return nil, err
+	}
+	return buf, nil
+}
+
+// checkRelPath returns error if relPath is empty, absolute or has ".."
+// element.
+func checkRelPath(relPath string) error {
+	if relPath == "" || path.IsAbs(relPath) || strings.Contains(relPath, "\\") {
+		return fmt.Errorf("%w: relative path %q", ErrInvalidName, relPath)
+	}
+	for _, elem := range strings.Split(relPath, "/") {
+		if elem == ".." {
+			return fmt.Errorf("%w: relative path %q", ErrInvalidName, relPath)
+		}
+	}
+	return nil
+}
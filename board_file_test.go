@@ -0,0 +1,43 @@
+package bbs
+
+import (
+	"errors"
+	"testing"
+)
+
+type fakeBoardFileConnector struct {
+	fakeConnector
+}
+
+var _ BoardFileConnector = &fakeBoardFileConnector{}
+
+func (c *fakeBoardFileConnector) GetBoardFilePath(boardID string, relPath string) (string, error) {
+	return "boards/" + boardID + "/" + relPath, nil
+}
+
+func (c *fakeBoardFileConnector) ReadBoardArticleFile(name string) ([]byte, error) {
+	return []byte(name), nil
+}
+
+func TestReadBoardFile(t *testing.T) {
+	db := &DB{connector: &fakeBoardFileConnector{}}
+
+	buf, err := db.ReadBoardFile("SYSOP", "notes")
+	if err != nil {
+		t.Fatalf("ReadBoardFile error: %v", err)
+	}
+	if string(buf) != "boards/SYSOP/notes" {
+		t.Errorf("ReadBoardFile expected: boards/SYSOP/notes, got: %s", buf)
+	}
+
+	for _, relPath := range []string{"", "../../.PASSWDS", "/etc/passwd", "a/../../b", "..\\.PASSWDS"} {
+		if _, err := db.ReadBoardFile("SYSOP", relPath); err == nil {
+			t.Errorf("ReadBoardFile(%q) should return error", relPath)
+		}
+	}
+
+	db = &DB{connector: &fakeConnector{}}
+	if _, err := db.ReadBoardFile("SYSOP", "notes"); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("ReadBoardFile should return ErrNotSupported, got: %v", err)
+	}
+}
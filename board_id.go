@@ -0,0 +1,52 @@
+package bbs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Driver which implement BoardIDFoldConnector treats board ids
+// case-insensitively, DB folds board ids by it before building paths.
+type BoardIDFoldConnector interface {
+	// FoldBoardID should return the canonical form of boardID, such as the
+	// case used by board directory.
+	FoldBoardID(boardID string) string
+}
+
+// isBoardIDChar returns true if c is allowed in board id.
+func isBoardIDChar(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '-' || c == '.'
+}
+
+// ValidateBoardID returns ErrInvalidBoardID if boardID is empty, does not
+// start with a letter or digit, or has characters other than letters,
+// digits, "_", "-" and ".". Class ids of pttbbs like "1..........." are
+// valid.
+func ValidateBoardID(boardID string) error {
+	if boardID == "" {
+		return fmt.Errorf("%w: empty", ErrInvalidBoardID)
+	}
+	for i, c := range boardID {
+		if !isBoardIDChar(c) || (i == 0 && (c == '_' || c == '-' || c == '.')) {
+			return fmt.Errorf("%w: %q", ErrInvalidBoardID, boardID)
+		}
+	}
+	return nil
+}
+
+// normalizeBoardID trims spaces of boardID, validates it by
+// ValidateBoardID, and folds it if connector implements
+// BoardIDFoldConnector. Board methods of DB pass board ids through it
+// before building paths.
+func (db *DB) normalizeBoardID(boardID string) (string, error) {
+	boardID = strings.TrimSpace(boardID)
+	if err := ValidateBoardID(boardID); err != nil {
+		return "", err
+	}
+	var fc BoardIDFoldConnector
+	if db.connectorAs(&fc) {
+		boardID = fc.FoldBoardID(boardID)
+	}
+	return boardID, nil
+}
@@ -0,0 +1,67 @@
+package bbs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type fakeBoardIDFoldConnector struct {
+	fakeConnector
+}
+
+func (c *fakeBoardIDFoldConnector) FoldBoardID(boardID string) string {
+	return strings.ToLower(boardID)
+}
+
+func TestValidateBoardID(t *testing.T) {
+	valid := []string{"SYSOP", "Test_1", "C-Chat", "1...........", "ALLPOST"}
+	for _, id := range valid {
+		if err := ValidateBoardID(id); err != nil {
+			t.Errorf("ValidateBoardID(%q) error: %v", id, err)
+		}
+	}
+	invalid := []string{"", " ", "..", ".", "../SYSOP", "a/b", "a\\b", "SYS OP", "_SYSOP", "看板", "SYSOP\x00"}
+	for _, id := range invalid {
+		err := ValidateBoardID(id)
+		if !errors.Is(err, ErrInvalidBoardID) || !errors.Is(err, ErrInvalidName) {
+			t.Errorf("ValidateBoardID(%q) should return ErrInvalidBoardID, got: %v", id, err)
+		}
+	}
+}
+
+func TestNormalizeBoardID(t *testing.T) {
+	var gotPath string
+	fc := fakeConnector{
+		fakeGetBoardArticleRecordsPath: func() (string, error) { return ".DIR", nil },
+		fakeReadArticleRecordsFile:     func() ([]ArticleRecord, error) { return []ArticleRecord{}, nil },
+	}
+	db := &DB{connector: &fc}
+	db.SetPathOverride(func(kind, id string) (string, bool) {
+		gotPath = id
+		return "", false
+	})
+
+	if _, err := db.ReadBoardArticleRecordsFile(" SYSOP\n"); err != nil {
+		t.Fatalf("ReadBoardArticleRecordsFile error: %v", err)
+	}
+	if gotPath != "SYSOP" {
+		t.Errorf("board id should be trimmed, got: %q", gotPath)
+	}
+	for _, id := range []string{"", "  ", "../SYSOP"} {
+		if _, err := db.ReadBoardArticleRecordsFile(id); !errors.Is(err, ErrInvalidBoardID) {
+			t.Errorf("ReadBoardArticleRecordsFile(%q) should return ErrInvalidBoardID, got: %v", id, err)
+		}
+		if _, err := db.GetBoardRecord(id); !errors.Is(err, ErrInvalidBoardID) {
+			t.Errorf("GetBoardRecord(%q) should return ErrInvalidBoardID, got: %v", id, err)
+		}
+	}
+
+	db.connector = &fakeBoardIDFoldConnector{fakeConnector: fc}
+	if _, err := db.ReadBoardArticleRecordsFile("SYSOP"); err != nil {
+		t.Fatalf("ReadBoardArticleRecordsFile error: %v", err)
+	}
+	if gotPath != "sysop" {
+		t.Errorf("board id should be folded by connector, got: %q", gotPath)
+	}
+}
@@ -0,0 +1,129 @@
+package bbs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"log"
+	"strings"
+	"unicode"
+)
+
+// BoardInfoConnector is a connector for bbs which supports board info file
+// (進板畫面), the notes edited by BMs and shown when entering a board.
+type BoardInfoConnector interface {
+
+	// GetBoardInfoPath should return the info file path of board,
+	// eg: BBSHome/boards/{{b}}/{{boardID}}/notes
+	GetBoardInfoPath(boardID string) (string, error)
+}
+
+// BoardInfo is the parsed board info file.
+type BoardInfo struct {
+	// Text is the whole file decoded from Big5, ANSI escapes are kept for
+	// display.
+	Text string
+	// Notice is the lines of 置底/公告 section without ANSI escapes.
+	Notice []string
+	// PostingRules is the lines of 板規/發文規則 section without ANSI
+	// escapes.
+	PostingRules []string
+}
+
+// boardInfoSections maps keywords of section heading lines to the section
+// they start.
+var boardInfoSections = []struct {
+	keyword string
+	section string
+}{
+	{keyword: "置底", section: "notice"},
+	{keyword: "公告", section: "notice"},
+	{keyword: "板規", section: "rules"},
+	{keyword: "版規", section: "rules"},
+	{keyword: "發文規則", section: "rules"},
+}
+
+// ParseBoardInfo parses the board info file data in Big5. Sections start at
+// a line containing one of 置底, 公告, 板規, 版規 or 發文規則, and contain
+// the following non-empty lines until the next section heading. Lines of
+// only box drawing or punctuation characters are skipped.
+func ParseBoardInfo(data []byte) BoardInfo {
+	info := BoardInfo{Text: Big5ToUtf8(data)}
+	section := ""
+	for _, line := range strings.Split(strings.ReplaceAll(info.Text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(FilterStringANSI(line))
+		if line == "" || isBoardInfoDecoration(line) {
+			continue
+		}
+
+		heading := false
+		for _, s := range boardInfoSections {
+			if strings.Contains(line, s.keyword) {
+				section, heading = s.section, true
+				break
+			}
+		}
+		if heading {
+			continue
+		}
+
+		switch section {
+		case "notice":
+			info.Notice = append(info.Notice, line)
+		case "rules":
+			info.PostingRules = append(info.PostingRules, line)
+		}
+	}
+	return info
+}
+
+// isBoardInfoDecoration returns true if line has no letter or digit, such
+// as a border of box drawing characters.
+func isBoardInfoDecoration(line string) bool {
+	for _, c := range line {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// ReadBoardInfo returns the parsed info file of board, it returns empty
+// BoardInfo if board has no info file. It returns ErrNotSupported if
+// connector does not implement BoardInfoConnector.
+func (db *DB) ReadBoardInfo(boardID string) (BoardInfo, error) {
+	if boardID == "" {
+		return BoardInfo{}, fmt.Errorf("%w: empty board id", ErrInvalidName)
+	}
+	if err := checkNames(boardID); err != nil {
+		return BoardInfo{}, err
+	}
+	var bic BoardInfoConnector
+	if !db.connectorAs(&bic) {
+		return BoardInfo{}, ErrNotSupported
+	}
+
+	path, err := db.boardInfoPath(bic, boardID)
+	if err != nil {
+		log.Println("bbs: GetBoardInfoPath error:", err)
+		return BoardInfo{}, err
+	}
+
+	f, err := db.openFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return BoardInfo{}, nil
+	}
+	if err != nil {
+		log.Println("bbs: open board info file error:", err)
+		return BoardInfo{}, err
+	}
+	defer f.Close()
+
+	buf, err := ioutil.ReadAll(f)
+	if err != nil {
+		log.Println("bbs: read board info file error:", err)
+		return BoardInfo{}, err
+	}
+	return ParseBoardInfo(buf), nil
+}
@@ -0,0 +1,65 @@
+package bbs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+type fakeBoardInfoConnector struct {
+	fakeConnector
+}
+
+var _ BoardInfoConnector = &fakeBoardInfoConnector{}
+
+func (c *fakeBoardInfoConnector) GetBoardInfoPath(boardID string) (string, error) {
+	return "boards/" + boardID + "/notes", nil
+}
+
+func TestReadBoardInfo(t *testing.T) {
+	notes := Utf8ToBig5("\x1b[1;33m歡迎來到 SYSOP 板\x1b[m\r\n" +
+		"════════════\r\n" +
+		"【置底公告】\r\n" +
+		"  站務問題請在此發文\r\n" +
+		"\r\n" +
+		"【板規】\r\n" +
+		"1. 禁止人身攻擊\r\n" +
+		"2. 標題需加分類\r\n")
+	db := &DB{
+		connector: &fakeBoardInfoConnector{},
+		fsys: fstest.MapFS{
+			"boards/SYSOP/notes": &fstest.MapFile{Data: notes},
+		},
+	}
+
+	info, err := db.ReadBoardInfo("SYSOP")
+	if err != nil {
+		t.Fatalf("ReadBoardInfo error: %v", err)
+	}
+	if info.Text != Big5ToUtf8(notes) {
+		t.Errorf("text expected: %q, got: %q", Big5ToUtf8(notes), info.Text)
+	}
+	if expected := []string{"站務問題請在此發文"}; !reflect.DeepEqual(info.Notice, expected) {
+		t.Errorf("notice expected: %q, got: %q", expected, info.Notice)
+	}
+	if expected := []string{"1. 禁止人身攻擊", "2. 標題需加分類"}; !reflect.DeepEqual(info.PostingRules, expected) {
+		t.Errorf("posting rules expected: %q, got: %q", expected, info.PostingRules)
+	}
+
+	info, err = db.ReadBoardInfo("Test")
+	if err != nil || !reflect.DeepEqual(info, BoardInfo{}) {
+		t.Errorf("ReadBoardInfo without info file expected: empty, <nil>, got: %v, %v", info, err)
+	}
+
+	for _, boardID := range []string{"", "../SYSOP"} {
+		if _, err := db.ReadBoardInfo(boardID); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("ReadBoardInfo(%q) should return ErrInvalidName, got: %v", boardID, err)
+		}
+	}
+
+	db = &DB{connector: &fakeConnector{}}
+	if _, err := db.ReadBoardInfo("SYSOP"); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("ReadBoardInfo should return ErrNotSupported, got: %v", err)
+	}
+}
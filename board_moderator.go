@@ -0,0 +1,88 @@
+package bbs
+
+import (
+	"errors"
+	"log"
+	"strings"
+)
+
+// OnlineUserRecord return UserRecord interface which support IsOnline
+type OnlineUserRecord interface {
+	// IsOnline return true if user has an online session.
+	IsOnline() bool
+	// Unwrap return the UserRecord of driver, which may implement other
+	// optional interfaces.
+	Unwrap() UserRecord
+}
+
+// onlineUserRecord annotates UserRecord with its online status.
+type onlineUserRecord struct {
+	UserRecord
+	online bool
+}
+
+func (u *onlineUserRecord) IsOnline() bool     { return u.online }
+func (u *onlineUserRecord) Unwrap() UserRecord { return u.UserRecord }
+
+// BoardModerators returns the UserRecords of BMs of boardID in BM order,
+// see BMList. User records are scanned once, and BM ids without user record
+// are skipped. If connector implements UtmpConnector, returned records
+// implement OnlineUserRecord. It returns ErrRecordNotFound if there is no
+// such board.
+func (db *DB) BoardModerators(boardID string) ([]UserRecord, error) {
+	b, err := db.GetBoardRecord(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	bms := BMList(b)
+	if len(bms) == 0 {
+		return []UserRecord{}, nil
+	}
+	found := map[string]UserRecord{}
+	for _, id := range bms {
+		found[strings.ToLower(id)] = nil
+	}
+	remaining := len(found)
+	err = db.eachUserRecord(func(u UserRecord) bool {
+		key := strings.ToLower(u.UserID())
+		if r, ok := found[key]; ok && r == nil {
+			found[key] = u
+			remaining--
+		}
+		return remaining > 0
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	online := map[string]bool{}
+	sessions, err := db.WhoIsOnline()
+	hasUtmp := err == nil
+	if err != nil && !errors.Is(err, ErrNotSupported) {
+		log.Println("bbs: WhoIsOnline error:", err)
+	}
+	for _, r := range sessions {
+		online[strings.ToLower(r.UserID())] = true
+	}
+
+	ret := []UserRecord{}
+	seen := map[string]bool{}
+	for _, id := range bms {
+		key := strings.ToLower(id)
+		u := found[key]
+		if u == nil {
+			log.Println("bbs: skip unknown BM:", id)
+			continue
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if hasUtmp {
+			u = &onlineUserRecord{UserRecord: u, online: online[key]}
+		}
+		ret = append(ret, u)
+	}
+	return ret, nil
+}
@@ -0,0 +1,71 @@
+package bbs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBoardModerators(t *testing.T) {
+	users := []UserRecord{
+		&fakeUserRecord{userID: "SYSOP", nickname: "站長"},
+		&fakeUserRecord{userID: "pichu", nickname: "皮丘"},
+		&fakeUserRecord{userID: "Kahou"},
+	}
+	boards := []BoardRecord{
+		&fakeBoardRecord{boardID: "SYSOP", bm: []string{"pichu/nobody SYSOP"}},
+		&fakeBoardRecord{boardID: "Test"},
+	}
+	fc := fakeConnector{
+		fakeGetUserRecordsPath:   func() (string, error) { return ".PASSWDS", nil },
+		fakeReadUserRecordsFile:  func() ([]UserRecord, error) { return users, nil },
+		fakeGetBoardRecordsPath:  func() (string, error) { return ".BRD", nil },
+		fakeReadBoardRecordsFile: func() ([]BoardRecord, error) { return boards, nil },
+	}
+
+	db := &DB{connector: &fc}
+	recs, err := db.BoardModerators("sysop")
+	if err != nil {
+		t.Fatalf("BoardModerators error: %v", err)
+	}
+	if len(recs) != 2 || recs[0].Nickname() != "皮丘" || recs[1].Nickname() != "站長" {
+		t.Fatalf("moderators expected: [pichu SYSOP], got: %v", recs)
+	}
+	if _, ok := recs[0].(OnlineUserRecord); ok {
+		t.Errorf("moderators should not implement OnlineUserRecord without utmp")
+	}
+
+	db = &DB{connector: &fakeUtmpConnector{
+		fakeConnector: fc,
+		fakeReadUtmp: func() ([]UtmpRecord, error) {
+			return []UtmpRecord{&fakeUtmpRecord{userID: "SYSOP"}}, nil
+		},
+	}}
+	recs, err = db.BoardModerators("SYSOP")
+	if err != nil {
+		t.Fatalf("BoardModerators error: %v", err)
+	}
+	expected := []bool{false, true}
+	if len(recs) != len(expected) {
+		t.Fatalf("moderators length expected: %v, got: %v", len(expected), len(recs))
+	}
+	for i, r := range recs {
+		ou, ok := r.(OnlineUserRecord)
+		if !ok {
+			t.Fatalf("moderator %v should implement OnlineUserRecord", r.UserID())
+		}
+		if ou.IsOnline() != expected[i] {
+			t.Errorf("online of %v expected: %v, got: %v", r.UserID(), expected[i], ou.IsOnline())
+		}
+		if ou.Unwrap() != users[1-i] {
+			t.Errorf("Unwrap of %v should return the record of driver", r.UserID())
+		}
+	}
+
+	recs, err = db.BoardModerators("Test")
+	if err != nil || len(recs) != 0 {
+		t.Errorf("BoardModerators of board without BM expected: [], <nil>, got: %v, %v", recs, err)
+	}
+	if _, err := db.BoardModerators("nothing"); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("BoardModerators should return ErrRecordNotFound, got: %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package bbs
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// isTopClassID returns true if classID means the top level class, some bbs
+// system leaves it empty and pttbbs uses "1".
+func isTopClassID(classID string) bool {
+	return classID == "" || classID == "1"
+}
+
+// GetBoardRecord returns the BoardRecord of boardID, boardID is matched
+// case-insensitively. If connector implements SHMBoardConnector the board is
+// looked up in SHM first, the board records file is scanned when SHM misses
+// or fails. It returns ErrRecordNotFound if there is no such board, and
+// ErrInvalidBoardID if boardID is invalid.
+func (db *DB) GetBoardRecord(boardID string) (BoardRecord, error) {
+	boardID, err := db.normalizeBoardID(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sc SHMBoardConnector
+	if db.connectorAs(&sc) {
+		r, ok, err := sc.LookupBoardSHM(boardID)
+		if err != nil {
+			log.Println("bbs: LookupBoardSHM error:", err)
+		} else if ok {
+			return r, nil
+		}
+	}
+
+	recs, err := db.ReadBoardRecords()
+	if err != nil {
+		log.Println("bbs: ReadBoardRecords error:", err)
+		return nil, err
+	}
+	for _, r := range recs {
+		if strings.EqualFold(r.BoardID(), boardID) {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: board %q", ErrRecordNotFound, boardID)
+}
+
+// ReadBoardRecordsByClass returns the boards and sub-classes which belong to
+// classID in .BRD order, empty classID or "1" means the top level class.
+// Like BoardTree, classID and ClassID of boards refer to a class by its
+// BoardID or its 1-based position in .BRD, so either form finds the same
+// boards. A classID which refers to no class matches ClassID of boards as
+// is.
+func (db *DB) ReadBoardRecordsByClass(classID string) ([]BoardRecord, error) {
+	recs, err := db.ReadBoardRecords()
+	if err != nil {
+		log.Println("bbs: ReadBoardRecords error:", err)
+		return nil, err
+	}
+
+	classIndex := boardClassIndex(recs)
+	target, found := resolveBoardClass(classIndex, classID)
+	ret := []BoardRecord{}
+	for _, r := range recs {
+		p, ok := resolveBoardClass(classIndex, r.ClassID())
+		if found && ok && p == target || !found && !ok && r.ClassID() == classID {
+			ret = append(ret, r)
+		}
+	}
+	return ret, nil
+}
+
+// ReadBoardRecordsByBM returns the boards which userID is one of the BM,
+// userID is matched case-insensitively.
+func (db *DB) ReadBoardRecordsByBM(userID string) ([]BoardRecord, error) {
+	recs, err := db.ReadBoardRecords()
+	if err != nil {
+		log.Println("bbs: ReadBoardRecords error:", err)
+		return nil, err
+	}
+
+	ret := []BoardRecord{}
+	for _, r := range recs {
+		if isBoardBM(r, userID) {
+			ret = append(ret, r)
+		}
+	}
+	return ret, nil
+}
+
+// BoardsModeratedBy returns the board ids which userID is one of the BM.
+// It uses the BM index if connector implements BMIndexConnector, otherwise
+// it scans all boards, see ReadBoardRecordsByBM.
+func (db *DB) BoardsModeratedBy(userID string) ([]string, error) {
+	var bc BMIndexConnector
+	if !db.connectorAs(&bc) {
+		recs, err := db.ReadBoardRecordsByBM(userID)
+		if err != nil {
+			return nil, err
+		}
+		ret := make([]string, len(recs))
+		for i, r := range recs {
+			ret[i] = r.BoardID()
+		}
+		return ret, nil
+	}
+
+	path, err := db.userBMIndexPath(bc, userID)
+	if err != nil {
+		log.Println("bbs: GetUserBMIndexPath error:", err)
+		return nil, err
+	}
+	boardIDs, err := bc.ReadBMIndexFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	} else if err != nil {
+		log.Println("bbs: ReadBMIndexFile error:", err)
+		return nil, err
+	}
+	return boardIDs, nil
+}
+
+// SplitBM splits the raw BM field like "alice/bob crystal" by slash and
+// whitespace into user ids, it returns empty slice if there is no BM.
+func SplitBM(raw string) []string {
+	return strings.FieldsFunc(raw, func(c rune) bool {
+		return c == '/' || unicode.IsSpace(c)
+	})
+}
+
+// BMList returns the BM user ids of r, one id per element. Some drivers put
+// the whole raw BM field in one element of BM(), so each element is split
+// again by SplitBM. It returns empty slice if board has no BM.
+func BMList(r BoardRecord) []string {
+	ret := []string{}
+	for _, field := range r.BM() {
+		ret = append(ret, SplitBM(field)...)
+	}
+	return ret
+}
+
+func isBoardBM(r BoardRecord, userID string) bool {
+	for _, bm := range BMList(r) {
+		if strings.EqualFold(bm, userID) {
+			return true
+		}
+	}
+	return false
+}
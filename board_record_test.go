@@ -0,0 +1,261 @@
+package bbs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+)
+
+var testBoardRecords = []BoardRecord{
+	&fakeBoardRecord{boardID: "1...........", isClass: true, classID: "1"},
+	&fakeBoardRecord{boardID: "SYSOP", classID: "2", bm: []string{"pichu"}},
+	&fakeBoardRecord{boardID: "2...........", isClass: true, classID: ""},
+	&fakeBoardRecord{boardID: "junk", classID: "2", bm: []string{"SYSOP", "Pichu"}},
+	&fakeBoardRecord{boardID: "Test", classID: "5", bm: []string{""}},
+	&fakeBoardRecord{boardID: "Raw", classID: "6", bm: []string{"alice/bob  Crystal"}},
+}
+
+func newTestBoardRecordsDB() *DB {
+	return &DB{
+		connector: &fakeConnector{
+			fakeGetBoardRecordsPath: func() (string, error) {
+				return ".BRD", nil
+			},
+			fakeReadBoardRecordsFile: func() ([]BoardRecord, error) {
+				return testBoardRecords, nil
+			},
+		},
+	}
+}
+
+func boardIDs(recs []BoardRecord) []string {
+	ret := []string{}
+	for _, r := range recs {
+		ret = append(ret, r.BoardID())
+	}
+	return ret
+}
+
+func TestReadBoardRecordsByClass(t *testing.T) {
+	db := newTestBoardRecordsDB()
+
+	tests := []struct {
+		classID  string
+		expected []string
+	}{
+		{"2", []string{"SYSOP", "junk"}},
+		{"1", []string{"1...........", "2..........."}},
+		{"", []string{"1...........", "2..........."}},
+		{"404", []string{}},
+	}
+
+	for _, c := range tests {
+		recs, err := db.ReadBoardRecordsByClass(c.classID)
+		if err != nil {
+			t.Errorf("ReadBoardRecordsByClass(%q) error: %v", c.classID, err)
+		}
+		if actual := boardIDs(recs); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("boards of class %q not match, expected: %v, got: %v", c.classID, c.expected, actual)
+		}
+	}
+}
+
+func TestReadBoardRecordsByClassIndex(t *testing.T) {
+	recs := []BoardRecord{
+		&fakeBoardRecord{boardID: "1...........", isClass: true, classID: "1"},
+		&fakeBoardRecord{boardID: "2...........", isClass: true, classID: "1"},
+		&fakeBoardRecord{boardID: "SYSOP", classID: "2"},
+		&fakeBoardRecord{boardID: "Test", classID: "2..........."},
+		&fakeBoardRecord{boardID: "junk", classID: "1"},
+	}
+	db := &DB{
+		connector: &fakeConnector{
+			fakeGetBoardRecordsPath:  func() (string, error) { return ".BRD", nil },
+			fakeReadBoardRecordsFile: func() ([]BoardRecord, error) { return recs, nil },
+		},
+	}
+
+	tests := []struct {
+		classID  string
+		expected []string
+	}{
+		{"2", []string{"SYSOP", "Test"}},
+		{"2...........", []string{"SYSOP", "Test"}},
+		{"1", []string{"1...........", "2...........", "junk"}},
+		{"3", []string{}},
+	}
+	for _, c := range tests {
+		recs, err := db.ReadBoardRecordsByClass(c.classID)
+		if err != nil {
+			t.Errorf("ReadBoardRecordsByClass(%q) error: %v", c.classID, err)
+		}
+		if actual := boardIDs(recs); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("boards of class %q not match, expected: %v, got: %v", c.classID, c.expected, actual)
+		}
+	}
+
+	// BoardTree resolves ClassID the same way.
+	tree, err := db.BoardTree()
+	if err != nil {
+		t.Fatalf("BoardTree error: %v", err)
+	}
+	if actual := boardTreeIDs(tree)["2..........."]; !reflect.DeepEqual(actual, []string{"SYSOP", "Test"}) {
+		t.Errorf("boards of class 2 in BoardTree not match, got: %v", actual)
+	}
+}
+
+func TestReadBoardRecordsByBM(t *testing.T) {
+	db := newTestBoardRecordsDB()
+
+	tests := []struct {
+		userID   string
+		expected []string
+	}{
+		{"pichu", []string{"SYSOP", "junk"}},
+		{"sysop", []string{"junk"}},
+		{"crystal", []string{"Raw"}},
+		{"nobody", []string{}},
+		{"", []string{}},
+	}
+
+	for _, c := range tests {
+		recs, err := db.ReadBoardRecordsByBM(c.userID)
+		if err != nil {
+			t.Errorf("ReadBoardRecordsByBM(%q) error: %v", c.userID, err)
+		}
+		if actual := boardIDs(recs); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("boards of BM %q not match, expected: %v, got: %v", c.userID, c.expected, actual)
+		}
+	}
+}
+
+func TestBMList(t *testing.T) {
+	tests := []struct {
+		bm       []string
+		expected []string
+	}{
+		{[]string{"SYSOP", "pichu"}, []string{"SYSOP", "pichu"}},
+		{[]string{"alice/bob crystal"}, []string{"alice", "bob", "crystal"}},
+		{[]string{" alice/ ", "/bob//"}, []string{"alice", "bob"}},
+		{[]string{""}, []string{}},
+		{nil, []string{}},
+	}
+
+	for _, c := range tests {
+		actual := BMList(&fakeBoardRecord{bm: c.bm})
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("BMList(%q) expected: %q, got: %q", c.bm, c.expected, actual)
+		}
+	}
+}
+
+type fakeBMIndexConnector struct {
+	fakeConnector
+	index map[string][]string
+}
+
+var _ BMIndexConnector = &fakeBMIndexConnector{}
+
+func (c *fakeBMIndexConnector) GetUserBMIndexPath(userID string) (string, error) {
+	return userID, nil
+}
+
+func (c *fakeBMIndexConnector) ReadBMIndexFile(name string) ([]string, error) {
+	boardIDs, ok := c.index[name]
+	if !ok {
+		return nil, fmt.Errorf("open %v: %w", name, os.ErrNotExist)
+	}
+	return boardIDs, nil
+}
+
+func TestBoardsModeratedBy(t *testing.T) {
+	db := newTestBoardRecordsDB()
+	boardIDs, err := db.BoardsModeratedBy("bob")
+	if err != nil {
+		t.Fatalf("BoardsModeratedBy error: %v", err)
+	}
+	if expected := []string{"Raw"}; !reflect.DeepEqual(boardIDs, expected) {
+		t.Errorf("boards not match, expected: %v, got: %v", expected, boardIDs)
+	}
+
+	db = &DB{connector: &fakeBMIndexConnector{
+		index: map[string][]string{"pichu": {"SYSOP", "junk"}},
+	}}
+	boardIDs, err = db.BoardsModeratedBy("pichu")
+	if err != nil {
+		t.Fatalf("BoardsModeratedBy error: %v", err)
+	}
+	if expected := []string{"SYSOP", "junk"}; !reflect.DeepEqual(boardIDs, expected) {
+		t.Errorf("boards not match, expected: %v, got: %v", expected, boardIDs)
+	}
+
+	boardIDs, err = db.BoardsModeratedBy("nobody")
+	if err != nil || len(boardIDs) != 0 {
+		t.Errorf("BoardsModeratedBy of user without index expected: [], <nil>, got: %v, %v", boardIDs, err)
+	}
+}
+
+type fakeSHMBoardConnector struct {
+	fakeConnector
+	boards map[string]BoardRecord
+	err    error
+	scans  int
+}
+
+func (c *fakeSHMBoardConnector) LookupBoardSHM(boardID string) (BoardRecord, bool, error) {
+	if c.err != nil {
+		return nil, false, c.err
+	}
+	r, ok := c.boards[boardID]
+	return r, ok, nil
+}
+
+func TestGetBoardRecord(t *testing.T) {
+	c := &fakeSHMBoardConnector{
+		boards: map[string]BoardRecord{"SYSOP": testBoardRecords[1]},
+	}
+	c.fakeGetBoardRecordsPath = func() (string, error) {
+		return ".BRD", nil
+	}
+	c.fakeReadBoardRecordsFile = func() ([]BoardRecord, error) {
+		c.scans++
+		return testBoardRecords, nil
+	}
+	db := &DB{connector: c}
+
+	r, err := db.GetBoardRecord("SYSOP")
+	if err != nil || r.BoardID() != "SYSOP" {
+		t.Errorf("GetBoardRecord(SYSOP) expected: SYSOP, got: %v, %v", r, err)
+	}
+	if c.scans != 0 {
+		t.Errorf("GetBoardRecord should not scan board records on SHM hit, scans: %d", c.scans)
+	}
+
+	r, err = db.GetBoardRecord("test")
+	if err != nil || r.BoardID() != "Test" {
+		t.Errorf("GetBoardRecord(test) expected: Test, got: %v, %v", r, err)
+	}
+	if c.scans != 1 {
+		t.Errorf("GetBoardRecord should scan board records on SHM miss, scans: %d", c.scans)
+	}
+
+	c.err = fmt.Errorf("shm detached")
+	r, err = db.GetBoardRecord("SYSOP")
+	if err != nil || r.BoardID() != "SYSOP" {
+		t.Errorf("GetBoardRecord should fall back when SHM fails, got: %v, %v", r, err)
+	}
+
+	if _, err := db.GetBoardRecord("404"); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("GetBoardRecord(%q) should return ErrRecordNotFound, got: %v", "404", err)
+	}
+	if _, err := db.GetBoardRecord(""); !errors.Is(err, ErrInvalidBoardID) {
+		t.Errorf("GetBoardRecord(%q) should return ErrInvalidBoardID, got: %v", "", err)
+	}
+
+	r, err = newTestBoardRecordsDB().GetBoardRecord("junk")
+	if err != nil || r.BoardID() != "junk" {
+		t.Errorf("GetBoardRecord without SHM expected: junk, got: %v, %v", r, err)
+	}
+}
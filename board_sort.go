@@ -0,0 +1,69 @@
+package bbs
+
+import (
+	"fmt"
+	"log"
+	"sort"
+)
+
+// BoardSortKey is the field by which ReadBoardRecordsSorted sorts.
+type BoardSortKey int
+
+const (
+	// BoardSortByID sorts by BoardID.
+	BoardSortByID BoardSortKey = iota
+	// BoardSortByTitle sorts by Title, then BoardID.
+	BoardSortByTitle
+	// BoardSortByClassID sorts by ClassID, then BoardID.
+	BoardSortByClassID
+)
+
+// SetBoardCollation sets the less function comparing board ids, titles and
+// class ids in ReadBoardRecordsSorted, such as a collator for Chinese
+// titles. nil means byte order of the UTF-8 strings, which is the default.
+func (db *DB) SetBoardCollation(less func(a, b string) bool) {
+	db.boardCollation = less
+}
+
+// ReadBoardRecordsSorted returns the board records sorted by key, so the
+// order does not depend on the layout of board records file. The sort is
+// stable, records with equal keys keep the order in file.
+func (db *DB) ReadBoardRecordsSorted(by BoardSortKey) ([]BoardRecord, error) {
+	var field func(BoardRecord) string
+	switch by {
+	case BoardSortByID:
+		field = nil
+	case BoardSortByTitle:
+		field = BoardRecord.Title
+	case BoardSortByClassID:
+		field = BoardRecord.ClassID
+	default:
+		return nil, fmt.Errorf("bbs: invalid board sort key %v", by)
+	}
+
+	recs, err := db.ReadBoardRecords()
+	if err != nil {
+		log.Println("bbs: ReadBoardRecords error:", err)
+		return nil, err
+	}
+
+	// connector may return a shared slice, such as a cached one.
+	recs = append([]BoardRecord{}, recs...)
+	less := db.boardCollation
+	if less == nil {
+		less = func(a, b string) bool { return a < b }
+	}
+	sort.SliceStable(recs, func(i, j int) bool {
+		if field != nil {
+			a, b := field(recs[i]), field(recs[j])
+			if less(a, b) {
+				return true
+			}
+			if less(b, a) {
+				return false
+			}
+		}
+		return less(recs[i].BoardID(), recs[j].BoardID())
+	})
+	return recs, nil
+}
@@ -0,0 +1,63 @@
+package bbs
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadBoardRecordsSorted(t *testing.T) {
+	db := &DB{
+		connector: &fakeConnector{
+			fakeGetBoardRecordsPath: func() (string, error) { return ".BRD", nil },
+			fakeReadBoardRecordsFile: func() ([]BoardRecord, error) {
+				return []BoardRecord{
+					&fakeBoardRecord{boardID: "b", title: "測試", classID: "2"},
+					&fakeBoardRecord{boardID: "C", title: "站務", classID: "1"},
+					&fakeBoardRecord{boardID: "a", title: "測試", classID: "2"},
+					&fakeBoardRecord{boardID: "D", title: "Test", classID: "1"},
+				}, nil
+			},
+		},
+	}
+
+	ids := func(recs []BoardRecord) []string {
+		ret := []string{}
+		for _, r := range recs {
+			ret = append(ret, r.BoardID())
+		}
+		return ret
+	}
+
+	tests := []struct {
+		by       BoardSortKey
+		expected []string
+	}{
+		{BoardSortByID, []string{"C", "D", "a", "b"}},
+		{BoardSortByTitle, []string{"D", "a", "b", "C"}},
+		{BoardSortByClassID, []string{"C", "D", "a", "b"}},
+	}
+	for _, tt := range tests {
+		recs, err := db.ReadBoardRecordsSorted(tt.by)
+		if err != nil {
+			t.Errorf("ReadBoardRecordsSorted(%v) error: %v", tt.by, err)
+			continue
+		}
+		if actual := ids(recs); !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("ReadBoardRecordsSorted(%v) expected: %v, got: %v", tt.by, tt.expected, actual)
+		}
+	}
+
+	db.SetBoardCollation(func(a, b string) bool { return strings.ToLower(a) < strings.ToLower(b) })
+	recs, err := db.ReadBoardRecordsSorted(BoardSortByID)
+	if err != nil {
+		t.Fatalf("ReadBoardRecordsSorted error: %v", err)
+	}
+	if actual := ids(recs); !reflect.DeepEqual(actual, []string{"a", "b", "C", "D"}) {
+		t.Errorf("ReadBoardRecordsSorted with collation got: %v", actual)
+	}
+
+	if _, err := db.ReadBoardRecordsSorted(BoardSortKey(100)); err == nil {
+		t.Errorf("ReadBoardRecordsSorted with invalid key should return error")
+	}
+}
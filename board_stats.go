@@ -0,0 +1,87 @@
+package bbs
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+)
+
+// BoardStats is the aggregate stats of articles in a board.
+type BoardStats struct {
+	BoardID string
+	// NumArticles is the number of article records in board, including
+	// deleted ones which are still in the records file.
+	NumArticles int
+	// LastUpdate is the time of the newest article, it is zero if board
+	// has no article.
+	LastUpdate time.Time
+	// TotalRecommend is the sum of recommend (推) counts of all articles.
+	TotalRecommend int
+}
+
+// BoardStats returns the BoardStats of board by scanning all its article
+// records. Use NumBoardArticles if only the count is needed.
+func (db *DB) BoardStats(boardID string) (BoardStats, error) {
+	recs, err := db.ReadBoardArticleRecordsFile(boardID)
+	if err != nil {
+		log.Println("bbs: ReadBoardArticleRecordsFile error:", err)
+		return BoardStats{}, err
+	}
+
+	stats := BoardStats{
+		BoardID:     boardID,
+		NumArticles: len(recs),
+	}
+	for _, r := range recs {
+		stats.TotalRecommend += r.Recommend()
+		if t, ok := articleTime(r, db.Location()); ok && t.After(stats.LastUpdate) {
+			stats.LastUpdate = t
+		}
+	}
+	return stats, nil
+}
+
+// NumBoardArticles returns the number of article records in board. If
+// connector implements RecordSizer, it is computed from the records file
+// size without reading any record, unless deleted records are skipped by
+// SetSkipDeletedArticles.
+func (db *DB) NumBoardArticles(boardID string) (int, error) {
+	var rs RecordSizer
+	if db.skipDeletedArticles || !db.connectorAs(&rs) || rs.ArticleRecordSize() <= 0 {
+		recs, err := db.ReadBoardArticleRecordsFile(boardID)
+		if err != nil {
+			return 0, err
+		}
+		return len(recs), nil
+	}
+
+	path, err := db.boardArticleRecordsPath(boardID)
+	if err != nil {
+		log.Println("bbs: GetBoardArticleRecordsPath error:", err)
+		return 0, err
+	}
+	stat, err := db.statFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		log.Println("bbs: stat article records file error:", err)
+		return 0, err
+	}
+	return int(stat.Size() / int64(rs.ArticleRecordSize())), nil
+}
+
+// BoardArticleRange returns the 1-based indices of the first and last
+// articles of board, which are the article numbers shown to users. It
+// returns (0, 0) for an empty board. Like NumBoardArticles, it is computed
+// from the record count without reading the records.
+func (db *DB) BoardArticleRange(boardID string) (first, last int, err error) {
+	n, err := db.NumBoardArticles(boardID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if n == 0 {
+		return 0, 0, nil
+	}
+	return 1, n, nil
+}
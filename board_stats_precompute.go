@@ -0,0 +1,231 @@
+package bbs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// SetBoardStatsCache sets the file where PrecomputeBoardStats stores its
+// result, the next calls reuse the file while the board records file and
+// the article records files of boards keep the modification times and sizes
+// they had when the stats were computed, or until InvalidateBoardStatsCache
+// is called. The file is on disk even if DB is opened with WithFS. Empty
+// path disables the cache.
+func (db *DB) SetBoardStatsCache(path string) {
+	db.boardStatsCachePath = path
+}
+
+// InvalidateBoardStatsCache removes the cache file of PrecomputeBoardStats,
+// so the next call scans all boards again.
+func (db *DB) InvalidateBoardStatsCache() error {
+	if db.boardStatsCachePath == "" {
+		return nil
+	}
+	err := os.Remove(db.boardStatsCachePath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// PrecomputeBoardStats returns the BoardStats of all boards except classes
+// in .BRD order, boards are scanned by concurrency goroutines in parallel,
+// concurrency less than 1 is treated as 1. If cache file is set by
+// SetBoardStatsCache, the cached stats are returned if any, otherwise the
+// result is written to it. It stops and returns the error of ctx when ctx is
+// done.
+func (db *DB) PrecomputeBoardStats(ctx context.Context, concurrency int) ([]BoardStats, error) {
+	if stats, ok := db.loadBoardStatsCache(); ok {
+		return stats, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	recs, err := db.ReadBoardRecords()
+	if err != nil {
+		log.Println("bbs: ReadBoardRecords error:", err)
+		return nil, err
+	}
+	boardIDs := []string{}
+	for _, r := range recs {
+		if !r.IsClass() {
+			boardIDs = append(boardIDs, r.BoardID())
+		}
+	}
+
+	// files are stamped before scanning, so writes during scan invalidate
+	// the saved stats.
+	files, err := db.boardStatsFiles(boardIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ret := make([]BoardStats, len(boardIDs))
+	jobs := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				stats, err := db.BoardStats(boardIDs[i])
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				ret[i] = stats
+			}
+		}()
+	}
+
+send:
+	for i := range boardIDs {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.saveBoardStatsCache(boardStatsCache{Stats: ret, Files: files})
+	return ret, nil
+}
+
+// boardStatsCache is the content of board stats cache file.
+type boardStatsCache struct {
+	Stats []BoardStats
+	// Files are the stamps of the board records file and the article
+	// records files of boards by path, the stats are valid while they are
+	// not changed.
+	Files map[string]fileStamp
+}
+
+// fileStamp is the modification time and size of a file, Size is -1 if the
+// file does not exist.
+type fileStamp struct {
+	ModTime time.Time
+	Size    int64
+}
+
+// stampFile returns the fileStamp of name, see statFile.
+func (db *DB) stampFile(name string) (fileStamp, error) {
+	fi, err := db.statFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fileStamp{Size: -1}, nil
+	} else if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{ModTime: fi.ModTime(), Size: fi.Size()}, nil
+}
+
+// boardStatsFiles returns the stamps of the board records file and the
+// article records files of boardIDs.
+func (db *DB) boardStatsFiles(boardIDs []string) (map[string]fileStamp, error) {
+	paths := []string{}
+	path, err := db.boardRecordsPath()
+	if err != nil {
+		log.Println("bbs: GetBoardRecordsPath error:", err)
+		return nil, err
+	}
+	paths = append(paths, path)
+	for _, boardID := range boardIDs {
+		path, err := db.boardArticleRecordsPath(boardID)
+		if err != nil {
+			log.Println("bbs: GetBoardArticleRecordsPath error:", err)
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+
+	ret := make(map[string]fileStamp, len(paths))
+	for _, path := range paths {
+		stamp, err := db.stampFile(path)
+		if err != nil {
+			log.Println("bbs: stat records file error:", err)
+			return nil, err
+		}
+		ret[path] = stamp
+	}
+	return ret, nil
+}
+
+// loadBoardStatsCache returns the stats in cache file, ok is false if cache
+// is not set, not exist, broken or any stamped file is changed.
+func (db *DB) loadBoardStatsCache() ([]BoardStats, bool) {
+	if db.boardStatsCachePath == "" {
+		return nil, false
+	}
+	f, err := os.Open(db.boardStatsCachePath)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Println("bbs: open board stats cache error:", err)
+		}
+		return nil, false
+	}
+	defer f.Close()
+
+	cache := boardStatsCache{}
+	if err := json.NewDecoder(f).Decode(&cache); err != nil {
+		log.Println("bbs: decode board stats cache error:", err)
+		return nil, false
+	}
+	if len(cache.Files) == 0 {
+		return nil, false
+	}
+	for path, stamp := range cache.Files {
+		current, err := db.stampFile(path)
+		if err != nil || current.Size != stamp.Size || !current.ModTime.Equal(stamp.ModTime) {
+			return nil, false
+		}
+	}
+	if cache.Stats == nil {
+		cache.Stats = []BoardStats{}
+	}
+	return cache.Stats, true
+}
+
+// saveBoardStatsCache writes cache to cache file if it is set, errors are
+// logged only because the stats are still valid.
+func (db *DB) saveBoardStatsCache(cache boardStatsCache) {
+	if db.boardStatsCachePath == "" {
+		return
+	}
+	err := WriteFileAtomic(db.boardStatsCachePath, 0644, func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(cache)
+	})
+	if err != nil {
+		log.Println("bbs: write board stats cache error:", err)
+	}
+}
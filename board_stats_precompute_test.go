@@ -0,0 +1,143 @@
+package bbs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestPrecomputeDB(boards int, reads *int64) *DB {
+	recs := []BoardRecord{&fakeBoardRecord{boardID: "1...........", isClass: true}}
+	for i := 0; i < boards; i++ {
+		recs = append(recs, &fakeBoardRecord{boardID: fmt.Sprintf("board%d", i)})
+	}
+	newest := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	return &DB{connector: &fakeConnector{
+		fakeGetBoardRecordsPath:        func() (string, error) { return ".BRD", nil },
+		fakeReadBoardRecordsFile:       func() ([]BoardRecord, error) { return recs, nil },
+		fakeGetBoardArticleRecordsPath: func() (string, error) { return ".DIR", nil },
+		fakeReadArticleRecordsFile: func() ([]ArticleRecord, error) {
+			atomic.AddInt64(reads, 1)
+			return []ArticleRecord{
+				&fakeArticleRecord{modified: newest, recommend: 3},
+				&fakeArticleRecord{modified: newest.Add(-time.Hour), recommend: 1},
+			}, nil
+		},
+	}}
+}
+
+func TestPrecomputeBoardStats(t *testing.T) {
+	var reads int64
+	db := newTestPrecomputeDB(20, &reads)
+	db.SetBoardStatsCache(filepath.Join(t.TempDir(), "board_stats.json"))
+
+	stats, err := db.PrecomputeBoardStats(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("PrecomputeBoardStats error: %v", err)
+	}
+	check := func(stats []BoardStats) {
+		if len(stats) != 20 {
+			t.Fatalf("stats length expected: 20, got: %v", len(stats))
+		}
+		for i, s := range stats {
+			if s.BoardID != fmt.Sprintf("board%d", i) || s.NumArticles != 2 || s.TotalRecommend != 4 ||
+				!s.LastUpdate.Equal(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)) {
+				t.Errorf("stats %d not match, got: %+v", i, s)
+			}
+		}
+	}
+	check(stats)
+	if reads != 20 {
+		t.Errorf("article records reads expected: 20, got: %v", reads)
+	}
+
+	stats, err = db.PrecomputeBoardStats(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("PrecomputeBoardStats error: %v", err)
+	}
+	check(stats)
+	if reads != 20 {
+		t.Errorf("cached stats should be reused, reads: %v", reads)
+	}
+
+	if err := db.InvalidateBoardStatsCache(); err != nil {
+		t.Fatalf("InvalidateBoardStatsCache error: %v", err)
+	}
+	if _, err := os.Stat(db.boardStatsCachePath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("cache file should be removed, got: %v", err)
+	}
+	if _, err := db.PrecomputeBoardStats(context.Background(), 0); err != nil {
+		t.Fatalf("PrecomputeBoardStats error: %v", err)
+	}
+	if reads != 40 {
+		t.Errorf("boards should be scanned again after invalidation, reads: %v", reads)
+	}
+}
+
+func TestPrecomputeBoardStatsCacheValidated(t *testing.T) {
+	dir := t.TempDir()
+	brdPath, dirPath := filepath.Join(dir, ".BRD"), filepath.Join(dir, ".DIR")
+	for _, path := range []string{brdPath, dirPath} {
+		if err := ioutil.WriteFile(path, []byte("records"), 0644); err != nil {
+			t.Fatalf("write file error: %v", err)
+		}
+	}
+
+	var reads int64
+	db := newTestPrecomputeDB(3, &reads)
+	c := db.connector.(*fakeConnector)
+	c.fakeGetBoardRecordsPath = func() (string, error) { return brdPath, nil }
+	c.fakeGetBoardArticleRecordsPath = func() (string, error) { return dirPath, nil }
+	db.SetBoardStatsCache(filepath.Join(dir, "board_stats.json"))
+
+	for i := 0; i < 2; i++ {
+		if _, err := db.PrecomputeBoardStats(context.Background(), 1); err != nil {
+			t.Fatalf("PrecomputeBoardStats error: %v", err)
+		}
+	}
+	if reads != 3 {
+		t.Errorf("cached stats should be reused, reads: %v", reads)
+	}
+
+	// a new article in the records file, such as posted by mbbsd.
+	if err := ioutil.WriteFile(dirPath, []byte("records and more"), 0644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+	if _, err := db.PrecomputeBoardStats(context.Background(), 1); err != nil {
+		t.Fatalf("PrecomputeBoardStats error: %v", err)
+	}
+	if reads != 6 {
+		t.Errorf("boards should be scanned again after records file is changed, reads: %v", reads)
+	}
+
+	modTime := time.Now().Add(time.Hour)
+	if err := os.Chtimes(brdPath, modTime, modTime); err != nil {
+		t.Fatalf("Chtimes error: %v", err)
+	}
+	if _, err := db.PrecomputeBoardStats(context.Background(), 1); err != nil {
+		t.Fatalf("PrecomputeBoardStats error: %v", err)
+	}
+	if reads != 9 {
+		t.Errorf("boards should be scanned again after board records file is changed, reads: %v", reads)
+	}
+}
+
+func TestPrecomputeBoardStatsCanceled(t *testing.T) {
+	var reads int64
+	db := newTestPrecomputeDB(20, &reads)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := db.PrecomputeBoardStats(ctx, 4); !errors.Is(err, context.Canceled) {
+		t.Errorf("PrecomputeBoardStats should return context.Canceled, got: %v", err)
+	}
+	if reads != 0 {
+		t.Errorf("no board should be scanned after cancellation, reads: %v", reads)
+	}
+}
@@ -0,0 +1,95 @@
+package bbs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBoardStats(t *testing.T) {
+	newest := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	db := &DB{connector: &fakeConnector{
+		fakeGetBoardArticleRecordsPath: func() (string, error) { return "boards/S/SYSOP/.DIR", nil },
+		fakeReadArticleRecordsFile: func() ([]ArticleRecord, error) {
+			return []ArticleRecord{
+				&fakeArticleRecord{modified: newest.Add(-time.Hour), recommend: 3},
+				&fakeArticleRecord{modified: newest, recommend: -1},
+				&fakeArticleRecord{modified: newest.Add(-2 * time.Hour), recommend: 10},
+			}, nil
+		},
+	}}
+
+	stats, err := db.BoardStats("SYSOP")
+	if err != nil {
+		t.Fatalf("BoardStats error: %v", err)
+	}
+	expected := BoardStats{BoardID: "SYSOP", NumArticles: 3, LastUpdate: newest, TotalRecommend: 12}
+	if stats != expected {
+		t.Errorf("stats not match, expected: %+v, got: %+v", expected, stats)
+	}
+}
+
+func TestNumBoardArticles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "board_stats_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dirPath := filepath.Join(dir, ".DIR")
+	if err := ioutil.WriteFile(dirPath, make([]byte, 128*5), 0644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+
+	fc := &fakeConnector{
+		fakeGetBoardArticleRecordsPath: func() (string, error) { return dirPath, nil },
+		fakeReadArticleRecordsFile: func() ([]ArticleRecord, error) {
+			t.Fatalf("records should not be read")
+			return nil, nil
+		},
+	}
+	db := &DB{connector: &fakeRecordSizerConnector{fakeConnector: fc, size: 128}}
+	n, err := db.NumBoardArticles("SYSOP")
+	if err != nil {
+		t.Fatalf("NumBoardArticles error: %v", err)
+	}
+	if n != 5 {
+		t.Errorf("NumBoardArticles expected: 5, got: %v", n)
+	}
+
+	dirPath = filepath.Join(dir, "notexist")
+	n, err = db.NumBoardArticles("SYSOP")
+	if err != nil || n != 0 {
+		t.Errorf("NumBoardArticles of not exist file expected: 0, <nil>, got: %v, %v", n, err)
+	}
+}
+
+func TestBoardArticleRange(t *testing.T) {
+	dir, err := ioutil.TempDir("", "board_stats_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dirPath := filepath.Join(dir, ".DIR")
+	if err := ioutil.WriteFile(dirPath, make([]byte, 128*3), 0644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+
+	fc := &fakeConnector{
+		fakeGetBoardArticleRecordsPath: func() (string, error) { return dirPath, nil },
+	}
+	db := &DB{connector: &fakeRecordSizerConnector{fakeConnector: fc, size: 128}}
+	first, last, err := db.BoardArticleRange("SYSOP")
+	if err != nil || first != 1 || last != 3 {
+		t.Errorf("BoardArticleRange expected: 1, 3, <nil>, got: %v, %v, %v", first, last, err)
+	}
+
+	dirPath = filepath.Join(dir, "notexist")
+	first, last, err = db.BoardArticleRange("SYSOP")
+	if err != nil || first != 0 || last != 0 {
+		t.Errorf("BoardArticleRange of empty board expected: 0, 0, <nil>, got: %v, %v, %v", first, last, err)
+	}
+}
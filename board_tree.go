@@ -0,0 +1,195 @@
+package bbs
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// UnclassifiedBoardID is the BoardID of the synthetic class node in
+// BoardTree, which holds the boards whose class can not be found.
+const UnclassifiedBoardID = "unclassified"
+
+const (
+	boardTreeParentRoot         = -1
+	boardTreeParentUnclassified = -2
+)
+
+// BoardNode is a node of board class tree, Record is nil for the root node.
+type BoardNode struct {
+	Record   BoardRecord
+	Children []*BoardNode
+}
+
+type unclassifiedBoardRecord struct{}
+
+func (unclassifiedBoardRecord) BoardID() string { return UnclassifiedBoardID }
+func (unclassifiedBoardRecord) Title() string   { return "未分類看板" }
+func (unclassifiedBoardRecord) IsClass() bool   { return true }
+func (unclassifiedBoardRecord) ClassID() string { return "" }
+func (unclassifiedBoardRecord) BM() []string    { return []string{} }
+
+// BoardTree returns the class tree of all boards, rooted at the top class.
+// ClassID of a board may refer to its class by the BoardID of the class or by
+// the 1-based position of the class in .BRD as pttbbs does.
+// Boards whose class can not be found and boards in a ClassID cycle are
+// placed under a synthetic class with UnclassifiedBoardID.
+func (db *DB) BoardTree() (*BoardNode, error) {
+	recs, err := db.ReadBoardRecords()
+	if err != nil {
+		log.Println("bbs: ReadBoardRecords error:", err)
+		return nil, err
+	}
+	return newBoardTree(recs), nil
+}
+
+// boardClassIndex returns a map from class id to the index of class in recs,
+// a class is indexed by its BoardID and its 1-based position in recs.
+func boardClassIndex(recs []BoardRecord) map[string]int {
+	classIndex := map[string]int{}
+	for i, r := range recs {
+		if r.IsClass() {
+			classIndex[strconv.Itoa(i+1)] = i
+		}
+	}
+	for i, r := range recs {
+		if r.IsClass() {
+			classIndex[r.BoardID()] = i
+		}
+	}
+	return classIndex
+}
+
+// resolveBoardClass returns the index of the class which classID refers to
+// in classIndex of boardClassIndex, so a class is found by its BoardID or
+// its 1-based position in .BRD. It returns boardTreeParentRoot for the top
+// class, and false if there is no such class.
+func resolveBoardClass(classIndex map[string]int, classID string) (int, bool) {
+	if isTopClassID(classID) {
+		return boardTreeParentRoot, true
+	}
+	i, ok := classIndex[classID]
+	return i, ok
+}
+
+func newBoardTree(recs []BoardRecord) *BoardNode {
+	classIndex := boardClassIndex(recs)
+
+	parents := make([]int, len(recs))
+	for i, r := range recs {
+		classID := r.ClassID()
+		p, ok := resolveBoardClass(classIndex, classID)
+		if !ok || p == i {
+			log.Println("bbs: class not found for board:", r.BoardID(), classID)
+			parents[i] = boardTreeParentUnclassified
+			continue
+		}
+		parents[i] = p
+	}
+	breakBoardTreeCycles(recs, parents)
+
+	root := &BoardNode{Children: []*BoardNode{}}
+	unclassified := &BoardNode{Record: unclassifiedBoardRecord{}, Children: []*BoardNode{}}
+	nodes := make([]*BoardNode, len(recs))
+	for i, r := range recs {
+		nodes[i] = &BoardNode{Record: r, Children: []*BoardNode{}}
+	}
+	for i, p := range parents {
+		switch p {
+		case boardTreeParentRoot:
+			root.Children = append(root.Children, nodes[i])
+		case boardTreeParentUnclassified:
+			unclassified.Children = append(unclassified.Children, nodes[i])
+		default:
+			nodes[p].Children = append(nodes[p].Children, nodes[i])
+		}
+	}
+	if len(unclassified.Children) != 0 {
+		root.Children = append(root.Children, unclassified)
+	}
+	return root
+}
+
+// breakBoardTreeCycles walks up from each board, and moves the board which
+// closes a cycle to the unclassified class.
+func breakBoardTreeCycles(recs []BoardRecord, parents []int) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(parents))
+	for i := range parents {
+		path := []int{}
+		cur := i
+		for cur >= 0 && state[cur] == unvisited {
+			state[cur] = visiting
+			path = append(path, cur)
+			next := parents[cur]
+			if next >= 0 && state[next] == visiting {
+				log.Println("bbs: class cycle found at board:", recs[cur].BoardID())
+				parents[cur] = boardTreeParentUnclassified
+				break
+			}
+			cur = next
+		}
+		for _, p := range path {
+			state[p] = visited
+		}
+	}
+}
+
+// IsBoardInClass returns true if board is under class classID directly or
+// through its parent classes. boardID is matched case-insensitively, classID
+// is matched as ClassID of BoardRecord as in BoardTree, and every board is
+// under the top class ("" or "1"). A class is not under itself. Walking up
+// stops with false if a ClassID cycle is found. It returns ErrRecordNotFound
+// if there is no such board, and ErrInvalidBoardID if boardID is invalid.
+func (db *DB) IsBoardInClass(boardID, classID string) (bool, error) {
+	boardID, err := db.normalizeBoardID(boardID)
+	if err != nil {
+		return false, err
+	}
+	recs, err := db.ReadBoardRecords()
+	if err != nil {
+		log.Println("bbs: ReadBoardRecords error:", err)
+		return false, err
+	}
+
+	cur := -1
+	for i, r := range recs {
+		if strings.EqualFold(r.BoardID(), boardID) {
+			cur = i
+			break
+		}
+	}
+	if cur < 0 {
+		return false, fmt.Errorf("%w: board %q", ErrRecordNotFound, boardID)
+	}
+	if isTopClassID(classID) {
+		return true, nil
+	}
+
+	classIndex := boardClassIndex(recs)
+	target, ok := resolveBoardClass(classIndex, classID)
+	if !ok {
+		return false, nil
+	}
+	visited := map[int]bool{cur: true}
+	for {
+		p, ok := resolveBoardClass(classIndex, recs[cur].ClassID())
+		if !ok || p == boardTreeParentRoot {
+			return false, nil
+		}
+		if visited[p] {
+			log.Println("bbs: class cycle found at board:", recs[p].BoardID())
+			return false, nil
+		}
+		if p == target {
+			return true, nil
+		}
+		visited[p] = true
+		cur = p
+	}
+}
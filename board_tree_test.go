@@ -0,0 +1,125 @@
+package bbs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func boardTreeIDs(node *BoardNode) map[string][]string {
+	ret := map[string][]string{}
+	var walk func(n *BoardNode)
+	walk = func(n *BoardNode) {
+		id := ""
+		if n.Record != nil {
+			id = n.Record.BoardID()
+		}
+		if len(n.Children) == 0 {
+			return
+		}
+		ret[id] = []string{}
+		for _, c := range n.Children {
+			ret[id] = append(ret[id], c.Record.BoardID())
+			walk(c)
+		}
+	}
+	walk(node)
+	return ret
+}
+
+func TestNewBoardTree(t *testing.T) {
+	recs := []BoardRecord{
+		&fakeBoardRecord{boardID: "SYSOP", classID: "2"},
+		&fakeBoardRecord{boardID: "1...........", isClass: true, classID: "1"},
+		&fakeBoardRecord{boardID: "junk", classID: "2"},
+		&fakeBoardRecord{boardID: "Sub", isClass: true, classID: "1..........."},
+		&fakeBoardRecord{boardID: "Test", classID: "Sub"},
+		&fakeBoardRecord{boardID: "Orphan", classID: "404"},
+		&fakeBoardRecord{boardID: "CycleA", isClass: true, classID: "CycleB"},
+		&fakeBoardRecord{boardID: "CycleB", isClass: true, classID: "CycleA"},
+		&fakeBoardRecord{boardID: "Self", isClass: true, classID: "Self"},
+	}
+
+	expected := map[string][]string{
+		"":                  {"1...........", UnclassifiedBoardID},
+		"1...........":      {"SYSOP", "junk", "Sub"},
+		"Sub":               {"Test"},
+		UnclassifiedBoardID: {"Orphan", "CycleB", "Self"},
+		"CycleB":            {"CycleA"},
+	}
+
+	actual := boardTreeIDs(newBoardTree(recs))
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("board tree not match, expected: %v, got: %v", expected, actual)
+	}
+}
+
+func TestBoardTreeWithoutUnclassified(t *testing.T) {
+	db := newTestBoardRecordsDB()
+	root, err := db.BoardTree()
+	if err != nil {
+		t.Fatalf("BoardTree error: %v", err)
+	}
+	for _, c := range root.Children {
+		if c.Record.BoardID() == UnclassifiedBoardID && len(c.Children) == 0 {
+			t.Errorf("empty unclassified node should not be added")
+		}
+	}
+}
+
+func TestIsBoardInClass(t *testing.T) {
+	recs := []BoardRecord{
+		&fakeBoardRecord{boardID: "SYSOP", classID: "2"},
+		&fakeBoardRecord{boardID: "1...........", isClass: true, classID: "1"},
+		&fakeBoardRecord{boardID: "junk", classID: "2"},
+		&fakeBoardRecord{boardID: "Sub", isClass: true, classID: "1..........."},
+		&fakeBoardRecord{boardID: "Test", classID: "Sub"},
+		&fakeBoardRecord{boardID: "Orphan", classID: "404"},
+		&fakeBoardRecord{boardID: "CycleA", isClass: true, classID: "CycleB"},
+		&fakeBoardRecord{boardID: "CycleB", isClass: true, classID: "CycleA"},
+		&fakeBoardRecord{boardID: "InCycle", classID: "CycleA"},
+	}
+	db := &DB{
+		connector: &fakeConnector{
+			fakeGetBoardRecordsPath: func() (string, error) {
+				return ".BRD", nil
+			},
+			fakeReadBoardRecordsFile: func() ([]BoardRecord, error) {
+				return recs, nil
+			},
+		},
+	}
+
+	tests := []struct {
+		boardID  string
+		classID  string
+		expected bool
+	}{
+		{"Test", "Sub", true},
+		{"test", "1...........", true},
+		{"Test", "2", true},
+		{"Test", "", true},
+		{"SYSOP", "1...........", true},
+		{"SYSOP", "Sub", false},
+		{"Sub", "Sub", false},
+		{"Orphan", "1...........", false},
+		{"Test", "404", false},
+		{"InCycle", "CycleB", true},
+		{"InCycle", "Sub", false},
+		{"CycleA", "CycleA", false},
+	}
+	for _, tt := range tests {
+		actual, err := db.IsBoardInClass(tt.boardID, tt.classID)
+		if err != nil {
+			t.Errorf("IsBoardInClass(%q, %q) error: %v", tt.boardID, tt.classID, err)
+			continue
+		}
+		if actual != tt.expected {
+			t.Errorf("IsBoardInClass(%q, %q) expected: %v, got: %v", tt.boardID, tt.classID, tt.expected, actual)
+		}
+	}
+
+	if _, err := db.IsBoardInClass("404", "Sub"); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("IsBoardInClass should return ErrRecordNotFound, got: %v", err)
+	}
+}
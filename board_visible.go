@@ -0,0 +1,55 @@
+package bbs
+
+import "log"
+
+// isUserOver18 returns true if u implements Over18UserRecord and has
+// declared to be an adult.
+func isUserOver18(u UserRecord) bool {
+	ou, ok := u.(Over18UserRecord)
+	return ok && ou.IsOver18()
+}
+
+// isBoardVisible returns true if u can see board r in menus. Sysops and
+// board admins see all boards, hidden boards are visible to their BM only,
+// and over-18 boards are visible to adults only. u nil is a guest.
+func isBoardVisible(r BoardRecord, u UserRecord) bool {
+	a := BoardAttributes(r)
+	if a == 0 {
+		return true
+	}
+	if u != nil {
+		f := UserFlags(u)
+		if f.IsSysop() || f.Has(UserFlagBoardAdmin) {
+			return true
+		}
+	}
+	if a.IsHidden() && (u == nil || !isBoardBM(r, u.UserID())) {
+		return false
+	}
+	if a.Over18() && (u == nil || !isUserOver18(u)) {
+		return false
+	}
+	return true
+}
+
+// ReadVisibleBoards returns the boards which u can see in board menus, in
+// .BRD order. Class entries are skipped, and boards are filtered by their
+// attributes and the flags of u, see BoardAttributes and UserFlags. Board
+// friends lists are not read, so hidden boards are visible to their BM,
+// sysops and board admins only. Boards of drivers without attributes are
+// all visible. u nil is a guest.
+func (db *DB) ReadVisibleBoards(u UserRecord) ([]BoardRecord, error) {
+	recs, err := db.ReadBoardRecords()
+	if err != nil {
+		log.Println("bbs: ReadBoardRecords error:", err)
+		return nil, err
+	}
+
+	ret := []BoardRecord{}
+	for _, r := range recs {
+		if !r.IsClass() && isBoardVisible(r, u) {
+			ret = append(ret, r)
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,64 @@
+package bbs
+
+import (
+	"reflect"
+	"testing"
+)
+
+type fakeOver18UserRecord struct {
+	fakeFlaggedUserRecord
+	over18 bool
+}
+
+func (u *fakeOver18UserRecord) IsOver18() bool { return u.over18 }
+
+func TestReadVisibleBoards(t *testing.T) {
+	recs := []BoardRecord{
+		&fakeBoardRecord{boardID: "1...........", isClass: true, classID: "1"},
+		&fakeBoardRecord{boardID: "SYSOP", classID: "2"},
+		&fakeAttributedBoardRecord{fakeBoardRecord: fakeBoardRecord{boardID: "Secret", classID: "2", bm: []string{"pichu"}}, attr: BoardAttrHidden},
+		&fakeAttributedBoardRecord{fakeBoardRecord: fakeBoardRecord{boardID: "Adult", classID: "2"}, attr: BoardAttrOver18},
+		&fakeAttributedBoardRecord{fakeBoardRecord: fakeBoardRecord{boardID: "Test", classID: "2"}, attr: BoardAttrNoBoo},
+	}
+	db := &DB{
+		connector: &fakeConnector{
+			fakeGetBoardRecordsPath: func() (string, error) {
+				return ".BRD", nil
+			},
+			fakeReadBoardRecordsFile: func() ([]BoardRecord, error) {
+				return recs, nil
+			},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		user     UserRecord
+		expected []string
+	}{
+		{name: "guest", user: nil, expected: []string{"SYSOP", "Test"}},
+		{name: "user without support", user: &fakeUserRecord{userID: "Kahou"}, expected: []string{"SYSOP", "Test"}},
+		{name: "adult", user: &fakeOver18UserRecord{fakeFlaggedUserRecord: fakeFlaggedUserRecord{fakeUserRecord: fakeUserRecord{userID: "Kahou"}}, over18: true}, expected: []string{"SYSOP", "Adult", "Test"}},
+		{name: "BM", user: &fakeOver18UserRecord{fakeFlaggedUserRecord: fakeFlaggedUserRecord{fakeUserRecord: fakeUserRecord{userID: "Pichu"}}}, expected: []string{"SYSOP", "Secret", "Test"}},
+		{name: "sysop", user: &fakeFlaggedUserRecord{fakeUserRecord: fakeUserRecord{userID: "SYSOP"}, flags: UserFlagSysop}, expected: []string{"SYSOP", "Secret", "Adult", "Test"}},
+	}
+	for _, c := range testCases {
+		actual, err := db.ReadVisibleBoards(c.user)
+		if err != nil {
+			t.Fatalf("%s: ReadVisibleBoards error: %v", c.name, err)
+		}
+		if ids := boardIDs(actual); !reflect.DeepEqual(ids, c.expected) {
+			t.Errorf("%s: boards expected: %v, got: %v", c.name, c.expected, ids)
+		}
+	}
+
+	db = newTestBoardRecordsDB()
+	actual, err := db.ReadVisibleBoards(nil)
+	if err != nil {
+		t.Fatalf("ReadVisibleBoards error: %v", err)
+	}
+	expected := []string{"SYSOP", "junk", "Test", "Raw"}
+	if ids := boardIDs(actual); !reflect.DeepEqual(ids, expected) {
+		t.Errorf("boards without attributes expected: %v, got: %v", expected, ids)
+	}
+}
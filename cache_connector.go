@@ -0,0 +1,241 @@
+package bbs
+
+import (
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	value    interface{}
+	expireAt time.Time
+
+	// modTime and size are the stat of file when value is read, they are
+	// set only if stat validation is enabled.
+	modTime time.Time
+	size    int64
+}
+
+// cacheConnector memoizes user and board records for ttl.
+type cacheConnector struct {
+	Connector
+	ttl time.Duration
+
+	// stat is used to validate entries against the modification time and
+	// size of file, nil disables validation.
+	stat func(name string) (fs.FileInfo, error)
+
+	mutex   sync.RWMutex
+	entries map[string]cacheEntry
+}
+
+// cacheWriteBoardConnector is cacheConnector for the connector which
+// implements WriteBoardConnector, it invalidates board records on writes.
+type cacheWriteBoardConnector struct {
+	*cacheConnector
+	wbc WriteBoardConnector
+}
+
+// CacheOption configures the connector returned by WithCache.
+type CacheOption func(c *cacheConnector)
+
+// CacheValidateStat returns CacheOption which validates cached records
+// against the modification time and size of their file on every read, so
+// records are read again as soon as the file is modified by another
+// process, without waiting for ttl. stat is called with the file name
+// passed to the connector, nil means os.Stat.
+func CacheValidateStat(stat func(name string) (fs.FileInfo, error)) CacheOption {
+	if stat == nil {
+		stat = os.Stat
+	}
+	return func(c *cacheConnector) {
+		c.stat = stat
+	}
+}
+
+// WithCache returns a connector which memoizes the records read by
+// ReadUserRecordsFile and ReadBoardRecordsFile for ttl, records are read
+// again after ttl expired. Writes through WriteBoardConnector invalidate the
+// cached board records of that file, see CacheValidateStat for detecting
+// writes of other processes. It is safe for concurrent use.
+func WithCache(c Connector, ttl time.Duration, opts ...CacheOption) Connector {
+	cc := &cacheConnector{
+		Connector: c,
+		ttl:       ttl,
+		entries:   map[string]cacheEntry{},
+	}
+	for _, opt := range opts {
+		opt(cc)
+	}
+	if wbc, ok := c.(WriteBoardConnector); ok {
+		return &cacheWriteBoardConnector{cacheConnector: cc, wbc: wbc}
+	}
+	return cc
+}
+
+// Unwrap returns the connector wrapped by cache.
+func (c *cacheConnector) Unwrap() Connector {
+	return c.Connector
+}
+
+// fileStat returns the modification time and size of file name, ok is
+// false if stat validation is disabled or stat fails.
+func (c *cacheConnector) fileStat(name string) (modTime time.Time, size int64, ok bool) {
+	if c.stat == nil {
+		return time.Time{}, 0, false
+	}
+	fi, err := c.stat(name)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	return fi.ModTime(), fi.Size(), true
+}
+
+// get returns the cached value of key read from file name, it misses if the
+// entry expired or the file is modified since the value is read.
+func (c *cacheConnector) get(key, name string) (interface{}, bool) {
+	c.mutex.RLock()
+	e, ok := c.entries[key]
+	c.mutex.RUnlock()
+	if !ok || time.Now().After(e.expireAt) {
+		return nil, false
+	}
+	if c.stat != nil {
+		modTime, size, ok := c.fileStat(name)
+		if !ok || !modTime.Equal(e.modTime) || size != e.size {
+			return nil, false
+		}
+	}
+	return e.value, true
+}
+
+// load returns the value of key by get, or by read and caches it. The file
+// is stat before read, so a modification during read is detected next time.
+// If read fails, its value is returned with the error without caching.
+func (c *cacheConnector) load(key, name string, read func() (interface{}, error)) (interface{}, error) {
+	if v, ok := c.get(key, name); ok {
+		return v, nil
+	}
+	modTime, size, statOK := c.fileStat(name)
+	v, err := read()
+	if err != nil {
+		return v, err
+	}
+	if c.stat != nil && !statOK {
+		return v, nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries[key] = cacheEntry{
+		value:    v,
+		expireAt: time.Now().Add(c.ttl),
+		modTime:  modTime,
+		size:     size,
+	}
+	return v, nil
+}
+
+func (c *cacheConnector) invalidate(key string) {
+	c.mutex.Lock()
+	delete(c.entries, key)
+	c.mutex.Unlock()
+	invalidateWrapped(c.Connector, key)
+}
+
+// invalidateWrapped drops the cached records of key in the first cache
+// wrapped by c, so a key reaches every cache when caches wrap each other.
+func invalidateWrapped(c Connector, key string) {
+	for c != nil {
+		if ci, ok := c.(cacheInvalidator); ok {
+			ci.invalidate(key)
+			return
+		}
+		w, ok := c.(ConnectorWrapper)
+		if !ok {
+			return
+		}
+		c = w.Unwrap()
+	}
+}
+
+// cacheInvalidator is implemented by cacheConnector, DB uses it to drop the
+// cached records after writing through a connector which cache does not
+// wrap, such as WriteUserConnector.
+type cacheInvalidator interface {
+	invalidate(key string)
+}
+
+// invalidateCache drops the cached records of key if connector is wrapped
+// by WithCache.
+func (db *DB) invalidateCache(key string) {
+	var ci cacheInvalidator
+	if db.connectorAs(&ci) {
+		ci.invalidate(key)
+	}
+}
+
+func userRecordsCacheKey(name string) string  { return "user:" + name }
+func boardRecordsCacheKey(name string) string { return "board:" + name }
+
+func (c *cacheConnector) ReadUserRecordsFile(name string) ([]UserRecord, error) {
+	v, err := c.load(userRecordsCacheKey(name), name, func() (interface{}, error) {
+		return c.Connector.ReadUserRecordsFile(name)
+	})
+	if err != nil {
+		recs, _ := v.([]UserRecord)
+		return recs, err
+	}
+	return append([]UserRecord{}, v.([]UserRecord)...), nil
+}
+
+func (c *cacheConnector) ReadBoardRecordsFile(name string) ([]BoardRecord, error) {
+	v, err := c.load(boardRecordsCacheKey(name), name, func() (interface{}, error) {
+		return c.Connector.ReadBoardRecordsFile(name)
+	})
+	if err != nil {
+		recs, _ := v.([]BoardRecord)
+		return recs, err
+	}
+	return append([]BoardRecord{}, v.([]BoardRecord)...), nil
+}
+
+func (c *cacheWriteBoardConnector) NewBoardRecord(args map[string]interface{}) (BoardRecord, error) {
+	return c.wbc.NewBoardRecord(args)
+}
+
+func (c *cacheWriteBoardConnector) AddBoardRecordFileRecord(name string, brd BoardRecord) error {
+	defer c.invalidate(boardRecordsCacheKey(name))
+	return c.wbc.AddBoardRecordFileRecord(name, brd)
+}
+
+func (c *cacheWriteBoardConnector) AddBoardRecordFileRecords(name string, brds []BoardRecord) (uint, error) {
+	defer c.invalidate(boardRecordsCacheKey(name))
+	return c.wbc.AddBoardRecordFileRecords(name, brds)
+}
+
+func (c *cacheWriteBoardConnector) UpdateBoardRecordFileRecord(name string, index uint, brd BoardRecord) error {
+	defer c.invalidate(boardRecordsCacheKey(name))
+	return c.wbc.UpdateBoardRecordFileRecord(name, index, brd)
+}
+
+func (c *cacheWriteBoardConnector) ReadBoardRecordFileRecord(name string, index uint) (BoardRecord, error) {
+	return c.wbc.ReadBoardRecordFileRecord(name, index)
+}
+
+func (c *cacheWriteBoardConnector) RemoveBoardRecordFileRecord(name string, index uint) error {
+	defer c.invalidate(boardRecordsCacheKey(name))
+	return c.wbc.RemoveBoardRecordFileRecord(name, index)
+}
+
+func (c *cacheWriteBoardConnector) MoveBoardRecordFileRecord(name string, from, to uint) error {
+	defer c.invalidate(boardRecordsCacheKey(name))
+	return c.wbc.MoveBoardRecordFileRecord(name, from, to)
+}
+
+func (c *cacheWriteBoardConnector) NumBoardRecordFileRecord(name string) (uint, error) {
+	return c.wbc.NumBoardRecordFileRecord(name)
+}
+
+var _ WriteBoardConnector = &cacheWriteBoardConnector{}
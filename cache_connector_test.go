@@ -0,0 +1,251 @@
+package bbs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeWriteBoardConnector struct {
+	fakeConnector
+	records []BoardRecord
+}
+
+var _ WriteBoardConnector = &fakeWriteBoardConnector{}
+
+func (c *fakeWriteBoardConnector) NewBoardRecord(args map[string]interface{}) (BoardRecord, error) {
+	return &fakeBoardRecord{boardID: args["board_id"].(string)}, nil
+}
+
+func (c *fakeWriteBoardConnector) AddBoardRecordFileRecord(name string, brd BoardRecord) error {
+	c.records = append(c.records, brd)
+	return nil
+}
+
+func (c *fakeWriteBoardConnector) AddBoardRecordFileRecords(name string, brds []BoardRecord) (uint, error) {
+	index := uint(len(c.records))
+	c.records = append(c.records, brds...)
+	return index, nil
+}
+
+func (c *fakeWriteBoardConnector) UpdateBoardRecordFileRecord(name string, index uint, brd BoardRecord) error {
+	c.records[index] = brd
+	return nil
+}
+
+func (c *fakeWriteBoardConnector) ReadBoardRecordFileRecord(name string, index uint) (BoardRecord, error) {
+	return c.records[index], nil
+}
+
+func (c *fakeWriteBoardConnector) RemoveBoardRecordFileRecord(name string, index uint) error {
+	c.records = append(c.records[:index], c.records[index+1:]...)
+	return nil
+}
+
+func (c *fakeWriteBoardConnector) MoveBoardRecordFileRecord(name string, from, to uint) error {
+	if from >= uint(len(c.records)) || to >= uint(len(c.records)) {
+		return ErrRecordNotFound
+	}
+	brd := c.records[from]
+	c.records = append(c.records[:from], c.records[from+1:]...)
+	c.records = append(c.records[:to], append([]BoardRecord{brd}, c.records[to:]...)...)
+	return nil
+}
+
+func (c *fakeWriteBoardConnector) NumBoardRecordFileRecord(name string) (uint, error) {
+	return uint(len(c.records)), nil
+}
+
+func newFakeWriteBoardConnector(reads *int) *fakeWriteBoardConnector {
+	c := &fakeWriteBoardConnector{}
+	c.fakeGetBoardRecordsPath = func() (string, error) {
+		return ".BRD", nil
+	}
+	c.fakeReadBoardRecordsFile = func() ([]BoardRecord, error) {
+		*reads++
+		return append([]BoardRecord{}, c.records...), nil
+	}
+	return c
+}
+
+func TestWithCacheBoardRecords(t *testing.T) {
+	reads := 0
+	db := &DB{connector: WithCache(newFakeWriteBoardConnector(&reads), time.Hour)}
+
+	for i := 0; i < 3; i++ {
+		if _, err := db.ReadBoardRecords(); err != nil {
+			t.Fatalf("ReadBoardRecords error: %v", err)
+		}
+	}
+	if reads != 1 {
+		t.Errorf("board records should be read once, got: %d", reads)
+	}
+
+	brd, err := db.NewBoardRecord(map[string]interface{}{"board_id": "SYSOP"})
+	if err != nil {
+		t.Fatalf("NewBoardRecord error: %v", err)
+	}
+	if err := db.AddBoardRecord(brd); err != nil {
+		t.Fatalf("AddBoardRecord error: %v", err)
+	}
+
+	recs, err := db.ReadBoardRecords()
+	if err != nil {
+		t.Fatalf("ReadBoardRecords error: %v", err)
+	}
+	if reads != 2 {
+		t.Errorf("board records should be read again after write, got: %d", reads)
+	}
+	if len(recs) != 1 || recs[0].BoardID() != "SYSOP" {
+		t.Errorf("board records not match after write, got: %v", boardIDs(recs))
+	}
+}
+
+func TestWithCacheExpired(t *testing.T) {
+	reads := 0
+	db := &DB{connector: WithCache(newFakeWriteBoardConnector(&reads), time.Nanosecond)}
+
+	for i := 0; i < 2; i++ {
+		if _, err := db.ReadBoardRecords(); err != nil {
+			t.Fatalf("ReadBoardRecords error: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if reads != 2 {
+		t.Errorf("board records should be read again after ttl, got: %d", reads)
+	}
+}
+
+func TestWithCacheUnwrap(t *testing.T) {
+	db := &DB{connector: WithCache(&fakeMailConnector{}, time.Hour)}
+
+	var mc MailConnector
+	if !db.connectorAs(&mc) {
+		t.Errorf("MailConnector should be found through cache connector")
+	}
+
+	var wbc WriteBoardConnector
+	if db.connectorAs(&wbc) {
+		t.Errorf("WriteBoardConnector should not be found")
+	}
+}
+
+func TestWithCacheConcurrentRead(t *testing.T) {
+	reads := 0
+	c := newFakeWriteBoardConnector(&reads)
+	c.records = testBoardRecords
+	db := &DB{connector: WithCache(c, time.Hour)}
+	if _, err := db.ReadBoardRecords(); err != nil {
+		t.Fatalf("ReadBoardRecords error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			recs, err := db.ReadBoardRecords()
+			if err != nil || len(recs) != len(testBoardRecords) {
+				t.Errorf("ReadBoardRecords got %v records, error: %v", len(recs), err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestAddBoardRecords(t *testing.T) {
+	reads := 0
+	db := &DB{connector: WithCache(newFakeWriteBoardConnector(&reads), time.Hour)}
+
+	if _, err := db.ReadBoardRecords(); err != nil {
+		t.Fatalf("ReadBoardRecords error: %v", err)
+	}
+
+	var brds []BoardRecord
+	for _, id := range []string{"SYSOP", "Test"} {
+		brd, _ := db.NewBoardRecord(map[string]interface{}{"board_id": id})
+		brds = append(brds, brd)
+	}
+	added := 0
+	db.SetWriteHook(func(op string, path string, index uint, rec interface{}) {
+		added++
+	})
+	if err := db.AddBoardRecords(brds); err != nil {
+		t.Fatalf("AddBoardRecords error: %v", err)
+	}
+	if added != len(brds) {
+		t.Errorf("write hook should be called %d times, got: %d", len(brds), added)
+	}
+
+	recs, err := db.ReadBoardRecords()
+	if err != nil {
+		t.Fatalf("ReadBoardRecords error: %v", err)
+	}
+	if len(recs) != len(brds) || reads != 2 {
+		t.Errorf("board records should be invalidated, got: %d records, %d reads", len(recs), reads)
+	}
+}
+
+func TestWithCacheValidateStat(t *testing.T) {
+	name := filepath.Join(t.TempDir(), ".BRD")
+	if err := ioutil.WriteFile(name, []byte("0123"), 0644); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+
+	reads := 0
+	c := newFakeWriteBoardConnector(&reads)
+	c.fakeGetBoardRecordsPath = func() (string, error) {
+		return name, nil
+	}
+	db := &DB{connector: WithCache(c, time.Hour, CacheValidateStat(nil))}
+
+	for i := 0; i < 3; i++ {
+		if _, err := db.ReadBoardRecords(); err != nil {
+			t.Fatalf("ReadBoardRecords error: %v", err)
+		}
+	}
+	if reads != 1 {
+		t.Errorf("board records should be read once while file is unchanged, got: %d", reads)
+	}
+
+	// another process appends a record.
+	c.records = append(c.records, &fakeBoardRecord{boardID: "SYSOP"})
+	if err := ioutil.WriteFile(name, []byte("01234567"), 0644); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+	recs, err := db.ReadBoardRecords()
+	if err != nil {
+		t.Fatalf("ReadBoardRecords error: %v", err)
+	}
+	if reads != 2 || len(recs) != 1 {
+		t.Errorf("board records should be read again after file modified, reads: %d, records: %v", reads, boardIDs(recs))
+	}
+
+	// same size, modification time only.
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(name, future, future); err != nil {
+		t.Fatalf("Chtimes error: %v", err)
+	}
+	if _, err := db.ReadBoardRecords(); err != nil {
+		t.Fatalf("ReadBoardRecords error: %v", err)
+	}
+	if reads != 3 {
+		t.Errorf("board records should be read again after modification time changed, got: %d", reads)
+	}
+
+	// records are not cached if file can not be stat.
+	if err := os.Remove(name); err != nil {
+		t.Fatalf("Remove error: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := db.ReadBoardRecords(); err != nil {
+			t.Fatalf("ReadBoardRecords error: %v", err)
+		}
+	}
+	if reads != 5 {
+		t.Errorf("board records should not be cached without stat, got: %d", reads)
+	}
+}
@@ -0,0 +1,151 @@
+package bbs
+
+// Capabilities is the optional features supported by connector of DB, so
+// frontends can enable or disable features without probing connector
+// interfaces themselves.
+type Capabilities struct {
+	// ReadOnly is true if DB is opened with WithFS, Can* writes are all
+	// false then.
+	ReadOnly bool
+
+	// CanWriteBoards is true if boards can be added, updated and removed,
+	// see WriteBoardConnector.
+	CanWriteBoards bool
+	// CanWriteUsers is true if users can be created, see
+	// WriteUserConnector.
+	CanWriteUsers bool
+	// CanWriteArticles is true if articles can be posted, see
+	// WriteArticleConnector.
+	CanWriteArticles bool
+	// CanChangePasswords is true if passwords can be rewritten, see
+	// PasswordConnector.
+	CanChangePasswords bool
+	// CanAdjustMoney is true if money of users can be adjusted, see
+	// MoneyConnector.
+	CanAdjustMoney bool
+	// CanSetBadPosts is true if number of bad posts of users can be set,
+	// see BadPostConnector.
+	CanSetBadPosts bool
+	// CanSetNumPosts is true if number of posts of users can be set, see
+	// NumPostsConnector.
+	CanSetNumPosts bool
+
+	// HasUserArticleCache is true if the articles of each user are cached,
+	// see UserArticleConnector.
+	HasUserArticleCache bool
+	// HasUserComments is true if the comments of each user are recorded,
+	// see UserCommentConnector.
+	HasUserComments bool
+	// HasUserDrafts is true if drafts of users can be read, see
+	// UserDraftConnector.
+	HasUserDrafts bool
+
+	// SupportsMail is true if mailboxes of users can be read, see
+	// MailConnector.
+	SupportsMail bool
+	// SupportsUtmp is true if online users can be listed, see
+	// UtmpConnector.
+	SupportsUtmp bool
+	// SupportsBoardInfo is true if board info files can be read, see
+	// BoardInfoConnector.
+	SupportsBoardInfo bool
+	// SupportsPlans is true if plan files of users can be read, see
+	// PlanConnector.
+	SupportsPlans bool
+	// SupportsRelations is true if friends and rejects of users can be
+	// read, see RelationConnector.
+	SupportsRelations bool
+	// SupportsReadState is true if unread markers of boards can be read, see
+	// ReadStateConnector.
+	SupportsReadState bool
+	// SupportsSystemFiles is true if files under etc directory, such as
+	// MOTD, can be read, see SystemFileConnector.
+	SupportsSystemFiles bool
+	// SupportsRangedReads is true if a range of records is read without
+	// reading the whole file, see RecordSizer and RecordDecoder.
+	SupportsRangedReads bool
+	// SupportsMmap is true if article records can be memory-mapped, see
+	// MmapConnector.
+	SupportsMmap bool
+	// SupportsLockTimeout is true if timeout of file locks can be set, see
+	// LockConnector.
+	SupportsLockTimeout bool
+}
+
+// Driver which implement CapabilitiesConnector declares which features it
+// really supports, such as when a method of an implemented interface is not
+// available in its configuration or on the platform.
+type CapabilitiesConnector interface {
+	// Capabilities is called with the features probed from the interfaces
+	// implemented by connector, and returns them with the unsupported ones
+	// turned off.
+	Capabilities(caps Capabilities) Capabilities
+}
+
+// Capabilities returns the features supported by connector, they are
+// computed once from the interfaces implemented by connector and the
+// connectors it wraps, then declared by CapabilitiesConnector if connector
+// implements it.
+func (db *DB) Capabilities() Capabilities {
+	db.capabilitiesOnce.Do(func() {
+		db.capabilities = db.computeCapabilities()
+	})
+	return db.capabilities
+}
+
+func (db *DB) computeCapabilities() Capabilities {
+	var (
+		wbc WriteBoardConnector
+		wuc WriteUserConnector
+		wac WriteArticleConnector
+		pc  PasswordConnector
+		mc  MoneyConnector
+		bpc BadPostConnector
+		npc NumPostsConnector
+		uac UserArticleConnector
+		ucc UserCommentConnector
+		udc UserDraftConnector
+		mlc MailConnector
+		uc  UtmpConnector
+		bic BoardInfoConnector
+		plc PlanConnector
+		rc  RelationConnector
+		rsc ReadStateConnector
+		sfc SystemFileConnector
+		mmc MmapConnector
+		lc  LockConnector
+		cc  CapabilitiesConnector
+	)
+	_, _, ranged := db.rangeConnector()
+	writable := db.fsys == nil
+	caps := Capabilities{
+		ReadOnly: !writable,
+
+		CanWriteBoards:     writable && db.connectorAs(&wbc),
+		CanWriteUsers:      writable && db.connectorAs(&wuc),
+		CanWriteArticles:   writable && db.connectorAs(&wac),
+		CanChangePasswords: writable && db.connectorAs(&pc),
+		CanAdjustMoney:     writable && db.connectorAs(&mc),
+		CanSetBadPosts:     writable && db.connectorAs(&bpc),
+		CanSetNumPosts:     writable && db.connectorAs(&npc),
+
+		HasUserArticleCache: db.connectorAs(&uac),
+		HasUserComments:     db.connectorAs(&ucc),
+		HasUserDrafts:       db.connectorAs(&udc),
+
+		SupportsMail:        db.connectorAs(&mlc),
+		SupportsUtmp:        db.connectorAs(&uc),
+		SupportsBoardInfo:   db.connectorAs(&bic),
+		SupportsPlans:       db.connectorAs(&plc),
+		SupportsRelations:   db.connectorAs(&rc),
+		SupportsReadState:   db.connectorAs(&rsc),
+		SupportsSystemFiles: db.connectorAs(&sfc),
+		SupportsRangedReads: ranged,
+		SupportsMmap:        db.connectorAs(&mmc),
+		SupportsLockTimeout: db.connectorAs(&lc),
+	}
+	if db.connectorAs(&cc) {
+		caps = cc.Capabilities(caps)
+	}
+	return caps
+}
@@ -0,0 +1,67 @@
+package bbs
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+type fakeCapabilitiesConnector struct {
+	fakeWriteBoardConnector
+}
+
+func (c *fakeCapabilitiesConnector) ReadUtmp() ([]UtmpRecord, error) {
+	return nil, nil
+}
+
+func (c *fakeCapabilitiesConnector) ReadSystemFile(relPath string) ([]byte, error) {
+	return nil, nil
+}
+
+type fakeDeclaredCapabilitiesConnector struct {
+	fakeCapabilitiesConnector
+}
+
+func (c *fakeDeclaredCapabilitiesConnector) Capabilities(caps Capabilities) Capabilities {
+	caps.CanWriteBoards = false
+	return caps
+}
+
+func TestCapabilities(t *testing.T) {
+	db := &DB{connector: &fakeCapabilitiesConnector{}}
+	expected := Capabilities{
+		CanWriteBoards:      true,
+		SupportsUtmp:        true,
+		SupportsSystemFiles: true,
+	}
+	if actual := db.Capabilities(); actual != expected {
+		t.Errorf("Capabilities expected: %+v, got: %+v", expected, actual)
+	}
+
+	db = &DB{connector: WithCache(&fakeCapabilitiesConnector{}, 0), fsys: fstest.MapFS{}}
+	expected = Capabilities{
+		ReadOnly:            true,
+		SupportsUtmp:        true,
+		SupportsSystemFiles: true,
+	}
+	if actual := db.Capabilities(); actual != expected {
+		t.Errorf("Capabilities of read-only wrapped connector expected: %+v, got: %+v", expected, actual)
+	}
+
+	db = &DB{connector: WithCache(&fakeDeclaredCapabilitiesConnector{}, 0)}
+	expected = Capabilities{
+		SupportsUtmp:        true,
+		SupportsSystemFiles: true,
+	}
+	if actual := db.Capabilities(); actual != expected {
+		t.Errorf("Capabilities declared by connector expected: %+v, got: %+v", expected, actual)
+	}
+
+	db = &DB{connector: &fakeRecordSizerConnector{fakeConnector: &fakeConnector{}, size: 128}}
+	if actual := db.Capabilities(); actual != (Capabilities{}) {
+		t.Errorf("Capabilities of connector without ranged reads expected: {}, got: %+v", actual)
+	}
+	db = &DB{connector: &fakeRangeConnector{fakeRecordSizerConnector: fakeRecordSizerConnector{fakeConnector: &fakeConnector{}, size: 128}}}
+	if actual := db.Capabilities(); !actual.SupportsRangedReads {
+		t.Errorf("SupportsRangedReads expected: true, got: %+v", actual)
+	}
+}
@@ -0,0 +1,139 @@
+package crypt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrPasswordMismatch is returned by Verify of schemes when the password
+// does not match the hash.
+var ErrPasswordMismatch = errors.New("password incorrect")
+
+// DES is the traditional DES crypt(3) scheme used by Maple and pttbbs, the
+// hash is 13 characters and the first 2 characters are salt.
+type DES struct{}
+
+// Hash returns the DES crypt hash of plain, only the first 2 characters of
+// salt are used.
+func (DES) Hash(plain, salt string) (string, error) {
+	if len(salt) < 2 {
+		return "", fmt.Errorf("%w: DES salt should have 2 characters", ErrInvalidCrypt)
+	}
+	res, err := Fcrypt([]byte(plain), []byte(salt[:2]))
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(string(res), "\x00"), nil
+}
+
+// Verify returns nil if plain matches DES crypt hash hashed.
+func (s DES) Verify(plain, hashed string) error {
+	if len(hashed) < 2 {
+		return ErrPasswordMismatch
+	}
+	res, err := s.Hash(plain, hashed)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(res), []byte(hashed)) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
+
+// pbkdf2SHA256Prefix is the prefix of hashes of PBKDF2SHA256.
+const pbkdf2SHA256Prefix = "$pbkdf2-sha256$"
+
+// PBKDF2SHA256 is the PBKDF2 scheme with HMAC-SHA256, hashes are formatted
+// as "$pbkdf2-sha256$<iterations>$<salt>$<base64 key>".
+type PBKDF2SHA256 struct {
+	// Iterations is the iteration count of new hashes, 0 means 100000.
+	Iterations int
+}
+
+// Hash returns the PBKDF2-SHA256 hash of plain with salt, salt should not
+// contain "$".
+func (s PBKDF2SHA256) Hash(plain, salt string) (string, error) {
+	if salt == "" || strings.Contains(salt, "$") {
+		return "", fmt.Errorf("%w: invalid PBKDF2 salt %q", ErrInvalidCrypt, salt)
+	}
+	iter := s.Iterations
+	if iter <= 0 {
+		iter = 100000
+	}
+	key := pbkdf2SHA256([]byte(plain), []byte(salt), iter, sha256.Size)
+	return fmt.Sprintf("%s%d$%s$%s", pbkdf2SHA256Prefix, iter, salt,
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify returns nil if plain matches PBKDF2-SHA256 hash hashed, the
+// iteration count in hashed is used.
+func (PBKDF2SHA256) Verify(plain, hashed string) error {
+	if !strings.HasPrefix(hashed, pbkdf2SHA256Prefix) {
+		return ErrPasswordMismatch
+	}
+	parts := strings.Split(strings.TrimPrefix(hashed, pbkdf2SHA256Prefix), "$")
+	if len(parts) != 3 {
+		return fmt.Errorf("%w: malformed PBKDF2 hash", ErrInvalidCrypt)
+	}
+	iter, err := strconv.Atoi(parts[0])
+	if err != nil || iter <= 0 {
+		return fmt.Errorf("%w: malformed PBKDF2 iterations", ErrInvalidCrypt)
+	}
+	expected, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("%w: malformed PBKDF2 key", ErrInvalidCrypt)
+	}
+	key := pbkdf2SHA256([]byte(plain), []byte(parts[1]), iter, len(expected))
+	if subtle.ConstantTimeCompare(key, expected) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
+
+// pbkdf2SHA256 derives a key of keyLen bytes, see RFC 8018 section 5.2.
+func pbkdf2SHA256(password, salt []byte, iter, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	var key []byte
+	buf := make([]byte, 4)
+	for block := uint32(1); len(key) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(buf, block)
+		prf.Write(buf)
+		u := prf.Sum(nil)
+		t := append([]byte{}, u...)
+		for i := 1; i < iter; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
+
+// Mixed is the scheme for migrated accounts with mixed hash formats, it
+// hashes new passwords with PBKDF2SHA256, and verifies PBKDF2-SHA256 hashes
+// by prefix and others as DES hashes.
+type Mixed struct {
+	PBKDF2SHA256
+}
+
+// Verify returns nil if plain matches hashed of either scheme.
+func (m Mixed) Verify(plain, hashed string) error {
+	if strings.HasPrefix(hashed, pbkdf2SHA256Prefix) {
+		return m.PBKDF2SHA256.Verify(plain, hashed)
+	}
+	return DES{}.Verify(plain, hashed)
+}
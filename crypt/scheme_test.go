@@ -0,0 +1,61 @@
+package crypt
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestDES(t *testing.T) {
+	s := DES{}
+	hashed, err := s.Hash("012345678901", "AA")
+	if err != nil {
+		t.Fatalf("Hash error: %v", err)
+	}
+	if hashed != "AA3QBhLWk1BWA" {
+		t.Errorf("Hash expected: AA3QBhLWk1BWA, got: %v", hashed)
+	}
+	if err := s.Verify("012345678901", hashed); err != nil {
+		t.Errorf("Verify error: %v", err)
+	}
+	if err := s.Verify("wrong", hashed); !errors.Is(err, ErrPasswordMismatch) {
+		t.Errorf("Verify with wrong password should return ErrPasswordMismatch, got: %v", err)
+	}
+}
+
+func TestPBKDF2SHA256(t *testing.T) {
+	// test vectors of PBKDF2-HMAC-SHA256.
+	tests := []struct {
+		iter     int
+		expected string
+	}{
+		{1, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{2, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+	}
+	for _, tt := range tests {
+		key := hex.EncodeToString(pbkdf2SHA256([]byte("password"), []byte("salt"), tt.iter, 32))
+		if key != tt.expected {
+			t.Errorf("pbkdf2SHA256 with %d iterations expected: %v, got: %v", tt.iter, tt.expected, key)
+		}
+	}
+
+	s := PBKDF2SHA256{Iterations: 1000}
+	hashed, err := s.Hash("123456", "saltsalt")
+	if err != nil {
+		t.Fatalf("Hash error: %v", err)
+	}
+	if err := s.Verify("123456", hashed); err != nil {
+		t.Errorf("Verify error: %v", err)
+	}
+	if err := s.Verify("654321", hashed); !errors.Is(err, ErrPasswordMismatch) {
+		t.Errorf("Verify with wrong password should return ErrPasswordMismatch, got: %v", err)
+	}
+
+	m := Mixed{s}
+	if err := m.Verify("123456", hashed); err != nil {
+		t.Errorf("Mixed Verify of PBKDF2 hash error: %v", err)
+	}
+	if err := m.Verify("012345678901", "AA3QBhLWk1BWA"); err != nil {
+		t.Errorf("Mixed Verify of DES hash error: %v", err)
+	}
+}
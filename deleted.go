@@ -0,0 +1,42 @@
+package bbs
+
+import "strings"
+
+// DeletableArticleRecord is implemented by ArticleRecord which can tell
+// whether its slot in records file is deleted. BBS usually deletes an
+// article by marking its record instead of compacting the records file.
+type DeletableArticleRecord interface {
+	// IsDeleted returns true if the record is a deleted slot.
+	IsDeleted() bool
+}
+
+// SetSkipDeletedArticles sets whether ReadBoardArticleRecordsFile skips
+// deleted article records, which are records implementing
+// DeletableArticleRecord and reporting deleted, or records with empty
+// filename. By default, deleted records are returned as is.
+func (db *DB) SetSkipDeletedArticles(skip bool) {
+	db.skipDeletedArticles = skip
+}
+
+// isDeletedArticle returns true if r is a deleted slot in records file.
+func isDeletedArticle(r ArticleRecord) bool {
+	if dr, ok := r.(DeletableArticleRecord); ok && dr.IsDeleted() {
+		return true
+	}
+	return strings.TrimSpace(r.Filename()) == ""
+}
+
+// filterDeletedArticles returns recs without deleted records if
+// skipDeletedArticles is set.
+func (db *DB) filterDeletedArticles(recs []ArticleRecord) []ArticleRecord {
+	if !db.skipDeletedArticles {
+		return recs
+	}
+	ret := make([]ArticleRecord, 0, len(recs))
+	for _, r := range recs {
+		if !isDeletedArticle(r) {
+			ret = append(ret, r)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,54 @@
+package bbs
+
+import (
+	"reflect"
+	"testing"
+)
+
+type fakeDeletableArticleRecord struct {
+	fakeArticleRecord
+	deleted bool
+}
+
+func (r *fakeDeletableArticleRecord) IsDeleted() bool { return r.deleted }
+
+func TestSkipDeletedArticles(t *testing.T) {
+	recs := []ArticleRecord{
+		&fakeArticleRecord{filename: "M.1599059246.A.CF6"},
+		&fakeArticleRecord{filename: ""},
+		&fakeDeletableArticleRecord{fakeArticleRecord: fakeArticleRecord{filename: ".deleted"}, deleted: true},
+		&fakeDeletableArticleRecord{fakeArticleRecord: fakeArticleRecord{filename: "M.1599059500.A.123"}},
+	}
+	db := &DB{
+		connector: &fakeConnector{
+			fakeGetBoardArticleRecordsPath: func() (string, error) {
+				return "boards/S/SYSOP/.DIR", nil
+			},
+			fakeReadArticleRecordsFile: func() ([]ArticleRecord, error) {
+				return recs, nil
+			},
+		},
+	}
+
+	actual, err := db.ReadBoardArticleRecordsFile("SYSOP")
+	if err != nil {
+		t.Fatalf("ReadBoardArticleRecordsFile error: %v", err)
+	}
+	if len(actual) != len(recs) {
+		t.Errorf("deleted records should be returned by default, expected: %d, got: %d", len(recs), len(actual))
+	}
+
+	db.SetSkipDeletedArticles(true)
+	actual, err = db.ReadBoardArticleRecordsFile("SYSOP")
+	if err != nil {
+		t.Fatalf("ReadBoardArticleRecordsFile error: %v", err)
+	}
+	expected := []string{"M.1599059246.A.CF6", "M.1599059500.A.123"}
+	filenames := []string{}
+	for _, r := range actual {
+		filenames = append(filenames, r.Filename())
+	}
+	if !reflect.DeepEqual(filenames, expected) {
+		t.Errorf("filenames not match, expected: %v, got: %v", expected, filenames)
+	}
+}
@@ -0,0 +1,73 @@
+package bbs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnregister(t *testing.T) {
+	Register("fake", &fakeConnector{fakeOpen: func() error { return nil }})
+	if _, err := Open("fake", ""); err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+
+	Unregister("fake")
+	if _, err := Open("fake", ""); err == nil {
+		t.Errorf("Open unregistered driver should return error")
+	}
+
+	Register("fake1", &fakeConnector{})
+	Register("fake2", &fakeConnector{})
+	UnregisterAll()
+	for _, name := range []string{"fake1", "fake2"} {
+		if _, ok := driver(name); ok {
+			t.Errorf("driver %v should be unregistered", name)
+		}
+	}
+}
+
+func TestOpenConnector(t *testing.T) {
+	opened := ""
+	c := &fakeConnector{fakeOpen: func() error {
+		opened = "opened"
+		return nil
+	}}
+
+	db, err := OpenConnector(WithCache(c, time.Minute), "/home/bbs")
+	if err != nil {
+		t.Fatalf("OpenConnector error: %v", err)
+	}
+	if db == nil || opened != "opened" {
+		t.Errorf("connector should be opened")
+	}
+
+	if _, err := OpenConnector(nil, "/home/bbs"); err == nil {
+		t.Errorf("OpenConnector with nil connector should return error")
+	}
+}
+
+type fakeFactoryConnector struct {
+	fakeConnector
+}
+
+func (c *fakeFactoryConnector) NewConnector() Connector {
+	return &fakeFactoryConnector{fakeConnector: fakeConnector{fakeOpen: func() error { return nil }}}
+}
+
+func TestOpenConnectorFactory(t *testing.T) {
+	registered := &fakeFactoryConnector{}
+	Register("fakefactory", registered)
+	defer Unregister("fakefactory")
+
+	db1, err := Open("fakefactory", "")
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	db2, err := Open("fakefactory", "")
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	if db1.connector == Connector(registered) || db1.connector == db2.connector {
+		t.Errorf("each DB should have a new connector")
+	}
+}
@@ -0,0 +1,33 @@
+package bbs
+
+import (
+	"log"
+)
+
+// SetDryRun sets whether board record writes, AddBoardRecord,
+// AddBoardRecords, UpdateBoardRecord, RemoveBoardRecord and MoveBoardRecord,
+// only validate the path and index and log the intended change without
+// touching the file. ImportBoard only extracts the archive to temporary
+// directories to validate it. Writes return nil in dry-run mode if the
+// change is valid, and the write hook is not called.
+func (db *DB) SetDryRun(dryRun bool) {
+	db.dryRun = dryRun
+}
+
+// dryRunBoardRecord logs a board record write of op on index in path, index
+// is checked by caller.
+func (db *DB) dryRunBoardRecord(op string, path string, index uint, brd BoardRecord) error {
+	boardID := ""
+	if brd != nil {
+		boardID = brd.BoardID()
+	}
+	log.Printf("bbs: dry-run: %s board record %d %q in %s", op, index, boardID, path)
+	return nil
+}
+
+// dryRunMoveBoardRecord logs moving board record from to to in path, indices
+// are checked by caller.
+func (db *DB) dryRunMoveBoardRecord(path string, from, to uint) error {
+	log.Printf("bbs: dry-run: %s board record %d to %d in %s", WriteOpMove, from, to, path)
+	return nil
+}
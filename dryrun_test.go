@@ -0,0 +1,47 @@
+package bbs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSetDryRun(t *testing.T) {
+	reads := 0
+	c := newFakeWriteBoardConnector(&reads)
+	db := &DB{connector: c}
+
+	brd, _ := db.NewBoardRecord(map[string]interface{}{"board_id": "SYSOP"})
+	if err := db.AddBoardRecord(brd); err != nil {
+		t.Fatalf("AddBoardRecord error: %v", err)
+	}
+
+	called := false
+	db.SetWriteHook(func(op string, path string, index uint, rec interface{}) {
+		called = true
+	})
+	db.SetDryRun(true)
+
+	test, _ := db.NewBoardRecord(map[string]interface{}{"board_id": "Test"})
+	if err := db.AddBoardRecord(test); err != nil {
+		t.Errorf("AddBoardRecord error: %v", err)
+	}
+	if err := db.AddBoardRecords([]BoardRecord{test}); err != nil {
+		t.Errorf("AddBoardRecords error: %v", err)
+	}
+	if err := db.UpdateBoardRecord(0, &test); err != nil {
+		t.Errorf("UpdateBoardRecord error: %v", err)
+	}
+	if err := db.RemoveBoardRecord(0); err != nil {
+		t.Errorf("RemoveBoardRecord error: %v", err)
+	}
+	if err := db.RemoveBoardRecord(1); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("RemoveBoardRecord out of range should return ErrRecordNotFound, got: %v", err)
+	}
+
+	if len(c.records) != 1 || c.records[0].BoardID() != "SYSOP" {
+		t.Errorf("records should not be changed in dry-run, got: %v", c.records)
+	}
+	if called {
+		t.Errorf("write hook should not be called in dry-run")
+	}
+}
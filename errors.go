@@ -0,0 +1,67 @@
+package bbs
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotSupported is returned when the driver does not implement the
+	// connector which the method requires.
+	ErrNotSupported = errors.New("bbs: not supported by driver")
+
+	// ErrInvalidName is returned when a filename or id passed to DB has path
+	// separator or "..", which may be used to read files outside BBSHome.
+	ErrInvalidName = errors.New("bbs: invalid name")
+
+	// ErrInvalidBoardID is returned when a board id passed to DB is empty or
+	// has characters other than letters, digits, "_", "-" and ".". It wraps
+	// ErrInvalidName.
+	ErrInvalidBoardID = fmt.Errorf("%w: invalid board id", ErrInvalidName)
+
+	// ErrRecordNotFound is returned when the record to read or modify does
+	// not exist.
+	ErrRecordNotFound = errors.New("bbs: record not found")
+
+	// ErrUserExists is returned when creating a user whose user id is used.
+	ErrUserExists = errors.New("bbs: user already exists")
+
+	// ErrPasswordScheme is returned when upgrading passwords to a scheme
+	// which the password scheme of DB can not verify.
+	ErrPasswordScheme = errors.New("bbs: password scheme can not verify new hash")
+
+	// ErrMoneyOverflow is returned when adjusting user money overflows the
+	// money field of driver.
+	ErrMoneyOverflow = errors.New("bbs: money overflow")
+
+	// ErrUnknownFormat is returned when driver can not recognize the format
+	// of a file.
+	ErrUnknownFormat = errors.New("bbs: unknown file format")
+
+	// ErrBrokenAttachment is returned with the attachments which decode
+	// cleanly when some attachments of article are truncated or corrupt.
+	ErrBrokenAttachment = errors.New("bbs: broken attachment")
+)
+
+// DecodeError is returned when a record in a record file fails to decode, it
+// locates the corrupt record. Use errors.As to get it from the returned
+// error.
+type DecodeError struct {
+	// Path is the record file path, it is empty when the records are
+	// decoded from a reader.
+	Path string
+	// Index is the 0-based index of the record.
+	Index int
+	// Offset is the byte offset of the record in file.
+	Offset int64
+	// Err is the error of decoding the record.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("bbs: decode record %d (offset %d) of %q: %v", e.Index, e.Offset, e.Path, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,52 @@
+package bbs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind is the kind of record checked by Exists.
+type Kind int
+
+const (
+	// KindUser is for user records, ids are user ids.
+	KindUser Kind = iota
+	// KindBoard is for board records, ids are board ids.
+	KindBoard
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindUser:
+		return "user"
+	case KindBoard:
+		return "board"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Exists returns true if the record of kind and id exists, ids are matched
+// case-insensitively. Boards are looked up by GetBoardRecord, which uses
+// SHMBoardConnector if driver supports, and users are looked up by
+// UsersExist, which stops scanning at the first match. A record which does
+// not exist is (false, nil), and (false, err) is returned only if records
+// can not be read or id is invalid.
+func (db *DB) Exists(kind Kind, id string) (bool, error) {
+	switch kind {
+	case KindUser:
+		found, err := db.UsersExist([]string{id})
+		if err != nil {
+			return false, err
+		}
+		return found[id], nil
+	case KindBoard:
+		_, err := db.GetBoardRecord(id)
+		if errors.Is(err, ErrRecordNotFound) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, fmt.Errorf("bbs: unknown kind %v", kind)
+}
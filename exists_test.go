@@ -0,0 +1,63 @@
+package bbs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExists(t *testing.T) {
+	db := &DB{
+		connector: &fakeConnector{
+			fakeGetUserRecordsPath:   func() (string, error) { return ".PASSWDS", nil },
+			fakeReadUserRecordsFile:  func() ([]UserRecord, error) { return testUserRecords, nil },
+			fakeGetBoardRecordsPath:  func() (string, error) { return ".BRD", nil },
+			fakeReadBoardRecordsFile: func() ([]BoardRecord, error) { return testBoardRecords, nil },
+		},
+	}
+
+	testCases := []struct {
+		kind     Kind
+		id       string
+		expected bool
+	}{
+		{kind: KindUser, id: "SYSOP", expected: true},
+		{kind: KindUser, id: "kahou", expected: true},
+		{kind: KindUser, id: "nobody", expected: false},
+		{kind: KindUser, id: "", expected: false},
+		{kind: KindBoard, id: "junk", expected: true},
+		{kind: KindBoard, id: "sysop", expected: true},
+		{kind: KindBoard, id: "404", expected: false},
+	}
+	for _, c := range testCases {
+		actual, err := db.Exists(c.kind, c.id)
+		if err != nil {
+			t.Fatalf("Exists(%v, %q) error: %v", c.kind, c.id, err)
+		}
+		if actual != c.expected {
+			t.Errorf("Exists(%v, %q) expected: %v, got: %v", c.kind, c.id, c.expected, actual)
+		}
+	}
+
+	if _, err := db.Exists(KindBoard, "../SYSOP"); !errors.Is(err, ErrInvalidBoardID) {
+		t.Errorf("Exists of invalid board id should return ErrInvalidBoardID, got: %v", err)
+	}
+	if _, err := db.Exists(Kind(42), "SYSOP"); err == nil {
+		t.Errorf("Exists of unknown kind should return error")
+	}
+
+	ioErr := fmt.Errorf("disk error")
+	db = &DB{
+		connector: &fakeConnector{
+			fakeGetUserRecordsPath:   func() (string, error) { return ".PASSWDS", nil },
+			fakeReadUserRecordsFile:  func() ([]UserRecord, error) { return nil, ioErr },
+			fakeGetBoardRecordsPath:  func() (string, error) { return ".BRD", nil },
+			fakeReadBoardRecordsFile: func() ([]BoardRecord, error) { return nil, ioErr },
+		},
+	}
+	for _, kind := range []Kind{KindUser, KindBoard} {
+		if ok, err := db.Exists(kind, "SYSOP"); ok || !errors.Is(err, ioErr) {
+			t.Errorf("Exists(%v) with I/O error expected: false, %v, got: %v, %v", kind, ioErr, ok, err)
+		}
+	}
+}
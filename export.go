@@ -0,0 +1,111 @@
+package bbs
+
+import (
+	"archive/tar"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path"
+)
+
+// ExportBoard writes a tar archive of board to w, which contains the
+// article records file `.DIR`, every article file referenced by it, and the
+// treasure under `man/`, such as `man/.DIR` and `man/D1/M.123.A.456`. Files
+// are streamed one by one so large boards are not buffered in memory.
+// Missing files referenced by stale records are skipped and logged.
+func (db *DB) ExportBoard(boardID string, w io.Writer) error {
+	tw := tar.NewWriter(w)
+
+	dirPath, err := db.boardArticleRecordsPath(boardID)
+	if err != nil {
+		log.Println("bbs: GetBoardArticleRecordsPath error:", err)
+		return err
+	}
+	if err := db.addTarFile(tw, ".DIR", dirPath); err != nil {
+		return err
+	}
+
+	recs, err := db.ReadBoardArticleRecordsFile(boardID)
+	if err != nil {
+		return err
+	}
+	for _, r := range recs {
+		if r.Filename() == "" || checkNames(r.Filename()) != nil {
+			continue
+		}
+		p, err := db.boardArticleFilePath(boardID, r.Filename())
+		if err != nil {
+			log.Println("bbs: GetBoardArticleFilePath error:", err)
+			return err
+		}
+		if err := db.addTarFile(tw, r.Filename(), p); err != nil {
+			return err
+		}
+	}
+
+	if db.isBoardTreasureDir(boardID, []string{}) {
+		p, err := db.boardTreasureRecordsPath(boardID, []string{})
+		if err != nil {
+			return err
+		}
+		if err := db.addTarFile(tw, "man/.DIR", p); err != nil {
+			return err
+		}
+	}
+	err = db.WalkBoardTreasure(boardID, func(treasureID []string, r ArticleRecord, isDir bool) error {
+		sub := append(append([]string{}, treasureID...), r.Filename())
+		name := path.Join(append([]string{"man"}, sub...)...)
+		if isDir {
+			p, err := db.boardTreasureRecordsPath(boardID, sub)
+			if err != nil {
+				return err
+			}
+			return db.addTarFile(tw, name+"/.DIR", p)
+		}
+		p, err := db.boardTreasureFilePath(boardID, treasureID, r.Filename())
+		if err != nil {
+			return err
+		}
+		return db.addTarFile(tw, name, p)
+	})
+	if err != nil {
+		log.Println("bbs: WalkBoardTreasure error:", err)
+		return err
+	}
+
+	return tw.Close()
+}
+
+// addTarFile writes file p as name to tw, it skips and logs p if p does not
+// exist.
+func (db *DB) addTarFile(tw *tar.Writer, name, p string) error {
+	f, err := db.openFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Println("bbs: skip missing file:", p)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if stat.IsDir() {
+		log.Println("bbs: skip directory:", p)
+		return nil
+	}
+	err = tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    stat.Size(),
+		ModTime: stat.ModTime(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
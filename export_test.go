@@ -0,0 +1,61 @@
+package bbs
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestExportBoard(t *testing.T) {
+	dir, err := ioutil.TempDir("", "export_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"boards/SYSOP/.DIR":      "M.1.A.001\nM.2.A.002\n",
+		"boards/SYSOP/M.1.A.001": "first",
+		// M.2.A.002 is missing and should be skipped.
+		"man/SYSOP/.DIR":         "D1\n",
+		"man/SYSOP/D1/.DIR":      "M.3.A.003\n",
+		"man/SYSOP/D1/M.3.A.003": "treasure",
+	})
+	db := &DB{connector: &fakeBoardHomeConnector{home: dir}}
+
+	var buf bytes.Buffer
+	if err := db.ExportBoard("SYSOP", &buf); err != nil {
+		t.Fatalf("ExportBoard error: %v", err)
+	}
+
+	files := map[string]string{}
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("read tar error: %v", err)
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("read tar file error: %v", err)
+		}
+		files[hdr.Name] = string(data)
+	}
+
+	expected := map[string]string{
+		".DIR":             "M.1.A.001\nM.2.A.002\n",
+		"M.1.A.001":        "first",
+		"man/.DIR":         "D1\n",
+		"man/D1/.DIR":      "M.3.A.003\n",
+		"man/D1/M.3.A.003": "treasure",
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("exported files expected: %v, got: %v", expected, files)
+	}
+}
@@ -0,0 +1,49 @@
+package bbs
+
+import (
+	"errors"
+	"io/fs"
+	"log"
+)
+
+// FavoriteBoardIDs returns board ids in favorites of userID, folders are
+// flattened in order and separator lines are skipped. Duplicated board ids
+// are returned once at the first-seen position. It returns an empty slice if
+// user has no favorite file.
+func (db *DB) FavoriteBoardIDs(userID string) ([]string, error) {
+	path, err := db.userFavoriteRecordsPath(userID)
+	if err != nil {
+		log.Println("bbs: get user favorite records path error:", err)
+		return nil, err
+	}
+
+	recs, err := db.connector.ReadUserFavoriteRecordsFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		log.Println("bbs: read user favorite records error:", err)
+		return nil, err
+	}
+
+	ret := []string{}
+	seen := map[string]bool{}
+	var walk func(recs []FavoriteRecord)
+	walk = func(recs []FavoriteRecord) {
+		for _, r := range recs {
+			switch r.Type() {
+			case FavoriteTypeBoard:
+				id := r.BoardID()
+				if id == "" || seen[id] {
+					continue
+				}
+				seen[id] = true
+				ret = append(ret, id)
+			case FavoriteTypeFolder:
+				walk(r.Records())
+			}
+		}
+	}
+	walk(recs)
+	return ret, nil
+}
@@ -0,0 +1,75 @@
+package bbs
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+)
+
+type fakeFavoriteRecord struct {
+	title   string
+	favType FavoriteType
+	boardID string
+	records []FavoriteRecord
+}
+
+func (r *fakeFavoriteRecord) Title() string             { return r.title }
+func (r *fakeFavoriteRecord) Type() FavoriteType        { return r.favType }
+func (r *fakeFavoriteRecord) BoardID() string           { return r.boardID }
+func (r *fakeFavoriteRecord) Records() []FavoriteRecord { return r.records }
+
+func TestFavoriteBoardIDs(t *testing.T) {
+	recs := []FavoriteRecord{
+		&fakeFavoriteRecord{favType: FavoriteTypeBoard, boardID: "SYSOP"},
+		&fakeFavoriteRecord{favType: FavoriteTypeLine},
+		&fakeFavoriteRecord{favType: FavoriteTypeFolder, title: "新的目錄", records: []FavoriteRecord{
+			&fakeFavoriteRecord{favType: FavoriteTypeBoard, boardID: "Test"},
+			&fakeFavoriteRecord{favType: FavoriteTypeBoard, boardID: "SYSOP"},
+			&fakeFavoriteRecord{favType: FavoriteTypeFolder, records: []FavoriteRecord{
+				&fakeFavoriteRecord{favType: FavoriteTypeBoard, boardID: "WhoAmI"},
+			}},
+		}},
+		&fakeFavoriteRecord{favType: FavoriteTypeBoard, boardID: "Test"},
+		&fakeFavoriteRecord{favType: FavoriteTypeBoard, boardID: "PttNewHand"},
+	}
+	var readErr error
+	db := &DB{
+		connector: &fakeConnector{
+			fakeGetUserFavoriteRecordsPath: func() (string, error) { return "home/p/pichu/.fav", nil },
+			fakeReadUserFavoriteRecordsFile: func() ([]FavoriteRecord, error) {
+				return recs, readErr
+			},
+		},
+	}
+
+	actual, err := db.FavoriteBoardIDs("pichu")
+	if err != nil {
+		t.Fatalf("FavoriteBoardIDs error: %v", err)
+	}
+	expected := []string{"SYSOP", "Test", "WhoAmI", "PttNewHand"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("FavoriteBoardIDs expected: %v, got: %v", expected, actual)
+	}
+
+	recs = nil
+	actual, err = db.FavoriteBoardIDs("pichu")
+	if err != nil || actual == nil || len(actual) != 0 {
+		t.Errorf("FavoriteBoardIDs of empty favorites expected: [], <nil>, got: %v, %v", actual, err)
+	}
+
+	readErr = fmt.Errorf("open favorite error: %w", os.ErrNotExist)
+	actual, err = db.FavoriteBoardIDs("pichu")
+	if err != nil || actual == nil || len(actual) != 0 {
+		t.Errorf("FavoriteBoardIDs of missing favorites expected: [], <nil>, got: %v, %v", actual, err)
+	}
+}
+
+func TestFavoriteTypeIsSeparator(t *testing.T) {
+	if !FavoriteTypeLine.IsSeparator() {
+		t.Errorf("FavoriteTypeLine should be separator")
+	}
+	if FavoriteTypeBoard.IsSeparator() || FavoriteTypeFolder.IsSeparator() {
+		t.Errorf("board and folder should not be separator")
+	}
+}
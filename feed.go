@@ -0,0 +1,169 @@
+package bbs
+
+import (
+	"encoding/xml"
+	"errors"
+	"io/fs"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultFeedSummaryLength is the characters of entry summary in BoardFeed.
+const DefaultFeedSummaryLength = 200
+
+// FeedOptions configures BoardFeed.
+type FeedOptions struct {
+	// BaseURL is the web URL of articles passed to ArticleURL, the feed links
+	// to BaseURL followed by board id. Empty means no links, ids of feed and
+	// entries are URNs of board id and filename instead.
+	BaseURL string
+	// SummaryLength is the maximum characters of entry summary read from
+	// article file, 0 means DefaultFeedSummaryLength, negative omits summary
+	// without reading article files.
+	SummaryLength int
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    *atomLink   `xml:"link,omitempty"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID      string     `xml:"id"`
+	Title   string     `xml:"title"`
+	Updated string     `xml:"updated"`
+	Author  atomAuthor `xml:"author"`
+	Link    *atomLink  `xml:"link,omitempty"`
+	Summary string     `xml:"summary,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+// BoardFeed returns the Atom feed of the latest n articles of board
+// newest-first, with title, owner and time of article records and a summary
+// of article text normalized by NormalizeArticleText. Board without articles
+// has a feed without entries. Deleted article files have no summary.
+func (db *DB) BoardFeed(boardID string, n int, opts FeedOptions) ([]byte, error) {
+	brd, err := db.GetBoardRecord(boardID)
+	if err != nil {
+		return nil, err
+	}
+	boardID = brd.BoardID()
+	recs, err := db.RecentArticles(boardID, n)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := atomFeed{
+		ID:      "urn:bbs:" + url.PathEscape(boardID),
+		Title:   boardID,
+		Entries: []atomEntry{},
+	}
+	if t := strings.TrimSpace(brd.Title()); t != "" {
+		feed.Title += " - " + t
+	}
+	if opts.BaseURL != "" {
+		feed.ID = strings.TrimSuffix(opts.BaseURL, "/") + "/" + url.PathEscape(boardID)
+		feed.Link = &atomLink{Href: feed.ID}
+	}
+
+	var updated time.Time
+	for _, r := range recs {
+		t, _ := articleTime(r, db.Location())
+		if t.After(updated) {
+			updated = t
+		}
+		entry := atomEntry{
+			ID:      feed.ID + ":" + url.PathEscape(r.Filename()),
+			Title:   r.Title(),
+			Updated: t.Format(time.RFC3339),
+			Author:  atomAuthor{Name: r.Owner()},
+		}
+		if opts.BaseURL != "" {
+			if u, ok := ArticleURL(opts.BaseURL, boardID, r); ok {
+				entry.ID = u
+				entry.Link = &atomLink{Href: u}
+			}
+		}
+		if opts.SummaryLength >= 0 {
+			entry.Summary = db.articleSummary(boardID, r.Filename(), opts.SummaryLength)
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	feed.Updated = updated.In(db.Location()).Format(time.RFC3339)
+
+	buf, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), buf...), nil
+}
+
+// articleSummary returns at most n characters of article text without
+// header and signature, n 0 means DefaultFeedSummaryLength. It returns empty
+// string if article file can not be read.
+func (db *DB) articleSummary(boardID, filename string, n int) string {
+	if n == 0 {
+		n = DefaultFeedSummaryLength
+	}
+	raw, err := db.ReadBoardArticleFile(boardID, filename)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Println("bbs: ReadBoardArticleFile error:", err)
+		}
+		return ""
+	}
+	text := NormalizeArticleText(raw, TextOptions{StripANSI: true, JoinWrapped: true, LineEnding: "\n"})
+
+	words := []string{}
+	header := true
+	for _, line := range strings.Split(Big5ToUtf8(text), "\n") {
+		line = strings.TrimSpace(line)
+		if header && isArticleHeaderLine(line) {
+			continue
+		}
+		header = false
+		if line == "--" {
+			break
+		}
+		if line != "" {
+			words = append(words, line)
+		}
+	}
+	summary := strings.Join(words, " ")
+	if utf8.RuneCountInString(summary) > n {
+		summary = string([]rune(summary)[:n]) + "…"
+	}
+	return summary
+}
+
+// isArticleHeaderLine returns true if line is one of the header lines at the
+// beginning of article file, such as "作者: SYSOP 看板: Test", or the empty
+// line after them.
+func isArticleHeaderLine(line string) bool {
+	if line == "" {
+		return true
+	}
+	for _, prefix := range []string{"作者:", "看板:", "標題:", "時間:", "站內:"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
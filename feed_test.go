@@ -0,0 +1,111 @@
+package bbs
+
+import (
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeFeedConnector struct {
+	fakeArticleFileConnector
+	articles map[string][]ArticleRecord
+}
+
+func (c *fakeFeedConnector) GetBoardArticleRecordsPath(boardID string) (string, error) {
+	return boardID, nil
+}
+
+func (c *fakeFeedConnector) ReadArticleRecordsFile(name string) ([]ArticleRecord, error) {
+	return c.articles[name], nil
+}
+
+func (c *fakeFeedConnector) GetBoardArticleFilePath(boardID string, filename string) (string, error) {
+	return boardID + "/" + filename, nil
+}
+
+func TestBoardFeed(t *testing.T) {
+	posted := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	c := &fakeFeedConnector{
+		fakeArticleFileConnector: fakeArticleFileConnector{
+			fakeConnector: fakeConnector{
+				fakeGetBoardRecordsPath: func() (string, error) { return ".BRD", nil },
+				fakeReadBoardRecordsFile: func() ([]BoardRecord, error) {
+					return []BoardRecord{
+						&fakeBoardRecord{boardID: "SYSOP", title: "站長好"},
+						&fakeBoardRecord{boardID: "Empty"},
+					}, nil
+				},
+			},
+			reads: map[string]int{},
+			files: map[string][]byte{
+				"SYSOP/M.1614834367.A.000": Utf8ToBig5("作者: SYSOP (站長) 看板: SYSOP\n標題: [公告] 測試\n時間: Thu Mar  4 05:06:07 2021\n\n" +
+					"\x1b[1;33m第一行\x1b[m\n第二行\n\n--\n※ 發信站: 批踢踢實業坊(ptt.cc)\n"),
+			},
+		},
+		articles: map[string][]ArticleRecord{
+			"SYSOP": {
+				&fakeArticleRecord{filename: "M.1614834367.A.000", title: "[公告] 測試", owner: "SYSOP", modified: posted},
+				&fakeArticleRecord{filename: "M.1614834400.A.001", title: "deleted", owner: "pichu", modified: posted.Add(time.Minute)},
+			},
+		},
+	}
+	db := &DB{connector: c, location: time.UTC}
+
+	buf, err := db.BoardFeed("sysop", 10, FeedOptions{BaseURL: "https://example.com/bbs/"})
+	if err != nil {
+		t.Fatalf("BoardFeed error: %v", err)
+	}
+	feed := atomFeed{}
+	if err := xml.Unmarshal(buf, &feed); err != nil {
+		t.Fatalf("feed should be valid xml, got: %v", err)
+	}
+	if feed.ID != "https://example.com/bbs/SYSOP" || feed.Title != "SYSOP - 站長好" || feed.Updated != "2021-03-04T05:07:07Z" {
+		t.Errorf("feed not match, got: %+v", feed)
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("entries length expected: 2, got: %v", len(feed.Entries))
+	}
+	if e := feed.Entries[0]; e.Title != "deleted" || e.Summary != "" || e.Author.Name != "pichu" {
+		t.Errorf("newest entry not match, got: %+v", e)
+	}
+	e := feed.Entries[1]
+	if e.ID != "https://example.com/bbs/SYSOP/1WG6g_00" || e.Link == nil || e.Link.Href != e.ID ||
+		e.Title != "[公告] 測試" || e.Updated != "2021-03-04T05:06:07Z" || e.Author.Name != "SYSOP" {
+		t.Errorf("entry not match, got: %+v", e)
+	}
+	if e.Summary != "第一行 第二行" {
+		t.Errorf("summary expected: %q, got: %q", "第一行 第二行", e.Summary)
+	}
+
+	reads := c.reads["SYSOP/M.1614834367.A.000"]
+	feed = atomFeed{}
+	buf, err = db.BoardFeed("SYSOP", 2, FeedOptions{SummaryLength: -1})
+	if err != nil {
+		t.Fatalf("BoardFeed error: %v", err)
+	}
+	if err := xml.Unmarshal(buf, &feed); err != nil {
+		t.Fatalf("feed should be valid xml, got: %v", err)
+	}
+	if len(feed.Entries) != 2 || feed.Entries[0].ID != "urn:bbs:SYSOP:M.1614834400.A.001" || feed.Entries[0].Link != nil {
+		t.Errorf("entries without base url not match, got: %+v", feed.Entries)
+	}
+	if c.reads["SYSOP/M.1614834367.A.000"] != reads || feed.Entries[1].Summary != "" {
+		t.Errorf("article files should not be read without summary, got: %+v", feed.Entries[1])
+	}
+
+	buf, err = db.BoardFeed("Empty", 10, FeedOptions{})
+	if err != nil {
+		t.Fatalf("BoardFeed error: %v", err)
+	}
+	feed = atomFeed{}
+	if err := xml.Unmarshal(buf, &feed); err != nil || feed.ID != "urn:bbs:Empty" || len(feed.Entries) != 0 ||
+		!strings.Contains(string(buf), "<updated>") {
+		t.Errorf("empty feed not match, got: %s, %v", buf, err)
+	}
+
+	if _, err := db.BoardFeed("notexist", 10, FeedOptions{}); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("BoardFeed should return ErrRecordNotFound, got: %v", err)
+	}
+}
@@ -1,15 +1,59 @@
+// Package filelock provides advisory locks on files.
+//
+// On linux, darwin and other unix systems, locks are taken with flock(2),
+// they are advisory: only processes which also lock the file are excluded,
+// and a lock is held by the open file, so opening the same file twice in one
+// process and locking both blocks. On other platforms, such as windows,
+// locking is not supported and all functions succeed without locking.
 package filelock
 
-type File interface{}
+import (
+	"errors"
+	"time"
+)
 
+// File is a file which can be locked, such as *os.File.
+type File interface {
+	Fd() uintptr
+}
+
+// ErrTimeout is returned by LockTimeout when the lock is not acquired in
+// time.
+var ErrTimeout = errors.New("filelock: lock timeout")
+
+// pollInterval is the interval of retrying lock in LockTimeout.
+var pollInterval = 10 * time.Millisecond
+
+// Lock takes an exclusive lock on f, it blocks until the lock is acquired.
 func Lock(f File) error {
-	return nil
+	return lock(f, true, true)
 }
 
+// RLock takes a shared lock on f, it blocks until the lock is acquired.
 func RLock(f File) error {
-	return nil
+	return lock(f, false, true)
+}
+
+// LockTimeout takes an exclusive lock on f, it returns ErrTimeout if the
+// lock is not acquired in timeout. timeout <= 0 blocks like Lock.
+func LockTimeout(f File, timeout time.Duration) error {
+	if timeout <= 0 {
+		return Lock(f)
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		err := lock(f, true, false)
+		if err != errWouldBlock {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return ErrTimeout
+		}
+		time.Sleep(pollInterval)
+	}
 }
 
+// Unlock releases the lock on f.
 func Unlock(f File) error {
-	return nil
+	return unlock(f)
 }
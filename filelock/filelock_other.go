@@ -0,0 +1,18 @@
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!dragonfly
+
+package filelock
+
+import "errors"
+
+// Supported reports whether locking is supported on this platform.
+const Supported = false
+
+var errWouldBlock = errors.New("filelock: would block")
+
+func lock(f File, exclusive bool, block bool) error {
+	return nil
+}
+
+func unlock(f File) error {
+	return nil
+}
@@ -0,0 +1,43 @@
+package filelock
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLockTimeout(t *testing.T) {
+	if !Supported {
+		t.Skip("locking is not supported")
+	}
+
+	tmpfile, err := ioutil.TempFile("", "filelock_test_*")
+	if err != nil {
+		t.Fatalf("create tmp file error: %v", err)
+	}
+	defer os.Remove(tmpfile.Name()) // clean up
+	defer tmpfile.Close()
+
+	if err := Lock(tmpfile); err != nil {
+		t.Fatalf("Lock error: %v", err)
+	}
+
+	f, err := os.Open(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("open error: %v", err)
+	}
+	defer f.Close()
+
+	if err := LockTimeout(f, 30*time.Millisecond); err != ErrTimeout {
+		t.Errorf("LockTimeout should time out, got: %v", err)
+	}
+
+	if err := Unlock(tmpfile); err != nil {
+		t.Fatalf("Unlock error: %v", err)
+	}
+	if err := LockTimeout(f, 30*time.Millisecond); err != nil {
+		t.Errorf("LockTimeout error: %v", err)
+	}
+	Unlock(f)
+}
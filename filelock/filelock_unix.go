@@ -0,0 +1,38 @@
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package filelock
+
+import (
+	"errors"
+
+	syscall "golang.org/x/sys/unix"
+)
+
+// Supported reports whether locking is supported on this platform.
+const Supported = true
+
+var errWouldBlock = syscall.EWOULDBLOCK
+
+func lock(f File, exclusive bool, block bool) error {
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	if !block {
+		how |= syscall.LOCK_NB
+	}
+	for {
+		err := syscall.Flock(int(f.Fd()), how)
+		if errors.Is(err, syscall.EINTR) {
+			continue
+		}
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return errWouldBlock
+		}
+		return err
+	}
+}
+
+func unlock(f File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,71 @@
+package bbs
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+)
+
+// FSConnector is implemented by connector which can read bbs files from a
+// fs.FS, such as a read-only snapshot, zip archive or fstest.MapFS in tests.
+type FSConnector interface {
+	// OpenFS should make connector read all files from fsys, root is the
+	// BBSHome directory in fsys. Connector opened with fsys should be
+	// read-only.
+	OpenFS(fsys fs.FS, root string) error
+}
+
+// OpenFS opens a DB which reads bbs files from fsys instead of disk, root is
+// the BBSHome directory in fsys, use "." for the root of fsys. It is Open
+// with WithFS, and opts are applied after it.
+// It returns ErrNotSupported if driver does not implement FSConnector and
+// ConnectorFactory, the fs.FS is kept by connector so it should not be
+// shared with other DBs.
+func OpenFS(drivername string, fsys fs.FS, root string, opts ...OpenOption) (*DB, error) {
+	c, ok := driver(drivername)
+	if !ok {
+		return nil, fmt.Errorf("bbs: drivername: %v not found", drivername)
+	}
+	if _, ok := c.(ConnectorFactory); !ok {
+		return nil, fmt.Errorf("bbs: drivername: %v open fs error: %w", drivername, ErrNotSupported)
+	}
+	return Open(drivername, "", append([]OpenOption{WithFS(fsys, root)}, opts...)...)
+}
+
+// WithFS returns OpenOption which makes connector and DB read bbs files from
+// fsys instead of disk, root is the BBSHome directory in fsys. It replaces
+// the BBSHome of dataSourceName, other arguments such as "fork" still apply,
+// eg: Open("pttbbs", "?fork=pttbbs", WithFS(fsys, ".")). The option returns
+// ErrNotSupported if connector does not implement FSConnector.
+func WithFS(fsys fs.FS, root string) OpenOption {
+	return func(db *DB) error {
+		var fc FSConnector
+		if !db.connectorAs(&fc) {
+			return fmt.Errorf("bbs: open fs error: %w", ErrNotSupported)
+		}
+		if err := fc.OpenFS(fsys, root); err != nil {
+			return fmt.Errorf("bbs: open fs error: %w", err)
+		}
+		db.fsys = fsys
+		return nil
+	}
+}
+
+// openFile opens file name returned by connector, from fsys if DB is opened
+// with OpenFS, otherwise from disk.
+func (db *DB) openFile(name string) (fs.File, error) {
+	if db.fsys != nil {
+		return db.fsys.Open(path.Clean(name))
+	}
+	return os.Open(name)
+}
+
+// statFile returns fs.FileInfo of file name returned by connector, from fsys
+// if DB is opened with WithFS, otherwise from disk.
+func (db *DB) statFile(name string) (fs.FileInfo, error) {
+	if db.fsys != nil {
+		return fs.Stat(db.fsys, path.Clean(name))
+	}
+	return os.Stat(name)
+}
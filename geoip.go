@@ -0,0 +1,82 @@
+package bbs
+
+import (
+	"container/list"
+	"net"
+)
+
+// geoIPCacheSize is the maximum number of IPs whose resolved country is
+// cached, the least recently used IP is evicted when it is full.
+const geoIPCacheSize = 4096
+
+type geoIPCacheEntry struct {
+	ip      string
+	country string
+}
+
+// SetGeoIPResolver sets the function to resolve country from IP address,
+// it will be used by UserLastCountry when user record do not store country.
+// Resolved results of at most geoIPCacheSize recently used IPs are cached,
+// set a new resolver clears the cache.
+func (db *DB) SetGeoIPResolver(fn func(net.IP) string) {
+	db.geoIPMutex.Lock()
+	defer db.geoIPMutex.Unlock()
+	db.geoIPResolver = fn
+	db.geoIPLRU = list.New()
+	db.geoIPCache = map[string]*list.Element{}
+}
+
+// UserLastCountry returns the country of user's last login. It uses the
+// country stored by driver if there is one, otherwise it resolves LastHost
+// with GeoIP resolver. It returns empty string if country is unknown.
+func (db *DB) UserLastCountry(u UserRecord) string {
+	if lc, ok := u.(LastCountryUserRecord); ok {
+		if country := lc.LastLoginCountry(); country != "" {
+			return country
+		}
+	}
+
+	var ip net.IP
+	var ok bool
+	if ph, isParsed := u.(ParsedHostUserRecord); isParsed {
+		ip, ok = ph.LastHostIP()
+	} else {
+		ip, ok = ParseHostIP(u.LastHost())
+	}
+	if !ok {
+		return ""
+	}
+	return db.resolveCountry(ip)
+}
+
+func (db *DB) resolveCountry(ip net.IP) string {
+	key := ip.String()
+
+	db.geoIPMutex.Lock()
+	resolver := db.geoIPResolver
+	el, cached := db.geoIPCache[key]
+	if cached {
+		db.geoIPLRU.MoveToFront(el)
+	}
+	db.geoIPMutex.Unlock()
+	if resolver == nil {
+		return ""
+	}
+	if cached {
+		return el.Value.(*geoIPCacheEntry).country
+	}
+
+	country := resolver(ip)
+
+	db.geoIPMutex.Lock()
+	defer db.geoIPMutex.Unlock()
+	if _, ok := db.geoIPCache[key]; ok {
+		return country
+	}
+	db.geoIPCache[key] = db.geoIPLRU.PushFront(&geoIPCacheEntry{ip: key, country: country})
+	if db.geoIPLRU.Len() > geoIPCacheSize {
+		e := db.geoIPLRU.Remove(db.geoIPLRU.Back()).(*geoIPCacheEntry)
+		delete(db.geoIPCache, e.ip)
+	}
+	return country
+}
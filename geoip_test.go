@@ -0,0 +1,99 @@
+package bbs
+
+import (
+	"net"
+	"testing"
+)
+
+type fakeLastCountryUserRecord struct {
+	fakeUserRecord
+	country string
+}
+
+func (u *fakeLastCountryUserRecord) LastLoginCountry() string { return u.country }
+
+func TestUserLastCountry(t *testing.T) {
+	db := &DB{}
+	resolved := 0
+	db.SetGeoIPResolver(func(ip net.IP) string {
+		resolved++
+		if ip.Equal(net.ParseIP("59.124.167.226")) {
+			return "TW"
+		}
+		return ""
+	})
+
+	tests := []struct {
+		name     string
+		input    UserRecord
+		expected string
+	}{
+		{
+			name:     "stored country first",
+			input:    &fakeLastCountryUserRecord{fakeUserRecord{lastHost: "8.8.8.8"}, "US"},
+			expected: "US",
+		},
+		{
+			name:     "resolve by last host",
+			input:    &fakeUserRecord{lastHost: "59.124.167.226"},
+			expected: "TW",
+		},
+		{
+			name:     "empty stored country resolve by last host",
+			input:    &fakeLastCountryUserRecord{fakeUserRecord{lastHost: "59.124.167.226"}, ""},
+			expected: "TW",
+		},
+		{
+			name:     "partial host",
+			input:    &fakeUserRecord{lastHost: "140.112."},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := db.UserLastCountry(tt.input)
+			if actual != tt.expected {
+				t.Errorf("country not match, expected: %q, got: %q", tt.expected, actual)
+			}
+		})
+	}
+
+	if resolved != 1 {
+		t.Errorf("resolver should be called once for cached ip, got: %d", resolved)
+	}
+}
+
+func TestUserLastCountryCacheSize(t *testing.T) {
+	db := &DB{}
+	resolved := 0
+	db.SetGeoIPResolver(func(ip net.IP) string {
+		resolved++
+		return "TW"
+	})
+
+	ipOf := func(i int) string {
+		return net.IPv4(10, byte(i>>16), byte(i>>8), byte(i)).String()
+	}
+	for i := 0; i <= geoIPCacheSize; i++ {
+		db.UserLastCountry(&fakeUserRecord{lastHost: ipOf(i)})
+	}
+	if n := len(db.geoIPCache); n != geoIPCacheSize {
+		t.Errorf("cached ips expected: %d, got: %d", geoIPCacheSize, n)
+	}
+
+	resolved = 0
+	db.UserLastCountry(&fakeUserRecord{lastHost: ipOf(geoIPCacheSize)})
+	db.UserLastCountry(&fakeUserRecord{lastHost: ipOf(0)})
+	if resolved != 1 {
+		t.Errorf("least recently used ip should be evicted, resolved: %d", resolved)
+	}
+}
+
+func TestUserLastCountryWithoutResolver(t *testing.T) {
+	db := &DB{}
+	actual := db.UserLastCountry(&fakeUserRecord{lastHost: "59.124.167.226"})
+	if actual != "" {
+		t.Errorf("country should be empty without resolver, got: %q", actual)
+	}
+}
@@ -1,6 +1,6 @@
 module github.com/Ptt-official-app/go-bbs
 
-go 1.14
+go 1.16
 
 require (
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
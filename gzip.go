@@ -0,0 +1,41 @@
+package bbs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// SetRawArticleFile sets whether ReadBoardArticleFile and
+// ReadBoardTreasureFile return the raw file. By default, gzipped article
+// files of archives, such as `M.123.A.456.gz`, are decompressed
+// transparently.
+func (db *DB) SetRawArticleFile(raw bool) {
+	db.rawArticleFile = raw
+}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// decompressArticleFile decompresses buf read from path if it starts with
+// gzip magic bytes or path has ".gz" suffix.
+func (db *DB) decompressArticleFile(path string, buf []byte) ([]byte, error) {
+	if db.rawArticleFile {
+		return buf, nil
+	}
+	if !bytes.HasPrefix(buf, gzipMagic) && !strings.HasSuffix(path, ".gz") {
+		return buf, nil
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("bbs: gzip article %v error: %w", path, err)
+	}
+	defer r.Close()
+	ret, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("bbs: gzip article %v error: %w", path, err)
+	}
+	return ret, nil
+}
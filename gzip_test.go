@@ -0,0 +1,49 @@
+package bbs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+)
+
+func TestReadGzippedBoardArticleFile(t *testing.T) {
+	content := []byte("作者: SYSOP (站長) 看板: SYSOP\n標題: 測試\n")
+
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	if _, err := w.Write(content); err != nil {
+		t.Fatalf("gzip write error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("gzip close error: %v", err)
+	}
+
+	files := map[string][]byte{
+		"M.1.A.001":    content,
+		"M.2.A.002.gz": gz.Bytes(),
+	}
+	for filename := range files {
+		filename := filename
+		db := &DB{connector: &fakeConnector{
+			fakeGetBoardArticleFilePath: func() (string, error) { return filename, nil },
+			fakeReadBoardArticleFile:    func() ([]byte, error) { return files[filename], nil },
+		}}
+
+		buf, err := db.ReadBoardArticleFile("SYSOP", filename)
+		if err != nil {
+			t.Fatalf("ReadBoardArticleFile(%v) error: %v", filename, err)
+		}
+		if !bytes.Equal(buf, content) {
+			t.Errorf("ReadBoardArticleFile(%v) expected: %q, got: %q", filename, content, buf)
+		}
+
+		db.SetRawArticleFile(true)
+		buf, err = db.ReadBoardArticleFile("SYSOP", filename)
+		if err != nil {
+			t.Fatalf("ReadBoardArticleFile(%v) error: %v", filename, err)
+		}
+		if !bytes.Equal(buf, files[filename]) {
+			t.Errorf("raw ReadBoardArticleFile(%v) should return the raw file", filename)
+		}
+	}
+}
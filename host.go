@@ -0,0 +1,21 @@
+package bbs
+
+import (
+	"net"
+	"strings"
+)
+
+// ParseHostIP parses host stored in user record as net.IP. It returns false
+// when host is a domain name or a truncated address like `140.112.`, bbs
+// system often stores partial host and it can not be used as an IP address.
+func ParseHostIP(host string) (net.IP, bool) {
+	host = strings.TrimSpace(host)
+	if host == "" {
+		return nil, false
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, false
+	}
+	return ip, true
+}
@@ -0,0 +1,32 @@
+package bbs
+
+import (
+	"net"
+	"testing"
+)
+
+func TestParseHostIP(t *testing.T) {
+	tests := []struct {
+		input      string
+		expected   net.IP
+		expectedOK bool
+	}{
+		{"59.124.167.226", net.ParseIP("59.124.167.226"), true},
+		{" 59.124.167.226 ", net.ParseIP("59.124.167.226"), true},
+		{"2001:db8::1", net.ParseIP("2001:db8::1"), true},
+		{"140.112.", nil, false},
+		{"140.112", nil, false},
+		{"ptt.cc", nil, false},
+		{"", nil, false},
+	}
+
+	for _, c := range tests {
+		actual, ok := ParseHostIP(c.input)
+		if ok != c.expectedOK {
+			t.Errorf("ok not match with input %q, expected: %v, got: %v", c.input, c.expectedOK, ok)
+		}
+		if !actual.Equal(c.expected) {
+			t.Errorf("ip not match with input %q, expected: %v, got: %v", c.input, c.expected, actual)
+		}
+	}
+}
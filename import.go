@@ -0,0 +1,321 @@
+package bbs
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Driver which implement ImportBoardConnector supports replacing the
+// directories of a board, such as by ImportBoard.
+type ImportBoardConnector interface {
+
+	// ReplaceBoardDirs replaces each directory of dirs with the staged
+	// directory of the same index, either all of them are replaced or none.
+	// It should return error without replacing if connector is not writable,
+	// see ReplaceDirs.
+	ReplaceBoardDirs(dirs, staged []string) error
+}
+
+// ImportBoard restores board from a tar archive written by ExportBoard. The
+// archive is extracted to temporary directories next to the board directory
+// and the treasure directory. Files of the old board which ExportBoard does
+// not export, such as the board info and deleted articles, are linked into
+// them, then connector replaces the old directories with them at once, so a
+// bad archive or a failed replace leaves the board untouched. Entries with
+// absolute paths or "..", and entries other than regular files and
+// directories are rejected with ErrInvalidName. The board record in board
+// records file is not changed, use AddBoardRecord for a new board. Cached
+// article files and board stats of the board are invalidated, and the write
+// hook is called with WriteOpImport. In dry-run mode, the archive is
+// extracted and checked but the board is not replaced, see SetDryRun. It
+// returns ErrNotSupported if DB is opened with WithFS or connector does not
+// implement ImportBoardConnector.
+//
+// Unlike other writes, the temporary directories are written by os calls
+// instead of write connectors, as they are not part of the board until
+// ReplaceBoardDirs of connector replaces the board with them.
+func (db *DB) ImportBoard(boardID string, r io.Reader) (err error) {
+	var ic ImportBoardConnector
+	if db.fsys != nil || !db.connectorAs(&ic) {
+		return ErrNotSupported
+	}
+	if err := checkNames(boardID); err != nil {
+		return err
+	}
+
+	dirPath, err := db.boardArticleRecordsPath(boardID)
+	if err != nil {
+		log.Println("bbs: GetBoardArticleRecordsPath error:", err)
+		return err
+	}
+	manPath, err := db.boardTreasureRecordsPath(boardID, []string{})
+	if err != nil {
+		log.Println("bbs: GetBoardTreasureRecordsPath error:", err)
+		return err
+	}
+	boardDir, manDir := filepath.Dir(dirPath), filepath.Dir(manPath)
+
+	// article files of the old board are exported, so they are replaced by
+	// the archive instead of linked.
+	exported := map[string]bool{filepath.Base(dirPath): true}
+	oldRecs, err := db.ReadBoardArticleRecordsFile(boardID)
+	if err != nil {
+		return err
+	}
+	for _, rec := range oldRecs {
+		exported[rec.Filename()] = true
+	}
+
+	boardTmp, err := tempDirFor(boardDir)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(boardTmp)
+	manTmp := ""
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		name, err := checkArchiveName(hdr.Name)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(boardTmp, filepath.FromSlash(name))
+		if name == "man" || strings.HasPrefix(name, "man/") {
+			if manTmp == "" {
+				if manTmp, err = tempDirFor(manDir); err != nil {
+					return err
+				}
+				defer os.RemoveAll(manTmp)
+			}
+			dst = filepath.Join(manTmp, filepath.FromSlash(strings.TrimPrefix(name[len("man"):], "/")))
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(dst, 0755)
+		case tar.TypeReg, tar.TypeRegA:
+			err = extractFile(dst, tr)
+		default:
+			err = fmt.Errorf("%w: archive entry %q is not a regular file", ErrInvalidName, hdr.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	names, err := dirFileNames(boardDir, boardTmp)
+	if err != nil {
+		return err
+	}
+	err = linkMissing(boardDir, boardTmp, func(rel string) bool { return exported[rel] })
+	if err != nil {
+		log.Println("bbs: link board files error:", err)
+		return err
+	}
+	dirs, staged := []string{boardDir}, []string{boardTmp}
+	if manTmp != "" {
+		if err := linkMissing(manDir, manTmp, nil); err != nil {
+			log.Println("bbs: link treasure files error:", err)
+			return err
+		}
+		dirs, staged = append(dirs, manDir), append(staged, manTmp)
+	}
+
+	if db.dryRun {
+		log.Printf("bbs: dry-run: %s board %q from archive to %s", WriteOpImport, boardID, strings.Join(dirs, ", "))
+		return nil
+	}
+
+	err = ic.ReplaceBoardDirs(dirs, staged)
+	if err != nil {
+		log.Println("bbs: ReplaceBoardDirs error:", err)
+		return err
+	}
+	for _, name := range names {
+		if p, err := db.boardArticleFilePath(boardID, name); err == nil {
+			db.invalidateCache(articleFileCacheKey(p))
+		}
+	}
+	if err := db.InvalidateBoardStatsCache(); err != nil {
+		log.Println("bbs: InvalidateBoardStatsCache error:", err)
+	}
+	db.countWrite(articleRecordKind)
+	db.callWriteHook(WriteOpImport, dirPath, 0, nil)
+	return nil
+}
+
+// dirFileNames returns the names of regular files directly in dirs, dirs
+// which do not exist are skipped.
+func dirFileNames(dirs ...string) ([]string, error) {
+	ret := []string{}
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.Type().IsRegular() {
+				ret = append(ret, e.Name())
+			}
+		}
+	}
+	return ret, nil
+}
+
+// linkMissing hard links the files in src which are not in dst into dst,
+// keeping their relative paths, files are copied if they can not be linked.
+// Files whose slash-separated path relative to src is accepted by skip are
+// not linked, skip may be nil. It does nothing if src does not exist.
+func linkMissing(src, dst string, skip func(rel string) bool) error {
+	err := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if _, err := os.Lstat(target); err == nil {
+			return nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if skip != nil && skip(filepath.ToSlash(rel)) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if err := os.Link(p, target); err == nil {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return extractFile(target, f)
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// ReplaceDirs replaces each directory of dirs with the staged directory of
+// the same index, staged directories should be on the same file system,
+// such as created next to the directories. The old directories are renamed
+// aside until all directories are replaced, and restored with staged
+// directories moved back if any rename fails, so either all directories are
+// replaced or none. Drivers can use it to implement
+// ImportBoardConnector.ReplaceBoardDirs.
+func ReplaceDirs(dirs, staged []string) error {
+	if len(dirs) != len(staged) {
+		return fmt.Errorf("bbs: %d directories with %d staged directories", len(dirs), len(staged))
+	}
+
+	// backups[i] is the old dirs[i], it is empty if dirs[i] did not exist.
+	backups := []string{}
+	rollback := func() {
+		for i := len(backups) - 1; i >= 0; i-- {
+			os.Rename(dirs[i], staged[i])
+			if backups[i] != "" {
+				os.Rename(backups[i], dirs[i])
+			}
+		}
+	}
+	for i, dir := range dirs {
+		if err := os.Chmod(staged[i], 0755); err != nil {
+			rollback()
+			return err
+		}
+		backup := staged[i] + ".old"
+		err := os.Rename(dir, backup)
+		if errors.Is(err, os.ErrNotExist) {
+			backup = ""
+		} else if err != nil {
+			rollback()
+			return err
+		}
+		if err := os.Rename(staged[i], dir); err != nil {
+			if backup != "" {
+				os.Rename(backup, dir)
+			}
+			rollback()
+			return err
+		}
+		backups = append(backups, backup)
+	}
+
+	for _, backup := range backups {
+		if backup != "" {
+			os.RemoveAll(backup)
+		}
+	}
+	return nil
+}
+
+// checkArchiveName returns the cleaned name of archive entry, it returns
+// ErrInvalidName if name may point outside the board.
+func checkArchiveName(name string) (string, error) {
+	cleaned := path.Clean(strings.TrimSuffix(name, "/"))
+	if strings.Contains(name, "\\") || !fs.ValidPath(cleaned) || cleaned == "." {
+		return "", fmt.Errorf("%w: archive entry %q", ErrInvalidName, name)
+	}
+	for _, elem := range strings.Split(name, "/") {
+		if elem == ".." {
+			return "", fmt.Errorf("%w: archive entry %q", ErrInvalidName, name)
+		}
+	}
+	return cleaned, nil
+}
+
+// tempDirFor creates a temporary directory next to dir.
+func tempDirFor(dir string) (string, error) {
+	parent := filepath.Dir(dir)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return "", err
+	}
+	return ioutil.TempDir(parent, "."+filepath.Base(dir)+".import*")
+}
+
+func extractFile(dst string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
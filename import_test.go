@@ -0,0 +1,253 @@
+package bbs
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type fakeImportBoardConnector struct {
+	fakeBoardHomeConnector
+	replaceErr error
+}
+
+func (c *fakeImportBoardConnector) ReplaceBoardDirs(dirs, staged []string) error {
+	if c.replaceErr != nil {
+		return c.replaceErr
+	}
+	return ReplaceDirs(dirs, staged)
+}
+
+func (c *fakeImportBoardConnector) ReadBoardArticleFile(name string) ([]byte, error) {
+	return ioutil.ReadFile(name)
+}
+
+func TestImportBoard(t *testing.T) {
+	src, err := ioutil.TempDir("", "import_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(src)
+	dst, err := ioutil.TempDir("", "import_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dst)
+
+	writeTestFiles(t, src, map[string]string{
+		"boards/SYSOP/.DIR":      "M.1.A.001\n",
+		"boards/SYSOP/M.1.A.001": "first",
+		"man/SYSOP/.DIR":         "D1\n",
+		"man/SYSOP/D1/.DIR":      "M.3.A.003\n",
+		"man/SYSOP/D1/M.3.A.003": "treasure",
+	})
+	writeTestFiles(t, dst, map[string]string{
+		"boards/SYSOP/.DIR":      "M.0.A.000\nM.1.A.001\n",
+		"boards/SYSOP/M.0.A.000": "old",
+		"boards/SYSOP/M.1.A.001": "old first",
+		"boards/SYSOP/info":      "board info",
+		"man/SYSOP/D2/M.4.A.004": "old treasure",
+	})
+
+	var buf bytes.Buffer
+	srcDB := &DB{connector: &fakeBoardHomeConnector{home: src}}
+	if err := srcDB.ExportBoard("SYSOP", &buf); err != nil {
+		t.Fatalf("ExportBoard error: %v", err)
+	}
+
+	c := &fakeImportBoardConnector{fakeBoardHomeConnector: fakeBoardHomeConnector{home: dst}}
+	db := &DB{connector: WithArticleCache(c, 1024)}
+	statsCache := filepath.Join(dst, "board_stats.json")
+	db.SetBoardStatsCache(statsCache)
+	writeTestFiles(t, dst, map[string]string{"board_stats.json": "[]"})
+	if data, err := db.ReadBoardArticleFile("SYSOP", "M.1.A.001"); err != nil || string(data) != "old first" {
+		t.Fatalf("ReadBoardArticleFile expected: old first, got: %q, %v", data, err)
+	}
+	var events []string
+	db.SetWriteHook(func(op string, path string, index uint, rec interface{}) {
+		events = append(events, fmt.Sprintf("%s %s %d %v", op, path, index, rec))
+	})
+
+	if err := db.ImportBoard("SYSOP", &buf); err != nil {
+		t.Fatalf("ImportBoard error: %v", err)
+	}
+	expected := []string{fmt.Sprintf("import %s 0 <nil>", filepath.Join(dst, "boards", "SYSOP", ".DIR"))}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("write hook events expected: %q, got: %q", expected, events)
+	}
+
+	for name, expected := range map[string]string{
+		"boards/SYSOP/.DIR":      "M.1.A.001\n",
+		"boards/SYSOP/M.1.A.001": "first",
+		"boards/SYSOP/info":      "board info",
+		"man/SYSOP/D1/M.3.A.003": "treasure",
+		"man/SYSOP/D2/M.4.A.004": "old treasure",
+	} {
+		data, err := ioutil.ReadFile(filepath.Join(dst, name))
+		if err != nil || string(data) != expected {
+			t.Errorf("%v expected: %q, got: %q, %v", name, expected, data, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dst, "boards/SYSOP/M.0.A.000")); !os.IsNotExist(err) {
+		t.Errorf("exported article files of old board should be replaced, got: %v", err)
+	}
+	if files, _ := ioutil.ReadDir(filepath.Join(dst, "boards")); len(files) != 1 {
+		t.Errorf("temporary directories should be removed, got %d files", len(files))
+	}
+	if data, err := db.ReadBoardArticleFile("SYSOP", "M.1.A.001"); err != nil || string(data) != "first" {
+		t.Errorf("cached article file should be invalidated, got: %q, %v", data, err)
+	}
+	if _, err := os.Stat(statsCache); !os.IsNotExist(err) {
+		t.Errorf("board stats cache should be invalidated, got: %v", err)
+	}
+}
+
+func TestImportBoardDryRun(t *testing.T) {
+	dst := t.TempDir()
+	writeTestFiles(t, dst, map[string]string{
+		"boards/SYSOP/.DIR":      "M.0.A.000\n",
+		"boards/SYSOP/M.0.A.000": "old",
+	})
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tw.WriteHeader(&tar.Header{Name: ".DIR", Mode: 0644, Size: 4})
+	tw.Write([]byte("new\n"))
+	tw.Close()
+
+	db := &DB{connector: &fakeImportBoardConnector{fakeBoardHomeConnector: fakeBoardHomeConnector{home: dst}}}
+	db.SetDryRun(true)
+	called := false
+	db.SetWriteHook(func(op string, path string, index uint, rec interface{}) {
+		called = true
+	})
+	if err := db.ImportBoard("SYSOP", &buf); err != nil {
+		t.Errorf("ImportBoard in dry-run error: %v", err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dst, "boards/SYSOP/.DIR"))
+	if err != nil || string(data) != "M.0.A.000\n" {
+		t.Errorf("board should be untouched in dry-run, got: %q, %v", data, err)
+	}
+	if files, _ := ioutil.ReadDir(filepath.Join(dst, "boards")); len(files) != 1 {
+		t.Errorf("temporary directories should be removed, got %d files", len(files))
+	}
+	if called {
+		t.Errorf("write hook should not be called in dry-run")
+	}
+
+	buf.Reset()
+	tw = tar.NewWriter(&buf)
+	tw.WriteHeader(&tar.Header{Name: "../.PASSWDS", Mode: 0644})
+	tw.Close()
+	if err := db.ImportBoard("SYSOP", &buf); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("ImportBoard in dry-run should still check archive, got: %v", err)
+	}
+}
+
+func TestImportBoardReplaceError(t *testing.T) {
+	dst := t.TempDir()
+	writeTestFiles(t, dst, map[string]string{
+		"boards/SYSOP/.DIR":      "M.0.A.000\n",
+		"boards/SYSOP/M.0.A.000": "old",
+	})
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tw.WriteHeader(&tar.Header{Name: ".DIR", Mode: 0644, Size: 4})
+	tw.Write([]byte("new\n"))
+	tw.Close()
+
+	replaceErr := errors.New("read-only")
+	db := &DB{connector: &fakeImportBoardConnector{fakeBoardHomeConnector: fakeBoardHomeConnector{home: dst}, replaceErr: replaceErr}}
+	if err := db.ImportBoard("SYSOP", &buf); !errors.Is(err, replaceErr) {
+		t.Errorf("ImportBoard should return error of ReplaceBoardDirs, got: %v", err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dst, "boards/SYSOP/.DIR"))
+	if err != nil || string(data) != "M.0.A.000\n" {
+		t.Errorf("board should be untouched, got: %q, %v", data, err)
+	}
+	if files, _ := ioutil.ReadDir(filepath.Join(dst, "boards")); len(files) != 1 {
+		t.Errorf("temporary directories should be removed, got %d files", len(files))
+	}
+
+	db = &DB{connector: &fakeBoardHomeConnector{home: dst}}
+	if err := db.ImportBoard("SYSOP", &buf); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("ImportBoard should return ErrNotSupported, got: %v", err)
+	}
+}
+
+func TestReplaceDirs(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"a/file":     "old a",
+		"b/file":     "old b",
+		"a.new/file": "new a",
+	})
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+
+	// b.new does not exist, so replacing b fails after a is replaced.
+	err := ReplaceDirs([]string{a, b}, []string{a + ".new", b + ".new"})
+	if err == nil {
+		t.Fatalf("ReplaceDirs should return error")
+	}
+	for name, expected := range map[string]string{"a/file": "old a", "b/file": "old b", "a.new/file": "new a"} {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(data) != expected {
+			t.Errorf("%v should be restored, expected: %q, got: %q, %v", name, expected, data, err)
+		}
+	}
+
+	writeTestFiles(t, dir, map[string]string{"b.new/file": "new b"})
+	if err := ReplaceDirs([]string{a, b}, []string{a + ".new", b + ".new"}); err != nil {
+		t.Fatalf("ReplaceDirs error: %v", err)
+	}
+	for name, expected := range map[string]string{"a/file": "new a", "b/file": "new b"} {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(data) != expected {
+			t.Errorf("%v expected: %q, got: %q, %v", name, expected, data, err)
+		}
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 2 {
+		t.Errorf("old directories should be removed, got %d files", len(files))
+	}
+}
+
+func TestImportBoardTraversal(t *testing.T) {
+	dst, err := ioutil.TempDir("", "import_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dst)
+	writeTestFiles(t, dst, map[string]string{
+		"boards/SYSOP/.DIR": "M.0.A.000\n",
+	})
+
+	for _, name := range []string{"../../evil", "/etc/evil", "man/../../evil"} {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		tw.WriteHeader(&tar.Header{Name: ".DIR", Mode: 0644, Size: 4})
+		tw.Write([]byte("new\n"))
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: 4})
+		tw.Write([]byte("evil"))
+		tw.Close()
+
+		db := &DB{connector: &fakeImportBoardConnector{fakeBoardHomeConnector: fakeBoardHomeConnector{home: dst}}}
+		if err := db.ImportBoard("SYSOP", &buf); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("ImportBoard with %q should return ErrInvalidName, got: %v", name, err)
+		}
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dst, "boards/SYSOP/.DIR"))
+	if err != nil || string(data) != "M.0.A.000\n" {
+		t.Errorf("board should be untouched, got: %q, %v", data, err)
+	}
+	if files, _ := ioutil.ReadDir(dst); len(files) != 1 {
+		t.Errorf("no file should be written outside board, got %d files", len(files))
+	}
+}
@@ -0,0 +1,33 @@
+package bbs
+
+import "time"
+
+// Driver which implement LocationConnector supports returning times of
+// records in a configured time zone.
+type LocationConnector interface {
+	// SetLocation sets the time zone of times returned by records read by
+	// the connector.
+	SetLocation(loc *time.Location)
+}
+
+// SetLocation sets the time zone of times returned by records, such as
+// LastLogin of UserRecord, and the time zone Date of ArticleRecord is
+// parsed in. nil resets it to time.Local. The location is kept by DB and
+// passed to connector if it implements LocationConnector, user records
+// decoded by the codec of fork follow it too. Records of connectors without
+// LocationConnector keep the time zone of driver.
+func (db *DB) SetLocation(loc *time.Location) {
+	db.location = loc
+	var lc LocationConnector
+	if db.connectorAs(&lc) {
+		lc.SetLocation(db.Location())
+	}
+}
+
+// Location returns the time zone set by SetLocation, time.Local by default.
+func (db *DB) Location() *time.Location {
+	if db.location == nil {
+		return time.Local
+	}
+	return db.location
+}
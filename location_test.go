@@ -0,0 +1,66 @@
+package bbs
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeLocationConnector struct {
+	fakeConnector
+	loc *time.Location
+}
+
+func (c *fakeLocationConnector) SetLocation(loc *time.Location) { c.loc = loc }
+
+func TestSetLocation(t *testing.T) {
+	taipei := time.FixedZone("CST", 8*60*60)
+	recs := []ArticleRecord{&fakeArticleRecord{filename: "NOTE", date: " 1/02"}}
+	c := &fakeLocationConnector{
+		fakeConnector: fakeConnector{
+			fakeGetBoardArticleRecordsPath: func() (string, error) {
+				return "boards/S/SYSOP/.DIR", nil
+			},
+			fakeReadArticleRecordsFile: func() ([]ArticleRecord, error) {
+				return recs, nil
+			},
+		},
+	}
+	db := &DB{connector: c}
+	if db.Location() != time.Local {
+		t.Errorf("default location expected: %v, got: %v", time.Local, db.Location())
+	}
+
+	// 1/02 00:00 in Taipei is before since, 1/02 00:00 in UTC is after it.
+	since := time.Date(time.Now().Year(), 1, 1, 20, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		loc      *time.Location
+		expected int
+	}{
+		{loc: taipei, expected: 0},
+		{loc: time.UTC, expected: 1},
+	}
+	for _, tc := range testCases {
+		db.SetLocation(tc.loc)
+		if c.loc != tc.loc {
+			t.Errorf("location of connector expected: %v, got: %v", tc.loc, c.loc)
+		}
+		actual, err := db.FilterBoardArticles("SYSOP", ArticleFilter{Since: since})
+		if err != nil {
+			t.Fatalf("FilterBoardArticles error: %v", err)
+		}
+		if len(actual) != tc.expected {
+			t.Errorf("articles since %v in %v expected: %v, got: %v", since, tc.loc, tc.expected, len(actual))
+		}
+	}
+
+	db.SetLocation(nil)
+	if db.Location() != time.Local || c.loc != time.Local {
+		t.Errorf("nil location should reset to %v, got: %v, %v", time.Local, db.Location(), c.loc)
+	}
+
+	db = &DB{connector: &fakeConnector{}}
+	db.SetLocation(time.UTC)
+	if db.Location() != time.UTC {
+		t.Errorf("location expected: %v, got: %v", time.UTC, db.Location())
+	}
+}
@@ -0,0 +1,24 @@
+package bbs
+
+import "time"
+
+// Driver which implement LockConnector supports a timeout of locking record
+// files on writes, so a write fails instead of waiting forever while the
+// running bbs daemon holds the lock.
+type LockConnector interface {
+	// SetLockTimeout sets the timeout of locking record files, d <= 0 means
+	// waiting until the lock is acquired.
+	SetLockTimeout(d time.Duration)
+}
+
+// SetLockTimeout sets the timeout of the file locks taken by connector
+// around writes, such as AddBoardRecord and CreateUser. It returns
+// ErrNotSupported if connector does not implement LockConnector.
+func (db *DB) SetLockTimeout(d time.Duration) error {
+	var lc LockConnector
+	if !db.connectorAs(&lc) {
+		return ErrNotSupported
+	}
+	lc.SetLockTimeout(d)
+	return nil
+}
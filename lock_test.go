@@ -0,0 +1,32 @@
+package bbs
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeLockConnector struct {
+	fakeConnector
+	timeout time.Duration
+}
+
+func (c *fakeLockConnector) SetLockTimeout(d time.Duration) {
+	c.timeout = d
+}
+
+func TestSetLockTimeout(t *testing.T) {
+	c := &fakeLockConnector{}
+	db := &DB{connector: WithCache(c, time.Hour)}
+	if err := db.SetLockTimeout(time.Second); err != nil {
+		t.Fatalf("SetLockTimeout error: %v", err)
+	}
+	if c.timeout != time.Second {
+		t.Errorf("timeout expected: %v, got: %v", time.Second, c.timeout)
+	}
+
+	db = &DB{connector: &fakeConnector{}}
+	if err := db.SetLockTimeout(time.Second); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("SetLockTimeout should return ErrNotSupported, got: %v", err)
+	}
+}
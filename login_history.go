@@ -0,0 +1,19 @@
+package bbs
+
+import (
+	"time"
+)
+
+// UserLoginHistory returns recent login hosts and times of user,
+// most-recent-first. It falls back to LastHost and LastLogin when user record
+// do not implement LoginHistoryUserRecord, and returns nil if user never
+// logged in.
+func UserLoginHistory(u UserRecord) (hosts []string, times []time.Time) {
+	if lh, ok := u.(LoginHistoryUserRecord); ok {
+		return lh.LoginHosts(), lh.LoginTimes()
+	}
+	if u.LastHost() == "" && u.LastLogin().IsZero() {
+		return nil, nil
+	}
+	return []string{u.LastHost()}, []time.Time{u.LastLogin()}
+}
@@ -0,0 +1,58 @@
+package bbs
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeLoginHistoryUserRecord struct {
+	fakeUserRecord
+	hosts []string
+	times []time.Time
+}
+
+func (u *fakeLoginHistoryUserRecord) LoginHosts() []string    { return u.hosts }
+func (u *fakeLoginHistoryUserRecord) LoginTimes() []time.Time { return u.times }
+
+func TestUserLoginHistory(t *testing.T) {
+	t1 := time.Date(2020, 9, 22, 6, 28, 14, 0, time.UTC)
+	t2 := t1.Add(-time.Hour)
+
+	tests := []struct {
+		name          string
+		input         UserRecord
+		expectedHosts []string
+		expectedTimes []time.Time
+	}{
+		{
+			name: "history",
+			input: &fakeLoginHistoryUserRecord{
+				fakeUserRecord{lastHost: "59.124.167.226", lastLogin: t1},
+				[]string{"59.124.167.226", "140.112.1.1"},
+				[]time.Time{t1, t2},
+			},
+			expectedHosts: []string{"59.124.167.226", "140.112.1.1"},
+			expectedTimes: []time.Time{t1, t2},
+		},
+		{
+			name:          "last host only",
+			input:         &fakeUserRecord{lastHost: "59.124.167.226", lastLogin: t1},
+			expectedHosts: []string{"59.124.167.226"},
+			expectedTimes: []time.Time{t1},
+		},
+		{
+			name:  "never logged in",
+			input: &fakeUserRecord{},
+		},
+	}
+	for _, tt := range tests {
+		hosts, times := UserLoginHistory(tt.input)
+		if !reflect.DeepEqual(hosts, tt.expectedHosts) {
+			t.Errorf("%s: hosts expected: %v, got: %v", tt.name, tt.expectedHosts, hosts)
+		}
+		if !reflect.DeepEqual(times, tt.expectedTimes) {
+			t.Errorf("%s: times expected: %v, got: %v", tt.name, tt.expectedTimes, times)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package bbs
+
+import (
+	"errors"
+	"log"
+	"os"
+)
+
+// MailRecord is a mail in user's mailbox (信箱).
+type MailRecord interface {
+	// Filename return the file name of this mail in mailbox.
+	Filename() string
+	// Sender return the user id or email of who sent this mail.
+	Sender() string
+	// Title return the title of this mail.
+	Title() string
+	// Date return the date string of this mail, eg: " 9/02".
+	Date() string
+	// IsRead return true if user has read this mail.
+	IsRead() bool
+}
+
+// ReadUserMail returns the MailRecords in mailbox of userID.
+func (db *DB) ReadUserMail(userID string) ([]MailRecord, error) {
+	var mc MailConnector
+	if !db.connectorAs(&mc) {
+		return nil, ErrNotSupported
+	}
+
+	path, err := db.userMailboxPath(mc, userID)
+	if err != nil {
+		log.Println("bbs: GetUserMailboxPath error:", err)
+		return nil, err
+	}
+	log.Println("path:", path)
+
+	recs, err := mc.ReadMailIndexFile(path)
+	if err != nil {
+		log.Println("bbs: ReadMailIndexFile error:", err)
+		return nil, err
+	}
+	return recs, nil
+}
+
+// ReadUserMailFile returns raw file of the mail with filename in mailbox of userID.
+func (db *DB) ReadUserMailFile(userID, filename string) ([]byte, error) {
+	if err := checkNames(filename); err != nil {
+		return nil, err
+	}
+	var mc MailConnector
+	if !db.connectorAs(&mc) {
+		return nil, ErrNotSupported
+	}
+
+	path, err := db.userMailFilePath(mc, userID, filename)
+	if err != nil {
+		log.Println("bbs: GetUserMailFilePath error:", err)
+		return nil, err
+	}
+	log.Println("path:", path)
+
+	buf, err := mc.ReadMailFile(path)
+	if err != nil {
+		log.Println("bbs: ReadMailFile error:", err)
+		return nil, err
+	}
+	return buf, nil
+}
+
+// NumUnreadMail returns how many unread mails in mailbox of userID, it
+// returns 0 if user has no mailbox file.
+func (db *DB) NumUnreadMail(userID string) (int, error) {
+	recs, err := db.ReadUserMail(userID)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	n := 0
+	for _, r := range recs {
+		if !r.IsRead() {
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,101 @@
+package bbs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+type fakeMailRecord struct {
+	filename string
+	sender   string
+	title    string
+	date     string
+	isRead   bool
+}
+
+func (m *fakeMailRecord) Filename() string { return m.filename }
+func (m *fakeMailRecord) Sender() string   { return m.sender }
+func (m *fakeMailRecord) Title() string    { return m.title }
+func (m *fakeMailRecord) Date() string     { return m.date }
+func (m *fakeMailRecord) IsRead() bool     { return m.isRead }
+
+type fakeMailConnector struct {
+	fakeConnector
+	fakeReadMailIndexFile func() ([]MailRecord, error)
+}
+
+var _ MailConnector = &fakeMailConnector{}
+
+func (c *fakeMailConnector) GetUserMailboxPath(userID string) (string, error) {
+	return "home/" + userID + "/.DIR", nil
+}
+
+func (c *fakeMailConnector) GetUserMailFilePath(userID string, filename string) (string, error) {
+	return "home/" + userID + "/" + filename, nil
+}
+
+func (c *fakeMailConnector) ReadMailIndexFile(name string) ([]MailRecord, error) {
+	return c.fakeReadMailIndexFile()
+}
+
+func (c *fakeMailConnector) ReadMailFile(name string) ([]byte, error) {
+	return nil, nil
+}
+
+func TestNumUnreadMail(t *testing.T) {
+	tests := []struct {
+		name          string
+		readMailIndex func() ([]MailRecord, error)
+		expected      int
+		hasErr        bool
+	}{
+		{
+			name: "count unread mails",
+			readMailIndex: func() ([]MailRecord, error) {
+				return []MailRecord{
+					&fakeMailRecord{filename: "M.1.A.1", isRead: true},
+					&fakeMailRecord{filename: "M.2.A.2"},
+					&fakeMailRecord{filename: "M.3.A.3"},
+				}, nil
+			},
+			expected: 2,
+		},
+		{
+			name: "missing mailbox",
+			readMailIndex: func() ([]MailRecord, error) {
+				return nil, fmt.Errorf("open error: %w", os.ErrNotExist)
+			},
+			expected: 0,
+		},
+		{
+			name: "read error",
+			readMailIndex: func() ([]MailRecord, error) {
+				return nil, errors.New("permission denied")
+			},
+			hasErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &DB{connector: &fakeMailConnector{fakeReadMailIndexFile: tt.readMailIndex}}
+			actual, err := db.NumUnreadMail("SYSOP")
+			if (err != nil) != tt.hasErr {
+				t.Errorf("err = %v, hasErr expected: %v", err, tt.hasErr)
+			}
+			if actual != tt.expected {
+				t.Errorf("unread mail count not match, expected: %v, got: %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestReadUserMailNotSupported(t *testing.T) {
+	db := &DB{connector: &fakeConnector{}}
+	_, err := db.ReadUserMail("SYSOP")
+	if !errors.Is(err, ErrNotSupported) {
+		t.Errorf("err expected: %v, got: %v", ErrNotSupported, err)
+	}
+}
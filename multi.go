@@ -0,0 +1,169 @@
+package bbs
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// InstanceSeparator separates the instance name and the id in a qualified
+// id of MultiDB, such as "ptt:SYSOP".
+const InstanceSeparator = ":"
+
+// MultiDB aggregates the DBs of several BBS instances, such as mirrors of
+// different BBS, for cross-BBS services. Board ids and user ids of
+// different instances may collide, so records returned by MultiDB are
+// tagged by instance and lookups take qualified ids of the form
+// "instance:id".
+type MultiDB struct {
+	dbs       map[string]*DB
+	instances []string
+}
+
+// InstanceBoardRecord is a BoardRecord tagged by its instance.
+type InstanceBoardRecord struct {
+	BoardRecord
+	Instance string
+}
+
+// QualifiedID returns the qualified board id, such as "ptt:SYSOP".
+func (r InstanceBoardRecord) QualifiedID() string {
+	return QualifyID(r.Instance, r.BoardID())
+}
+
+// InstanceUserRecord is a UserRecord tagged by its instance.
+type InstanceUserRecord struct {
+	UserRecord
+	Instance string
+}
+
+// QualifiedID returns the qualified user id, such as "ptt:SYSOP".
+func (r InstanceUserRecord) QualifiedID() string {
+	return QualifyID(r.Instance, r.UserID())
+}
+
+// OpenMulti returns a MultiDB of connectors keyed by instance name, the
+// connectors should be opened already. Instance names must be non-empty
+// and must not contain InstanceSeparator.
+func OpenMulti(connectors map[string]Connector) (*MultiDB, error) {
+	m := &MultiDB{dbs: map[string]*DB{}}
+	for name, c := range connectors {
+		if name == "" || strings.Contains(name, InstanceSeparator) {
+			return nil, fmt.Errorf("%w: instance %q", ErrInvalidName, name)
+		}
+		if c == nil {
+			return nil, fmt.Errorf("bbs: connector of instance %q is nil", name)
+		}
+		m.dbs[name] = &DB{connector: c}
+		m.instances = append(m.instances, name)
+	}
+	sort.Strings(m.instances)
+	return m, nil
+}
+
+// QualifyID returns the qualified id of id in instance.
+func QualifyID(instance, id string) string {
+	return instance + InstanceSeparator + id
+}
+
+// SplitQualifiedID splits qualified id into instance and id, it returns
+// ErrInvalidName if qualifiedID has no instance.
+func SplitQualifiedID(qualifiedID string) (instance, id string, err error) {
+	i := strings.Index(qualifiedID, InstanceSeparator)
+	if i <= 0 {
+		return "", "", fmt.Errorf("%w: %q has no instance", ErrInvalidName, qualifiedID)
+	}
+	return qualifiedID[:i], qualifiedID[i+len(InstanceSeparator):], nil
+}
+
+// Instances returns the instance names in sorted order.
+func (m *MultiDB) Instances() []string {
+	return append([]string{}, m.instances...)
+}
+
+// DB returns the DB of instance.
+func (m *MultiDB) DB(instance string) (*DB, bool) {
+	db, ok := m.dbs[instance]
+	return db, ok
+}
+
+// lookup returns the DB, instance and id of qualifiedID.
+func (m *MultiDB) lookup(qualifiedID string) (db *DB, instance, id string, err error) {
+	instance, id, err = SplitQualifiedID(qualifiedID)
+	if err != nil {
+		return nil, "", "", err
+	}
+	db, ok := m.dbs[instance]
+	if !ok {
+		return nil, "", "", fmt.Errorf("%w: instance %q", ErrRecordNotFound, instance)
+	}
+	return db, instance, id, nil
+}
+
+// ReadBoardRecords returns the board records of all instances, ordered by
+// instance name and then by the order in each instance.
+func (m *MultiDB) ReadBoardRecords() ([]InstanceBoardRecord, error) {
+	ret := []InstanceBoardRecord{}
+	for _, name := range m.instances {
+		recs, err := m.dbs[name].ReadBoardRecords()
+		if err != nil {
+			log.Println("bbs: ReadBoardRecords of", name, "error:", err)
+			return nil, err
+		}
+		for _, r := range recs {
+			ret = append(ret, InstanceBoardRecord{BoardRecord: r, Instance: name})
+		}
+	}
+	return ret, nil
+}
+
+// ReadUserRecords returns the user records of all instances, ordered by
+// instance name and then by the order in each instance.
+func (m *MultiDB) ReadUserRecords() ([]InstanceUserRecord, error) {
+	ret := []InstanceUserRecord{}
+	for _, name := range m.instances {
+		recs, err := m.dbs[name].ReadUserRecords()
+		if err != nil {
+			log.Println("bbs: ReadUserRecords of", name, "error:", err)
+			return nil, err
+		}
+		for _, r := range recs {
+			ret = append(ret, InstanceUserRecord{UserRecord: r, Instance: name})
+		}
+	}
+	return ret, nil
+}
+
+// GetUserRecord returns the user record of qualified user id, such as
+// "ptt:SYSOP".
+func (m *MultiDB) GetUserRecord(qualifiedUserID string) (InstanceUserRecord, error) {
+	db, instance, userID, err := m.lookup(qualifiedUserID)
+	if err != nil {
+		return InstanceUserRecord{}, err
+	}
+	u, err := db.GetUserRecord(userID)
+	if err != nil {
+		return InstanceUserRecord{}, err
+	}
+	return InstanceUserRecord{UserRecord: u, Instance: instance}, nil
+}
+
+// ReadBoardArticleRecordsFile returns the article records of qualified
+// board id, such as "ptt:SYSOP".
+func (m *MultiDB) ReadBoardArticleRecordsFile(qualifiedBoardID string) ([]ArticleRecord, error) {
+	db, _, boardID, err := m.lookup(qualifiedBoardID)
+	if err != nil {
+		return nil, err
+	}
+	return db.ReadBoardArticleRecordsFile(boardID)
+}
+
+// ReadBoardArticleFile returns the article file of qualified board id.
+func (m *MultiDB) ReadBoardArticleFile(qualifiedBoardID string, filename string) ([]byte, error) {
+	db, _, boardID, err := m.lookup(qualifiedBoardID)
+	if err != nil {
+		return nil, err
+	}
+	return db.ReadBoardArticleFile(boardID, filename)
+}
@@ -0,0 +1,71 @@
+package bbs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func newTestMultiConnector(boardIDs ...string) *fakeConnector {
+	recs := []BoardRecord{}
+	for _, id := range boardIDs {
+		recs = append(recs, &fakeBoardRecord{boardID: id})
+	}
+	return &fakeConnector{
+		fakeGetBoardRecordsPath:  func() (string, error) { return ".BRD", nil },
+		fakeReadBoardRecordsFile: func() ([]BoardRecord, error) { return recs, nil },
+		fakeGetUserRecordsPath:   func() (string, error) { return ".PASSWDS", nil },
+		fakeReadUserRecordsFile:  func() ([]UserRecord, error) { return testUserRecords, nil },
+		fakeGetBoardArticleRecordsPath: func() (string, error) {
+			return ".DIR", nil
+		},
+		fakeReadArticleRecordsFile: func() ([]ArticleRecord, error) {
+			return []ArticleRecord{&fakeArticleRecord{filename: boardIDs[0]}}, nil
+		},
+	}
+}
+
+func TestOpenMulti(t *testing.T) {
+	m, err := OpenMulti(map[string]Connector{
+		"ptt":  newTestMultiConnector("SYSOP", "Test"),
+		"ptt2": newTestMultiConnector("SYSOP"),
+	})
+	if err != nil {
+		t.Fatalf("OpenMulti error: %v", err)
+	}
+	if !reflect.DeepEqual(m.Instances(), []string{"ptt", "ptt2"}) {
+		t.Errorf("Instances not match, got: %v", m.Instances())
+	}
+
+	brds, err := m.ReadBoardRecords()
+	if err != nil {
+		t.Fatalf("ReadBoardRecords error: %v", err)
+	}
+	ids := []string{}
+	for _, b := range brds {
+		ids = append(ids, b.QualifiedID())
+	}
+	expected := []string{"ptt:SYSOP", "ptt:Test", "ptt2:SYSOP"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("board ids expected: %v, got: %v", expected, ids)
+	}
+
+	u, err := m.GetUserRecord("ptt2:pichu")
+	if err != nil {
+		t.Fatalf("GetUserRecord error: %v", err)
+	}
+	if u.QualifiedID() != "ptt2:pichu" {
+		t.Errorf("user qualified id expected: ptt2:pichu, got: %v", u.QualifiedID())
+	}
+
+	if _, err := m.ReadBoardArticleRecordsFile("SYSOP"); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("unqualified id should return ErrInvalidName, got: %v", err)
+	}
+	if _, err := m.ReadBoardArticleRecordsFile("nobbs:SYSOP"); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("unknown instance should return ErrRecordNotFound, got: %v", err)
+	}
+
+	if _, err := OpenMulti(map[string]Connector{"a:b": &fakeConnector{}}); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("instance with separator should return ErrInvalidName, got: %v", err)
+	}
+}
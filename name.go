@@ -0,0 +1,19 @@
+package bbs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkNames returns ErrInvalidName if any of names has path separator, ".."
+// or NUL. It is used by DB methods before passing filenames
+// from caller to connector, so that every driver is protected from path
+// traversal such as "../../.PASSWDS".
+func checkNames(names ...string) error {
+	for _, name := range names {
+		if strings.ContainsAny(name, "/\\\x00") || strings.Contains(name, "..") {
+			return fmt.Errorf("%w: %q", ErrInvalidName, name)
+		}
+	}
+	return nil
+}
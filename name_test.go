@@ -0,0 +1,46 @@
+package bbs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckNames(t *testing.T) {
+	valid := []string{"M.1609459200.A.123", ".DIR", "D690", "0"}
+	for _, name := range valid {
+		if err := checkNames(name); err != nil {
+			t.Errorf("checkNames(%q) error: %v", name, err)
+		}
+	}
+
+	payloads := []string{
+		"../../.PASSWDS",
+		"..",
+		"M.123.A/../../.PASSWDS",
+		"/etc/passwd",
+		"..\\..\\.PASSWDS",
+		"M.123.A\x00.txt",
+	}
+	for _, name := range payloads {
+		if err := checkNames(name); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("checkNames(%q) should return ErrInvalidName, got: %v", name, err)
+		}
+	}
+}
+
+func TestReadFileRejectsTraversal(t *testing.T) {
+	db := &DB{connector: &fakeMailConnector{}}
+
+	if _, err := db.ReadBoardArticleFile("SYSOP", "../../.PASSWDS"); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("ReadBoardArticleFile should return ErrInvalidName, got: %v", err)
+	}
+	if _, err := db.ReadBoardTreasureFile("SYSOP", []string{"D690", ".."}, "M.123.A"); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("ReadBoardTreasureFile should return ErrInvalidName, got: %v", err)
+	}
+	if _, err := db.ReadUserMailFile("SYSOP", "../pichu/M.123.A"); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("ReadUserMailFile should return ErrInvalidName, got: %v", err)
+	}
+	if _, err := db.GetUserDrafts("SYSOP", "../../.PASSWDS"); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("GetUserDrafts should return ErrInvalidName, got: %v", err)
+	}
+}
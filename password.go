@@ -0,0 +1,148 @@
+package bbs
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log"
+)
+
+// PasswordScheme hashes and verifies user passwords, such as DES crypt of
+// Maple or PBKDF2. Implementations are in package crypt.
+type PasswordScheme interface {
+	// Hash returns the hash of plain password with salt.
+	Hash(plain, salt string) (string, error)
+	// Verify returns nil if plain password matches hashed.
+	Verify(plain, hashed string) error
+}
+
+// Driver which implement PasswordSchemeConnector supports hashing new user
+// passwords with a scheme other than its default one.
+type PasswordSchemeConnector interface {
+	// SetPasswordScheme sets the scheme of hashing new user passwords.
+	SetPasswordScheme(s PasswordScheme)
+}
+
+// Driver which implement PasswordConnector supports rewriting the password
+// hash of users.
+type PasswordConnector interface {
+
+	// UpdateUserRecordFilePassword calls update with the password hash of
+	// userID in record file name, and writes the returned hash back. The
+	// record should be locked from reading to writing, so concurrent
+	// password changes are not lost. Nothing is written if update returns
+	// error, which is returned as is. It should return error wrapping
+	// ErrNotSupported without writing if the new hash does not fit in the
+	// password field.
+	UpdateUserRecordFilePassword(name string, userID string, update func(hashed string) (string, error)) error
+}
+
+// OpenOption configures DB in Open and OpenConnector.
+type OpenOption func(db *DB) error
+
+// WithPasswordScheme returns OpenOption which overrides the password scheme
+// of driver, see SetPasswordScheme.
+func WithPasswordScheme(s PasswordScheme) OpenOption {
+	return func(db *DB) error {
+		db.SetPasswordScheme(s)
+		return nil
+	}
+}
+
+// SetPasswordScheme sets the scheme used by VerifyUserPassword, and by
+// CreateUser if connector implements PasswordSchemeConnector. nil resets
+// to the default scheme of driver, which is DES crypt for Maple.
+func (db *DB) SetPasswordScheme(s PasswordScheme) {
+	db.passwordScheme = s
+	var pc PasswordSchemeConnector
+	if db.connectorAs(&pc) {
+		pc.SetPasswordScheme(s)
+	}
+}
+
+// VerifyUserPassword returns nil if plain password matches the password of
+// u. It uses the password scheme set by SetPasswordScheme, and falls back to
+// u.VerifyPassword.
+func (db *DB) VerifyUserPassword(u UserRecord, plain string) error {
+	if db.passwordScheme == nil {
+		return u.VerifyPassword(plain)
+	}
+	return db.passwordScheme.Verify(plain, u.HashedPassword())
+}
+
+// saltChars is the alphabet of salts generated by UpgradePasswordOnVerify,
+// which is safe for DES crypt and PBKDF2.
+const saltChars = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// newSalt returns a random salt of n characters of saltChars.
+func newSalt(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = saltChars[int(b)%len(saltChars)]
+	}
+	return string(buf), nil
+}
+
+// UpgradePasswordOnVerify verifies plaintext password of userID with the
+// scheme set by SetPasswordScheme, and on success rewrites the password hash
+// of user with newScheme, so passwords are migrated to newScheme as users
+// log in. The scheme of DB should verify both old and new hashes, such as
+// crypt.Mixed, or upgraded users could not log in, so it returns
+// ErrPasswordScheme without writing if no scheme is set or it does not
+// verify the new hash.
+//
+// The hash is verified and rewritten under the record lock of driver.
+// upgraded is false if the hash already matches newScheme, and the error of
+// verification is returned with upgraded false if the password is wrong. It
+// returns ErrNotSupported if connector does not implement PasswordConnector
+// or the new hash does not fit in the record of driver, such as the 13
+// bytes DES field of pttbbs.
+func (db *DB) UpgradePasswordOnVerify(userID, plaintext string, newScheme PasswordScheme) (upgraded bool, err error) {
+	var pc PasswordConnector
+	if !db.connectorAs(&pc) {
+		return false, ErrNotSupported
+	}
+	scheme := db.passwordScheme
+	if scheme == nil {
+		return false, fmt.Errorf("%w: no password scheme is set", ErrPasswordScheme)
+	}
+
+	path, err := db.userRecordsPath()
+	if err != nil {
+		log.Println("bbs: GetUserRecordsPath error:", err)
+		return false, err
+	}
+	err = pc.UpdateUserRecordFilePassword(path, userID, func(hashed string) (string, error) {
+		if newScheme.Verify(plaintext, hashed) == nil {
+			return hashed, nil
+		}
+		if err := scheme.Verify(plaintext, hashed); err != nil {
+			return "", err
+		}
+
+		salt, err := newSalt(16)
+		if err != nil {
+			return "", err
+		}
+		newHashed, err := newScheme.Hash(plaintext, salt)
+		if err != nil {
+			return "", fmt.Errorf("bbs: hash password error: %w", err)
+		}
+		if err := scheme.Verify(plaintext, newHashed); err != nil {
+			return "", fmt.Errorf("%w: %v", ErrPasswordScheme, err)
+		}
+		upgraded = true
+		return newHashed, nil
+	})
+	if err != nil {
+		log.Println("bbs: UpdateUserRecordFilePassword error:", err)
+		return false, err
+	}
+	if upgraded {
+		db.invalidateCache(userRecordsCacheKey(path))
+		db.countWrite(userRecordKind)
+	}
+	return upgraded, nil
+}
@@ -0,0 +1,122 @@
+package bbs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Ptt-official-app/go-bbs/crypt"
+)
+
+type fakePasswordSchemeConnector struct {
+	fakeConnector
+	scheme PasswordScheme
+}
+
+func (c *fakePasswordSchemeConnector) SetPasswordScheme(s PasswordScheme) {
+	c.scheme = s
+}
+
+func TestWithPasswordScheme(t *testing.T) {
+	c := &fakePasswordSchemeConnector{}
+	c.fakeOpen = func() error { return nil }
+
+	scheme := crypt.PBKDF2SHA256{Iterations: 10}
+	db, err := OpenConnector(c, "", WithPasswordScheme(scheme))
+	if err != nil {
+		t.Fatalf("OpenConnector error: %v", err)
+	}
+	if c.scheme != scheme {
+		t.Errorf("password scheme should be set to connector, got: %v", c.scheme)
+	}
+
+	hashed, err := scheme.Hash("123456", "salt")
+	if err != nil {
+		t.Fatalf("Hash error: %v", err)
+	}
+	u := &fakeUserRecord{userID: "pichu", password: hashed}
+	if err := db.VerifyUserPassword(u, "123456"); err != nil {
+		t.Errorf("VerifyUserPassword error: %v", err)
+	}
+	if err := db.VerifyUserPassword(u, "654321"); !errors.Is(err, crypt.ErrPasswordMismatch) {
+		t.Errorf("VerifyUserPassword with wrong password should return ErrPasswordMismatch, got: %v", err)
+	}
+
+	des := &fakeUserRecord{userID: "SYSOP", password: "AA3QBhLWk1BWA"}
+	db.SetPasswordScheme(crypt.DES{})
+	if err := db.VerifyUserPassword(des, "012345678901"); err != nil {
+		t.Errorf("VerifyUserPassword of DES hash error: %v", err)
+	}
+}
+
+type fakePasswordConnector struct {
+	fakeConnector
+	users []UserRecord
+}
+
+func (c *fakePasswordConnector) UpdateUserRecordFilePassword(name string, userID string, update func(string) (string, error)) error {
+	for _, u := range c.users {
+		if strings.EqualFold(u.UserID(), userID) {
+			hashed, err := update(u.HashedPassword())
+			if err != nil {
+				return err
+			}
+			u.(*fakeUserRecord).password = hashed
+			return nil
+		}
+	}
+	return ErrRecordNotFound
+}
+
+func TestUpgradePasswordOnVerify(t *testing.T) {
+	old, err := crypt.DES{}.Hash("123456", "ab")
+	if err != nil {
+		t.Fatalf("Hash error: %v", err)
+	}
+	c := &fakePasswordConnector{users: []UserRecord{&fakeUserRecord{userID: "pichu", password: old}}}
+	c.fakeGetUserRecordsPath = func() (string, error) { return ".PASSWDS", nil }
+	c.fakeReadUserRecordsFile = func() ([]UserRecord, error) { return c.users, nil }
+	db := &DB{connector: c}
+	newScheme := crypt.PBKDF2SHA256{Iterations: 1}
+	if _, err := db.UpgradePasswordOnVerify("pichu", "123456", newScheme); !errors.Is(err, ErrPasswordScheme) {
+		t.Errorf("UpgradePasswordOnVerify without scheme should return ErrPasswordScheme, got: %v", err)
+	}
+	db.SetPasswordScheme(crypt.DES{})
+	if _, err := db.UpgradePasswordOnVerify("pichu", "123456", newScheme); !errors.Is(err, ErrPasswordScheme) {
+		t.Errorf("UpgradePasswordOnVerify with DES scheme should return ErrPasswordScheme, got: %v", err)
+	}
+	if c.users[0].HashedPassword() != old {
+		t.Errorf("hash should not be changed if DB can not verify new hash")
+	}
+	db.SetPasswordScheme(crypt.Mixed{PBKDF2SHA256: newScheme})
+
+	upgraded, err := db.UpgradePasswordOnVerify("pichu", "654321", newScheme)
+	if upgraded || !errors.Is(err, crypt.ErrPasswordMismatch) {
+		t.Errorf("wrong password expected: false, ErrPasswordMismatch, got: %v, %v", upgraded, err)
+	}
+	if c.users[0].HashedPassword() != old {
+		t.Errorf("hash should not be changed on wrong password")
+	}
+
+	upgraded, err = db.UpgradePasswordOnVerify("Pichu", "123456", newScheme)
+	if !upgraded || err != nil {
+		t.Fatalf("UpgradePasswordOnVerify expected: true, <nil>, got: %v, %v", upgraded, err)
+	}
+	if err := newScheme.Verify("123456", c.users[0].HashedPassword()); err != nil {
+		t.Errorf("hash should be upgraded to new scheme, got: %q", c.users[0].HashedPassword())
+	}
+
+	if err := db.VerifyUserPassword(c.users[0], "123456"); err != nil {
+		t.Errorf("VerifyUserPassword of upgraded hash error: %v", err)
+	}
+
+	upgraded, err = db.UpgradePasswordOnVerify("pichu", "123456", newScheme)
+	if upgraded || err != nil {
+		t.Errorf("upgraded hash expected: false, <nil>, got: %v, %v", upgraded, err)
+	}
+
+	db = &DB{connector: &fakeConnector{}}
+	if _, err := db.UpgradePasswordOnVerify("pichu", "123456", newScheme); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("UpgradePasswordOnVerify should return ErrNotSupported, got: %v", err)
+	}
+}
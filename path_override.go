@@ -0,0 +1,201 @@
+package bbs
+
+import "strings"
+
+// Kinds of path passed to the function set by SetPathOverride, id of each
+// kind is noted, ids of multiple parts are joined by "/".
+const (
+	PathKindUserRecords          = "user_records"           // ""
+	PathKindBoardRecords         = "board_records"          // ""
+	PathKindUserFavoriteRecords  = "user_favorite_records"  // userID
+	PathKindBoardArticleRecords  = "board_article_records"  // boardID
+	PathKindBoardTreasureRecords = "board_treasure_records" // boardID/treasureID...
+	PathKindBoardArticleFile     = "board_article_file"     // boardID/filename
+	PathKindBoardTreasureFile    = "board_treasure_file"    // boardID/treasureID.../filename
+	PathKindBoardFile            = "board_file"             // boardID/relPath
+	PathKindBoardInfo            = "board_info"             // boardID
+	PathKindUserArticleRecords   = "user_article_records"   // userID
+	PathKindUserCommentRecords   = "user_comment_records"   // userID
+	PathKindUserDraft            = "user_draft"             // userID/draftID
+	PathKindUserMailbox          = "user_mailbox"           // userID
+	PathKindUserMailFile         = "user_mail_file"         // userID/filename
+	PathKindUserBMIndex          = "user_bm_index"          // userID
+	PathKindUserPlan             = "user_plan"              // userID
+	PathKindUserFriends          = "user_friends"           // userID
+	PathKindUserRejects          = "user_rejects"           // userID
+)
+
+// SetPathOverride sets fn to be consulted before the Get*Path methods of
+// connector, for deployments whose directory layout differs from the one of
+// driver, such as symlinked boards or sharded homes. kind is one of the
+// PathKind constants, if fn returns ok, the returned path is used instead of
+// the path of connector. Passing nil removes the override.
+func (db *DB) SetPathOverride(fn func(kind, id string) (string, bool)) {
+	db.pathOverride = fn
+}
+
+// overridePath returns the overridden path of kind and id parts, ok is
+// false if there is no override.
+func (db *DB) overridePath(kind string, id ...string) (string, bool) {
+	if db.pathOverride == nil {
+		return "", false
+	}
+	parts := []string{}
+	for _, p := range id {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return db.pathOverride(kind, strings.Join(parts, "/"))
+}
+
+func (db *DB) userRecordsPath() (string, error) {
+	if p, ok := db.overridePath(PathKindUserRecords); ok {
+		return p, nil
+	}
+	return db.connector.GetUserRecordsPath()
+}
+
+func (db *DB) boardRecordsPath() (string, error) {
+	if p, ok := db.overridePath(PathKindBoardRecords); ok {
+		return p, nil
+	}
+	return db.connector.GetBoardRecordsPath()
+}
+
+func (db *DB) userFavoriteRecordsPath(userID string) (string, error) {
+	if p, ok := db.overridePath(PathKindUserFavoriteRecords, userID); ok {
+		return p, nil
+	}
+	return db.connector.GetUserFavoriteRecordsPath(userID)
+}
+
+func (db *DB) boardArticleRecordsPath(boardID string) (string, error) {
+	boardID, err := db.normalizeBoardID(boardID)
+	if err != nil {
+		return "", err
+	}
+	if p, ok := db.overridePath(PathKindBoardArticleRecords, boardID); ok {
+		return p, nil
+	}
+	return db.connector.GetBoardArticleRecordsPath(boardID)
+}
+
+func (db *DB) boardTreasureRecordsPath(boardID string, treasureID []string) (string, error) {
+	boardID, err := db.normalizeBoardID(boardID)
+	if err != nil {
+		return "", err
+	}
+	if p, ok := db.overridePath(PathKindBoardTreasureRecords, append([]string{boardID}, treasureID...)...); ok {
+		return p, nil
+	}
+	return db.connector.GetBoardTreasureRecordsPath(boardID, treasureID)
+}
+
+func (db *DB) boardArticleFilePath(boardID string, filename string) (string, error) {
+	boardID, err := db.normalizeBoardID(boardID)
+	if err != nil {
+		return "", err
+	}
+	if p, ok := db.overridePath(PathKindBoardArticleFile, boardID, filename); ok {
+		return p, nil
+	}
+	return db.connector.GetBoardArticleFilePath(boardID, filename)
+}
+
+func (db *DB) boardTreasureFilePath(boardID string, treasureID []string, name string) (string, error) {
+	boardID, err := db.normalizeBoardID(boardID)
+	if err != nil {
+		return "", err
+	}
+	id := append(append([]string{boardID}, treasureID...), name)
+	if p, ok := db.overridePath(PathKindBoardTreasureFile, id...); ok {
+		return p, nil
+	}
+	return db.connector.GetBoardTreasureFilePath(boardID, treasureID, name)
+}
+
+func (db *DB) boardFilePath(bfc BoardFileConnector, boardID string, relPath string) (string, error) {
+	boardID, err := db.normalizeBoardID(boardID)
+	if err != nil {
+		return "", err
+	}
+	if p, ok := db.overridePath(PathKindBoardFile, boardID, relPath); ok {
+		return p, nil
+	}
+	return bfc.GetBoardFilePath(boardID, relPath)
+}
+
+func (db *DB) userArticleRecordsPath(uac UserArticleConnector, userID string) (string, error) {
+	if p, ok := db.overridePath(PathKindUserArticleRecords, userID); ok {
+		return p, nil
+	}
+	return uac.GetUserArticleRecordsPath(userID)
+}
+
+func (db *DB) userCommentRecordsPath(ucc UserCommentConnector, userID string) (string, error) {
+	if p, ok := db.overridePath(PathKindUserCommentRecords, userID); ok {
+		return p, nil
+	}
+	return ucc.GetUserCommentRecordsPath(userID)
+}
+
+func (db *DB) userDraftPath(udc UserDraftConnector, userID, draftID string) (string, error) {
+	if p, ok := db.overridePath(PathKindUserDraft, userID, draftID); ok {
+		return p, nil
+	}
+	return udc.GetUserDraftPath(userID, draftID)
+}
+
+func (db *DB) userMailboxPath(mc MailConnector, userID string) (string, error) {
+	if p, ok := db.overridePath(PathKindUserMailbox, userID); ok {
+		return p, nil
+	}
+	return mc.GetUserMailboxPath(userID)
+}
+
+func (db *DB) userMailFilePath(mc MailConnector, userID string, filename string) (string, error) {
+	if p, ok := db.overridePath(PathKindUserMailFile, userID, filename); ok {
+		return p, nil
+	}
+	return mc.GetUserMailFilePath(userID, filename)
+}
+
+func (db *DB) userBMIndexPath(bc BMIndexConnector, userID string) (string, error) {
+	if p, ok := db.overridePath(PathKindUserBMIndex, userID); ok {
+		return p, nil
+	}
+	return bc.GetUserBMIndexPath(userID)
+}
+
+func (db *DB) userPlanPath(pc PlanConnector, userID string) (string, error) {
+	if p, ok := db.overridePath(PathKindUserPlan, userID); ok {
+		return p, nil
+	}
+	return pc.GetUserPlanPath(userID)
+}
+
+func (db *DB) boardInfoPath(bic BoardInfoConnector, boardID string) (string, error) {
+	boardID, err := db.normalizeBoardID(boardID)
+	if err != nil {
+		return "", err
+	}
+	if p, ok := db.overridePath(PathKindBoardInfo, boardID); ok {
+		return p, nil
+	}
+	return bic.GetBoardInfoPath(boardID)
+}
+
+func (db *DB) userFriendsPath(rc RelationConnector, userID string) (string, error) {
+	if p, ok := db.overridePath(PathKindUserFriends, userID); ok {
+		return p, nil
+	}
+	return rc.GetUserFriendsPath(userID)
+}
+
+func (db *DB) userRejectsPath(rc RelationConnector, userID string) (string, error) {
+	if p, ok := db.overridePath(PathKindUserRejects, userID); ok {
+		return p, nil
+	}
+	return rc.GetUserRejectsPath(userID)
+}
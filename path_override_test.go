@@ -0,0 +1,75 @@
+package bbs
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSetPathOverride(t *testing.T) {
+	fsys := fstest.MapFS{
+		".BRD":                   &fstest.MapFile{},
+		"shard/0/.PASSWDS":       &fstest.MapFile{},
+		"boards/SYSOP/M.1.A.001": &fstest.MapFile{Data: []byte("default")},
+		"links/sysop/M.1.A.001":  &fstest.MapFile{Data: []byte("overridden")},
+		"boards/Test/M.2.A.002":  &fstest.MapFile{Data: []byte("default")},
+	}
+	c := &fakeUserArticleFileConnector{fakeUserArticleConnector: &fakeUserArticleConnector{}}
+	c.fakeGetUserRecordsPath = func() (string, error) { return ".PASSWDS", nil }
+	c.fakeGetBoardRecordsPath = func() (string, error) { return ".BRD", nil }
+	c.fakeGetBoardTreasureRecordsPath = func() (string, error) { return "boards/SYSOP/man/.DIR", nil }
+	c.fakeReadArticleRecordsFile = func() ([]ArticleRecord, error) { return []ArticleRecord{}, nil }
+	db := &DB{connector: c, fsys: fsys}
+
+	if err := db.Ping(); err == nil {
+		t.Fatalf("Ping should return error without override")
+	}
+
+	type call struct{ kind, id string }
+	calls := []call{}
+	db.SetPathOverride(func(kind, id string) (string, bool) {
+		calls = append(calls, call{kind, id})
+		switch {
+		case kind == PathKindUserRecords:
+			return "shard/0/.PASSWDS", true
+		case kind == PathKindBoardArticleFile && id == "SYSOP/M.1.A.001":
+			return "links/sysop/M.1.A.001", true
+		}
+		return "", false
+	})
+
+	if err := db.Ping(); err != nil {
+		t.Errorf("Ping with overridden user records path error: %v", err)
+	}
+
+	for boardID, expected := range map[string]string{"SYSOP": "overridden", "Test": "default"} {
+		filename := "M.1.A.001"
+		if boardID == "Test" {
+			filename = "M.2.A.002"
+		}
+		rc, err := db.OpenBoardArticleFile(boardID, filename)
+		if err != nil {
+			t.Fatalf("OpenBoardArticleFile(%v) error: %v", boardID, err)
+		}
+		buf, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		if string(buf) != expected {
+			t.Errorf("OpenBoardArticleFile(%v) expected: %v, got: %s", boardID, expected, buf)
+		}
+	}
+
+	calls = calls[:0]
+	if _, err := db.ReadBoardTreasureRecordsFile("SYSOP", []string{"D1", "D2"}); err != nil {
+		t.Fatalf("ReadBoardTreasureRecordsFile error: %v", err)
+	}
+	expected := []call{{PathKindBoardTreasureRecords, "SYSOP/D1/D2"}}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("override calls expected: %v, got: %v", expected, calls)
+	}
+
+	db.SetPathOverride(nil)
+	if err := db.Ping(); err == nil {
+		t.Errorf("Ping should return error after override is removed")
+	}
+}
@@ -0,0 +1,49 @@
+package bbs
+
+import (
+	"fmt"
+	"log"
+)
+
+// Ping verifies the critical files of bbs, such as user records file
+// (.PASSWDS) and board records file (.BRD), exist and are readable.
+// Call it after Open to find out misconfigured BBSHome early.
+func (db *DB) Ping() error {
+	getPaths := []struct {
+		name    string
+		getPath func() (string, error)
+	}{
+		{"user records", db.userRecordsPath},
+		{"board records", db.boardRecordsPath},
+	}
+
+	for _, p := range getPaths {
+		path, err := p.getPath()
+		if err != nil {
+			log.Printf("bbs: get %v path error: %v", p.name, err)
+			return fmt.Errorf("bbs: get %v path error: %w", p.name, err)
+		}
+		if err := db.checkReadable(path); err != nil {
+			log.Printf("bbs: ping %v error: %v", p.name, err)
+			return fmt.Errorf("bbs: ping %v %v error: %w", p.name, path, err)
+		}
+	}
+	return nil
+}
+
+func (db *DB) checkReadable(path string) error {
+	f, err := db.openFile(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if stat.IsDir() {
+		return fmt.Errorf("%v is a directory", path)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package bbs
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ping_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	passwds := filepath.Join(dir, ".PASSWDS")
+	brd := filepath.Join(dir, ".BRD")
+	if err := ioutil.WriteFile(passwds, []byte{}, 0644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+
+	db := &DB{
+		connector: &fakeConnector{
+			fakeGetUserRecordsPath:  func() (string, error) { return passwds, nil },
+			fakeGetBoardRecordsPath: func() (string, error) { return brd, nil },
+		},
+	}
+
+	err = db.Ping()
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Ping should return not exist error, got: %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), brd) {
+		t.Errorf("Ping error should contain the failed path %v, got: %v", brd, err)
+	}
+
+	if err := ioutil.WriteFile(brd, []byte{}, 0644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+	if err := db.Ping(); err != nil {
+		t.Errorf("Ping error: %v", err)
+	}
+}
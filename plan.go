@@ -0,0 +1,56 @@
+package bbs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"log"
+)
+
+// PlanConnector is a connector for bbs which supports user plan (名片檔), the
+// file shown on user's profile when queried.
+type PlanConnector interface {
+
+	// GetUserPlanPath should return the plan file path of user,
+	// eg: BBSHome/home/{{u}}/{{userID}}/plans
+	GetUserPlanPath(userID string) (string, error)
+}
+
+// ReadUserPlan returns raw plan file of userID, it is not decoded from Big5
+// as article files. It returns an empty slice if user has no plan file.
+func (db *DB) ReadUserPlan(userID string) ([]byte, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("%w: empty user id", ErrInvalidName)
+	}
+	if err := checkNames(userID); err != nil {
+		return nil, err
+	}
+	var pc PlanConnector
+	if !db.connectorAs(&pc) {
+		return nil, ErrNotSupported
+	}
+
+	path, err := db.userPlanPath(pc, userID)
+	if err != nil {
+		log.Println("bbs: GetUserPlanPath error:", err)
+		return nil, err
+	}
+
+	f, err := db.openFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []byte{}, nil
+	}
+	if err != nil {
+		log.Println("bbs: open plan file error:", err)
+		return nil, err
+	}
+	defer f.Close()
+
+	buf, err := ioutil.ReadAll(f)
+	if err != nil {
+		log.Println("bbs: read plan file error:", err)
+		return nil, err
+	}
+	return buf, nil
+}
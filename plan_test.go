@@ -0,0 +1,52 @@
+package bbs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+type fakePlanConnector struct {
+	fakeConnector
+}
+
+var _ PlanConnector = &fakePlanConnector{}
+
+func (c *fakePlanConnector) GetUserPlanPath(userID string) (string, error) {
+	return "home/" + userID + "/plans", nil
+}
+
+func TestReadUserPlan(t *testing.T) {
+	plan := Utf8ToBig5("我的名片檔\n")
+	db := &DB{
+		connector: &fakePlanConnector{},
+		fsys: fstest.MapFS{
+			"home/pichu/plans": &fstest.MapFile{Data: plan},
+		},
+	}
+
+	actual, err := db.ReadUserPlan("pichu")
+	if err != nil {
+		t.Fatalf("ReadUserPlan error: %v", err)
+	}
+	if !bytes.Equal(actual, plan) {
+		t.Errorf("plan expected: %q, got: %q", plan, actual)
+	}
+
+	actual, err = db.ReadUserPlan("SYSOP")
+	if err != nil || actual == nil || len(actual) != 0 {
+		t.Errorf("ReadUserPlan without plan file expected: [], <nil>, got: %q, %v", actual, err)
+	}
+
+	for _, userID := range []string{"", "../pichu", "a/b"} {
+		if _, err := db.ReadUserPlan(userID); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("ReadUserPlan(%q) should return ErrInvalidName, got: %v", userID, err)
+		}
+	}
+
+	db = &DB{connector: &fakeConnector{}}
+	if _, err := db.ReadUserPlan("pichu"); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("ReadUserPlan should return ErrNotSupported, got: %v", err)
+	}
+}
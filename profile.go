@@ -0,0 +1,81 @@
+package bbs
+
+import "fmt"
+
+// Gender is the gender shown in user profile.
+type Gender int
+
+const (
+	// GenderUnknown is for users whose driver does not record gender.
+	GenderUnknown Gender = iota
+	GenderMale
+	GenderFemale
+	// GenderOther is for the joke genders of Maple, such as 植物 and 礦物.
+	GenderOther
+)
+
+func (g Gender) String() string {
+	switch g {
+	case GenderUnknown:
+		return "unknown"
+	case GenderMale:
+		return "male"
+	case GenderFemale:
+		return "female"
+	case GenderOther:
+		return "other"
+	}
+	return fmt.Sprintf("Gender(%d)", int(g))
+}
+
+// Marital status of user profile, values other than these are defined by
+// the fork which records them.
+const (
+	// MaritalStatusUnknown is for users whose driver does not record
+	// marital status.
+	MaritalStatusUnknown = -1
+	MaritalStatusSingle  = 0
+	MaritalStatusMarried = 1
+)
+
+// ProfileUserRecord return UserRecord interface which support gender and
+// marital status of user profile.
+type ProfileUserRecord interface {
+	Gender() Gender
+	MaritalStatus() int
+}
+
+// MapleGender decodes the sex byte of Maple and early pttbbs userec, which
+// is an index of 美男子, 美少女, 底迪, 美眉, 葛格, 姐接, 植物 and 礦物: even
+// values below 6 are male, odd values below 6 are female, 6 and 7 are
+// other, values beyond are unknown. Current pttbbs reuses the byte as
+// over_18 and records no gender.
+func MapleGender(sex byte) Gender {
+	switch {
+	case sex < 6 && sex%2 == 0:
+		return GenderMale
+	case sex < 6:
+		return GenderFemale
+	case sex < 8:
+		return GenderOther
+	}
+	return GenderUnknown
+}
+
+// UserGender returns the gender of u, or GenderUnknown if u does not
+// implement ProfileUserRecord.
+func UserGender(u UserRecord) Gender {
+	if pu, ok := u.(ProfileUserRecord); ok {
+		return pu.Gender()
+	}
+	return GenderUnknown
+}
+
+// UserMaritalStatus returns the marital status of u, or
+// MaritalStatusUnknown if u does not implement ProfileUserRecord.
+func UserMaritalStatus(u UserRecord) int {
+	if pu, ok := u.(ProfileUserRecord); ok {
+		return pu.MaritalStatus()
+	}
+	return MaritalStatusUnknown
+}
@@ -0,0 +1,26 @@
+package bbs
+
+import "testing"
+
+func TestMapleGender(t *testing.T) {
+	expected := []Gender{
+		GenderMale, GenderFemale, GenderMale, GenderFemale,
+		GenderMale, GenderFemale, GenderOther, GenderOther,
+		GenderUnknown,
+	}
+	for sex, g := range expected {
+		if actual := MapleGender(byte(sex)); actual != g {
+			t.Errorf("MapleGender(%d) expected: %v, got: %v", sex, g, actual)
+		}
+	}
+	if actual := MapleGender(0xff); actual != GenderUnknown {
+		t.Errorf("MapleGender(0xff) expected: unknown, got: %v", actual)
+	}
+}
+
+func TestUserGender(t *testing.T) {
+	u := &fakeUserRecord{userID: "SYSOP"}
+	if g, m := UserGender(u), UserMaritalStatus(u); g != GenderUnknown || m != MaritalStatusUnknown {
+		t.Errorf("profile of unsupported record expected: unknown, -1, got: %v, %v", g, m)
+	}
+}
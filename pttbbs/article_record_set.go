@@ -0,0 +1,100 @@
+package pttbbs
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Ptt-official-app/go-bbs"
+	"github.com/Ptt-official-app/go-bbs/filelock"
+)
+
+// fileHeaderSet is bbs.ArticleRecordSet of a memory-mapped .DIR file, file
+// headers are decoded on access. The file is kept open with a shared lock
+// until Close.
+type fileHeaderSet struct {
+	mutex sync.RWMutex
+	f     *os.File
+	data  []byte
+	n     int
+
+	// loc is the location of file headers, nil means Location.
+	loc *time.Location
+}
+
+// OpenArticleRecordSet maps .DIR file name privately and returns the file
+// headers in it, a trailing partial record is ignored. The file is share
+// locked until the set is closed, so writers which lock the file, such as
+// AddArticleRecordFileRecord, wait for Close. Truncating the mapped file
+// without the lock may crash the process with SIGBUS when a record is
+// accessed, so the set should be closed soon after use. It returns
+// bbs.ErrNotSupported if connector is opened with OpenFS or mmap is not
+// available on the platform.
+func (c *Connector) OpenArticleRecordSet(name string) (bbs.ArticleRecordSet, error) {
+	if c.fsys != nil || !mmapSupported {
+		return nil, bbs.ErrNotSupported
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := filelock.RLock(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	// the size is read under the lock, so the mapping covers whole records.
+	stat, err := f.Stat()
+	n := 0
+	if err == nil {
+		n = int(stat.Size() / FileHeaderRecordLength)
+	}
+	var data []byte
+	if err == nil && n > 0 {
+		data, err = mmapFile(f, n*FileHeaderRecordLength)
+	}
+	if err != nil || n == 0 {
+		filelock.Unlock(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		return &fileHeaderSet{}, nil
+	}
+	return &fileHeaderSet{f: f, data: data, n: n, loc: c.location}, nil
+}
+
+func (s *fileHeaderSet) Len() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.n
+}
+
+func (s *fileHeaderSet) At(i int) bbs.ArticleRecord {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if i < 0 || i >= s.n {
+		panic("pttbbs: article record index out of range")
+	}
+	h, _ := NewFileHeaderWithByte(s.data[i*FileHeaderRecordLength : (i+1)*FileHeaderRecordLength])
+	h.loc = s.loc
+	return h
+}
+
+// Close unmaps and unlocks the file, it is safe to call more than once.
+func (s *fileHeaderSet) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.data == nil {
+		return nil
+	}
+	err := munmapFile(s.data)
+	filelock.Unlock(s.f)
+	if cerr := s.f.Close(); err == nil {
+		err = cerr
+	}
+	s.f, s.data, s.n = nil, nil, 0
+	return err
+}
+
+var _ bbs.MmapConnector = &Connector{}
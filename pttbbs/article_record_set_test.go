@@ -0,0 +1,74 @@
+package pttbbs
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/Ptt-official-app/go-bbs/filelock"
+)
+
+func TestOpenArticleRecordSet(t *testing.T) {
+	expected, err := OpenFileHeaderFile("testcase/file/01.DIR")
+	if err != nil {
+		t.Fatalf("OpenFileHeaderFile error: %v", err)
+	}
+
+	c := &Connector{}
+	set, err := c.OpenArticleRecordSet("testcase/file/01.DIR")
+	if err != nil {
+		t.Fatalf("OpenArticleRecordSet error: %v", err)
+	}
+	if set.Len() != len(expected) {
+		t.Fatalf("Len expected: %v, got: %v", len(expected), set.Len())
+	}
+	for i, h := range expected {
+		if actual := set.At(i); !reflect.DeepEqual(actual, h) {
+			t.Errorf("record %d expected: %+v, got: %+v", i, h, actual)
+		}
+	}
+	// writers wait for the shared lock of set.
+	f, err := os.OpenFile("testcase/file/01.DIR", os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatalf("open file error: %v", err)
+	}
+	defer f.Close()
+	if err := filelock.LockTimeout(f, 10*time.Millisecond); !errors.Is(err, filelock.ErrTimeout) {
+		t.Errorf("exclusive lock should wait for open set, got: %v", err)
+	}
+	if err := set.Close(); err != nil {
+		t.Errorf("Close error: %v", err)
+	}
+	if err := filelock.LockTimeout(f, 10*time.Millisecond); err != nil {
+		t.Errorf("exclusive lock after Close error: %v", err)
+	}
+	filelock.Unlock(f)
+	if err := set.Close(); err != nil || set.Len() != 0 {
+		t.Errorf("second Close expected: 0, <nil>, got: %v, %v", set.Len(), err)
+	}
+
+	dir := t.TempDir()
+	empty := filepath.Join(dir, ".DIR")
+	if err := ioutil.WriteFile(empty, nil, 0644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+	if set, err := c.OpenArticleRecordSet(empty); err != nil || set.Len() != 0 || set.Close() != nil {
+		t.Errorf("OpenArticleRecordSet of empty file expected: 0, <nil>, got: %v", err)
+	}
+	if _, err := c.OpenArticleRecordSet(filepath.Join(dir, "notexist")); !os.IsNotExist(err) {
+		t.Errorf("OpenArticleRecordSet should return ErrNotExist, got: %v", err)
+	}
+
+	c = &Connector{}
+	if err := c.OpenFS(fstest.MapFS{}, "."); err != nil {
+		t.Fatalf("OpenFS error: %v", err)
+	}
+	if _, err := c.OpenArticleRecordSet(".DIR"); err == nil {
+		t.Errorf("OpenArticleRecordSet should return error with OpenFS")
+	}
+}
@@ -21,9 +21,9 @@ import (
 	"io"
 	"log"
 	"os"
-	"strings"
 	"time"
 
+	"github.com/Ptt-official-app/go-bbs"
 	"github.com/Ptt-official-app/go-bbs/filelock"
 )
 
@@ -102,11 +102,49 @@ func (b *BoardHeader) IsAlignedComment() bool   { return b.Brdattr&0x04000000 !=
 func (b *BoardHeader) IsNoSelfDeletePost() bool { return b.Brdattr&0x08000000 != 0 }
 func (b *BoardHeader) IsBMMaskContent() bool    { return b.Brdattr&0x10000000 != 0 }
 
+// Attributes returns the bbs.BoardAttr mapped from Brdattr, the bit layout of
+// Brdattr in pttbbs is:
+//
+//	BRD_NOCOUNT         0x00000002  bbs.BoardAttrNoCount
+//	BRD_HIDE            0x00000010  bbs.BoardAttrHidden
+//	BRD_ANONYMOUS       0x00000040  bbs.BoardAttrAnonymous
+//	BRD_NOBOO           0x00010000  bbs.BoardAttrNoBoo
+//	BRD_RESTRICTEDPOST  0x00040000  bbs.BoardAttrRestricted
+//	BRD_OVER18          0x01000000  bbs.BoardAttrOver18
+//
+// See https://github.com/ptt/pttbbs/blob/master/include/pttstruct.h
+func (b *BoardHeader) Attributes() bbs.BoardAttr {
+	var a bbs.BoardAttr
+	if b.IsNoCount() {
+		a |= bbs.BoardAttrNoCount
+	}
+	if b.IsHide() {
+		a |= bbs.BoardAttrHidden
+	}
+	if b.IsAnonymous() {
+		a |= bbs.BoardAttrAnonymous
+	}
+	if b.IsNoBoo() {
+		a |= bbs.BoardAttrNoBoo
+	}
+	if b.IsRestrictedPost() {
+		a |= bbs.BoardAttrRestricted
+	}
+	if b.IsOver18() {
+		a |= bbs.BoardAttrOver18
+	}
+	return a
+}
+
+var _ bbs.AttributedBoardRecord = &BoardHeader{}
+
 func (b *BoardHeader) GetPostLimitPosts() uint8   { return b.PostLimitPosts }
 func (b *BoardHeader) GetPostLimitLogins() uint8  { return b.PostLimitLogins }
 func (b *BoardHeader) GetPostLimitBadPost() uint8 { return b.PostLimitBadPost }
 
-func (b *BoardHeader) BM() []string { return strings.Split(b.bm, "/") }
+// BM returns the BM user ids, BM in boardheader is separated by slash or
+// space, eg: "SYSOP/pichu".
+func (b *BoardHeader) BM() []string { return bbs.SplitBM(b.bm) }
 
 const (
 	// BoardTitleLength https://github.com/ptt/pttbbs/blob/master/include/pttstruct.h#L165
@@ -169,20 +207,30 @@ func OpenBoardHeaderFile(filename string) ([]*BoardHeader, error) {
 		log.Println(err)
 		return nil, err
 	}
+	defer file.Close()
+
+	return ReadBoardHeaders(file)
+}
 
+// ReadBoardHeaders reads all board headers from r, it reads the same format
+// as .BRD file. Decode errors are returned as *bbs.DecodeError, and so is a
+// partial record at the end of r with io.ErrUnexpectedEOF, the board headers
+// before the bad one are returned with it.
+func ReadBoardHeaders(r io.Reader) ([]*BoardHeader, error) {
 	ret := []*BoardHeader{}
 
-	for {
+	for i := 0; ; i++ {
 		hdr := make([]byte, BoardHeaderRecordLength)
-		_, err := file.Read(hdr)
-		// log.Println(len, err)
+		err := readRecord(r, hdr, i)
 		if err == io.EOF {
 			break
+		} else if err != nil {
+			return ret, err
 		}
 
 		f, err := UnmarshalBoardHeader(hdr)
 		if err != nil {
-			return nil, err
+			return ret, newDecodeError(i, BoardHeaderRecordLength, err)
 		}
 		ret = append(ret, f)
 		// log.Println(f.Filename)
@@ -194,75 +242,159 @@ func OpenBoardHeaderFile(filename string) ([]*BoardHeader, error) {
 
 func AppendBoardHeaderFileRecord(filename string, newBoardHeader *BoardHeader) error {
 	// If the file doesn't exist, create it, or append to the file
+	_, err := appendBoardHeaders(filename, []*BoardHeader{newBoardHeader}, 0)
+	return err
+}
+
+// AppendBoardHeaderFileRecords appends all newBoardHeaders to filename in a
+// single write while holding the file open and locked. It is all-or-nothing:
+// if any header can not be marshaled nothing is written, and if the write
+// fails the file is truncated back to its original size.
+func AppendBoardHeaderFileRecords(filename string, newBoardHeaders []*BoardHeader) error {
+	_, err := appendBoardHeaders(filename, newBoardHeaders, 0)
+	return err
+}
 
-	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// appendBoardHeaders appends newBoardHeaders to filename and returns the
+// index of the first appended header, which is counted under the file lock.
+func appendBoardHeaders(filename string, newBoardHeaders []*BoardHeader, lockTimeout time.Duration) (uint, error) {
+	data := make([]byte, 0, len(newBoardHeaders)*BoardHeaderRecordLength)
+	for _, h := range newBoardHeaders {
+		b, err := h.MarshalBinary()
+		if err != nil {
+			return 0, err
+		}
+		data = append(data, b...)
+	}
+	offset, err := appendRecordFile(filename, data, lockTimeout)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	defer f.Close()
+	return uint(offset / BoardHeaderRecordLength), nil
+}
+
+// MoveBoardHeaderFileRecord moves the board header at index from to index to
+// in filename, records between them are shifted by one and the number of
+// records is unchanged. The file is read and written under an exclusive lock.
+// It returns bbs.ErrRecordNotFound if from or to is out of range.
+func MoveBoardHeaderFileRecord(filename string, from, to int) error {
+	return moveBoardHeader(filename, from, to, 0)
+}
 
-	err = filelock.Lock(f)
+func moveBoardHeader(filename string, from, to int, lockTimeout time.Duration) error {
+	f, err := os.OpenFile(filename, os.O_RDWR, 0644)
 	if err != nil {
-		// File is lock
 		return err
 	}
+	defer f.Close()
 
-	data, err := newBoardHeader.MarshalBinary()
+	err = filelock.LockTimeout(f, lockTimeout)
 	if err != nil {
 		return err
 	}
+	defer filelock.Unlock(f)
 
-	if _, err := f.Write(data); err != nil {
+	data, err := io.ReadAll(f)
+	if err != nil {
 		return err
 	}
+	n := len(data) / BoardHeaderRecordLength
+	if from < 0 || from >= n || to < 0 || to >= n {
+		return fmt.Errorf("%w: move board record %d to %d of %d", bbs.ErrRecordNotFound, from, to, n)
+	}
+	if from == to {
+		return nil
+	}
 
-	filelock.Unlock(f)
-	return nil
+	lo, hi := from, to
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	span := data[lo*BoardHeaderRecordLength : (hi+1)*BoardHeaderRecordLength]
+	moved := make([]byte, 0, len(span))
+	if from < to {
+		moved = append(moved, span[BoardHeaderRecordLength:]...)
+		moved = append(moved, span[:BoardHeaderRecordLength]...)
+	} else {
+		moved = append(moved, span[len(span)-BoardHeaderRecordLength:]...)
+		moved = append(moved, span[:len(span)-BoardHeaderRecordLength]...)
+	}
+	_, err = f.WriteAt(moved, int64(lo*BoardHeaderRecordLength))
+	return err
 }
 
+// RemoveBoardHeaderFileRecord removes the board header at index in
+// filename, records after it are shifted forward by one. The file is read
+// and written under an exclusive lock. It returns bbs.ErrRecordNotFound if
+// index is out of range.
 func RemoveBoardHeaderFileRecord(filename string, index int) error {
+	return removeBoardHeader(filename, index, 0)
+}
 
-	fi, err := os.OpenFile(filename, os.O_RDONLY, 0644)
+func removeBoardHeader(filename string, index int, lockTimeout time.Duration) error {
+	f, err := os.OpenFile(filename, os.O_RDWR, 0644)
 	if err != nil {
-		return fmt.Errorf("fi.OpenFile error: %v", err)
+		return err
 	}
-	defer fi.Close()
+	defer f.Close()
 
-	err = filelock.Lock(fi)
+	err = filelock.LockTimeout(f, lockTimeout)
 	if err != nil {
-		// File is lock
 		return err
 	}
+	defer filelock.Unlock(f)
 
-	fo, err := os.OpenFile(filename, os.O_WRONLY, 0644)
+	data, err := io.ReadAll(f)
 	if err != nil {
-		return fmt.Errorf("fo.OpenFile error: %v", err)
+		return err
+	}
+	n := len(data) / BoardHeaderRecordLength
+	if index < 0 || index >= n {
+		return fmt.Errorf("%w: remove board record %d of %d", bbs.ErrRecordNotFound, index, n)
 	}
-	defer fo.Close()
 
-	_, err = fi.Seek(int64((index+1)*BoardHeaderRecordLength), os.SEEK_SET)
-	if err != nil {
-		return fmt.Errorf("fi.Seek error: %v", err)
+	rest := data[(index+1)*BoardHeaderRecordLength:]
+	if _, err := f.WriteAt(rest, int64(index*BoardHeaderRecordLength)); err != nil {
+		return err
 	}
-	_, err = fo.Seek(int64((index)*BoardHeaderRecordLength), os.SEEK_SET)
+	return f.Truncate(int64(len(data) - BoardHeaderRecordLength))
+}
+
+// UpdateBoardHeaderFileRecord overwrites the board header at index in
+// filename with b under an exclusive lock. It returns bbs.ErrRecordNotFound
+// if index is out of range.
+func UpdateBoardHeaderFileRecord(filename string, index int, b *BoardHeader) error {
+	return updateBoardHeader(filename, index, b, 0)
+}
+
+func updateBoardHeader(filename string, index int, b *BoardHeader, lockTimeout time.Duration) error {
+	data, err := b.MarshalBinary()
 	if err != nil {
-		return fmt.Errorf("fo.Seek error: %v", err)
+		return err
 	}
-	_, err = io.CopyBuffer(fo, fi, make([]byte, 256))
+
+	f, err := os.OpenFile(filename, os.O_RDWR, 0644)
 	if err != nil {
-		return fmt.Errorf("copy error: %v", err)
+		return err
 	}
-	log.Println("copy finish")
+	defer f.Close()
 
-	size, err := fo.Seek(0, os.SEEK_CUR)
+	err = filelock.LockTimeout(f, lockTimeout)
 	if err != nil {
-		return fmt.Errorf("fo.Seek for SEEK_CUR error: %v", err)
+		return err
 	}
+	defer filelock.Unlock(f)
 
-	fo.Truncate(size)
-	filelock.Unlock(fi)
-	return nil
-
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	n := int(stat.Size() / BoardHeaderRecordLength)
+	if index < 0 || index >= n {
+		return fmt.Errorf("%w: update board record %d of %d", bbs.ErrRecordNotFound, index, n)
+	}
+	_, err = f.WriteAt(data, int64(index*BoardHeaderRecordLength))
+	return err
 }
 
 func UnmarshalBoardHeader(data []byte) (*BoardHeader, error) {
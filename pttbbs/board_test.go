@@ -16,8 +16,12 @@ package pttbbs
 
 import (
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
@@ -697,3 +701,260 @@ func TestMarshalBinary(t *testing.T) {
 	}
 
 }
+
+func TestBoardHeaderAttributes(t *testing.T) {
+	b := &BoardHeader{Brdattr: BoardHide | 0x01000000 | 0x00000002}
+	a := b.Attributes()
+	expected := bbs.BoardAttrHidden | bbs.BoardAttrOver18 | bbs.BoardAttrNoCount
+	if a != expected {
+		t.Errorf("attributes not match, expected: %b, got: %b", expected, a)
+	}
+}
+
+func TestBoardHeaderBM(t *testing.T) {
+	tests := []struct {
+		bm       string
+		expected []string
+	}{
+		{"SYSOP/pichu", []string{"SYSOP", "pichu"}},
+		{"SYSOP pichu", []string{"SYSOP", "pichu"}},
+		{"", []string{}},
+	}
+	for _, c := range tests {
+		b := &BoardHeader{bm: c.bm}
+		if actual := b.BM(); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("BM of %q expected: %q, got: %q", c.bm, c.expected, actual)
+		}
+	}
+}
+
+func TestAppendBoardHeaderFileRecords(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "board_test_*")
+	if err != nil {
+		t.Fatalf("create tmp file error: %v", err)
+	}
+	filename := tmpfile.Name()
+	tmpfile.Close()
+	defer os.Remove(filename) // clean up
+
+	err = AppendBoardHeaderFileRecord(filename, &BoardHeader{BrdName: "SYSOP"})
+	if err != nil {
+		t.Fatalf("AppendBoardHeaderFileRecord error: %v", err)
+	}
+
+	brds := []*BoardHeader{
+		{BrdName: "Test"},
+		{BrdName: "Note"},
+	}
+	err = AppendBoardHeaderFileRecords(filename, brds)
+	if err != nil {
+		t.Fatalf("AppendBoardHeaderFileRecords error: %v", err)
+	}
+
+	headers, err := OpenBoardHeaderFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"SYSOP", "Test", "Note"}
+	if len(headers) != len(expected) {
+		t.Fatalf("len(headers) expected: %v, got %v", len(expected), len(headers))
+	}
+	for i, h := range headers {
+		if h.BrdName != expected[i] {
+			t.Errorf("headers[%d] BrdName not match, expected: %v, got %v", i, expected[i], h.BrdName)
+		}
+	}
+}
+
+func BenchmarkAppendBoardHeaderFileRecord(b *testing.B) {
+	benchmarkAppendBoardHeaders(b, func(filename string, hdrs []*BoardHeader) error {
+		for _, h := range hdrs {
+			if err := AppendBoardHeaderFileRecord(filename, h); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func BenchmarkAppendBoardHeaderFileRecords(b *testing.B) {
+	benchmarkAppendBoardHeaders(b, AppendBoardHeaderFileRecords)
+}
+
+func benchmarkAppendBoardHeaders(b *testing.B, appendFn func(string, []*BoardHeader) error) {
+	dir, err := ioutil.TempDir("", "board_bench_*")
+	if err != nil {
+		b.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir) // clean up
+
+	hdrs := make([]*BoardHeader, 1000)
+	for i := range hdrs {
+		hdrs[i] = &BoardHeader{BrdName: fmt.Sprintf("Board%d", i)}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		filename := filepath.Join(dir, fmt.Sprintf(".BRD.%d", i))
+		if err := appendFn(filename, hdrs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestMoveBoardHeaderFileRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "board_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, ".BRD")
+	names := []string{"A0", "A1", "A2", "A3", "A4", "A5"}
+	hdrs := make([]*BoardHeader, len(names))
+	for i, name := range names {
+		hdrs[i] = &BoardHeader{BrdName: name}
+	}
+	if err := AppendBoardHeaderFileRecords(filename, hdrs); err != nil {
+		t.Fatalf("AppendBoardHeaderFileRecords error: %v", err)
+	}
+
+	check := func(expected []string) {
+		t.Helper()
+		headers, err := OpenBoardHeaderFile(filename)
+		if err != nil {
+			t.Fatalf("OpenBoardHeaderFile error: %v", err)
+		}
+		actual := make([]string, len(headers))
+		for i, h := range headers {
+			actual[i] = h.BrdName
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("board names expected: %v, got: %v", expected, actual)
+		}
+	}
+
+	c := &Connector{home: dir}
+	if err := c.MoveBoardRecordFileRecord(filename, 4, 0); err != nil {
+		t.Fatalf("MoveBoardRecordFileRecord error: %v", err)
+	}
+	check([]string{"A4", "A0", "A1", "A2", "A3", "A5"})
+
+	if err := c.MoveBoardRecordFileRecord(filename, 1, 5); err != nil {
+		t.Fatalf("MoveBoardRecordFileRecord error: %v", err)
+	}
+	check([]string{"A4", "A1", "A2", "A3", "A5", "A0"})
+
+	if err := c.MoveBoardRecordFileRecord(filename, 2, 2); err != nil {
+		t.Fatalf("MoveBoardRecordFileRecord error: %v", err)
+	}
+	check([]string{"A4", "A1", "A2", "A3", "A5", "A0"})
+
+	err = c.MoveBoardRecordFileRecord(filename, 6, 0)
+	if !errors.Is(err, bbs.ErrRecordNotFound) {
+		t.Errorf("MoveBoardRecordFileRecord should return ErrRecordNotFound, got: %v", err)
+	}
+	check([]string{"A4", "A1", "A2", "A3", "A5", "A0"})
+}
+
+func TestBoardRecordFileRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "board_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	hdrs := []*BoardHeader{{BrdName: "A0"}, {BrdName: "A1"}, {BrdName: "A2"}}
+	if err := AppendBoardHeaderFileRecords(filepath.Join(dir, ".BRD"), hdrs); err != nil {
+		t.Fatalf("AppendBoardHeaderFileRecords error: %v", err)
+	}
+	db, err := bbs.Open("pttbbs", "file://"+dir)
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+
+	check := func(expected []string) {
+		t.Helper()
+		recs, err := db.ReadBoardRecords()
+		if err != nil {
+			t.Fatalf("ReadBoardRecords error: %v", err)
+		}
+		actual := make([]string, len(recs))
+		for i, r := range recs {
+			actual[i] = r.BoardID()
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("board names expected: %v, got: %v", expected, actual)
+		}
+	}
+
+	brd, err := db.ReadBoardRecord(1)
+	if err != nil || (*brd).BoardID() != "A1" {
+		t.Fatalf("ReadBoardRecord expected: A1, <nil>, got: %v, %v", brd, err)
+	}
+
+	var updated bbs.BoardRecord = &BoardHeader{BrdName: "B1", title: "updated"}
+	if err := db.UpdateBoardRecord(1, &updated); err != nil {
+		t.Fatalf("UpdateBoardRecord error: %v", err)
+	}
+	check([]string{"A0", "B1", "A2"})
+	if brd, err := db.ReadBoardRecord(1); err != nil || (*brd).Title() != "updated" {
+		t.Errorf("ReadBoardRecord after update expected title: updated, got: %v, %v", brd, err)
+	}
+
+	if err := db.RemoveBoardRecord(0); err != nil {
+		t.Fatalf("RemoveBoardRecord error: %v", err)
+	}
+	check([]string{"B1", "A2"})
+
+	if _, err := db.ReadBoardRecord(2); !errors.Is(err, bbs.ErrRecordNotFound) {
+		t.Errorf("ReadBoardRecord out of range should return ErrRecordNotFound, got: %v", err)
+	}
+	c := &Connector{home: dir}
+	if _, err := c.ReadBoardRecordFileRecord(filepath.Join(dir, ".BRD"), 2); !errors.Is(err, bbs.ErrRecordNotFound) {
+		t.Errorf("ReadBoardRecordFileRecord out of range should return ErrRecordNotFound, got: %v", err)
+	}
+	if err := c.UpdateBoardRecordFileRecord(filepath.Join(dir, ".BRD"), 2, updated); !errors.Is(err, bbs.ErrRecordNotFound) {
+		t.Errorf("UpdateBoardRecordFileRecord out of range should return ErrRecordNotFound, got: %v", err)
+	}
+	if err := c.RemoveBoardRecordFileRecord(filepath.Join(dir, ".BRD"), 2); !errors.Is(err, bbs.ErrRecordNotFound) {
+		t.Errorf("RemoveBoardRecordFileRecord out of range should return ErrRecordNotFound, got: %v", err)
+	}
+	check([]string{"B1", "A2"})
+}
+
+func TestNumBoardRecordFileRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "board_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &Connector{home: dir}
+	filename := filepath.Join(dir, ".BRD")
+	n, err := c.NumBoardRecordFileRecord(filename)
+	if err != nil || n != 0 {
+		t.Errorf("NumBoardRecordFileRecord of missing file expected: 0, <nil>, got: %v, %v", n, err)
+	}
+
+	hdrs := []*BoardHeader{{BrdName: "A0"}, {BrdName: "A1"}, {BrdName: "A2"}}
+	if err := AppendBoardHeaderFileRecords(filename, hdrs); err != nil {
+		t.Fatalf("AppendBoardHeaderFileRecords error: %v", err)
+	}
+	n, err = c.NumBoardRecordFileRecord(filename)
+	if err != nil || n != 3 {
+		t.Errorf("NumBoardRecordFileRecord expected: 3, <nil>, got: %v, %v", n, err)
+	}
+
+	// a partial record is not counted.
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("open file error: %v", err)
+	}
+	f.Write([]byte("partial"))
+	f.Close()
+	n, err = c.NumBoardRecordFileRecord(filename)
+	if err != nil || n != 3 {
+		t.Errorf("NumBoardRecordFileRecord with partial record expected: 3, <nil>, got: %v, %v", n, err)
+	}
+}
@@ -0,0 +1,26 @@
+package pttbbs
+
+import (
+	"github.com/Ptt-official-app/go-bbs"
+)
+
+// Capabilities turns off the writes if connector is opened with OpenFS, and
+// SupportsMmap if mmap is not available on the platform.
+func (c *Connector) Capabilities(caps bbs.Capabilities) bbs.Capabilities {
+	if c.fsys != nil {
+		caps.ReadOnly = true
+		caps.CanWriteBoards = false
+		caps.CanWriteUsers = false
+		caps.CanWriteArticles = false
+		caps.CanChangePasswords = false
+		caps.CanAdjustMoney = false
+		caps.CanSetBadPosts = false
+		caps.CanSetNumPosts = false
+	}
+	if !mmapSupported {
+		caps.SupportsMmap = false
+	}
+	return caps
+}
+
+var _ bbs.CapabilitiesConnector = &Connector{}
@@ -0,0 +1,44 @@
+package pttbbs
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/Ptt-official-app/go-bbs"
+)
+
+func TestCapabilities(t *testing.T) {
+	db, err := bbs.OpenConnector(&Connector{}, "file://testcase")
+	if err != nil {
+		t.Fatalf("OpenConnector error: %v", err)
+	}
+	caps := db.Capabilities()
+	if caps.ReadOnly || !caps.CanWriteBoards || !caps.CanWriteUsers || !caps.CanWriteArticles || !caps.CanChangePasswords {
+		t.Errorf("writes of pttbbs should be supported, got: %+v", caps)
+	}
+	if caps.SupportsMmap != mmapSupported {
+		t.Errorf("SupportsMmap expected: %v, got: %v", mmapSupported, caps.SupportsMmap)
+	}
+
+	c := &Connector{}
+	if err := c.OpenFS(fstest.MapFS{}, "."); err != nil {
+		t.Fatalf("OpenFS error: %v", err)
+	}
+	db, err = bbs.OpenConnector(bbs.WithCache(&readOnlyConnector{c}, 0), "")
+	if err != nil {
+		t.Fatalf("OpenConnector error: %v", err)
+	}
+	caps = db.Capabilities()
+	if !caps.ReadOnly || caps.CanWriteBoards || caps.CanWriteUsers || caps.CanSetNumPosts {
+		t.Errorf("writes of connector opened with fs.FS should not be supported, got: %+v", caps)
+	}
+}
+
+// readOnlyConnector keeps the fs.FS of Connector when it is opened.
+type readOnlyConnector struct {
+	*Connector
+}
+
+func (c *readOnlyConnector) Open(dataSourceName string) error { return nil }
+
+func (c *readOnlyConnector) Unwrap() bbs.Connector { return c.Connector }
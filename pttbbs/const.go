@@ -83,6 +83,20 @@ const (
 	FileAnonymous = 0x80 /* anonymous file */
 )
 
+const (
+	//////////
+	//common.h
+	//////////
+	// FileNameSafeDelete https://github.com/ptt/pttbbs/blob/master/include/common.h
+	FileNameSafeDelete = ".deleted" /* filename of safely deleted article */
+	// FileNameOverrides is fn_overrides in https://github.com/ptt/pttbbs/blob/master/mbbsd/var.c
+	FileNameOverrides = "overrides" /* filename of user friend list */
+	// FileNameReject is fn_reject in https://github.com/ptt/pttbbs/blob/master/mbbsd/var.c
+	FileNameReject = "reject" /* filename of user reject list */
+	// FileNameBoardNotes is fn_notes in https://github.com/ptt/pttbbs/blob/master/mbbsd/var.c
+	FileNameBoardNotes = "notes" /* filename of board info shown when entering board */
+)
+
 const (
 	////////////
 	// uflags.h
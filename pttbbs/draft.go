@@ -1,14 +1,16 @@
 package pttbbs
 
 import (
-	"io/ioutil"
 	"os"
 )
 
 func (c *Connector) ReadUserDraft(filename string) ([]byte, error) {
-	return ioutil.ReadFile(filename)
+	return c.readFile(filename)
 }
 
 func (c *Connector) DeleteUserDraft(filename string) error {
+	if err := c.checkWritable(); err != nil {
+		return err
+	}
 	return os.Remove(filename)
 }
@@ -0,0 +1,118 @@
+package pttbbs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Ptt-official-app/go-bbs"
+)
+
+// FavVersion is the version written in the first 2 bytes of current pttbbs
+// binary favorite files, see FAV_VERSION in fav.h.
+const FavVersion = 3363
+
+// FavFormat is the layout of favorite files.
+type FavFormat int
+
+const (
+	// FavFormatAuto sniffs the layout from the content of file.
+	FavFormatAuto FavFormat = iota
+	// FavFormatBinary is the binary layout of current pttbbs, see FavFile.
+	FavFormatBinary
+	// FavFormatText is the text layout used by some forks, each line is a
+	// board id, a line of "-" only is a separator line, empty lines and lines
+	// starting with "#" are ignored.
+	FavFormatText
+)
+
+// SetFavoriteFormat sets the layout of favorite files read by
+// ReadUserFavoriteRecordsFile, FavFormatAuto is the default.
+func (c *Connector) SetFavoriteFormat(f FavFormat) {
+	c.favFormat = f
+}
+
+// DetectFavFormat sniffs the layout of favorite file data. Data starts with
+// FavVersion is binary, data of printable UTF-8 text is text, otherwise
+// bbs.ErrUnknownFormat is returned.
+func DetectFavFormat(data []byte) (FavFormat, error) {
+	if len(data) >= 2 && binary.LittleEndian.Uint16(data[0:2]) == FavVersion {
+		return FavFormatBinary, nil
+	}
+	if len(bytes.TrimSpace(data)) > 0 && isFavText(data) {
+		return FavFormatText, nil
+	}
+	return FavFormatAuto, bbs.ErrUnknownFormat
+}
+
+func isFavText(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, c := range data {
+		if (c < 0x20 && c != '\t' && c != '\r' && c != '\n') || c == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// UnmarshalFavTextFile parses data of text layout and returns FavFile, boards
+// in returned FavFile have no BoardID but board id string only.
+func UnmarshalFavTextFile(data []byte) (*FavFile, error) {
+	if !isFavText(data) {
+		return nil, bbs.ErrUnknownFormat
+	}
+	folder := &FavFolder{}
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.Trim(line, "-") == "":
+			folder.NLines++
+			folder.LineID++
+			folder.FavItems = append(folder.FavItems, &FavItem{
+				FavType: FavItemTypeLine,
+				FavAttr: uint8(FavhFav),
+				Item:    &FavLineItem{LineID: folder.LineID},
+			})
+		default:
+			folder.NBoards++
+			folder.FavItems = append(folder.FavItems, &FavItem{
+				FavType: FavItemTypeBoard,
+				FavAttr: uint8(FavhFav),
+				Item:    &FavBoardItem{boardID: line},
+			})
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	folder.DataTail = uint16(len(folder.FavItems))
+	folder.NAlloc = folder.DataTail
+	return &FavFile{Folder: folder}, nil
+}
+
+// unmarshalFav parses favorite file data in format, FavFormatAuto sniffs
+// the format by DetectFavFormat.
+func unmarshalFav(data []byte, format FavFormat) (*FavFile, error) {
+	if format == FavFormatAuto {
+		var err error
+		format, err = DetectFavFormat(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	switch format {
+	case FavFormatBinary:
+		return UnmarshalFavFile(data)
+	case FavFormatText:
+		return UnmarshalFavTextFile(data)
+	}
+	return nil, fmt.Errorf("%w: favorite format %d", bbs.ErrUnknownFormat, format)
+}
@@ -0,0 +1,125 @@
+package pttbbs
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Ptt-official-app/go-bbs"
+)
+
+func TestDetectFavFormat(t *testing.T) {
+	binary, err := ioutil.ReadFile("testcase/fav/01.fav")
+	if err != nil {
+		t.Fatalf("read file error: %v", err)
+	}
+	text, err := ioutil.ReadFile("testcase/fav/03.fav")
+	if err != nil {
+		t.Fatalf("read file error: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		data     []byte
+		expected FavFormat
+		err      error
+	}{
+		{name: "binary", data: binary, expected: FavFormatBinary},
+		{name: "text", data: text, expected: FavFormatText},
+		{name: "empty", data: nil, err: bbs.ErrUnknownFormat},
+		{name: "other version", data: []byte{0x22, 0x0d, 0, 0, 0, 0}, err: bbs.ErrUnknownFormat},
+		{name: "invalid utf8", data: []byte("SYSOP\n\xff\xfe\n"), err: bbs.ErrUnknownFormat},
+	}
+	for _, c := range testCases {
+		actual, err := DetectFavFormat(c.data)
+		if !errors.Is(err, c.err) {
+			t.Errorf("%s: error not match, expected: %v, got: %v", c.name, c.err, err)
+			continue
+		}
+		if err == nil && actual != c.expected {
+			t.Errorf("%s: format not match, expected: %v, got: %v", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestReadUserFavoriteRecordsFileFormat(t *testing.T) {
+	brd, err := ioutil.ReadFile("testcase/board/01.BRD")
+	if err != nil {
+		t.Fatalf("read file error: %v", err)
+	}
+	binary, err := ioutil.ReadFile("testcase/fav/01.fav")
+	if err != nil {
+		t.Fatalf("read file error: %v", err)
+	}
+	text, err := ioutil.ReadFile("testcase/fav/03.fav")
+	if err != nil {
+		t.Fatalf("read file error: %v", err)
+	}
+	fsys := fstest.MapFS{
+		".BRD":                  &fstest.MapFile{Data: brd},
+		"home/S/SYSOP/.fav":     &fstest.MapFile{Data: binary},
+		"home/p/pichu/.fav":     &fstest.MapFile{Data: text},
+		"home/g/garbage/.fav":   &fstest.MapFile{Data: []byte{0, 1, 2, 3}},
+		"home/b/binarytxt/.fav": &fstest.MapFile{Data: text},
+		"home/e/empty/.fav":     &fstest.MapFile{},
+	}
+	c := &Connector{}
+	if err := c.OpenFS(fsys, "."); err != nil {
+		t.Fatalf("OpenFS error: %v", err)
+	}
+
+	path, _ := c.GetUserFavoriteRecordsPath("SYSOP")
+	recs, err := c.ReadUserFavoriteRecordsFile(path)
+	if err != nil {
+		t.Fatalf("ReadUserFavoriteRecordsFile error: %v", err)
+	}
+	headers, err := OpenBoardHeaderFile("testcase/board/01.BRD")
+	if err != nil {
+		t.Fatalf("OpenBoardHeaderFile error: %v", err)
+	}
+	expected := []string{headers[13].BrdName, headers[5].BrdName}
+	if len(recs) != len(expected) {
+		t.Fatalf("records length not match, expected: %v, got: %v", len(expected), len(recs))
+	}
+	for i, r := range recs {
+		if r.BoardID() != expected[i] {
+			t.Errorf("board id %d not match, expected: %v, got: %v", i, expected[i], r.BoardID())
+		}
+	}
+
+	path, _ = c.GetUserFavoriteRecordsPath("pichu")
+	recs, err = c.ReadUserFavoriteRecordsFile(path)
+	if err != nil {
+		t.Fatalf("ReadUserFavoriteRecordsFile error: %v", err)
+	}
+	expectedTypes := []bbs.FavoriteType{bbs.FavoriteTypeBoard, bbs.FavoriteTypeBoard, bbs.FavoriteTypeLine, bbs.FavoriteTypeBoard}
+	expected = []string{"SYSOP", "WhoAmI", "", "test"}
+	if len(recs) != len(expected) {
+		t.Fatalf("records length not match, expected: %v, got: %v", len(expected), len(recs))
+	}
+	for i, r := range recs {
+		if r.Type() != expectedTypes[i] {
+			t.Errorf("type %d not match, expected: %v, got: %v", i, expectedTypes[i], r.Type())
+		}
+		if r.BoardID() != expected[i] {
+			t.Errorf("board id %d not match, expected: %v, got: %v", i, expected[i], r.BoardID())
+		}
+	}
+
+	path, _ = c.GetUserFavoriteRecordsPath("garbage")
+	if _, err := c.ReadUserFavoriteRecordsFile(path); !errors.Is(err, bbs.ErrUnknownFormat) {
+		t.Errorf("ReadUserFavoriteRecordsFile should return ErrUnknownFormat, got: %v", err)
+	}
+
+	path, _ = c.GetUserFavoriteRecordsPath("empty")
+	if recs, err := c.ReadUserFavoriteRecordsFile(path); err != nil || len(recs) != 0 {
+		t.Errorf("ReadUserFavoriteRecordsFile of empty file expected: [], <nil>, got: %v, %v", recs, err)
+	}
+
+	c.SetFavoriteFormat(FavFormatBinary)
+	path, _ = c.GetUserFavoriteRecordsPath("binarytxt")
+	if _, err := c.ReadUserFavoriteRecordsFile(path); err == nil {
+		t.Errorf("ReadUserFavoriteRecordsFile should return error on text file with FavFormatBinary")
+	}
+}
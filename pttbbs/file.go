@@ -27,14 +27,16 @@
 package pttbbs
 
 import (
+	"github.com/Ptt-official-app/go-bbs"
+
 	"bytes"
 	"encoding/binary"
 	"io"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
-
-	"github.com/Ptt-official-app/go-bbs/filelock"
 )
 
 const (
@@ -47,6 +49,10 @@ const (
 
 	PosOfFileHeaderUnionMulti = 1 + PosOfFileHeaderTitle + TitleLength + 1
 	PosOfFileHeaderFilemode   = PosOfFileHeaderUnionMulti + 4
+
+	// FileHeaderRecordLength is the size of fileheader_t in .DIR, it is
+	// padded to 128 bytes.
+	FileHeaderRecordLength = 128
 )
 
 // VoteLimits shows the limitation of a vote post.
@@ -73,14 +79,25 @@ type FileHeader struct {
 	ReferFlag bool // 至底公告？
 
 	Filemode uint8
+
+	// loc is the location of the Connector which reads f, nil means
+	// Location.
+	loc *time.Location
 }
 
 func (f *FileHeader) Filename() string            { return f.filename }
 func (f *FileHeader) SetFilename(newValue string) { f.filename = newValue }
 
-func (f *FileHeader) Modified() time.Time { return f.modified }
+func (f *FileHeader) Modified() time.Time { return inLocation(f.modified, f.loc) }
 func (f *FileHeader) Recommend() int      { return int(f.recommend) }
 
+// RecommendDisplay returns the recommend token shown in article list, such
+// as 爆 or X3, see bbs.RecommendDisplay.
+func (f *FileHeader) RecommendDisplay() string { return bbs.RecommendDisplay(int(f.recommend)) }
+
+// RecommendValue returns the net score stored in the signed recommend byte.
+func (f *FileHeader) RecommendValue() int { return int(f.recommend) }
+
 func (f *FileHeader) Owner() string            { return f.owner }
 func (f *FileHeader) SetOwner(newValue string) { f.owner = newValue }
 
@@ -92,6 +109,34 @@ func (f *FileHeader) SetTitle(newValue string) { f.title = newValue }
 
 func (f *FileHeader) Money() int { return f.money }
 
+// PostedAt returns the time article is posted in the location of the
+// Connector which reads f, or Location. It is parsed from the timestamp in
+// filename such as `M.1234567890.A.123`, and falls back to Modified if
+// filename has no timestamp.
+func (f *FileHeader) PostedAt() time.Time {
+	parts := strings.Split(f.filename, ".")
+	if len(parts) >= 2 {
+		if ts, err := strconv.ParseInt(parts[1], 10, 64); err == nil && ts > 0 {
+			return time.Unix(ts, 0).In(orLocation(f.loc))
+		}
+	}
+	if f.modified.IsZero() || f.modified.Unix() == 0 {
+		return time.Time{}
+	}
+	return f.modified.In(orLocation(f.loc))
+}
+
+// IsDeleted returns true if f is a deleted slot in .DIR, pttbbs marks a
+// deleted article by renaming its filename to FN_SAFEDEL (".deleted"), and
+// a cleared slot has empty filename.
+func (f *FileHeader) IsDeleted() bool {
+	return f.filename == "" || strings.HasPrefix(f.filename, FileNameSafeDelete)
+}
+
+var _ bbs.TimedArticleRecord = &FileHeader{}
+var _ bbs.DeletableArticleRecord = &FileHeader{}
+var _ bbs.ScoredArticleRecord = &FileHeader{}
+
 func NewFileHeader() *FileHeader {
 	return &FileHeader{}
 }
@@ -106,19 +151,28 @@ func OpenFileHeaderFile(filename string) ([]*FileHeader, error) {
 	}
 	defer file.Close()
 
+	return ReadFileHeaders(file)
+}
+
+// ReadFileHeaders reads all file headers from r, it reads the same format as
+// .DIR file. Decode errors are returned as *bbs.DecodeError, and so is a
+// partial record at the end of r with io.ErrUnexpectedEOF, the file headers
+// before the bad one are returned with it.
+func ReadFileHeaders(r io.Reader) ([]*FileHeader, error) {
 	ret := []*FileHeader{}
 
-	for {
-		hdr := make([]byte, 128)
-		_, err := file.Read(hdr)
-		// log.Println(len, err)
+	for i := 0; ; i++ {
+		hdr := make([]byte, FileHeaderRecordLength)
+		err := readRecord(r, hdr, i)
 		if err == io.EOF {
 			break
+		} else if err != nil {
+			return ret, err
 		}
 
 		f, err := NewFileHeaderWithByte(hdr)
 		if err != nil {
-			return nil, err
+			return ret, newDecodeError(i, FileHeaderRecordLength, err)
 		}
 		ret = append(ret, f)
 		// log.Println(f.filename)
@@ -130,33 +184,20 @@ func OpenFileHeaderFile(filename string) ([]*FileHeader, error) {
 }
 
 func AppendFileHeaderFileRecord(filename string, newFileHeader *FileHeader) error {
+	return appendFileHeader(filename, newFileHeader, 0)
+}
 
-	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	err = filelock.Lock(f)
-	if err != nil {
-		// File is locked
-		return err
-	}
-	defer filelock.Unlock(f)
-
+func appendFileHeader(filename string, newFileHeader *FileHeader, lockTimeout time.Duration) error {
 	data, err := newFileHeader.MarshalToByte()
 	if err != nil {
 		return err
 	}
 
-	if _, err := f.Write(data); err != nil {
-		return err
-	}
-
 	// TODO: update BoardHeader ?
 	// https://github.com/ptt/pttbbs/blob/4d56e77f264960e43e060b77e442e166e5706417/mbbsd/syspost.c#L35
 
-	return nil
+	_, err = appendRecordFile(filename, data, lockTimeout)
+	return err
 }
 
 func NewFileHeaderWithByte(data []byte) (*FileHeader, error) {
@@ -191,7 +232,7 @@ func NewFileHeaderWithByte(data []byte) (*FileHeader, error) {
 }
 
 func (f *FileHeader) MarshalToByte() ([]byte, error) {
-	ret := make([]byte, 128)
+	ret := make([]byte, FileHeaderRecordLength)
 
 	copy(ret[PosOfFileHeaderFilename:PosOfFileHeaderFilename+FileNameLength], f.filename)
 	binary.LittleEndian.PutUint32(ret[PosOfFileHeaderModified:PosOfFileHeaderModified+4], uint32(f.modified.Unix()))
@@ -16,10 +16,17 @@ package pttbbs
 
 import (
 	"encoding/hex"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
+
+	"github.com/Ptt-official-app/go-bbs"
 )
 
 func TestParseFileHeader(t *testing.T) {
@@ -413,3 +420,142 @@ func hexToByte(input string) []byte {
 	b, _ := hex.DecodeString(s)
 	return b
 }
+
+func TestFileHeaderDeletedSlot(t *testing.T) {
+	var dir []byte
+	for _, filename := range []string{"M.1599059246.A.CF6", FileNameSafeDelete, ""} {
+		f := &FileHeader{filename: filename, owner: "SYSOP", modified: time.Unix(1599059246, 0)}
+		data, err := f.MarshalToByte()
+		if err != nil {
+			t.Fatalf("MarshalToByte error: %v", err)
+		}
+		dir = append(dir, data...)
+	}
+	fsys := fstest.MapFS{
+		"boards/S/SYSOP/.DIR": &fstest.MapFile{Data: dir},
+	}
+	db, err := bbs.OpenFS("pttbbs", fsys, ".")
+	if err != nil {
+		t.Fatalf("OpenFS error: %v", err)
+	}
+
+	recs, err := db.ReadBoardArticleRecordsFile("SYSOP")
+	if err != nil {
+		t.Fatalf("ReadBoardArticleRecordsFile error: %v", err)
+	}
+	expected := []bool{false, true, true}
+	if len(recs) != len(expected) {
+		t.Fatalf("records length not match, expected: %v, got: %v", len(expected), len(recs))
+	}
+	for i, r := range recs {
+		if actual := r.(*FileHeader).IsDeleted(); actual != expected[i] {
+			t.Errorf("IsDeleted of %d not match, expected: %v, got: %v", i, expected[i], actual)
+		}
+	}
+
+	db.SetSkipDeletedArticles(true)
+	recs, err = db.ReadBoardArticleRecordsFile("SYSOP")
+	if err != nil {
+		t.Fatalf("ReadBoardArticleRecordsFile error: %v", err)
+	}
+	if len(recs) != 1 || recs[0].Filename() != "M.1599059246.A.CF6" {
+		t.Errorf("deleted slots should be skipped, got: %v", recs)
+	}
+}
+
+// fullReadConnector hides RecordSizer and RecordDecoder of Connector, so
+// DB reads all article records instead of ranges.
+type fullReadConnector struct {
+	bbs.Connector
+}
+
+func TestReadBoardArticleRecordsRangeSkipDeleted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var data []byte
+	for _, filename := range []string{"M.1.A.001", FileNameSafeDelete, "M.2.A.002", "", "M.3.A.003", "M.4.A.004"} {
+		f := &FileHeader{filename: filename, owner: "SYSOP", modified: time.Unix(1599059246, 0)}
+		b, err := f.MarshalToByte()
+		if err != nil {
+			t.Fatalf("MarshalToByte error: %v", err)
+		}
+		data = append(data, b...)
+	}
+	path := filepath.Join(dir, "boards", "S", "SYSOP", ".DIR")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("MkdirAll error: %v", err)
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("write .DIR error: %v", err)
+	}
+
+	ranged, err := bbs.OpenConnector(&Connector{}, dir)
+	if err != nil {
+		t.Fatalf("OpenConnector error: %v", err)
+	}
+	full, err := bbs.OpenConnector(fullReadConnector{&Connector{}}, dir)
+	if err != nil {
+		t.Fatalf("OpenConnector error: %v", err)
+	}
+
+	read := func(db *bbs.DB) []string {
+		var ret []string
+		add := func(name string, recs []bbs.ArticleRecord, err error) {
+			var filenames []string
+			for _, r := range recs {
+				filenames = append(filenames, r.Filename())
+			}
+			ret = append(ret, fmt.Sprintf("%s: %v %v", name, filenames, err))
+		}
+		recs, err := db.ReadBoardArticleRecordsRange("SYSOP", 1, 2)
+		add("range", recs, err)
+		recs, err = db.ReadBoardArticleRecordsReverse("SYSOP", 1, 2)
+		add("reverse", recs, err)
+		recs, err = db.RecentArticles("SYSOP", 3)
+		add("recent", recs, err)
+		r, err := db.ArticleAtIndex("SYSOP", -2)
+		add("index", []bbs.ArticleRecord{r}, err)
+		n, err := db.NumBoardArticles("SYSOP")
+		ret = append(ret, fmt.Sprintf("num: %v %v", n, err))
+		return ret
+	}
+
+	for _, skip := range []bool{false, true} {
+		ranged.SetSkipDeletedArticles(skip)
+		full.SetSkipDeletedArticles(skip)
+		expected, actual := read(full), read(ranged)
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("ranged reads with skip deleted %v not match, expected: %q, got: %q", skip, expected, actual)
+		}
+	}
+}
+
+func TestFileHeaderRecommendDisplay(t *testing.T) {
+	testCases := []struct {
+		raw      byte
+		value    int
+		expected string
+	}{
+		{raw: 0x64, value: 100, expected: "爆"},
+		{raw: 0x63, value: 99, expected: "99"},
+		{raw: 0x00, value: 0, expected: ""},
+		{raw: 0xf6, value: -10, expected: "X1"},
+		{raw: 0x9d, value: -99, expected: "X9"},
+		{raw: 0x9c, value: -100, expected: "XX"},
+	}
+	for _, c := range testCases {
+		data := make([]byte, FileHeaderRecordLength)
+		data[PosOfFileHeaderRecommend] = c.raw
+		f, err := NewFileHeaderWithByte(data)
+		if err != nil {
+			t.Fatalf("NewFileHeaderWithByte error: %v", err)
+		}
+		if f.RecommendValue() != c.value || f.RecommendDisplay() != c.expected {
+			t.Errorf("recommend of %#x expected: %v %q, got: %v %q", c.raw, c.value, c.expected, f.RecommendValue(), f.RecommendDisplay())
+		}
+	}
+}
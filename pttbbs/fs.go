@@ -0,0 +1,79 @@
+package pttbbs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/Ptt-official-app/go-bbs"
+)
+
+// ErrReadOnly is returned by write methods when the connector is opened with
+// OpenFS, fs.FS is a read-only file system.
+var ErrReadOnly = errors.New("pttbbs: connector opened with fs.FS is read-only")
+
+// OpenFS makes connector read all files from fsys, root is the bbs home
+// directory in fsys, "." or "" means the root of fsys.
+func (c *Connector) OpenFS(fsys fs.FS, root string) error {
+	if root == "" {
+		root = "."
+	}
+	if !fs.ValidPath(root) {
+		return &fs.PathError{Op: "open", Path: root, Err: fs.ErrInvalid}
+	}
+	c.home = root
+	c.fsys = fsys
+	return nil
+}
+
+// open opens file name from fsys if connector is opened with OpenFS,
+// otherwise from disk.
+func (c *Connector) open(name string) (io.ReadCloser, error) {
+	if c.fsys != nil {
+		return c.fsys.Open(path.Clean(name))
+	}
+	return os.Open(name)
+}
+
+// readFile reads whole file name from fsys if connector is opened with
+// OpenFS, otherwise from disk.
+func (c *Connector) readFile(name string) ([]byte, error) {
+	if c.fsys != nil {
+		return fs.ReadFile(c.fsys, path.Clean(name))
+	}
+	return ioutil.ReadFile(name)
+}
+
+// ReadRecordFileRange reads at most size bytes starting from offset in record
+// file name, from fsys if connector is opened with OpenFS, see bbs.ReadRange.
+func (c *Connector) ReadRecordFileRange(name string, offset int64, size int) ([]byte, error) {
+	f, err := c.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return bbs.ReadRange(f, offset, size)
+}
+
+// stat returns fs.FileInfo of file name from fsys if connector is opened with
+// OpenFS, otherwise from disk.
+func (c *Connector) stat(name string) (fs.FileInfo, error) {
+	if c.fsys != nil {
+		return fs.Stat(c.fsys, path.Clean(name))
+	}
+	return os.Stat(name)
+}
+
+// checkWritable returns ErrReadOnly if connector is opened with OpenFS.
+func (c *Connector) checkWritable() error {
+	if c.fsys != nil {
+		return ErrReadOnly
+	}
+	return nil
+}
+
+var _ bbs.FSConnector = &Connector{}
+var _ bbs.RecordRangeConnector = &Connector{}
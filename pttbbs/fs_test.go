@@ -0,0 +1,135 @@
+package pttbbs
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Ptt-official-app/go-bbs"
+)
+
+func TestOpenFS(t *testing.T) {
+	passwds, err := ioutil.ReadFile("testcase/passwd/01.PASSWDS")
+	if err != nil {
+		t.Fatalf("read file error: %v", err)
+	}
+	brd, err := ioutil.ReadFile("testcase/board/01.BRD")
+	if err != nil {
+		t.Fatalf("read file error: %v", err)
+	}
+	fsys := fstest.MapFS{
+		"bbs/.PASSWDS": &fstest.MapFile{Data: passwds},
+		"bbs/.BRD":     &fstest.MapFile{Data: brd},
+	}
+
+	c := &Connector{}
+	if err := c.OpenFS(fsys, "bbs"); err != nil {
+		t.Fatalf("OpenFS error: %v", err)
+	}
+
+	path, _ := c.GetUserRecordsPath()
+	users, err := c.ReadUserRecordsFile(path)
+	if err != nil {
+		t.Fatalf("ReadUserRecordsFile error: %v", err)
+	}
+	if users[0].UserID() != "SYSOP" {
+		t.Errorf("user id not match, expected: SYSOP, got: %v", users[0].UserID())
+	}
+
+	path, _ = c.GetBoardRecordsPath()
+	boards, err := c.ReadBoardRecordsFile(path)
+	if err != nil {
+		t.Fatalf("ReadBoardRecordsFile error: %v", err)
+	}
+	if boards[0].BoardID() != testBoardHeaders[0].BrdName {
+		t.Errorf("board id not match, expected: %v, got: %v", testBoardHeaders[0].BrdName, boards[0].BoardID())
+	}
+
+	err = c.AddBoardRecordFileRecord(path, NewBoardHeader())
+	if !errors.Is(err, ErrReadOnly) {
+		t.Errorf("AddBoardRecordFileRecord should return ErrReadOnly, got: %v", err)
+	}
+
+	if err := c.OpenFS(fsys, "../bbs"); err == nil {
+		t.Errorf("OpenFS with invalid root should return error")
+	}
+}
+
+func TestBBSOpenFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		".PASSWDS": &fstest.MapFile{},
+	}
+	db, err := bbs.OpenFS("pttbbs", fsys, ".")
+	if err != nil {
+		t.Fatalf("OpenFS error: %v", err)
+	}
+	if err := db.Ping(); err == nil {
+		t.Errorf("Ping should return error when .BRD not exist")
+	}
+
+	fsys[".BRD"] = &fstest.MapFile{}
+	if err := db.Ping(); err != nil {
+		t.Errorf("Ping error: %v", err)
+	}
+}
+
+func TestOpenFSPerDB(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{".PASSWDS", ".BRD"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("write %v error: %v", name, err)
+		}
+	}
+	disk, err := bbs.Open("pttbbs", "file://"+dir)
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+
+	fsys := fstest.MapFS{".PASSWDS": &fstest.MapFile{}}
+	if _, err := bbs.OpenFS("pttbbs", fsys, "."); err != nil {
+		t.Fatalf("OpenFS error: %v", err)
+	}
+	if err := disk.Ping(); err != nil {
+		t.Errorf("OpenFS should not change other DBs, Ping error: %v", err)
+	}
+
+	if _, err := bbs.Open("pttbbs", "?fork=pttbbs", bbs.WithFS(fsys, ".")); err != nil {
+		t.Errorf("Open with fork and WithFS error: %v", err)
+	}
+	if _, err := bbs.Open("pttbbs", "?fork=notexist", bbs.WithFS(fsys, ".")); !errors.Is(err, bbs.ErrNotSupported) {
+		t.Errorf("Open with unknown fork should return ErrNotSupported, got: %v", err)
+	}
+}
+
+func TestReadUserRecordsRange(t *testing.T) {
+	passwds, err := ioutil.ReadFile("testcase/passwd/01.PASSWDS")
+	if err != nil {
+		t.Fatalf("read file error: %v", err)
+	}
+	fsys := fstest.MapFS{
+		".PASSWDS": &fstest.MapFile{Data: passwds},
+	}
+	db, err := bbs.OpenFS("pttbbs", fsys, ".")
+	if err != nil {
+		t.Fatalf("OpenFS error: %v", err)
+	}
+
+	all, err := db.ReadUserRecords()
+	if err != nil {
+		t.Fatalf("ReadUserRecords error: %v", err)
+	}
+	recs, err := db.ReadUserRecordsRange(1, 2)
+	if err != nil {
+		t.Fatalf("ReadUserRecordsRange error: %v", err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("len(recs) expected: 2, got: %v", len(recs))
+	}
+	for i, r := range recs {
+		if r.UserID() != all[i+1].UserID() {
+			t.Errorf("user id of record %v expected: %v, got: %v", i+1, all[i+1].UserID(), r.UserID())
+		}
+	}
+}
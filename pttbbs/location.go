@@ -0,0 +1,81 @@
+package pttbbs
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/Ptt-official-app/go-bbs"
+)
+
+// location stores the *time.Location of bbs.
+var location atomic.Value
+
+func init() {
+	location.Store(time.Local)
+}
+
+// Location returns the default time zone of times returned by records, such
+// as FileHeader.PostedAt and Userec.LastLogin, records read by a Connector
+// use the location set by Connector.SetLocation instead. It is time.Local by
+// default.
+func Location() *time.Location {
+	return location.Load().(*time.Location)
+}
+
+// SetLocation sets the time zone returned by Location, nil resets it to
+// time.Local.
+func SetLocation(loc *time.Location) {
+	if loc == nil {
+		loc = time.Local
+	}
+	location.Store(loc)
+}
+
+// orLocation returns loc, or Location if loc is nil.
+func orLocation(loc *time.Location) *time.Location {
+	if loc == nil {
+		return Location()
+	}
+	return loc
+}
+
+// inLocation returns t in loc, nil loc means Location, zero time is returned
+// as is.
+func inLocation(t time.Time, loc *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.In(orLocation(loc))
+}
+
+// SetLocation sets the time zone of times returned by records read by c, nil
+// resets it to Location. It is called by bbs.DB.SetLocation, other
+// Connectors are not affected since bbs.Open opens a new Connector for each
+// DB.
+func (c *Connector) SetLocation(loc *time.Location) {
+	c.location = loc
+}
+
+// userecsIn sets the location of us to the location of c, records keep
+// following Location if it is not set.
+func (c *Connector) userecsIn(us ...*Userec) {
+	if c.location == nil {
+		return
+	}
+	for _, u := range us {
+		u.loc = c.location
+	}
+}
+
+// fileHeadersIn sets the location of fs to the location of c, records keep
+// following Location if it is not set.
+func (c *Connector) fileHeadersIn(fs ...*FileHeader) {
+	if c.location == nil {
+		return
+	}
+	for _, f := range fs {
+		f.loc = c.location
+	}
+}
+
+var _ bbs.LocationConnector = &Connector{}
@@ -0,0 +1,137 @@
+package pttbbs
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Ptt-official-app/go-bbs"
+)
+
+func TestPostedAt(t *testing.T) {
+	defer SetLocation(Location())
+
+	f := &FileHeader{filename: "M.1606145447.A.2C5"}
+	if got := f.PostedAt(); got.Location() != time.Local {
+		t.Errorf("default location expected: %v, got: %v", time.Local, got.Location())
+	}
+
+	SetLocation(time.UTC)
+	expected := time.Date(2020, 11, 23, 15, 30, 47, 0, time.UTC)
+	if got := f.PostedAt(); !got.Equal(expected) || got.Location() != time.UTC {
+		t.Errorf("PostedAt expected: %v, got: %v", expected, got)
+	}
+
+	modified := time.Unix(1606145000, 0)
+	f = &FileHeader{filename: "NOTE", modified: modified}
+	if got := f.PostedAt(); !got.Equal(modified) {
+		t.Errorf("PostedAt should fall back to modified %v, got: %v", modified, got)
+	}
+
+	f = &FileHeader{filename: "NOTE"}
+	if got := f.PostedAt(); !got.IsZero() {
+		t.Errorf("PostedAt without timestamp should be zero, got: %v", got)
+	}
+}
+
+func TestUserecLocation(t *testing.T) {
+	defer SetLocation(Location())
+
+	u := &Userec{lastLogin: time.Unix(1606145447, 0), lastHost: "127.0.0.1"}
+	f := &FileHeader{modified: time.Unix(1606145447, 0)}
+	SetLocation(time.UTC)
+	for _, got := range []time.Time{u.LastLogin(), u.LoginTimes()[0], f.Modified()} {
+		if got.Location() != time.UTC || got.Hour() != 15 {
+			t.Errorf("time should follow Location %v, got: %v", time.UTC, got)
+		}
+	}
+
+	u = &Userec{}
+	if got := u.LastLogin(); !got.IsZero() {
+		t.Errorf("zero LastLogin should not be shifted, got: %v", got)
+	}
+}
+
+func TestConnectorLocation(t *testing.T) {
+	taipei := time.FixedZone("CST", 8*60*60)
+	c1, c2 := &Connector{}, &Connector{}
+	c1.SetLocation(taipei)
+	c2.SetLocation(time.UTC)
+	if Location() == taipei || Location() == time.UTC {
+		t.Errorf("SetLocation of Connector should not change Location, got: %v", Location())
+	}
+
+	testCases := []struct {
+		c   *Connector
+		loc *time.Location
+	}{
+		{c: c1, loc: taipei},
+		{c: c2, loc: time.UTC},
+	}
+	for _, tc := range testCases {
+		users, err := tc.c.ReadUserRecordsFile("testcase/passwd/01.PASSWDS")
+		if err != nil {
+			t.Fatalf("ReadUserRecordsFile error: %v", err)
+		}
+		articles, err := tc.c.ReadArticleRecordsFile("testcase/file/01.DIR")
+		if err != nil {
+			t.Fatalf("ReadArticleRecordsFile error: %v", err)
+		}
+		set, err := tc.c.OpenArticleRecordSet("testcase/file/01.DIR")
+		if err != nil {
+			t.Fatalf("OpenArticleRecordSet error: %v", err)
+		}
+		got := []time.Time{
+			users[0].LastLogin(),
+			articles[0].(*FileHeader).PostedAt(),
+			set.At(0).(*FileHeader).PostedAt(),
+		}
+		set.Close()
+		for _, tm := range got {
+			if tm.Location() != tc.loc {
+				t.Errorf("time read by connector expected in %v, got: %v", tc.loc, tm)
+			}
+		}
+	}
+
+}
+
+func TestDBLocation(t *testing.T) {
+	data, err := ioutil.ReadFile("testcase/passwd/01.PASSWDS")
+	if err != nil {
+		t.Fatalf("read file error: %v", err)
+	}
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, ".PASSWDS"), data, 0644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+
+	taipei := time.FixedZone("CST", 8*60*60)
+	db1, err := bbs.Open("pttbbs", "file://"+dir)
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	db2, err := bbs.Open("pttbbs", "file://"+dir)
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	db1.SetLocation(taipei)
+
+	testCases := []struct {
+		db  *bbs.DB
+		loc *time.Location
+	}{
+		{db: db1, loc: taipei},
+		{db: db2, loc: time.Local},
+	}
+	for _, tc := range testCases {
+		users, err := tc.db.ReadUserRecords()
+		if err != nil {
+			t.Fatalf("ReadUserRecords error: %v", err)
+		}
+		if got := users[0].LastLogin(); got.Location() != tc.loc {
+			t.Errorf("LastLogin expected in %v, got: %v", tc.loc, got)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package pttbbs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Ptt-official-app/go-bbs"
+	"github.com/Ptt-official-app/go-bbs/filelock"
+)
+
+// SetLockTimeout sets the timeout of locking record files when adding
+// records, writes fail with filelock.ErrTimeout if another process, such as
+// the running mbbsd, holds the lock longer than d. d <= 0 waits until the
+// lock is acquired, which is the default. Locks are flock(2) on unix and
+// not supported on other platforms, see package filelock.
+func (c *Connector) SetLockTimeout(d time.Duration) {
+	c.lockTimeout = d
+}
+
+// appendRecordFile appends data to the end of filename while holding an
+// exclusive lock and returns the offset data is written at, the file is
+// created if it does not exist. If the write fails, the file is truncated
+// back to its original size so no partial record is left.
+func appendRecordFile(filename string, data []byte, lockTimeout time.Duration) (int64, error) {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	err = filelock.LockTimeout(f, lockTimeout)
+	if err != nil {
+		// File is locked
+		return 0, err
+	}
+	defer filelock.Unlock(f)
+
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := f.Write(data); err != nil {
+		if terr := f.Truncate(size); terr != nil {
+			return 0, fmt.Errorf("write error: %v, truncate error: %v", err, terr)
+		}
+		return 0, err
+	}
+	return size, nil
+}
+
+var _ bbs.LockConnector = &Connector{}
@@ -0,0 +1,95 @@
+package pttbbs
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Ptt-official-app/go-bbs"
+	"github.com/Ptt-official-app/go-bbs/filelock"
+)
+
+func TestSetLockTimeout(t *testing.T) {
+	if !filelock.Supported {
+		t.Skip("locking is not supported")
+	}
+
+	tmpfile, err := ioutil.TempFile("", "board_test_*")
+	if err != nil {
+		t.Fatalf("create tmp file error: %v", err)
+	}
+	defer os.Remove(tmpfile.Name()) // clean up
+	defer tmpfile.Close()
+
+	if err := filelock.Lock(tmpfile); err != nil {
+		t.Fatalf("Lock error: %v", err)
+	}
+
+	c := Connector{}
+	c.SetLockTimeout(30 * time.Millisecond)
+	brd, err := c.NewBoardRecord(map[string]interface{}{"board_id": "SYSOP", "title": "SYSOP"})
+	if err != nil {
+		t.Fatalf("NewBoardRecord error: %v", err)
+	}
+	err = c.AddBoardRecordFileRecord(tmpfile.Name(), brd)
+	if err != filelock.ErrTimeout {
+		t.Errorf("AddBoardRecordFileRecord should time out, got: %v", err)
+	}
+
+	filelock.Unlock(tmpfile)
+	if err := c.AddBoardRecordFileRecord(tmpfile.Name(), brd); err != nil {
+		t.Errorf("AddBoardRecordFileRecord error: %v", err)
+	}
+	headers, err := OpenBoardHeaderFile(tmpfile.Name())
+	if err != nil || len(headers) != 1 {
+		t.Errorf("expected 1 header, got: %v, err: %v", len(headers), err)
+	}
+}
+
+func TestSetLockTimeoutPerDB(t *testing.T) {
+	if !filelock.Supported {
+		t.Skip("locking is not supported")
+	}
+
+	dir := t.TempDir()
+	f, err := os.Create(filepath.Join(dir, ".BRD"))
+	if err != nil {
+		t.Fatalf("create .BRD error: %v", err)
+	}
+	defer f.Close()
+	if err := filelock.Lock(f); err != nil {
+		t.Fatalf("Lock error: %v", err)
+	}
+	defer filelock.Unlock(f)
+
+	db1, err := bbs.Open("pttbbs", "file://"+dir)
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	db2, err := bbs.Open("pttbbs", "file://"+dir)
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	if err := db1.SetLockTimeout(30 * time.Millisecond); err != nil {
+		t.Fatalf("SetLockTimeout error: %v", err)
+	}
+	// a shared connector would make db1 wait for a minute.
+	if err := db2.SetLockTimeout(time.Minute); err != nil {
+		t.Fatalf("SetLockTimeout error: %v", err)
+	}
+
+	brd, err := db1.NewBoardRecord(map[string]interface{}{"board_id": "SYSOP", "title": "SYSOP"})
+	if err != nil {
+		t.Fatalf("NewBoardRecord error: %v", err)
+	}
+	start := time.Now()
+	if err := db1.AddBoardRecord(brd); !errors.Is(err, filelock.ErrTimeout) {
+		t.Errorf("AddBoardRecord should time out, got: %v", err)
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Errorf("lock timeout of db1 should not be changed by db2, waited: %v", d)
+	}
+}
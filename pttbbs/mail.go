@@ -0,0 +1,57 @@
+package pttbbs
+
+import (
+	"github.com/Ptt-official-app/go-bbs"
+)
+
+// Sender return the owner of mail, it is the user who sent this mail.
+func (f *FileHeader) Sender() string { return f.owner }
+
+// IsRead return true if this mail has been read, mail only.
+func (f *FileHeader) IsRead() bool { return f.Filemode&FileRead != 0 }
+
+func (c *Connector) GetUserMailboxPath(userID string) (string, error) {
+	return GetUserMailPath(c.home, userID, ".DIR")
+}
+
+func (c *Connector) GetUserMailFilePath(userID string, filename string) (string, error) {
+	return GetUserMailPath(c.home, userID, filename)
+}
+
+// ReadMailIndexFile returns mail records in mailbox .DIR file, mailbox uses
+// the same fileheader_t format with board.
+func (c *Connector) ReadMailIndexFile(filename string) ([]bbs.MailRecord, error) {
+	fileHeaders, err := c.readFileHeaders(filename)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]bbs.MailRecord, len(fileHeaders))
+	for i, v := range fileHeaders {
+		ret[i] = v
+	}
+	return ret, nil
+}
+
+// ReadMailFile returns raw file of specific mail.
+func (c *Connector) ReadMailFile(filename string) ([]byte, error) {
+	return c.readFile(filename)
+}
+
+// GetUserPlanPath returns the plan file path of user.
+func (c *Connector) GetUserPlanPath(userID string) (string, error) {
+	return GetUserPlanPath(c.home, userID)
+}
+
+// GetUserFriendsPath returns the friend list file path of user.
+func (c *Connector) GetUserFriendsPath(userID string) (string, error) {
+	return GetUserFriendsPath(c.home, userID)
+}
+
+// GetUserRejectsPath returns the reject list file path of user.
+func (c *Connector) GetUserRejectsPath(userID string) (string, error) {
+	return GetUserRejectsPath(c.home, userID)
+}
+
+var _ bbs.MailConnector = &Connector{}
+var _ bbs.RelationConnector = &Connector{}
+var _ bbs.PlanConnector = &Connector{}
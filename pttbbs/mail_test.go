@@ -0,0 +1,35 @@
+package pttbbs
+
+import (
+	"testing"
+)
+
+func TestReadMailIndexFile(t *testing.T) {
+	c := Connector{home: "./testcase"}
+
+	recs, err := c.ReadMailIndexFile("testcase/file/01.DIR")
+	if err != nil {
+		t.Fatalf("ReadMailIndexFile error: %v", err)
+	}
+
+	if len(recs) != 3 {
+		t.Fatalf("len(recs) expected: 3, got: %v", len(recs))
+	}
+
+	if recs[0].Sender() != "SYSOP" {
+		t.Errorf("sender not match, expected: SYSOP, got: %v", recs[0].Sender())
+	}
+	if recs[0].Title() != "[閒聊] 自己的文章自己寫" {
+		t.Errorf("title not match, expected: [閒聊] 自己的文章自己寫, got: %v", recs[0].Title())
+	}
+	if recs[0].IsRead() {
+		t.Errorf("mail should be unread")
+	}
+}
+
+func TestMailFileHeaderIsRead(t *testing.T) {
+	f := &FileHeader{Filemode: FileRead | FileMarked}
+	if !f.IsRead() {
+		t.Errorf("mail should be read")
+	}
+}
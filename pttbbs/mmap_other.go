@@ -0,0 +1,20 @@
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!dragonfly
+
+package pttbbs
+
+import (
+	"os"
+
+	"github.com/Ptt-official-app/go-bbs"
+)
+
+// mmapSupported is true if mmapFile maps files on the platform.
+const mmapSupported = false
+
+func mmapFile(f *os.File, size int) ([]byte, error) {
+	return nil, bbs.ErrNotSupported
+}
+
+func munmapFile(b []byte) error {
+	return bbs.ErrNotSupported
+}
@@ -0,0 +1,27 @@
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package pttbbs
+
+import (
+	"fmt"
+	"os"
+
+	syscall "golang.org/x/sys/unix"
+)
+
+// mmapSupported is true if mmapFile maps files on the platform.
+const mmapSupported = true
+
+// mmapFile maps size bytes of f read-only and private, so the mapping is
+// not written back to f.
+func mmapFile(f *os.File, size int) ([]byte, error) {
+	b, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_PRIVATE)
+	if err != nil {
+		return nil, fmt.Errorf("mmap error: %w", err)
+	}
+	return b, nil
+}
+
+func munmapFile(b []byte) error {
+	return syscall.Munmap(b)
+}
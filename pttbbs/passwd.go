@@ -15,12 +15,16 @@
 package pttbbs
 
 import (
+	"github.com/Ptt-official-app/go-bbs"
 	"github.com/Ptt-official-app/go-bbs/crypt"
+	"github.com/Ptt-official-app/go-bbs/filelock"
 
 	"encoding/binary"
 	"fmt"
 	"io"
 	"log"
+	"math"
+	"net"
 	"os"
 	"strings"
 	"time"
@@ -50,7 +54,8 @@ const (
 	PosOfPasswdExMailBox    = 2 + PosOfPasswdInvisible + 1
 
 	PosOfPasswdCareer        = 4 + PosOfPasswdExMailBox + 4
-	PosOfPasswdRole          = 20 + 4 + 44 + PosOfPasswdCareer + CareerSize
+	PosOfPasswdPhone         = PosOfPasswdCareer + CareerSize
+	PosOfPasswdRole          = 4 + 44 + PosOfPasswdPhone + PhoneSize
 	PosOfPasswdLastSeen      = PosOfPasswdRole + 4
 	PosOfPasswdTimeSetAngel  = PosOfPasswdLastSeen + 4
 	PosOfPasswdTimePlayAngel = PosOfPasswdTimeSetAngel + 4
@@ -84,6 +89,10 @@ const (
 	PosOfPasswdWithMe            = PosOfPasswdChessEloRating + 2
 	PosOfPasswdTimeRemoveBadPost = PosOfPasswdWithMe + 4
 	PosOfPasswdTimeViolateLaw    = PosOfPasswdTimeRemoveBadPost + 4
+
+	// UserecRecordLength is the size of userec_t in .PASSWDS, it is padded
+	// to 512 bytes.
+	UserecRecordLength = 512
 )
 
 // https://github.com/ptt/pttbbs/blob/master/include/pttstruct.h
@@ -94,6 +103,25 @@ type UserecGameScore struct {
 	Tie  uint16
 }
 
+// UserLevel bits of userec, the standard permission bits of Maple, PermBM
+// and PermSYSOP are also in this layout.
+// See https://github.com/ptt/pttbbs/blob/master/include/perm.h
+const (
+	PermBasic     = 000000000001 // 基本權力
+	PermChat      = 000000000002 // 進入聊天室
+	PermPage      = 000000000004 // 找人聊天
+	PermPost      = 000000000010 // 發表文章
+	PermLoginOK   = 000000000020 // 註冊程序認證
+	PermMailLimit = 000000000040 // 信件無上限
+	PermCloak     = 000000000100 // 隱身術
+	PermSeeCloak  = 000000000200 // 看見忍者
+	PermXempt     = 000000000400 // 永久保留帳號
+	PermSysopHide = 000000001000 // 站長隱身術
+	PermAccounts  = 000000004000 // 帳號總管
+	PermChatroom  = 000000010000 // 聊天室總管
+	PermBoard     = 000000020000 // 看板總管
+)
+
 type Userec struct {
 	Version  uint32 // Magic Number
 	userID   string // 使用者帳號，或稱使用者 ID
@@ -122,6 +150,7 @@ type Userec struct {
 	ExMailBox uint32
 
 	Career        string
+	Phone         string
 	Role          uint32
 	LastSeen      time.Time
 	TimeSetAngel  time.Time
@@ -145,8 +174,18 @@ type Userec struct {
 	WithMe            uint32
 	TimeRemoveBadPost time.Time
 	TimeViolateLaw    time.Time
+
+	// loc is the location of the Connector which reads u, nil means
+	// Location.
+	loc *time.Location
 }
 
+var _ bbs.ParsedHostUserRecord = &Userec{}
+var _ bbs.LoginHistoryUserRecord = &Userec{}
+var _ bbs.ContactUserRecord = &Userec{}
+var _ bbs.BadPostUserRecord = &Userec{}
+var _ bbs.Over18UserRecord = &Userec{}
+
 func (u *Userec) HashedPassword() string {
 	return u.password
 }
@@ -186,48 +225,412 @@ func (u *Userec) NumPosts() int { return int(u.numPosts) }
 // Money return the money this user have.
 func (u *Userec) Money() int { return int(u.money) }
 
+// LastLogin return the last login time of user in the location of the
+// Connector which reads u, or Location.
 func (u *Userec) LastLogin() time.Time {
-	return u.lastLogin
+	return inLocation(u.lastLogin, u.loc)
 }
 
 func (u *Userec) LastHost() string {
 	return u.lastHost
 }
 
+// NumBadPosts return how many bad post this user have.
+func (u *Userec) NumBadPosts() int { return int(u.BadPost) }
+
+// IsOver18 return true if user has declared to be an adult.
+func (u *Userec) IsOver18() bool { return u.Over18 }
+
+// ContactEmail return email of user.
+func (u *Userec) ContactEmail() string { return u.Email }
+
+// ContactAddress return address of user, it is decoded from Big5-UAO.
+func (u *Userec) ContactAddress() string { return u.Address }
+
+// ContactPhone return phone number of user.
+func (u *Userec) ContactPhone() string { return u.Phone }
+
+// LoginHosts return LastHost only, pttbbs stores last login host only.
+func (u *Userec) LoginHosts() []string {
+	if !u.hasLoggedIn() {
+		return nil
+	}
+	return []string{u.lastHost}
+}
+
+// LoginTimes return LastLogin only, pttbbs stores last login time only.
+func (u *Userec) LoginTimes() []time.Time {
+	if !u.hasLoggedIn() {
+		return nil
+	}
+	return []time.Time{u.LastLogin()}
+}
+
+// hasLoggedIn returns false for new users, whose last login is 0 in file.
+func (u *Userec) hasLoggedIn() bool {
+	return u.lastHost != "" || u.lastLogin.Unix() > 0
+}
+
+// LastHostIP return LastHost as net.IP, ok is false if lastHost is not
+// a complete IP address.
+func (u *Userec) LastHostIP() (net.IP, bool) {
+	return bbs.ParseHostIP(u.lastHost)
+}
+
 // UserFlag return user setting.
 // uint32, see https://github.com/ptt/pttbbs/blob/master/include/uflags.h
 func (u *Userec) UserFlag() uint32 {
 	return u.userFlag
 }
 
+// UserFlags return bbs.UserFlag mapped from UserLevel, see PermBasic for
+// the bit layout of UserLevel in pttbbs.
+func (u *Userec) UserFlags() bbs.UserFlag {
+	perms := []struct {
+		perm uint32
+		flag bbs.UserFlag
+	}{
+		{PermBasic, bbs.UserFlagBasic},
+		{PermPost, bbs.UserFlagPost},
+		{PermLoginOK, bbs.UserFlagRegistered},
+		{PermBM, bbs.UserFlagBM},
+		{PermAccounts, bbs.UserFlagAccountAdmin},
+		{PermBoard, bbs.UserFlagBoardAdmin},
+		{PermSYSOP, bbs.UserFlagSysop},
+	}
+
+	var f bbs.UserFlag
+	for _, p := range perms {
+		if u.UserLevel&p.perm != 0 {
+			f |= p.flag
+		}
+	}
+	return f
+}
+
+var _ bbs.FlaggedUserRecord = &Userec{}
+
 func OpenUserecFile(filename string) ([]*Userec, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
+	defer file.Close()
+
+	return ReadUserecs(file)
+}
 
+// ReadUserecs reads all userec records from r, it reads the same format as
+// .PASSWDS file. On error the records before the bad one are returned with
+// it, see EachUserec.
+func ReadUserecs(r io.Reader) ([]*Userec, error) {
 	ret := []*Userec{}
 
-	for {
-		buf := make([]byte, 512)
-		_, err := file.Read(buf)
-		// log.Println(len, buf, err)
+	err := EachUserec(r, func(u *Userec) bool {
+		ret = append(ret, u)
+		return true
+	})
+	return ret, err
+}
+
+// EachUserec reads userec records from r one by one and calls fn with each
+// of them, it stops when fn returns false. Decode errors are returned as
+// *bbs.DecodeError, and so is a partial record at the end of r with
+// io.ErrUnexpectedEOF.
+func EachUserec(r io.Reader, fn func(*Userec) bool) error {
+	for i := 0; ; i++ {
+		buf := make([]byte, UserecRecordLength)
+		err := readRecord(r, buf, i)
 		if err == io.EOF {
 			break
+		} else if err != nil {
+			return err
 		}
 
 		f, err := UnmarshalUserec(buf)
 		if err != nil {
-			return nil, err
+			return newDecodeError(i, UserecRecordLength, err)
+		}
+		if !fn(f) {
+			break
+		}
+	}
+	return nil
+}
+
+// AppendUserecFileRecord appends newUserec to the end of file, the file is
+// created if it does not exist.
+func AppendUserecFileRecord(filename string, newUserec *Userec) error {
+	return appendUserec(filename, newUserec, 0)
+}
+
+func appendUserec(filename string, newUserec *Userec, lockTimeout time.Duration) error {
+	data, err := newUserec.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	_, err = appendRecordFile(filename, data, lockTimeout)
+	return err
+}
+
+// AddUserecFileRecord writes newUserec to the first free record in
+// filename, whose user id is empty, or appends it if there is no free
+// record. The file is created if it does not exist. Records are checked and
+// written under an exclusive lock, and it returns bbs.ErrUserExists without
+// writing if the user id of newUserec is used, matched case-insensitively.
+func AddUserecFileRecord(filename string, newUserec *Userec) error {
+	return addUserec(filename, newUserec, 0)
+}
+
+func addUserec(filename string, newUserec *Userec, lockTimeout time.Duration) error {
+	data, err := newUserec.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = filelock.LockTimeout(f, lockTimeout)
+	if err != nil {
+		return err
+	}
+	defer filelock.Unlock(f)
+
+	free, n := -1, 0
+	exists := false
+	err = EachUserec(f, func(u *Userec) bool {
+		if u.userID == "" {
+			if free < 0 {
+				free = n
+			}
+		} else if strings.EqualFold(u.userID, newUserec.userID) {
+			exists = true
+			return false
 		}
-		ret = append(ret, f)
-		// log.Println(f.Filename)
+		n++
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("%w: %v", bbs.ErrUserExists, newUserec.userID)
+	}
+	if free < 0 {
+		free = n
+	}
+	_, err = f.WriteAt(data, int64(free)*UserecRecordLength)
+	return err
+}
 
+// AdjustUserecFileMoney adds delta to the money of userID in filename and
+// returns the new balance, userID is matched case-insensitively. The record
+// is read and written under an exclusive lock. It returns
+// bbs.ErrMoneyOverflow if the new balance does not fit in int32.
+func AdjustUserecFileMoney(filename string, userID string, delta int) (int, error) {
+	return adjustUserecMoney(filename, userID, delta, 0)
+}
+
+func adjustUserecMoney(filename string, userID string, delta int, lockTimeout time.Duration) (int, error) {
+	f, err := os.OpenFile(filename, os.O_RDWR, 0644)
+	if err != nil {
+		return 0, err
 	}
+	defer f.Close()
 
-	return ret, nil
+	err = filelock.LockTimeout(f, lockTimeout)
+	if err != nil {
+		// File is lock
+		return 0, err
+	}
+	defer filelock.Unlock(f)
 
+	index, u, err := findUserec(f, userID)
+	if err != nil {
+		return 0, err
+	}
+	money := u.money
+
+	if (delta > 0 && int64(delta) > math.MaxInt32-int64(money)) ||
+		(delta < 0 && int64(delta) < math.MinInt32-int64(money)) {
+		return 0, fmt.Errorf("%w: %d%+d", bbs.ErrMoneyOverflow, money, delta)
+	}
+	money += int32(delta)
+
+	buf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf, uint32(money))
+	offset := int64(index)*UserecRecordLength + PosOfPasswdMoney
+	if _, err := f.WriteAt(buf, offset); err != nil {
+		return 0, err
+	}
+	return int(money), nil
+}
+
+// AdjustUserecFileBadPost adds delta to the number of bad posts of userID in
+// filename and returns the new number, which is clamped to 0 and
+// math.MaxUint8. The record is read and written under an exclusive lock.
+func AdjustUserecFileBadPost(filename string, userID string, delta int) (int, error) {
+	return updateUserecBadPost(filename, userID, func(n int) int { return n + delta }, 0)
+}
+
+// SetUserecFileBadPost sets the number of bad posts of userID in filename to
+// n, which is clamped to 0 and math.MaxUint8.
+func SetUserecFileBadPost(filename string, userID string, n int) error {
+	_, err := updateUserecBadPost(filename, userID, func(int) int { return n }, 0)
+	return err
+}
+
+func updateUserecBadPost(filename string, userID string, update func(n int) int, lockTimeout time.Duration) (int, error) {
+	f, err := os.OpenFile(filename, os.O_RDWR, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	err = filelock.LockTimeout(f, lockTimeout)
+	if err != nil {
+		return 0, err
+	}
+	defer filelock.Unlock(f)
+
+	index, u, err := findUserec(f, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	n := update(int(u.BadPost))
+	if n < 0 {
+		n = 0
+	} else if n > math.MaxUint8 {
+		n = math.MaxUint8
+	}
+
+	offset := int64(index)*UserecRecordLength + PosOfPasswdBadPost
+	if _, err := f.WriteAt([]byte{uint8(n)}, offset); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// SetUserecFileNumPosts sets the number of posts of userID in filename to n,
+// which is clamped to 0 and math.MaxUint32. The record is written under an
+// exclusive lock.
+func SetUserecFileNumPosts(filename string, userID string, n int) error {
+	return setUserecNumPosts(filename, userID, n, 0)
+}
+
+func setUserecNumPosts(filename string, userID string, n int, lockTimeout time.Duration) error {
+	if n < 0 {
+		n = 0
+	} else if int64(n) > math.MaxUint32 {
+		n = math.MaxUint32
+	}
+
+	f, err := os.OpenFile(filename, os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = filelock.LockTimeout(f, lockTimeout)
+	if err != nil {
+		return err
+	}
+	defer filelock.Unlock(f)
+
+	index, _, err := findUserec(f, userID)
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf, uint32(n))
+	offset := int64(index)*UserecRecordLength + PosOfPasswdNumPosts
+	_, err = f.WriteAt(buf, offset)
+	return err
+}
+
+// SetUserecFilePassword sets the password hash of userID in filename to
+// hashed, it returns error wrapping bbs.ErrNotSupported without writing if
+// hashed does not fit in the password field of PasswordLength bytes. The
+// record is written under an exclusive lock.
+func SetUserecFilePassword(filename string, userID string, hashed string) error {
+	return updateUserecPassword(filename, userID, func(string) (string, error) { return hashed, nil }, 0)
+}
+
+// UpdateUserecFilePassword calls update with the password hash of userID in
+// filename and writes the returned hash back, the record is read and written
+// under an exclusive lock. Nothing is written if update returns error or the
+// hash is not changed, and it returns error wrapping bbs.ErrNotSupported
+// without writing if the new hash does not fit in the password field of
+// PasswordLength bytes, so hashes longer than DES crypt can not be stored.
+func UpdateUserecFilePassword(filename string, userID string, update func(hashed string) (string, error)) error {
+	return updateUserecPassword(filename, userID, update, 0)
+}
+
+func updateUserecPassword(filename string, userID string, update func(hashed string) (string, error), lockTimeout time.Duration) error {
+	f, err := os.OpenFile(filename, os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = filelock.LockTimeout(f, lockTimeout)
+	if err != nil {
+		return err
+	}
+	defer filelock.Unlock(f)
+
+	index, u, err := findUserec(f, userID)
+	if err != nil {
+		return err
+	}
+
+	hashed, err := update(u.password)
+	if err != nil {
+		return err
+	}
+	if hashed == u.password {
+		return nil
+	}
+	if len(hashed) >= PasswordLength {
+		return fmt.Errorf("%w: hashed password is longer than %d bytes", bbs.ErrNotSupported, PasswordLength-1)
+	}
+
+	buf := make([]byte, PasswordLength)
+	copy(buf, hashed)
+	offset := int64(index)*UserecRecordLength + PosOfPasswdPassword
+	_, err = f.WriteAt(buf, offset)
+	return err
+}
+
+// findUserec returns the index and record of userID in r, userID is matched
+// case-insensitively. It returns bbs.ErrRecordNotFound if there is no such
+// user.
+func findUserec(r io.Reader, userID string) (int, *Userec, error) {
+	index := -1
+	var found *Userec
+	i := 0
+	err := EachUserec(r, func(u *Userec) bool {
+		if u.userID != "" && strings.EqualFold(u.userID, userID) {
+			index, found = i, u
+			return false
+		}
+		i++
+		return true
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	if index < 0 || userID == "" {
+		return 0, nil, fmt.Errorf("%w: user %q", bbs.ErrRecordNotFound, userID)
+	}
+	return index, found, nil
 }
 
 func UnmarshalUserec(data []byte) (*Userec, error) {
@@ -260,6 +663,7 @@ func UnmarshalUserec(data []byte) (*Userec, error) {
 	user.ExMailBox = binary.LittleEndian.Uint32(data[PosOfPasswdExMailBox : PosOfPasswdExMailBox+4])
 
 	user.Career = newStringFormBig5UAOCString(data[PosOfPasswdCareer : PosOfPasswdCareer+CareerSize])
+	user.Phone = newStringFormBig5UAOCString(data[PosOfPasswdPhone : PosOfPasswdPhone+PhoneSize])
 	user.Role = binary.LittleEndian.Uint32(data[PosOfPasswdRole : PosOfPasswdRole+4])
 	user.LastSeen = time.Unix(int64(binary.LittleEndian.Uint32(data[PosOfPasswdLastSeen:PosOfPasswdLastSeen+4])), 0)
 	user.TimeSetAngel = time.Unix(int64(binary.LittleEndian.Uint32(data[PosOfPasswdTimeSetAngel:PosOfPasswdTimeSetAngel+4])), 0)
@@ -303,7 +707,7 @@ func UnmarshalUserec(data []byte) (*Userec, error) {
 }
 
 func (u *Userec) MarshalBinary() ([]byte, error) {
-	ret := make([]byte, 512)
+	ret := make([]byte, UserecRecordLength)
 
 	binary.LittleEndian.PutUint32(ret[PosOfPasswdVersion:PosOfPasswdVersion+4], u.Version)
 	copy(ret[PosOfPasswdUserID:PosOfPasswdUserID+IDLength+1], utf8ToBig5UAOString(u.userID))
@@ -342,6 +746,7 @@ func (u *Userec) MarshalBinary() ([]byte, error) {
 	binary.LittleEndian.PutUint32(ret[PosOfPasswdExMailBox:PosOfPasswdExMailBox+4], u.ExMailBox)
 
 	copy(ret[PosOfPasswdCareer:PosOfPasswdCareer+CareerSize], utf8ToBig5UAOString(u.Career))
+	copy(ret[PosOfPasswdPhone:PosOfPasswdPhone+PhoneSize], utf8ToBig5UAOString(u.Phone))
 
 	binary.LittleEndian.PutUint32(ret[PosOfPasswdLastSeen:PosOfPasswdLastSeen+4], uint32(u.LastSeen.Unix()))
 	binary.LittleEndian.PutUint32(ret[PosOfPasswdTimeSetAngel:PosOfPasswdTimeSetAngel+4], uint32(u.TimeSetAngel.Unix()))
@@ -16,8 +16,11 @@ package pttbbs
 
 import (
 	"encoding/hex"
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/Ptt-official-app/go-bbs"
 )
 
 func TestOpenUserecFile(t *testing.T) {
@@ -636,3 +639,96 @@ b36e c5e9 0000 0000 0000 0000 0000 0000
 	}
 
 }
+
+func TestUserecUserFlags(t *testing.T) {
+	recs, err := OpenUserecFile("testcase/passwd/01.PASSWDS")
+	if err != nil {
+		t.Fatalf("OpenUserecFile error: %v", err)
+	}
+
+	// SYSOP: UserLevel 0x20000407
+	f := recs[0].UserFlags()
+	expected := bbs.UserFlagBasic | bbs.UserFlagBM
+	if f != expected {
+		t.Errorf("flags of SYSOP expected: %b, got: %b", expected, f)
+	}
+
+	// CodingMan: UserLevel 0x0000001F
+	f = recs[1].UserFlags()
+	expected = bbs.UserFlagBasic | bbs.UserFlagPost | bbs.UserFlagRegistered
+	if f != expected {
+		t.Errorf("flags of CodingMan expected: %b, got: %b", expected, f)
+	}
+	if !f.IsRegistered() || f.IsSysop() {
+		t.Errorf("CodingMan should be registered and not sysop")
+	}
+
+	u := &Userec{UserLevel: PermBasic | PermSYSOP}
+	if !u.UserFlags().IsSysop() {
+		t.Errorf("user with PermSYSOP should be sysop")
+	}
+}
+
+func TestUserecContact(t *testing.T) {
+	u := &Userec{
+		userID:  "pichu",
+		Email:   "pichu@tih.tw",
+		Address: "北市蘆洲區123路3號",
+		Phone:   "0912345678",
+	}
+
+	data, err := u.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary error: %v", err)
+	}
+	actual, err := UnmarshalUserec(data)
+	if err != nil {
+		t.Fatalf("UnmarshalUserec error: %v", err)
+	}
+	if actual.ContactEmail() != u.Email {
+		t.Errorf("ContactEmail expected: %v, got: %v", u.Email, actual.ContactEmail())
+	}
+	if actual.ContactAddress() != u.Address {
+		t.Errorf("ContactAddress expected: %v, got: %v", u.Address, actual.ContactAddress())
+	}
+	if actual.ContactPhone() != u.Phone {
+		t.Errorf("ContactPhone expected: %v, got: %v", u.Phone, actual.ContactPhone())
+	}
+}
+
+func TestUserecLoginHistory(t *testing.T) {
+	recs, err := OpenUserecFile("testcase/passwd/01.PASSWDS")
+	if err != nil {
+		t.Fatalf("OpenUserecFile error: %v", err)
+	}
+
+	hosts := recs[0].LoginHosts()
+	if len(hosts) != 1 || hosts[0] != recs[0].LastHost() {
+		t.Errorf("LoginHosts expected: [%v], got: %v", recs[0].LastHost(), hosts)
+	}
+	times := recs[0].LoginTimes()
+	if len(times) != 1 || !times[0].Equal(recs[0].LastLogin()) {
+		t.Errorf("LoginTimes expected: [%v], got: %v", recs[0].LastLogin(), times)
+	}
+
+	u := &Userec{lastLogin: time.Unix(0, 0)}
+	if u.LoginHosts() != nil || u.LoginTimes() != nil {
+		t.Errorf("new user should have no login history")
+	}
+}
+
+func TestEachUserRecordsFile(t *testing.T) {
+	c := &Connector{home: "testcase"}
+
+	ids := []string{}
+	err := c.EachUserRecordsFile("testcase/passwd/01.PASSWDS", func(u bbs.UserRecord) bool {
+		ids = append(ids, u.UserID())
+		return len(ids) < 2
+	})
+	if err != nil {
+		t.Fatalf("EachUserRecordsFile error: %v", err)
+	}
+	if !reflect.DeepEqual(ids, []string{"SYSOP", "CodingMan"}) {
+		t.Errorf("EachUserRecordsFile should stop after 2 records, got: %v", ids)
+	}
+}
@@ -52,6 +52,21 @@ func GetUserMailPath(workDirectory string, userID string, filename string) (stri
 	return fmt.Sprintf("%s/home/%c/%s/%s", workDirectory, userID[0], userID, filename), nil
 }
 
+// Get Plan file path of user, which is shown when user is queried
+func GetUserPlanPath(workDirectory string, userID string) (string, error) {
+	return fmt.Sprintf("%s/home/%c/%s/plans", workDirectory, userID[0], userID), nil
+}
+
+// GetUserFriendsPath return the friend list (好友名單) file path of user.
+func GetUserFriendsPath(workDirectory string, userID string) (string, error) {
+	return fmt.Sprintf("%s/home/%c/%s/%s", workDirectory, userID[0], userID, FileNameOverrides), nil
+}
+
+// GetUserRejectsPath return the reject list (壞人名單) file path of user.
+func GetUserRejectsPath(workDirectory string, userID string) (string, error) {
+	return fmt.Sprintf("%s/home/%c/%s/%s", workDirectory, userID[0], userID, FileNameReject), nil
+}
+
 // Get Login Recent file path of user
 func GetLoginRecentPath(workDirectory string, userID string) (string, error) {
 	return fmt.Sprintf("%s/home/%c/%s/logins.recent", workDirectory, userID[0], userID), nil
@@ -70,6 +85,19 @@ func GetBoardArticleFilePath(workDirectory string, boardID string, filename stri
 	return fmt.Sprintf("%s/boards/%c/%s/%s", workDirectory, boardID[0], boardID, filename), nil
 }
 
+// GetBoardFilePath return file path of relPath in the directory of board,
+// such as "notes" for board description. relPath should be checked before
+// calling this function.
+func GetBoardFilePath(workDirectory string, boardID string, relPath string) (string, error) {
+	return fmt.Sprintf("%s/boards/%c/%s/%s", workDirectory, boardID[0], boardID, relPath), nil
+}
+
+// GetBoardInfoPath return the info file path of board, which is shown when
+// entering the board.
+func GetBoardInfoPath(workDirectory string, boardID string) (string, error) {
+	return GetBoardFilePath(workDirectory, boardID, FileNameBoardNotes)
+}
+
 // GetBoardTreasuresDirectoryPath return dir file path of specific board and path,
 // `workDirectory` is BBSHome usually, `boardID` means which board and `path` is a slice
 // figure out each directory, eg: `["M.971228479.A", "M.1035338027.A"]` in formosa BBS or
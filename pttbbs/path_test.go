@@ -326,6 +326,47 @@ func TestGetLoginRecentPath(t *testing.T) {
 
 }
 
+func TestGetUserPlanPath(t *testing.T) {
+
+	type Input struct {
+		wd     string
+		userID string
+	}
+	type TestCase struct {
+		input    Input
+		expected string
+	}
+	cases := []TestCase{
+
+		{
+			input: Input{
+				wd:     "/root",
+				userID: "SYSOP",
+			},
+			expected: "/root/home/S/SYSOP/plans",
+		},
+		{
+			input: Input{
+				wd:     "/root",
+				userID: "sysop",
+			},
+			expected: "/root/home/s/sysop/plans",
+		},
+	}
+
+	for i, c := range cases {
+		actual, err := GetUserPlanPath(c.input.wd, c.input.userID)
+		if err != nil {
+			t.Errorf("GetUserPlanPath err != nil on index %d", i)
+		}
+		if actual != c.expected {
+			t.Errorf("GetUserPlanPath result not match on index %d with input:%v , expected: %v, got: %v",
+				i, c.input, c.expected, actual)
+		}
+	}
+
+}
+
 func TestGetBoardArticlesDirectoryPath(t *testing.T) {
 
 	type Input struct {
@@ -613,3 +654,58 @@ func TestGetBoardNameFilePath(t *testing.T) {
 	}
 
 }
+
+func TestGetBoardFilePath(t *testing.T) {
+	actual, err := GetBoardFilePath("/home/bbs", "SYSOP", "notes")
+	if err != nil {
+		t.Errorf("GetBoardFilePath err != nil: %v", err)
+	}
+	expected := "/home/bbs/boards/S/SYSOP/notes"
+	if actual != expected {
+		t.Errorf("GetBoardFilePath result not match, expected: %v, got: %v", expected, actual)
+	}
+}
+
+func TestGetBoardInfoPath(t *testing.T) {
+
+	type Input struct {
+		wd      string
+		boardID string
+	}
+	type TestCase struct {
+		input    Input
+		expected string
+	}
+	cases := []TestCase{
+
+		{
+			input: Input{
+				wd:      "/root",
+				boardID: "SYSOP",
+			},
+			expected: "/root/boards/S/SYSOP/notes",
+		},
+	}
+
+	for i, c := range cases {
+		actual, err := GetBoardInfoPath(c.input.wd, c.input.boardID)
+		if err != nil {
+			t.Errorf("GetBoardInfoPath err != nil on index %d", i)
+		}
+		if actual != c.expected {
+			t.Errorf("GetBoardInfoPath result not match on index %d with input:%v , expected: %v, got: %v",
+				i, c.input, c.expected, actual)
+		}
+	}
+}
+
+func TestGetUserRelationPath(t *testing.T) {
+	actual, err := GetUserFriendsPath("/root", "SYSOP")
+	if err != nil || actual != "/root/home/S/SYSOP/overrides" {
+		t.Errorf("GetUserFriendsPath expected: /root/home/S/SYSOP/overrides, got: %v, %v", actual, err)
+	}
+	actual, err = GetUserRejectsPath("/root", "SYSOP")
+	if err != nil || actual != "/root/home/S/SYSOP/reject" {
+		t.Errorf("GetUserRejectsPath expected: /root/home/S/SYSOP/reject, got: %v, %v", actual, err)
+	}
+}
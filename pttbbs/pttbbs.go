@@ -3,23 +3,56 @@ package pttbbs
 import (
 	"github.com/Ptt-official-app/go-bbs"
 
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"io/ioutil"
-	"os"
 	"strings"
+	"time"
 )
 
+// Connector is the bbs driver of pttbbs. Every read opens its own file, so
+// it is safe for concurrent use once configured.
 type Connector struct {
 	home string
+
+	// fsys is set when connector is opened with OpenFS, all files are read
+	// from fsys instead of disk.
+	fsys fs.FS
+
+	// lockTimeout is the timeout of locking record files on writes, 0 means
+	// waiting until the lock is acquired.
+	lockTimeout time.Duration
+
+	// passwordScheme hashes passwords of new users, nil means crypt.DES.
+	passwordScheme bbs.PasswordScheme
+
+	// favFormat is the format of favorite files, FavFormatAuto sniffs it.
+	favFormat FavFormat
+
+	// location is the time zone of times returned by records read by
+	// connector, nil means Location.
+	location *time.Location
 }
 
 func init() {
 	bbs.Register("pttbbs", &Connector{})
+	bbs.RegisterRecordCodec("pttbbs", RecordCodec)
+}
+
+// NewConnector returns a new Connector, so each DB opened by bbs.Open has
+// settings of its own, such as SetLockTimeout.
+func (c *Connector) NewConnector() bbs.Connector {
+	return &Connector{}
 }
 
+var _ bbs.ConnectorFactory = &Connector{}
+
 // Open connect a file directory or SHMs, dataSourceName pointer to bbs home
 // And it can append argument for SHM
-// for example `file:///home/bbs/?UTMP=1993`
+// for example `file:///home/bbs/?UTMP=1993`, `fork` argument selects the
+// record layout, see RecordCodec.
 func (c *Connector) Open(dataSourceName string) error {
 
 	if strings.HasPrefix(dataSourceName, "file://") {
@@ -27,8 +60,9 @@ func (c *Connector) Open(dataSourceName string) error {
 		seg := strings.Split(s, "?")
 		c.home = seg[0]
 	} else {
-		c.home = dataSourceName
+		c.home = strings.SplitN(dataSourceName, "?", 2)[0]
 	}
+	c.fsys = nil
 	return nil
 }
 
@@ -37,14 +71,37 @@ func (c *Connector) GetUserRecordsPath() (string, error) {
 }
 
 func (c *Connector) ReadUserRecordsFile(filename string) ([]bbs.UserRecord, error) {
-	rec, err := OpenUserecFile(filename)
+	f, err := c.open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	rec, err := ReadUserecs(f)
+	c.userecsIn(rec...)
 	ret := make([]bbs.UserRecord, len(rec))
 	for i, v := range rec {
 		ret[i] = v
 	}
-	return ret, err
+	return ret, withDecodePath(err, filename)
 }
 
+// EachUserRecordsFile calls fn with each UserRecord in file called name, and
+// stops when fn returns false. Only one record is held in memory at a time.
+func (c *Connector) EachUserRecordsFile(name string, fn func(bbs.UserRecord) bool) error {
+	f, err := c.open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	err = EachUserec(f, func(u *Userec) bool {
+		c.userecsIn(u)
+		return fn(u)
+	})
+	return withDecodePath(err, name)
+}
+
+var _ bbs.StreamUserRecordsConnector = &Connector{}
+
 func (c *Connector) GetUserDraftPath(userID, draftID string) (string, error) {
 	return GetUserDraftPath(c.home, userID, draftID)
 }
@@ -54,7 +111,15 @@ func (c *Connector) GetUserFavoriteRecordsPath(userID string) (string, error) {
 }
 
 func (c *Connector) ReadUserFavoriteRecordsFile(filename string) ([]bbs.FavoriteRecord, error) {
-	rec, err := OpenFavFile(filename)
+	data, err := c.readFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("pttbbs: OpenFavFile error: %w", err)
+	}
+	// user who has never saved favorites may have an empty file.
+	if len(data) == 0 {
+		return []bbs.FavoriteRecord{}, nil
+	}
+	rec, err := unmarshalFav(data, c.favFormat)
 	if err != nil {
 		return nil, fmt.Errorf("pttbbs: OpenFavFile error: %w", err)
 	}
@@ -63,7 +128,7 @@ func (c *Connector) ReadUserFavoriteRecordsFile(filename string) ([]bbs.Favorite
 	if err != nil {
 		return nil, fmt.Errorf("pttbbs: GetBoardRecordsPath error: %w", err)
 	}
-	br, err := OpenBoardHeaderFile(bPath)
+	br, err := c.readBoardHeaders(bPath)
 	if err != nil {
 		return nil, fmt.Errorf("pttbbs: ReadBoardRecordsFile error: %w", err)
 	}
@@ -80,7 +145,14 @@ func appendBoardID(folder *FavFolder, brd []*BoardHeader) {
 
 		switch item.Item.(type) {
 		case *FavBoardItem:
-			bid := item.Item.(*FavBoardItem).BoardID - 1
+			// boards in text favorite files are referred by name.
+			if item.Item.(*FavBoardItem).BoardID == 0 {
+				break
+			}
+			bid := int(item.Item.(*FavBoardItem).BoardID) - 1
+			if bid >= len(brd) {
+				break
+			}
 			item.Item.(*FavBoardItem).boardID = brd[bid].BrdName
 		case *FavFolderItem:
 			appendBoardID(item.Item.(*FavFolderItem).ThisFolder, brd)
@@ -98,7 +170,7 @@ func (c *Connector) GetBoardRecordsPath() (string, error) {
 }
 
 func (c *Connector) ReadBoardRecordsFile(path string) ([]bbs.BoardRecord, error) {
-	rec, err := OpenBoardHeaderFile(path)
+	rec, err := c.readBoardHeaders(path)
 	ret := make([]bbs.BoardRecord, len(rec))
 	for i, v := range rec {
 		ret[i] = v
@@ -106,17 +178,22 @@ func (c *Connector) ReadBoardRecordsFile(path string) ([]bbs.BoardRecord, error)
 	return ret, err
 }
 
+func (c *Connector) readBoardHeaders(path string) ([]*BoardHeader, error) {
+	f, err := c.open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	recs, err := ReadBoardHeaders(f)
+	return recs, withDecodePath(err, path)
+}
+
 func (c *Connector) GetBoardArticleRecordsPath(boardID string) (string, error) {
 	return GetBoardArticlesDirectoryPath(c.home, boardID)
 }
 
 func (c *Connector) ReadArticleRecordsFile(filename string) ([]bbs.ArticleRecord, error) {
-	var fileHeaders []*FileHeader
-	var err error
-	fileHeaders, err = OpenFileHeaderFile(filename)
-	if err != nil {
-		return nil, err
-	}
+	fileHeaders, err := c.readFileHeaders(filename)
 	ret := make([]bbs.ArticleRecord, len(fileHeaders))
 	for i, v := range fileHeaders {
 		ret[i] = v
@@ -124,6 +201,43 @@ func (c *Connector) ReadArticleRecordsFile(filename string) ([]bbs.ArticleRecord
 	return ret, err
 }
 
+// newDecodeError returns *bbs.DecodeError of the index-th record of size
+// bytes, the path is set by withDecodePath.
+func newDecodeError(index, size int, err error) error {
+	return &bbs.DecodeError{Index: index, Offset: int64(index) * int64(size), Err: err}
+}
+
+// readRecord reads the index-th record of len(buf) bytes from r into buf. It
+// returns io.EOF at the end of r, and *bbs.DecodeError of io.ErrUnexpectedEOF
+// for a partial record.
+func readRecord(r io.Reader, buf []byte, index int) error {
+	_, err := io.ReadFull(r, buf)
+	if err == io.ErrUnexpectedEOF {
+		return newDecodeError(index, len(buf), err)
+	}
+	return err
+}
+
+// withDecodePath sets path of *bbs.DecodeError in err to name.
+func withDecodePath(err error, name string) error {
+	var de *bbs.DecodeError
+	if errors.As(err, &de) {
+		de.Path = name
+	}
+	return err
+}
+
+func (c *Connector) readFileHeaders(filename string) ([]*FileHeader, error) {
+	f, err := c.open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	recs, err := ReadFileHeaders(f)
+	c.fileHeadersIn(recs...)
+	return recs, withDecodePath(err, filename)
+}
+
 func (c *Connector) GetBoardTreasureRecordsPath(boardID string, treasureID []string) (string, error) {
 	return GetBoardTreasuresDirectoryPath(c.home, boardID, treasureID)
 }
@@ -136,16 +250,41 @@ func (c *Connector) GetBoardTreasureFilePath(boardID string, treasureID []string
 	return GetBoardTreasureFilePath(c.home, boardID, treasureID, filename)
 }
 
+func (c *Connector) GetBoardFilePath(boardID string, relPath string) (string, error) {
+	return GetBoardFilePath(c.home, boardID, relPath)
+}
+
+var _ bbs.BoardFileConnector = &Connector{}
+
+// GetBoardInfoPath returns the info file path of board.
+func (c *Connector) GetBoardInfoPath(boardID string) (string, error) {
+	return GetBoardInfoPath(c.home, boardID)
+}
+
+var _ bbs.BoardInfoConnector = &Connector{}
+
 // ReadBoardArticleFile returns raw file of specific filename article.
 func (c *Connector) ReadBoardArticleFile(filename string) ([]byte, error) {
-	file, err := os.Open(filename)
+	file, err := c.open(filename)
 	if err != nil {
-		return nil, fmt.Errorf("pttbbs: open file error: %v", err)
+		return nil, fmt.Errorf("pttbbs: open file error: %w", err)
 	}
 	defer file.Close()
 	buf, err := ioutil.ReadAll(file)
 	if err != nil {
-		return nil, fmt.Errorf("pttbbs: readfile error: %v", err)
+		return nil, fmt.Errorf("pttbbs: readfile error: %w", err)
 	}
 	return buf, err
 }
+
+// OpenBoardArticleFile opens raw file of specific filename article for
+// streaming.
+func (c *Connector) OpenBoardArticleFile(filename string) (io.ReadCloser, error) {
+	file, err := c.open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("pttbbs: open file error: %w", err)
+	}
+	return file, nil
+}
+
+var _ bbs.OpenArticleFileConnector = &Connector{}
@@ -1,8 +1,19 @@
 package pttbbs
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/Ptt-official-app/go-bbs"
 )
 
 func TestReadUserFavoriteRecordsFileNoFile(t *testing.T) {
@@ -13,3 +24,239 @@ func TestReadUserFavoriteRecordsFileNoFile(t *testing.T) {
 		t.Errorf("err not return OpenFavFile")
 	}
 }
+
+func TestReadUserFavoriteRecordsSeparator(t *testing.T) {
+	brd, err := ioutil.ReadFile("testcase/board/01.BRD")
+	if err != nil {
+		t.Fatalf("read file error: %v", err)
+	}
+	fav, err := ioutil.ReadFile("testcase/fav/02.fav")
+	if err != nil {
+		t.Fatalf("read file error: %v", err)
+	}
+	headers, err := OpenBoardHeaderFile("testcase/board/01.BRD")
+	if err != nil {
+		t.Fatalf("OpenBoardHeaderFile error: %v", err)
+	}
+	fsys := fstest.MapFS{
+		".PASSWDS":          &fstest.MapFile{},
+		".BRD":              &fstest.MapFile{Data: brd},
+		"home/p/pichu/.fav": &fstest.MapFile{Data: fav},
+	}
+	db, err := bbs.OpenFS("pttbbs", fsys, ".")
+	if err != nil {
+		t.Fatalf("OpenFS error: %v", err)
+	}
+	recs, err := db.ReadUserFavoriteRecords("pichu")
+	if err != nil {
+		t.Fatalf("ReadUserFavoriteRecords error: %v", err)
+	}
+
+	var render func(recs []bbs.FavoriteRecord, indent string) string
+	render = func(recs []bbs.FavoriteRecord, indent string) string {
+		var b strings.Builder
+		for _, r := range recs {
+			switch {
+			case r.Type().IsSeparator():
+				b.WriteString(indent + "----\n")
+			case r.Type() == bbs.FavoriteTypeFolder:
+				b.WriteString(indent + "[" + r.Title() + "]\n")
+				b.WriteString(render(r.Records(), indent+"  "))
+			default:
+				b.WriteString(indent + r.BoardID() + "\n")
+			}
+		}
+		return b.String()
+	}
+
+	expected := "[新的目錄]\n" +
+		"  ----\n" +
+		"  [Folder02]\n" +
+		"    [Folder03]\n" +
+		"      [Folder04]\n" +
+		"        " + headers[13].BrdName + "\n" +
+		"      " + headers[13].BrdName + "\n" +
+		"      ----\n" +
+		"      [MAX Length:2345672234567890323456789042345678905]\n" +
+		"  ----\n" +
+		headers[12].BrdName + "\n" +
+		headers[13].BrdName + "\n" +
+		headers[5].BrdName + "\n"
+	if actual := render(recs, ""); actual != expected {
+		t.Errorf("favorite list not match, expected:\n%v\ngot:\n%v", expected, actual)
+	}
+}
+
+func TestConcurrentReadBoardArticleRecords(t *testing.T) {
+	dir, err := ioutil.TempDir("", "concurrent_read_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &Connector{home: dir}
+	boards := []string{"SYSOP", "Test", "WhoAmI", "PttNewHand", "Gossiping", "Baseball"}
+	for _, b := range boards {
+		path, _ := c.GetBoardArticleRecordsPath(b)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("MkdirAll error: %v", err)
+		}
+		for i := 0; i < 20; i++ {
+			f := NewFileHeader()
+			f.SetFilename(fmt.Sprintf("M.%d.A.%03X", 1600000000+i, i))
+			f.SetTitle(b)
+			if err := AppendFileHeaderFileRecord(path, f); err != nil {
+				t.Fatalf("AppendFileHeaderFileRecord error: %v", err)
+			}
+		}
+	}
+
+	db, err := bbs.Open("pttbbs", "file://"+dir)
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 32; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				b := boards[(g+i)%len(boards)]
+				recs, err := db.ReadBoardArticleRecordsFile(b)
+				if err != nil {
+					t.Errorf("ReadBoardArticleRecordsFile error: %v", err)
+					return
+				}
+				if len(recs) != 20 {
+					t.Errorf("records of %v expected: 20, got: %v", b, len(recs))
+					return
+				}
+				for j, r := range recs {
+					if r.Title() != b || r.Filename() != fmt.Sprintf("M.%d.A.%03X", 1600000000+j, j) {
+						t.Errorf("record %d of %v not match, got: %v %v", j, b, r.Title(), r.Filename())
+						return
+					}
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if n := db.Stats().ArticleReads; n != 32*10 {
+		t.Errorf("ArticleReads expected: %v, got: %v", 32*10, n)
+	}
+}
+
+func TestWithDecodePath(t *testing.T) {
+	err := withDecodePath(fmt.Errorf("read error: %w", newDecodeError(3, FileHeaderRecordLength, bbs.ErrUnknownFormat)), ".DIR")
+	var de *bbs.DecodeError
+	if !errors.As(err, &de) {
+		t.Fatalf("error should be DecodeError, got: %v", err)
+	}
+	if de.Path != ".DIR" || de.Index != 3 || de.Offset != 3*FileHeaderRecordLength || !errors.Is(err, bbs.ErrUnknownFormat) {
+		t.Errorf("DecodeError expected: .DIR, 3, %v, ErrUnknownFormat, got: %+v", 3*FileHeaderRecordLength, de)
+	}
+
+	if err := withDecodePath(os.ErrNotExist, ".DIR"); err != os.ErrNotExist {
+		t.Errorf("other errors should be returned as is, got: %v", err)
+	}
+}
+
+func TestWithRetryMissingArticleFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "boards", "T", "Test"), 0755); err != nil {
+		t.Fatalf("mkdir error: %v", err)
+	}
+	db, err := bbs.OpenConnector(bbs.WithRetry(&Connector{}, 3, 10*time.Second), dir)
+	if err != nil {
+		t.Fatalf("OpenConnector error: %v", err)
+	}
+
+	start := time.Now()
+	_, err = db.ReadBoardArticleFile("Test", "M.1607202239.A.30D")
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ReadBoardArticleFile of missing file should return os.ErrNotExist, got: %v", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("missing file should not be retried, took: %v", d)
+	}
+}
+
+func TestTruncatedRecordFiles(t *testing.T) {
+	dir := t.TempDir()
+	// writeTruncated writes testcase src to dst with a partial record of 10 bytes.
+	writeTruncated := func(src, dst string) {
+		data, err := ioutil.ReadFile(src)
+		if err != nil {
+			t.Fatalf("read %v error: %v", src, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			t.Fatalf("mkdir error: %v", err)
+		}
+		if err := ioutil.WriteFile(dst, append(data, "0123456789"...), 0644); err != nil {
+			t.Fatalf("write %v error: %v", dst, err)
+		}
+	}
+	writeTruncated("testcase/passwd/01.PASSWDS", filepath.Join(dir, ".PASSWDS"))
+	writeTruncated("testcase/board/01.BRD", filepath.Join(dir, ".BRD"))
+	dirPath, _ := GetBoardArticlesDirectoryPath(dir, "SYSOP")
+	writeTruncated("testcase/file/01.DIR", dirPath)
+
+	c := &Connector{home: dir}
+	users, _ := OpenUserecFile("testcase/passwd/01.PASSWDS")
+	boards, _ := OpenBoardHeaderFile("testcase/board/01.BRD")
+	articles, _ := OpenFileHeaderFile("testcase/file/01.DIR")
+	testCases := []struct {
+		path string
+		size int
+		n    int
+		read func() (int, error)
+	}{
+		{filepath.Join(dir, ".PASSWDS"), UserecRecordLength, len(users), func() (int, error) {
+			recs, err := c.ReadUserRecordsFile(filepath.Join(dir, ".PASSWDS"))
+			return len(recs), err
+		}},
+		{filepath.Join(dir, ".PASSWDS"), UserecRecordLength, len(users), func() (int, error) {
+			n := 0
+			err := c.EachUserRecordsFile(filepath.Join(dir, ".PASSWDS"), func(bbs.UserRecord) bool {
+				n++
+				return true
+			})
+			return n, err
+		}},
+		{filepath.Join(dir, ".BRD"), BoardHeaderRecordLength, len(boards), func() (int, error) {
+			recs, err := c.ReadBoardRecordsFile(filepath.Join(dir, ".BRD"))
+			return len(recs), err
+		}},
+		{dirPath, FileHeaderRecordLength, len(articles), func() (int, error) {
+			recs, err := c.ReadArticleRecordsFile(dirPath)
+			return len(recs), err
+		}},
+	}
+	for _, tc := range testCases {
+		n, err := tc.read()
+		var de *bbs.DecodeError
+		if !errors.As(err, &de) || !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("partial record of %v should return DecodeError of io.ErrUnexpectedEOF, got: %v", tc.path, err)
+			continue
+		}
+		if de.Path != tc.path || de.Index != tc.n || de.Offset != int64(tc.n*tc.size) {
+			t.Errorf("DecodeError expected: %v, %v, %v, got: %+v", tc.path, tc.n, tc.n*tc.size, de)
+		}
+		if n != tc.n {
+			t.Errorf("records before partial record of %v expected: %v, got: %v", tc.path, tc.n, n)
+		}
+	}
+
+	db, err := bbs.OpenConnector(c, "file://"+dir)
+	if err != nil {
+		t.Fatalf("OpenConnector error: %v", err)
+	}
+	report, err := db.VerifyBoardArticleRecords("SYSOP")
+	if err != nil {
+		t.Fatalf("VerifyBoardArticleRecords error: %v", err)
+	}
+	if report.NumRecords != len(articles) || len(report.Problems) != 1 || report.Problems[0].Index != len(articles) {
+		t.Errorf("VerifyBoardArticleRecords should report the partial record, got: %+v", report)
+	}
+}
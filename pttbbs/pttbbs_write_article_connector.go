@@ -12,7 +12,12 @@ import (
 
 func (c *Connector) NewArticleRecord(args map[string]interface{}) (bbs.ArticleRecord, error) {
 
+	if err := c.checkWritable(); err != nil {
+		return nil, err
+	}
+
 	record := NewFileHeader()
+	c.fileHeadersIn(record)
 
 	owner, ok := args["owner"].(string)
 	if !ok {
@@ -80,5 +85,8 @@ func (c *Connector) AddArticleRecordFileRecord(name string, article bbs.ArticleR
 	if !ok {
 		return fmt.Errorf("article should be create with NewArticleRecord")
 	}
-	return AppendFileHeaderFileRecord(name, a)
+	if err := c.checkWritable(); err != nil {
+		return err
+	}
+	return appendFileHeader(name, a, c.lockTimeout)
 }
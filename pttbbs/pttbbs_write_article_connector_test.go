@@ -9,7 +9,7 @@ import (
 
 func TestNewArticleRecord(t *testing.T) {
 
-	c := Connector{"./testcase"}
+	c := Connector{home: "./testcase"}
 
 	input := map[string]interface{}{
 		"board_id": "SYSOP",
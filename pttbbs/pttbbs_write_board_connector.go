@@ -3,7 +3,9 @@ package pttbbs
 import (
 	"github.com/Ptt-official-app/go-bbs"
 
+	"errors"
 	"fmt"
+	"io/fs"
 )
 
 func (c *Connector) NewBoardRecord(args map[string]interface{}) (bbs.BoardRecord, error) {
@@ -33,23 +35,102 @@ func (c *Connector) AddBoardRecordFileRecord(name string, brd bbs.BoardRecord) e
 		return fmt.Errorf("brd should be create with NewBoardRecord")
 
 	}
-	return AppendBoardHeaderFileRecord(name, b)
+	if err := c.checkWritable(); err != nil {
+		return err
+	}
+	_, err := appendBoardHeaders(name, []*BoardHeader{b}, c.lockTimeout)
+	return err
+}
+
+// AddBoardRecordFileRecords appends brds to record file name in a single
+// pass, nothing is written if any of brds is not created by NewBoardRecord.
+// The returned index is counted while the file is locked.
+func (c *Connector) AddBoardRecordFileRecords(name string, brds []bbs.BoardRecord) (uint, error) {
+	hdrs := make([]*BoardHeader, len(brds))
+	for i, brd := range brds {
+		b, ok := brd.(*BoardHeader)
+		if !ok {
+			return 0, fmt.Errorf("brd should be create with NewBoardRecord")
+		}
+		hdrs[i] = b
+	}
+	if err := c.checkWritable(); err != nil {
+		return 0, err
+	}
+	return appendBoardHeaders(name, hdrs, c.lockTimeout)
 }
 
 // UpdateBoardRecordFileRecord update boardRecord brd on index in record file,
-// index is start with 0
+// index is start with 0, see UpdateBoardHeaderFileRecord.
 func (c *Connector) UpdateBoardRecordFileRecord(name string, index uint, brd bbs.BoardRecord) error {
-	return fmt.Errorf("not implement")
+	b, ok := brd.(*BoardHeader)
+	if !ok {
+		return fmt.Errorf("brd should be create with NewBoardRecord")
+	}
+	if err := c.checkWritable(); err != nil {
+		return err
+	}
+	return updateBoardHeader(name, int(index), b, c.lockTimeout)
 }
 
-// ReadBoardRecordFileRecord return boardRecord brd on index in record file.
+// ReadBoardRecordFileRecord return boardRecord brd on index in record file,
+// only that record is read. It returns bbs.ErrRecordNotFound if index is out
+// of range.
 func (c *Connector) ReadBoardRecordFileRecord(name string, index uint) (bbs.BoardRecord, error) {
-	return nil, fmt.Errorf("not implement")
+	data, err := c.ReadRecordFileRange(name, int64(index)*BoardHeaderRecordLength, BoardHeaderRecordLength)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < BoardHeaderRecordLength {
+		return nil, fmt.Errorf("%w: board record %d of %q", bbs.ErrRecordNotFound, index, name)
+	}
+	b, err := UnmarshalBoardHeader(data)
+	if err != nil {
+		return nil, &bbs.DecodeError{Path: name, Index: int(index), Offset: int64(index) * BoardHeaderRecordLength, Err: err}
+	}
+	return b, nil
 }
 
-// RemoveBoardRecordFileRecord remove boardRecord brd on index in record file.
+// RemoveBoardRecordFileRecord remove boardRecord brd on index in record file,
+// see RemoveBoardHeaderFileRecord.
 func (c *Connector) RemoveBoardRecordFileRecord(name string, index uint) error {
-	return fmt.Errorf("not implement")
+	if err := c.checkWritable(); err != nil {
+		return err
+	}
+	return removeBoardHeader(name, int(index), c.lockTimeout)
+}
+
+// MoveBoardRecordFileRecord moves boardRecord on index from to index to in
+// record file, see MoveBoardHeaderFileRecord.
+func (c *Connector) MoveBoardRecordFileRecord(name string, from, to uint) error {
+	if err := c.checkWritable(); err != nil {
+		return err
+	}
+	return moveBoardHeader(name, int(from), int(to), c.lockTimeout)
+}
+
+// NumBoardRecordFileRecord returns the number of board records in record
+// file from the file size, a partial record at the end is not counted. It
+// returns 0 if file does not exist.
+func (c *Connector) NumBoardRecordFileRecord(name string) (uint, error) {
+	stat, err := c.stat(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return uint(stat.Size() / BoardHeaderRecordLength), nil
 }
 
 var _ bbs.WriteBoardConnector = &Connector{}
+
+// ReplaceBoardDirs replaces the board directories dirs with the staged
+// directories, see bbs.ReplaceDirs.
+func (c *Connector) ReplaceBoardDirs(dirs, staged []string) error {
+	if err := c.checkWritable(); err != nil {
+		return err
+	}
+	return bbs.ReplaceDirs(dirs, staged)
+}
+
+var _ bbs.ImportBoardConnector = &Connector{}
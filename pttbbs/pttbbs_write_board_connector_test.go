@@ -0,0 +1,100 @@
+package pttbbs
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/Ptt-official-app/go-bbs"
+)
+
+func TestDryRunBoardRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "write_board_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	expected, err := ioutil.ReadFile("testcase/board/01.BRD")
+	if err != nil {
+		t.Fatalf("read testcase error: %v", err)
+	}
+	path := filepath.Join(dir, ".BRD")
+	if err := ioutil.WriteFile(path, expected, 0644); err != nil {
+		t.Fatalf("write .BRD error: %v", err)
+	}
+
+	db, err := bbs.OpenConnector(&Connector{}, dir)
+	if err != nil {
+		t.Fatalf("OpenConnector error: %v", err)
+	}
+	db.SetDryRun(true)
+
+	brd, err := db.NewBoardRecord(map[string]interface{}{"board_id": "DryRun", "title": "dry run"})
+	if err != nil {
+		t.Fatalf("NewBoardRecord error: %v", err)
+	}
+	if err := db.AddBoardRecord(brd); err != nil {
+		t.Errorf("AddBoardRecord error: %v", err)
+	}
+	if err := db.UpdateBoardRecord(0, &brd); err != nil {
+		t.Errorf("UpdateBoardRecord error: %v", err)
+	}
+	if err := db.RemoveBoardRecord(0); err != nil {
+		t.Errorf("RemoveBoardRecord error: %v", err)
+	}
+
+	actual, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read .BRD error: %v", err)
+	}
+	if !bytes.Equal(actual, expected) {
+		t.Errorf(".BRD should be unchanged in dry-run")
+	}
+}
+
+func TestAddBoardRecordFileRecordsIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "write_board_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".BRD")
+
+	// Connectors share the file but not memory, like separate processes.
+	const n = 20
+	indices := make([]uint, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := &Connector{}
+			brd := &BoardHeader{BrdName: fmt.Sprintf("Board%d", i)}
+			indices[i], errs[i] = c.AddBoardRecordFileRecords(path, []bbs.BoardRecord{brd})
+		}(i)
+	}
+	wg.Wait()
+
+	headers, err := OpenBoardHeaderFile(path)
+	if err != nil {
+		t.Fatalf("OpenBoardHeaderFile error: %v", err)
+	}
+	if len(headers) != n {
+		t.Fatalf("len(headers) expected: %v, got: %v", n, len(headers))
+	}
+	for i := 0; i < n; i++ {
+		if errs[i] != nil {
+			t.Fatalf("AddBoardRecordFileRecords error: %v", errs[i])
+		}
+		expected := fmt.Sprintf("Board%d", i)
+		if actual := headers[indices[i]].BrdName; actual != expected {
+			t.Errorf("record on returned index %d expected: %v, got: %v", indices[i], expected, actual)
+		}
+	}
+}
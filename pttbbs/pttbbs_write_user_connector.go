@@ -0,0 +1,161 @@
+package pttbbs
+
+import (
+	"crypto/rand"
+	"fmt"
+	"time"
+
+	"github.com/Ptt-official-app/go-bbs"
+	"github.com/Ptt-official-app/go-bbs/crypt"
+)
+
+const saltChars = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// NewUserRecord returns a new Userec with arguments, "user_id" and "password"
+// are required, "nickname" and "realname" are optional. Password is hashed
+// with crypt and a random salt, user has 1 login day, 0 posts, 0 money and
+// PermBasic by default.
+func (c *Connector) NewUserRecord(args map[string]interface{}) (bbs.UserRecord, error) {
+	userID, ok := args["user_id"].(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("NewUserRecord: user_id must not be empty")
+	}
+	if len(userID) > IDLength {
+		return nil, fmt.Errorf("NewUserRecord: user_id must not be longer than %d", IDLength)
+	}
+
+	password, ok := args["password"].(string)
+	if !ok || password == "" {
+		return nil, fmt.Errorf("NewUserRecord: password must not be empty")
+	}
+	hashed, err := c.hashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("NewUserRecord: hash password error: %w", err)
+	}
+
+	now := time.Now()
+	u := &Userec{
+		Version:      PasswdVersion,
+		userID:       userID,
+		password:     hashed,
+		UserLevel:    PermBasic,
+		numLoginDays: 1,
+		firstLogin:   now,
+		lastLogin:    now,
+		loc:          c.location,
+	}
+	if nickname, ok := args["nickname"].(string); ok {
+		u.nickname = nickname
+	}
+	if realName, ok := args["realname"].(string); ok {
+		u.realName = realName
+	}
+	return u, nil
+}
+
+// AddUserRecordFileRecord given record file name and new record, writes
+// record to the first free slot of that file or appends it, see
+// AddUserecFileRecord.
+func (c *Connector) AddUserRecordFileRecord(name string, u bbs.UserRecord) error {
+	rec, ok := u.(*Userec)
+	if !ok {
+		return fmt.Errorf("user should be create with NewUserRecord")
+	}
+	if err := c.checkWritable(); err != nil {
+		return err
+	}
+	return addUserec(name, rec, c.lockTimeout)
+}
+
+// AdjustUserRecordFileMoney adds delta to the money of userID in record file
+// name and returns the new balance, see AdjustUserecFileMoney.
+func (c *Connector) AdjustUserRecordFileMoney(name string, userID string, delta int) (int, error) {
+	if err := c.checkWritable(); err != nil {
+		return 0, err
+	}
+	return adjustUserecMoney(name, userID, delta, c.lockTimeout)
+}
+
+// AdjustUserRecordFileNumBadPosts adds delta to the number of bad posts of
+// userID in record file name, see AdjustUserecFileBadPost.
+func (c *Connector) AdjustUserRecordFileNumBadPosts(name string, userID string, delta int) (int, error) {
+	if err := c.checkWritable(); err != nil {
+		return 0, err
+	}
+	return updateUserecBadPost(name, userID, func(n int) int { return n + delta }, c.lockTimeout)
+}
+
+// SetUserRecordFileNumBadPosts sets the number of bad posts of userID in
+// record file name, see SetUserecFileBadPost.
+func (c *Connector) SetUserRecordFileNumBadPosts(name string, userID string, n int) error {
+	if err := c.checkWritable(); err != nil {
+		return err
+	}
+	_, err := updateUserecBadPost(name, userID, func(int) int { return n }, c.lockTimeout)
+	return err
+}
+
+// SetUserRecordFileNumPosts sets the number of posts of userID in record
+// file name, see SetUserecFileNumPosts.
+func (c *Connector) SetUserRecordFileNumPosts(name string, userID string, n int) error {
+	if err := c.checkWritable(); err != nil {
+		return err
+	}
+	return setUserecNumPosts(name, userID, n, c.lockTimeout)
+}
+
+// SetUserRecordFilePassword sets the password hash of userID in record
+// file name, see SetUserecFilePassword.
+func (c *Connector) SetUserRecordFilePassword(name string, userID string, hashed string) error {
+	if err := c.checkWritable(); err != nil {
+		return err
+	}
+	return updateUserecPassword(name, userID, func(string) (string, error) { return hashed, nil }, c.lockTimeout)
+}
+
+// UpdateUserRecordFilePassword calls update with the password hash of
+// userID in record file name and writes the returned hash back under the
+// record lock, see UpdateUserecFilePassword.
+func (c *Connector) UpdateUserRecordFilePassword(name string, userID string, update func(hashed string) (string, error)) error {
+	if err := c.checkWritable(); err != nil {
+		return err
+	}
+	return updateUserecPassword(name, userID, update, c.lockTimeout)
+}
+
+// SetPasswordScheme sets the scheme of hashing passwords in NewUserRecord,
+// nil means crypt.DES, the scheme of pttbbs. The hash should fit in the
+// password field of PasswordLength bytes.
+func (c *Connector) SetPasswordScheme(s bbs.PasswordScheme) {
+	c.passwordScheme = s
+}
+
+func (c *Connector) hashPassword(password string) (string, error) {
+	var s bbs.PasswordScheme = crypt.DES{}
+	if c.passwordScheme != nil {
+		s = c.passwordScheme
+	}
+
+	salt := make([]byte, 8)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	for i, b := range salt {
+		salt[i] = saltChars[int(b)%len(saltChars)]
+	}
+	hashed, err := s.Hash(password, string(salt))
+	if err != nil {
+		return "", err
+	}
+	if len(hashed) >= PasswordLength {
+		return "", fmt.Errorf("hashed password is longer than %d bytes", PasswordLength-1)
+	}
+	return hashed, nil
+}
+
+var _ bbs.WriteUserConnector = &Connector{}
+var _ bbs.MoneyConnector = &Connector{}
+var _ bbs.BadPostConnector = &Connector{}
+var _ bbs.NumPostsConnector = &Connector{}
+var _ bbs.PasswordSchemeConnector = &Connector{}
+var _ bbs.PasswordConnector = &Connector{}
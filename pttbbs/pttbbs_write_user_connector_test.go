@@ -0,0 +1,440 @@
+package pttbbs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Ptt-official-app/go-bbs"
+	"github.com/Ptt-official-app/go-bbs/crypt"
+)
+
+func TestCreateUserRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "write_user_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &Connector{home: dir}
+	u, err := c.NewUserRecord(map[string]interface{}{
+		"user_id":  "pichu",
+		"password": "123456",
+		"nickname": "皮丘",
+	})
+	if err != nil {
+		t.Fatalf("NewUserRecord error: %v", err)
+	}
+	if err := u.VerifyPassword("123456"); err != nil {
+		t.Errorf("VerifyPassword error: %v", err)
+	}
+	if u.NumLoginDays() != 1 || u.NumPosts() != 0 || u.Money() != 0 {
+		t.Errorf("default values not match, got: %v, %v, %v", u.NumLoginDays(), u.NumPosts(), u.Money())
+	}
+
+	path := filepath.Join(dir, ".PASSWDS")
+	if err := c.AddUserRecordFileRecord(path, u); err != nil {
+		t.Fatalf("AddUserRecordFileRecord error: %v", err)
+	}
+	recs, err := c.ReadUserRecordsFile(path)
+	if err != nil {
+		t.Fatalf("ReadUserRecordsFile error: %v", err)
+	}
+	if len(recs) != 1 || recs[0].UserID() != "pichu" || recs[0].Nickname() != "皮丘" {
+		t.Errorf("user record not match, got: %v", recs)
+	}
+	if err := recs[0].VerifyPassword("123456"); err != nil {
+		t.Errorf("VerifyPassword of read record error: %v", err)
+	}
+
+	if _, err := c.NewUserRecord(map[string]interface{}{"user_id": "pichu"}); err == nil {
+		t.Errorf("NewUserRecord without password should return error")
+	}
+}
+
+func TestAddUserRecordFileRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "write_user_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &Connector{home: dir}
+	path := filepath.Join(dir, ".PASSWDS")
+	// .PASSWDS of pttbbs is preallocated with empty records.
+	if err := ioutil.WriteFile(path, make([]byte, 3*UserecRecordLength), 0644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+	for _, id := range []string{"SYSOP", "pichu"} {
+		u, err := c.NewUserRecord(map[string]interface{}{"user_id": id, "password": "123456"})
+		if err != nil {
+			t.Fatalf("NewUserRecord error: %v", err)
+		}
+		if err := c.AddUserRecordFileRecord(path, u); err != nil {
+			t.Fatalf("AddUserRecordFileRecord error: %v", err)
+		}
+	}
+	recs, err := c.ReadUserRecordsFile(path)
+	if err != nil {
+		t.Fatalf("ReadUserRecordsFile error: %v", err)
+	}
+	if len(recs) != 3 || recs[0].UserID() != "SYSOP" || recs[1].UserID() != "pichu" || recs[2].UserID() != "" {
+		t.Errorf("users should be written to free records, got: %v", recs)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			u, err := c.NewUserRecord(map[string]interface{}{"user_id": "Raichu", "password": "123456"})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = c.AddUserRecordFileRecord(path, u)
+		}(i)
+	}
+	wg.Wait()
+	created := 0
+	for _, err := range errs {
+		if err == nil {
+			created++
+		} else if !errors.Is(err, bbs.ErrUserExists) {
+			t.Errorf("AddUserRecordFileRecord should return ErrUserExists, got: %v", err)
+		}
+	}
+	if created != 1 {
+		t.Errorf("concurrent creates expected 1 user, got: %v", created)
+	}
+	recs, err = c.ReadUserRecordsFile(path)
+	if err != nil {
+		t.Fatalf("ReadUserRecordsFile error: %v", err)
+	}
+	if len(recs) != 3 || recs[2].UserID() != "Raichu" {
+		t.Errorf("user should be written to the last free record, got: %v", recs)
+	}
+}
+
+func TestAdjustUserRecordFileMoney(t *testing.T) {
+	dir, err := ioutil.TempDir("", "write_user_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &Connector{home: dir}
+	path := filepath.Join(dir, ".PASSWDS")
+	for _, id := range []string{"SYSOP", "pichu"} {
+		u, err := c.NewUserRecord(map[string]interface{}{"user_id": id, "password": "123456"})
+		if err != nil {
+			t.Fatalf("NewUserRecord error: %v", err)
+		}
+		if err := c.AddUserRecordFileRecord(path, u); err != nil {
+			t.Fatalf("AddUserRecordFileRecord error: %v", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.AdjustUserRecordFileMoney(path, "PICHU", 10); err != nil {
+				t.Errorf("AdjustUserRecordFileMoney error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	money, err := c.AdjustUserRecordFileMoney(path, "pichu", -30)
+	if err != nil || money != 70 {
+		t.Errorf("AdjustUserRecordFileMoney expected: 70, <nil>, got: %v, %v", money, err)
+	}
+
+	_, err = c.AdjustUserRecordFileMoney(path, "pichu", math.MaxInt32)
+	if !errors.Is(err, bbs.ErrMoneyOverflow) {
+		t.Errorf("AdjustUserRecordFileMoney should return ErrMoneyOverflow, got: %v", err)
+	}
+	_, err = c.AdjustUserRecordFileMoney(path, "nobody", 1)
+	if !errors.Is(err, bbs.ErrRecordNotFound) {
+		t.Errorf("AdjustUserRecordFileMoney should return ErrRecordNotFound, got: %v", err)
+	}
+
+	recs, err := c.ReadUserRecordsFile(path)
+	if err != nil {
+		t.Fatalf("ReadUserRecordsFile error: %v", err)
+	}
+	if recs[0].Money() != 0 || recs[1].Money() != 70 {
+		t.Errorf("money expected: 0, 70, got: %v, %v", recs[0].Money(), recs[1].Money())
+	}
+}
+
+func TestAdjustBadPosts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "write_user_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &Connector{home: dir}
+	path := filepath.Join(dir, ".PASSWDS")
+	for _, id := range []string{"SYSOP", "pichu"} {
+		u, err := c.NewUserRecord(map[string]interface{}{"user_id": id, "password": "123456"})
+		if err != nil {
+			t.Fatalf("NewUserRecord error: %v", err)
+		}
+		if err := c.AddUserRecordFileRecord(path, u); err != nil {
+			t.Fatalf("AddUserRecordFileRecord error: %v", err)
+		}
+	}
+
+	db, err := bbs.Open("pttbbs", "file://"+dir)
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	numBadPosts := func() int {
+		u, err := db.GetUserRecord("pichu")
+		if err != nil {
+			t.Fatalf("GetUserRecord error: %v", err)
+		}
+		return u.(bbs.BadPostUserRecord).NumBadPosts()
+	}
+
+	if err := db.SetNumBadPosts("pichu", 3); err != nil {
+		t.Fatalf("SetNumBadPosts error: %v", err)
+	}
+	if n := numBadPosts(); n != 3 {
+		t.Errorf("NumBadPosts expected: 3, got: %v", n)
+	}
+	if err := db.AdjustBadPosts("PICHU", 2); err != nil {
+		t.Fatalf("AdjustBadPosts error: %v", err)
+	}
+	if n := numBadPosts(); n != 5 {
+		t.Errorf("NumBadPosts expected: 5, got: %v", n)
+	}
+	if err := db.AdjustBadPosts("pichu", -10); err != nil {
+		t.Fatalf("AdjustBadPosts error: %v", err)
+	}
+	if n := numBadPosts(); n != 0 {
+		t.Errorf("NumBadPosts should be clamped at 0, got: %v", n)
+	}
+	if err := db.AdjustBadPosts("nobody", 1); !errors.Is(err, bbs.ErrRecordNotFound) {
+		t.Errorf("AdjustBadPosts should return ErrRecordNotFound, got: %v", err)
+	}
+
+	recs, err := c.ReadUserRecordsFile(path)
+	if err != nil {
+		t.Fatalf("ReadUserRecordsFile error: %v", err)
+	}
+	if recs[0].(bbs.BadPostUserRecord).NumBadPosts() != 0 {
+		t.Errorf("bad posts of other user should not be changed")
+	}
+}
+
+type upperScheme struct{}
+
+func (upperScheme) Hash(plain, salt string) (string, error) { return strings.ToUpper(plain), nil }
+func (upperScheme) Verify(plain, hashed string) error       { return nil }
+
+func TestSetPasswordScheme(t *testing.T) {
+	c := &Connector{}
+	c.SetPasswordScheme(upperScheme{})
+	u, err := c.NewUserRecord(map[string]interface{}{"user_id": "pichu", "password": "abc"})
+	if err != nil {
+		t.Fatalf("NewUserRecord error: %v", err)
+	}
+	if u.HashedPassword() != "ABC" {
+		t.Errorf("hashed password expected: ABC, got: %v", u.HashedPassword())
+	}
+
+	c.SetPasswordScheme(crypt.PBKDF2SHA256{Iterations: 1})
+	if _, err := c.NewUserRecord(map[string]interface{}{"user_id": "pichu", "password": "abc"}); err == nil {
+		t.Errorf("NewUserRecord should return error if hash does not fit in password field")
+	}
+
+	c.SetPasswordScheme(nil)
+	u, err = c.NewUserRecord(map[string]interface{}{"user_id": "pichu", "password": "abc"})
+	if err != nil {
+		t.Fatalf("NewUserRecord error: %v", err)
+	}
+	if err := (crypt.DES{}).Verify("abc", u.HashedPassword()); err != nil {
+		t.Errorf("default scheme should be DES, Verify error: %v", err)
+	}
+}
+
+func TestSetUserRecordFileNumPosts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "write_user_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &Connector{home: dir}
+	path := filepath.Join(dir, ".PASSWDS")
+	for _, id := range []string{"SYSOP", "pichu"} {
+		u, err := c.NewUserRecord(map[string]interface{}{"user_id": id, "password": "123456"})
+		if err != nil {
+			t.Fatalf("NewUserRecord error: %v", err)
+		}
+		if err := c.AddUserRecordFileRecord(path, u); err != nil {
+			t.Fatalf("AddUserRecordFileRecord error: %v", err)
+		}
+	}
+
+	if err := c.SetUserRecordFileNumPosts(path, "PICHU", 42); err != nil {
+		t.Fatalf("SetUserRecordFileNumPosts error: %v", err)
+	}
+	if err := SetUserecFileNumPosts(path, "SYSOP", -1); err != nil {
+		t.Fatalf("SetUserecFileNumPosts error: %v", err)
+	}
+	if err := c.SetUserRecordFileNumPosts(path, "nobody", 1); !errors.Is(err, bbs.ErrRecordNotFound) {
+		t.Errorf("SetUserRecordFileNumPosts should return ErrRecordNotFound, got: %v", err)
+	}
+
+	recs, err := c.ReadUserRecordsFile(path)
+	if err != nil {
+		t.Fatalf("ReadUserRecordsFile error: %v", err)
+	}
+	if recs[0].NumPosts() != 0 || recs[1].NumPosts() != 42 {
+		t.Errorf("NumPosts expected: 0, 42, got: %v, %v", recs[0].NumPosts(), recs[1].NumPosts())
+	}
+}
+
+func TestSetUserRecordFilePassword(t *testing.T) {
+	dir, err := ioutil.TempDir("", "write_user_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &Connector{home: dir}
+	path := filepath.Join(dir, ".PASSWDS")
+	u, err := c.NewUserRecord(map[string]interface{}{"user_id": "pichu", "password": "123456"})
+	if err != nil {
+		t.Fatalf("NewUserRecord error: %v", err)
+	}
+	if err := c.AddUserRecordFileRecord(path, u); err != nil {
+		t.Fatalf("AddUserRecordFileRecord error: %v", err)
+	}
+
+	hashed, err := crypt.DES{}.Hash("654321", "ab")
+	if err != nil {
+		t.Fatalf("Hash error: %v", err)
+	}
+	if err := c.SetUserRecordFilePassword(path, "PICHU", hashed); err != nil {
+		t.Fatalf("SetUserRecordFilePassword error: %v", err)
+	}
+	if err := SetUserecFilePassword(path, "pichu", "$pbkdf2-sha256$1$salt$toolong"); err == nil {
+		t.Errorf("SetUserecFilePassword should return error for hash longer than password field")
+	}
+	if err := c.SetUserRecordFilePassword(path, "nobody", hashed); !errors.Is(err, bbs.ErrRecordNotFound) {
+		t.Errorf("SetUserRecordFilePassword should return ErrRecordNotFound, got: %v", err)
+	}
+
+	recs, err := c.ReadUserRecordsFile(path)
+	if err != nil {
+		t.Fatalf("ReadUserRecordsFile error: %v", err)
+	}
+	if recs[0].HashedPassword() != hashed || recs[0].VerifyPassword("654321") != nil {
+		t.Errorf("password should be rewritten, got hash: %q", recs[0].HashedPassword())
+	}
+}
+
+// shortScheme is a password scheme whose hash fits in the password field of
+// pttbbs, hashes are "$s$" followed by 2 characters of salt and 8 hex digits.
+type shortScheme struct{}
+
+func (shortScheme) Hash(plain, salt string) (string, error) {
+	sum := sha256.Sum256([]byte(salt[:2] + plain))
+	return "$s$" + salt[:2] + hex.EncodeToString(sum[:])[:8], nil
+}
+
+func (s shortScheme) Verify(plain, hashed string) error {
+	if !strings.HasPrefix(hashed, "$s$") || len(hashed) != 13 {
+		return crypt.ErrPasswordMismatch
+	}
+	if h, _ := s.Hash(plain, hashed[3:5]); h != hashed {
+		return crypt.ErrPasswordMismatch
+	}
+	return nil
+}
+
+// desOrShortScheme verifies hashes of both shortScheme and crypt.DES.
+type desOrShortScheme struct{ shortScheme }
+
+func (s desOrShortScheme) Verify(plain, hashed string) error {
+	if strings.HasPrefix(hashed, "$s$") {
+		return s.shortScheme.Verify(plain, hashed)
+	}
+	return crypt.DES{}.Verify(plain, hashed)
+}
+
+func TestUpgradePasswordOnVerify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "write_user_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &Connector{home: dir}
+	path := filepath.Join(dir, ".PASSWDS")
+	u, err := c.NewUserRecord(map[string]interface{}{"user_id": "pichu", "password": "123456"})
+	if err != nil {
+		t.Fatalf("NewUserRecord error: %v", err)
+	}
+	if err := c.AddUserRecordFileRecord(path, u); err != nil {
+		t.Fatalf("AddUserRecordFileRecord error: %v", err)
+	}
+	old := u.HashedPassword()
+
+	pbkdf2 := crypt.PBKDF2SHA256{Iterations: 1}
+	db, err := bbs.Open("pttbbs", "file://"+dir, bbs.WithPasswordScheme(crypt.Mixed{PBKDF2SHA256: pbkdf2}))
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	upgraded, err := db.UpgradePasswordOnVerify("pichu", "123456", pbkdf2)
+	if upgraded || !errors.Is(err, bbs.ErrNotSupported) {
+		t.Errorf("upgrading to PBKDF2 expected: false, ErrNotSupported, got: %v, %v", upgraded, err)
+	}
+
+	db.SetPasswordScheme(desOrShortScheme{})
+	upgraded, err = db.UpgradePasswordOnVerify("pichu", "654321", shortScheme{})
+	if upgraded || !errors.Is(err, crypt.ErrPasswordMismatch) {
+		t.Errorf("wrong password expected: false, ErrPasswordMismatch, got: %v, %v", upgraded, err)
+	}
+	recs, err := c.ReadUserRecordsFile(path)
+	if err != nil {
+		t.Fatalf("ReadUserRecordsFile error: %v", err)
+	}
+	if recs[0].HashedPassword() != old {
+		t.Errorf("hash should not be changed, got: %q", recs[0].HashedPassword())
+	}
+
+	upgraded, err = db.UpgradePasswordOnVerify("PICHU", "123456", shortScheme{})
+	if !upgraded || err != nil {
+		t.Fatalf("UpgradePasswordOnVerify expected: true, <nil>, got: %v, %v", upgraded, err)
+	}
+	got, err := db.GetUserRecord("pichu")
+	if err != nil {
+		t.Fatalf("GetUserRecord error: %v", err)
+	}
+	if err := (shortScheme{}).Verify("123456", got.HashedPassword()); err != nil {
+		t.Errorf("hash should be upgraded, got: %q", got.HashedPassword())
+	}
+	if err := db.VerifyUserPassword(got, "123456"); err != nil {
+		t.Errorf("VerifyUserPassword of upgraded hash error: %v", err)
+	}
+	upgraded, err = db.UpgradePasswordOnVerify("pichu", "123456", shortScheme{})
+	if upgraded || err != nil {
+		t.Errorf("upgraded hash expected: false, <nil>, got: %v, %v", upgraded, err)
+	}
+}
@@ -0,0 +1,81 @@
+package pttbbs
+
+import (
+	"github.com/Ptt-official-app/go-bbs"
+)
+
+// Record sizes of pttbbs (Maple3 based), records are stored in little
+// endian without any header:
+//
+//	.PASSWDS  userec_t       512 bytes  UserecRecordLength
+//	.BRD      boardheader_t  256 bytes  BoardHeaderRecordLength
+//	.DIR      fileheader_t   128 bytes  FileHeaderRecordLength
+//
+// See https://github.com/ptt/pttbbs/blob/master/include/pttstruct.h
+
+// UserRecordSize returns the size of userec in .PASSWDS.
+func (c *Connector) UserRecordSize() int { return UserecRecordLength }
+
+// BoardRecordSize returns the size of boardheader in .BRD.
+func (c *Connector) BoardRecordSize() int { return BoardHeaderRecordLength }
+
+// ArticleRecordSize returns the size of fileheader in .DIR.
+func (c *Connector) ArticleRecordSize() int { return FileHeaderRecordLength }
+
+var _ bbs.RecordSizer = &Connector{}
+
+// DecodeUserRecord decodes one userec from data.
+func (c *Connector) DecodeUserRecord(data []byte) (bbs.UserRecord, error) {
+	r, err := UnmarshalUserec(data)
+	if err != nil {
+		return nil, err
+	}
+	c.userecsIn(r)
+	return r, nil
+}
+
+// DecodeBoardRecord decodes one boardheader from data.
+func (c *Connector) DecodeBoardRecord(data []byte) (bbs.BoardRecord, error) {
+	r, err := UnmarshalBoardHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// DecodeArticleRecord decodes one fileheader from data.
+func (c *Connector) DecodeArticleRecord(data []byte) (bbs.ArticleRecord, error) {
+	r, err := NewFileHeaderWithByte(data)
+	if err != nil {
+		return nil, err
+	}
+	c.fileHeadersIn(r)
+	return r, nil
+}
+
+var _ bbs.RecordDecoder = &Connector{}
+
+// RecordCodec is the layout of userec of pttbbs, it is registered as fork
+// "pttbbs" of driver pttbbs and selected by dataSourceName such as
+// `file:///home/bbs?fork=pttbbs`. It is Native, so DB still reads Userec by
+// connector, and the layout is a base of forks derived from pttbbs.
+var RecordCodec = &bbs.RecordCodec{
+	Fork:         "pttbbs",
+	Native:       true,
+	DecodeString: big5uaoToUTF8String,
+	User: bbs.RecordLayout{
+		Size: UserecRecordLength,
+		Fields: map[string]bbs.RecordField{
+			bbs.UserFieldUserID:       {Offset: PosOfPasswdUserID, Size: IDLength + 1},
+			bbs.UserFieldRealName:     {Offset: PosOfPasswdRealName, Size: RealNameSize},
+			bbs.UserFieldNickname:     {Offset: PosOfPasswdNickname, Size: NicknameSize},
+			bbs.UserFieldPassword:     {Offset: PosOfPasswdPassword, Size: PasswordLength},
+			bbs.UserFieldUserFlag:     {Offset: PosOfPasswdUserFlag, Size: 4},
+			bbs.UserFieldNumLoginDays: {Offset: PosOfPasswdNumLoginDays, Size: 4},
+			bbs.UserFieldNumPosts:     {Offset: PosOfPasswdNumPosts, Size: 4},
+			bbs.UserFieldLastLogin:    {Offset: PosOfPasswdLastLogin, Size: 4},
+			bbs.UserFieldLastHost:     {Offset: PosOfPasswdLastHost, Size: IPV4Length + 1},
+			bbs.UserFieldMoney:        {Offset: PosOfPasswdMoney, Size: 4},
+		},
+	},
+}
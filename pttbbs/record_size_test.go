@@ -0,0 +1,110 @@
+package pttbbs
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/Ptt-official-app/go-bbs"
+)
+
+func TestRecordSize(t *testing.T) {
+	c := &Connector{}
+
+	u, err := (&Userec{}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("Userec MarshalBinary error: %v", err)
+	}
+	b, err := (&BoardHeader{}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("BoardHeader MarshalBinary error: %v", err)
+	}
+	f, err := (&FileHeader{}).MarshalToByte()
+	if err != nil {
+		t.Fatalf("FileHeader MarshalToByte error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		size      int
+		marshaled int
+		lastField int // end of the last field in struct
+	}{
+		{"userec", c.UserRecordSize(), len(u), PosOfPasswdTimeViolateLaw + 4},
+		{"boardheader", c.BoardRecordSize(), len(b), PosOfSRExpire + 4},
+		{"fileheader", c.ArticleRecordSize(), len(f), PosOfFileHeaderFilemode + 1},
+	}
+	for _, tt := range tests {
+		if tt.size != tt.marshaled {
+			t.Errorf("%v size %v not match marshaled size %v", tt.name, tt.size, tt.marshaled)
+		}
+		if tt.lastField > tt.size {
+			t.Errorf("%v size %v is smaller than the struct layout %v", tt.name, tt.size, tt.lastField)
+		}
+	}
+}
+
+func TestRecordCodec(t *testing.T) {
+	data, err := ioutil.ReadFile("testcase/passwd/01.PASSWDS")
+	if err != nil {
+		t.Fatalf("read file error: %v", err)
+	}
+	expected, err := OpenUserecFile("testcase/passwd/01.PASSWDS")
+	if err != nil {
+		t.Fatalf("OpenUserecFile error: %v", err)
+	}
+
+	for i, e := range expected {
+		u, err := RecordCodec.DecodeUserRecord(data[i*UserecRecordLength : (i+1)*UserecRecordLength])
+		if err != nil {
+			t.Fatalf("DecodeUserRecord %d error: %v", i, err)
+		}
+		if u.UserID() != e.UserID() || u.RealName() != e.RealName() || u.Nickname() != e.Nickname() ||
+			u.HashedPassword() != e.HashedPassword() || u.UserFlag() != e.UserFlag() ||
+			u.NumLoginDays() != e.NumLoginDays() || u.NumPosts() != e.NumPosts() ||
+			!u.LastLogin().Equal(e.LastLogin()) || u.LastHost() != e.LastHost() || u.Money() != e.Money() {
+			t.Errorf("user %d decoded by RecordCodec not match, expected: %v, got: %v", i, e, u)
+		}
+	}
+
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, ".PASSWDS"), data, 0644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+	db, err := bbs.Open("pttbbs", dir+"?fork=pttbbs")
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	if db.RecordCodec() != RecordCodec {
+		t.Errorf("RecordCodec of DB expected: pttbbs, got: %v", db.RecordCodec())
+	}
+	recs, err := db.ReadUserRecords()
+	if err != nil {
+		t.Fatalf("ReadUserRecords error: %v", err)
+	}
+	if len(recs) != len(expected) || recs[0].UserID() != expected[0].UserID() {
+		t.Errorf("ReadUserRecords with fork pttbbs not match, expected: %v users, got: %v", len(expected), len(recs))
+	}
+	if _, ok := recs[0].(*Userec); !ok {
+		t.Errorf("native fork pttbbs should be read by connector, got: %T", recs[0])
+	}
+	if _, ok := recs[0].(bbs.Over18UserRecord); !ok {
+		t.Errorf("user records of fork pttbbs should implement Over18UserRecord")
+	}
+	if err := recs[0].VerifyPassword("wrong password"); err == nil || errors.Is(err, bbs.ErrNotSupported) {
+		t.Errorf("VerifyPassword with fork pttbbs should check DES hash, got: %v", err)
+	}
+
+	n := 0
+	err = db.EachUserRecord(func(u bbs.UserRecord) (bool, error) {
+		if _, ok := u.(*Userec); !ok {
+			t.Errorf("EachUserRecord with fork pttbbs should stream Userec, got: %T", u)
+		}
+		n++
+		return false, nil
+	})
+	if err != nil || n != len(expected) {
+		t.Errorf("EachUserRecord with fork pttbbs expected: %v users, got: %v, %v", len(expected), n, err)
+	}
+}
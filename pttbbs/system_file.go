@@ -0,0 +1,57 @@
+package pttbbs
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/Ptt-official-app/go-bbs"
+)
+
+// GetSystemFilePath returns the path of system file relPath under etc
+// directory, eg: BBSHome/etc/{{relPath}}. It returns bbs.ErrInvalidName if
+// relPath may point outside of the etc directory.
+func GetSystemFilePath(workDirectory string, relPath string) (string, error) {
+	p, err := bbs.CleanSystemFilePath(relPath)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/etc/%s", workDirectory, p), nil
+}
+
+// ReadSystemFile returns the content of file relPath under etc directory.
+// When reading from disk, symbolic links resolved to outside of the etc
+// directory are rejected with bbs.ErrInvalidName.
+func (c *Connector) ReadSystemFile(relPath string) ([]byte, error) {
+	p, err := GetSystemFilePath(c.home, relPath)
+	if err != nil {
+		return nil, err
+	}
+	if c.fsys == nil {
+		if err := checkInsideDir(fmt.Sprintf("%s/etc", c.home), p); err != nil {
+			return nil, err
+		}
+	}
+	return c.readFile(p)
+}
+
+// checkInsideDir returns bbs.ErrInvalidName if name resolves to a file
+// outside of dir after following symbolic links.
+func checkInsideDir(dir string, name string) error {
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	realName, err := filepath.EvalSymlinks(name)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(realDir, realName)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return &fs.PathError{Op: "open", Path: name, Err: fmt.Errorf("%w: outside of %s", bbs.ErrInvalidName, dir)}
+	}
+	return nil
+}
+
+var _ bbs.SystemFileConnector = &Connector{}
@@ -0,0 +1,55 @@
+package pttbbs
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Ptt-official-app/go-bbs"
+)
+
+func TestReadSystemFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "system_file_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.MkdirAll(filepath.Join(dir, "etc"), 0755); err != nil {
+		t.Fatalf("mkdir error: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "etc", "motd"), []byte("welcome"), 0644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, ".PASSWDS"), []byte("secret"), 0644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(dir, ".PASSWDS"), filepath.Join(dir, "etc", "passwds")); err != nil {
+		t.Fatalf("symlink error: %v", err)
+	}
+
+	c := &Connector{home: dir}
+	if buf, err := c.ReadSystemFile("motd"); err != nil || string(buf) != "welcome" {
+		t.Errorf("ReadSystemFile expected: welcome, <nil>, got: %q, %v", buf, err)
+	}
+	if _, err := c.ReadSystemFile("passwds"); !errors.Is(err, bbs.ErrInvalidName) {
+		t.Errorf("ReadSystemFile of symlink outside etc should return ErrInvalidName, got: %v", err)
+	}
+	if _, err := c.ReadSystemFile("../.PASSWDS"); !errors.Is(err, bbs.ErrInvalidName) {
+		t.Errorf("ReadSystemFile should return ErrInvalidName, got: %v", err)
+	}
+	if _, err := c.ReadSystemFile("notexist"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ReadSystemFile should return ErrNotExist, got: %v", err)
+	}
+
+	c = &Connector{}
+	if err := c.OpenFS(fstest.MapFS{"etc/Welcome": &fstest.MapFile{Data: []byte("login")}}, "."); err != nil {
+		t.Fatalf("OpenFS error: %v", err)
+	}
+	if buf, err := c.ReadSystemFile("Welcome"); err != nil || string(buf) != "login" {
+		t.Errorf("ReadSystemFile expected: login, <nil>, got: %q, %v", buf, err)
+	}
+}
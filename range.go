@@ -0,0 +1,368 @@
+package bbs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+)
+
+// Driver which implement RecordRangeConnector supports reading part of a
+// record file, so ranged reads of DB read the requested records only
+// through connector, such as from the fs.FS of OpenFS.
+type RecordRangeConnector interface {
+	// ReadRecordFileRange reads at most size bytes starting from offset in
+	// record file name, fewer bytes are returned at the end of file, and
+	// size -1 means reading to the end of file. It may return
+	// ErrNotSupported to have DB read the file by itself.
+	ReadRecordFileRange(name string, offset int64, size int) ([]byte, error)
+}
+
+// readFileRange reads at most size bytes starting from offset in path by
+// RecordRangeConnector of connector, size -1 means reading to the end of
+// file. If connector does not implement it or returns ErrNotSupported, the
+// file is read from disk, or fsys if DB is opened with WithFS.
+func (db *DB) readFileRange(path string, offset int64, size int) ([]byte, error) {
+	var rc RecordRangeConnector
+	if db.connectorAs(&rc) {
+		data, err := rc.ReadRecordFileRange(path, offset, size)
+		if !errors.Is(err, ErrNotSupported) {
+			return data, err
+		}
+	}
+
+	f, err := db.openFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadRange(f, offset, size)
+}
+
+// ReadRange reads at most size bytes starting from offset in r, fewer bytes
+// are returned at the end of r, size -1 means reading to the end of r. It
+// seeks if r implements io.Seeker. Drivers can use it to implement
+// RecordRangeConnector.
+func ReadRange(r io.Reader, offset int64, size int) ([]byte, error) {
+	var err error
+	if s, ok := r.(io.Seeker); ok {
+		_, err = s.Seek(offset, io.SeekStart)
+	} else {
+		_, err = io.CopyN(ioutil.Discard, r, offset)
+	}
+	if err == io.EOF {
+		return []byte{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if size < 0 {
+		return ioutil.ReadAll(r)
+	}
+	buf := make([]byte, size)
+	n, err := io.ReadFull(r, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return buf[:n], err
+}
+
+// readRange reads at most limit records of size bytes starting from the
+// offset-th record in path, and decodes each of them by decode. limit 0
+// means reading to the end of file. Records are read through connector, see
+// readFileRange. Decode errors are returned as DecodeError, and so is a
+// partial record at the end of file with io.ErrUnexpectedEOF.
+func (db *DB) readRange(path string, size, offset, limit int, decode func([]byte) (interface{}, error)) ([]interface{}, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("bbs: invalid record size %v", size)
+	}
+	if offset < 0 || limit < 0 {
+		return nil, fmt.Errorf("bbs: invalid offset %v or limit %v", offset, limit)
+	}
+
+	n := -1
+	if limit != 0 {
+		n = limit * size
+	}
+	data, err := db.readFileRange(path, int64(offset)*int64(size), n)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []interface{}{}
+	for len(data) > 0 {
+		index := offset + len(ret)
+		if len(data) < size {
+			return nil, &DecodeError{Path: path, Index: index, Offset: int64(index) * int64(size), Err: io.ErrUnexpectedEOF}
+		}
+		r, err := decode(data[:size])
+		if err != nil {
+			return nil, &DecodeError{Path: path, Index: index, Offset: int64(index) * int64(size), Err: err}
+		}
+		ret = append(ret, r)
+		data = data[size:]
+	}
+	return ret, nil
+}
+
+// rangeConnector returns the RecordSizer and RecordDecoder of connector, ok
+// is false if connector does not implement both of them.
+func (db *DB) rangeConnector() (rs RecordSizer, rd RecordDecoder, ok bool) {
+	if !db.connectorAs(&rs) || !db.connectorAs(&rd) {
+		return nil, nil, false
+	}
+	return rs, rd, true
+}
+
+// fullReadRangeConnector is rangeConnector used by full reads such as
+// ReadUserRecords, ok is also false if connector is wrapped by WithCache so
+// full reads keep being cached.
+func (db *DB) fullReadRangeConnector() (rs RecordSizer, rd RecordDecoder, ok bool) {
+	var ci cacheInvalidator
+	if db.connectorAs(&ci) {
+		return nil, nil, false
+	}
+	return db.rangeConnector()
+}
+
+// articleRangeConnector is like rangeConnector, but ok is also false if
+// deleted article records are skipped. Indices of ranged reads count deleted
+// slots, so article records are read all and filtered then, keeping offset
+// and limit the same as ReadBoardArticleRecordsFile.
+func (db *DB) articleRangeConnector() (rs RecordSizer, rd RecordDecoder, ok bool) {
+	if db.skipDeletedArticles {
+		return nil, nil, false
+	}
+	return db.rangeConnector()
+}
+
+// ReadUserRecordsRange returns at most limit UserRecords starting from
+// offset, limit 0 means no limit. It only reads the requested records if
+// connector implements RecordSizer and RecordDecoder, otherwise it reads
+// all records and slices them.
+func (db *DB) ReadUserRecordsRange(offset, limit int) ([]UserRecord, error) {
+	if db.userCodec() != nil {
+		return db.readUserRecordsWithCodec(offset, limit)
+	}
+	rs, rd, ok := db.rangeConnector()
+	if !ok {
+		recs, err := db.ReadUserRecords()
+		if err != nil {
+			return nil, err
+		}
+		from, to, err := sliceRange(len(recs), offset, limit)
+		if err != nil {
+			return nil, err
+		}
+		return recs[from:to], nil
+	}
+
+	path, err := db.userRecordsPath()
+	if err != nil {
+		log.Println("bbs: open file error:", err)
+		return nil, err
+	}
+	recs, err := db.readRange(path, rs.UserRecordSize(), offset, limit, func(data []byte) (interface{}, error) {
+		return rd.DecodeUserRecord(data)
+	})
+	if err != nil {
+		log.Println("bbs: readRange error:", err)
+		return nil, err
+	}
+	db.countRead(userRecordKind, len(recs)*rs.UserRecordSize())
+	ret := make([]UserRecord, len(recs))
+	for i, r := range recs {
+		ret[i] = r.(UserRecord)
+	}
+	return ret, nil
+}
+
+// ReadBoardRecordsRange returns at most limit BoardRecords starting from
+// offset, limit 0 means no limit. See ReadUserRecordsRange.
+func (db *DB) ReadBoardRecordsRange(offset, limit int) ([]BoardRecord, error) {
+	rs, rd, ok := db.rangeConnector()
+	if !ok {
+		recs, err := db.ReadBoardRecords()
+		if err != nil {
+			return nil, err
+		}
+		from, to, err := sliceRange(len(recs), offset, limit)
+		if err != nil {
+			return nil, err
+		}
+		return recs[from:to], nil
+	}
+
+	path, err := db.boardRecordsPath()
+	if err != nil {
+		log.Println("bbs: open file error:", err)
+		return nil, err
+	}
+	recs, err := db.readRange(path, rs.BoardRecordSize(), offset, limit, func(data []byte) (interface{}, error) {
+		return rd.DecodeBoardRecord(data)
+	})
+	if err != nil {
+		log.Println("bbs: readRange error:", err)
+		return nil, err
+	}
+	db.countRead(boardRecordKind, len(recs)*rs.BoardRecordSize())
+	ret := make([]BoardRecord, len(recs))
+	for i, r := range recs {
+		ret[i] = r.(BoardRecord)
+	}
+	return ret, nil
+}
+
+// ReadBoardArticleRecordsRange returns at most limit ArticleRecords of board
+// starting from offset, limit 0 means no limit. It returns empty slice if
+// board has no article records file. See ReadUserRecordsRange, records are
+// always read all if SetSkipDeletedArticles is set.
+func (db *DB) ReadBoardArticleRecordsRange(boardID string, offset, limit int) ([]ArticleRecord, error) {
+	rs, rd, ok := db.articleRangeConnector()
+	if !ok {
+		recs, err := db.ReadBoardArticleRecordsFile(boardID)
+		if err != nil {
+			return nil, err
+		}
+		from, to, err := sliceRange(len(recs), offset, limit)
+		if err != nil {
+			return nil, err
+		}
+		return recs[from:to], nil
+	}
+	return db.readBoardArticleRecordsRange(boardID, rs, rd, offset, limit)
+}
+
+// readBoardArticleRecordsRange reads at most limit ArticleRecords of board
+// starting from offset by readRange, deleted records are not skipped.
+func (db *DB) readBoardArticleRecordsRange(boardID string, rs RecordSizer, rd RecordDecoder, offset, limit int) ([]ArticleRecord, error) {
+	path, err := db.boardArticleRecordsPath(boardID)
+	if err != nil {
+		log.Println("bbs: open file error:", err)
+		return nil, err
+	}
+	recs, err := db.readRange(path, rs.ArticleRecordSize(), offset, limit, func(data []byte) (interface{}, error) {
+		return rd.DecodeArticleRecord(data)
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return []ArticleRecord{}, nil
+	} else if err != nil {
+		log.Println("bbs: readRange error:", err)
+		return nil, err
+	}
+	db.countRead(articleRecordKind, len(recs)*rs.ArticleRecordSize())
+	ret := make([]ArticleRecord, len(recs))
+	for i, r := range recs {
+		ret[i] = r.(ArticleRecord)
+	}
+	return ret, nil
+}
+
+// ReadBoardArticleRecordsReverse returns at most limit ArticleRecords of
+// board newest-first, skipping the offset newest records, limit 0 means no
+// limit. It only reads the requested records from the end of file if
+// connector implements RecordSizer and RecordDecoder, otherwise it reads all
+// records and slices them.
+func (db *DB) ReadBoardArticleRecordsReverse(boardID string, offset, limit int) ([]ArticleRecord, error) {
+	if offset < 0 || limit < 0 {
+		return nil, fmt.Errorf("bbs: invalid offset %v or limit %v", offset, limit)
+	}
+
+	var recs []ArticleRecord
+	if _, _, ok := db.articleRangeConnector(); ok {
+		n, err := db.NumBoardArticles(boardID)
+		if err != nil {
+			return nil, err
+		}
+		from, to := reverseRange(n, offset, limit)
+		if from == to {
+			return []ArticleRecord{}, nil
+		}
+		recs, err = db.ReadBoardArticleRecordsRange(boardID, from, to-from)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		all, err := db.ReadBoardArticleRecordsFile(boardID)
+		if err != nil {
+			return nil, err
+		}
+		from, to := reverseRange(len(all), offset, limit)
+		recs = all[from:to]
+	}
+
+	ret := make([]ArticleRecord, len(recs))
+	for i, r := range recs {
+		ret[len(recs)-1-i] = r
+	}
+	return ret, nil
+}
+
+// RecentArticles returns the last n ArticleRecords of board newest-first,
+// such as for a recent posts widget. It returns fewer than n records if
+// board has fewer articles, and only reads the last n records when
+// connector supports it, see ReadBoardArticleRecordsReverse.
+func (db *DB) RecentArticles(boardID string, n int) ([]ArticleRecord, error) {
+	if n <= 0 {
+		return []ArticleRecord{}, nil
+	}
+	return db.ReadBoardArticleRecordsReverse(boardID, 0, n)
+}
+
+// ArticleAtIndex returns the index-th ArticleRecord of board, negative index
+// counts from the end, so -1 is the last article. It only reads that record
+// if connector implements RecordSizer and RecordDecoder, see
+// ReadBoardArticleRecordsRange. It returns ErrRecordNotFound if index is out
+// of range.
+func (db *DB) ArticleAtIndex(boardID string, index int) (ArticleRecord, error) {
+	i := index
+	if index < 0 {
+		n, err := db.NumBoardArticles(boardID)
+		if err != nil {
+			return nil, err
+		}
+		i = n + index
+	}
+	if i < 0 {
+		return nil, fmt.Errorf("%w: article index %d of board %q", ErrRecordNotFound, index, boardID)
+	}
+
+	recs, err := db.ReadBoardArticleRecordsRange(boardID, i, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(recs) == 0 {
+		return nil, fmt.Errorf("%w: article index %d of board %q", ErrRecordNotFound, index, boardID)
+	}
+	return recs[0], nil
+}
+
+// reverseRange returns the slice bounds of n records for offset and limit
+// counted from the end.
+func reverseRange(n, offset, limit int) (from, to int) {
+	to = n - offset
+	if to < 0 {
+		to = 0
+	}
+	if limit != 0 && to-limit > 0 {
+		from = to - limit
+	}
+	return from, to
+}
+
+// sliceRange returns the slice bounds of n records for offset and limit.
+func sliceRange(n, offset, limit int) (from, to int, err error) {
+	if offset < 0 || limit < 0 {
+		return 0, 0, fmt.Errorf("bbs: invalid offset %v or limit %v", offset, limit)
+	}
+	if offset > n {
+		offset = n
+	}
+	to = n
+	if limit != 0 && offset+limit < n {
+		to = offset + limit
+	}
+	return offset, to, nil
+}
@@ -0,0 +1,410 @@
+package bbs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type fakeRangeConnector struct {
+	fakeRecordSizerConnector
+	path string
+}
+
+var _ RecordDecoder = &fakeRangeConnector{}
+
+func (c *fakeRangeConnector) GetUserRecordsPath() (string, error) {
+	return c.path, nil
+}
+
+func (c *fakeRangeConnector) DecodeUserRecord(data []byte) (UserRecord, error) {
+	return &fakeUserRecord{userID: strings.TrimRight(string(data), "\x00")}, nil
+}
+
+func (c *fakeRangeConnector) DecodeBoardRecord(data []byte) (BoardRecord, error) {
+	return &fakeBoardRecord{boardID: strings.TrimRight(string(data), "\x00")}, nil
+}
+
+func (c *fakeRangeConnector) DecodeArticleRecord(data []byte) (ArticleRecord, error) {
+	return &fakeArticleRecord{filename: strings.TrimRight(string(data), "\x00")}, nil
+}
+
+func TestReadUserRecordsRange(t *testing.T) {
+	dir, err := ioutil.TempDir("", "range_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ".PASSWDS")
+	// 4 records of 4 bytes and a partial record.
+	if err := ioutil.WriteFile(path, []byte("aaaabbbbccccdddde"), 0644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+
+	db := &DB{connector: &fakeRangeConnector{
+		fakeRecordSizerConnector: fakeRecordSizerConnector{fakeConnector: &fakeConnector{}, size: 4},
+		path:                     path,
+	}}
+
+	tests := []struct {
+		offset   int
+		limit    int
+		expected []string
+	}{
+		{1, 2, []string{"bbbb", "cccc"}},
+		{3, 1, []string{"dddd"}},
+		{5, 1, []string{}},
+		{100, 0, []string{}},
+	}
+	for _, tt := range tests {
+		recs, err := db.ReadUserRecordsRange(tt.offset, tt.limit)
+		if err != nil {
+			t.Errorf("ReadUserRecordsRange(%v, %v) error: %v", tt.offset, tt.limit, err)
+			continue
+		}
+		ids := []string{}
+		for _, r := range recs {
+			ids = append(ids, r.UserID())
+		}
+		if !reflect.DeepEqual(ids, tt.expected) {
+			t.Errorf("ReadUserRecordsRange(%v, %v) expected: %v, got: %v", tt.offset, tt.limit, tt.expected, ids)
+		}
+	}
+
+	for _, tt := range [][2]int{{0, 0}, {3, 10}} {
+		_, err := db.ReadUserRecordsRange(tt[0], tt[1])
+		var de *DecodeError
+		if !errors.As(err, &de) || de.Index != 4 || de.Offset != 16 || !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("ReadUserRecordsRange(%v, %v) of partial record should return DecodeError of index 4, got: %v", tt[0], tt[1], err)
+		}
+	}
+
+	if _, err := db.ReadUserRecordsRange(-1, 0); err == nil {
+		t.Errorf("ReadUserRecordsRange with negative offset should return error")
+	}
+}
+
+type fakeRecordRangeConnector struct {
+	fakeRangeConnector
+	data  string
+	fails int
+}
+
+func (c *fakeRecordRangeConnector) ReadRecordFileRange(name string, offset int64, size int) ([]byte, error) {
+	if c.fails > 0 {
+		c.fails--
+		return nil, syscall.EAGAIN
+	}
+	return ReadRange(strings.NewReader(c.data), offset, size)
+}
+
+func TestReadUserRecordsRangeByConnector(t *testing.T) {
+	c := &fakeRecordRangeConnector{
+		fakeRangeConnector: fakeRangeConnector{
+			fakeRecordSizerConnector: fakeRecordSizerConnector{fakeConnector: &fakeConnector{}, size: 4},
+			path:                     "not/exist/.PASSWDS",
+		},
+		data:  "aaaabbbbcccc",
+		fails: 2,
+	}
+	db := &DB{connector: WithCache(WithRetry(c, 3, 0), time.Hour)}
+
+	recs, err := db.ReadUserRecordsRange(1, 0)
+	if err != nil {
+		t.Fatalf("ReadUserRecordsRange error: %v", err)
+	}
+	ids := []string{}
+	for _, r := range recs {
+		ids = append(ids, r.UserID())
+	}
+	if expected := []string{"bbbb", "cccc"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("ReadUserRecordsRange should read through connector with retry, expected: %v, got: %v", expected, ids)
+	}
+}
+
+func TestReadBoardArticleRecordsReverse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "range_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ".DIR")
+	if err := ioutil.WriteFile(path, []byte("aaaabbbbccccdddd"), 0644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+
+	fc := &fakeConnector{
+		fakeGetBoardArticleRecordsPath: func() (string, error) { return path, nil },
+		fakeReadArticleRecordsFile: func() ([]ArticleRecord, error) {
+			return []ArticleRecord{
+				&fakeArticleRecord{filename: "aaaa"},
+				&fakeArticleRecord{filename: "bbbb"},
+				&fakeArticleRecord{filename: "cccc"},
+				&fakeArticleRecord{filename: "dddd"},
+			}, nil
+		},
+	}
+	dbs := map[string]*DB{
+		"range": {connector: &fakeRangeConnector{
+			fakeRecordSizerConnector: fakeRecordSizerConnector{fakeConnector: fc, size: 4},
+		}},
+		"slice": {connector: fc},
+	}
+
+	tests := []struct {
+		offset   int
+		limit    int
+		expected []string
+	}{
+		{0, 0, []string{"dddd", "cccc", "bbbb", "aaaa"}},
+		{0, 2, []string{"dddd", "cccc"}},
+		{1, 2, []string{"cccc", "bbbb"}},
+		{3, 10, []string{"aaaa"}},
+		{4, 1, []string{}},
+		{100, 0, []string{}},
+	}
+	for name, db := range dbs {
+		for _, tt := range tests {
+			recs, err := db.ReadBoardArticleRecordsReverse("SYSOP", tt.offset, tt.limit)
+			if err != nil {
+				t.Errorf("%s: ReadBoardArticleRecordsReverse(%v, %v) error: %v", name, tt.offset, tt.limit, err)
+				continue
+			}
+			filenames := []string{}
+			for _, r := range recs {
+				filenames = append(filenames, r.Filename())
+			}
+			if !reflect.DeepEqual(filenames, tt.expected) {
+				t.Errorf("%s: ReadBoardArticleRecordsReverse(%v, %v) expected: %v, got: %v", name, tt.offset, tt.limit, tt.expected, filenames)
+			}
+		}
+	}
+}
+
+func TestRecentArticles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "range_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ".DIR")
+	if err := ioutil.WriteFile(path, []byte("aaaabbbbccccdddd"), 0644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+	fc := &fakeConnector{
+		fakeGetBoardArticleRecordsPath: func() (string, error) { return path, nil },
+	}
+
+	tests := []struct {
+		n        int
+		expected []string
+	}{
+		{2, []string{"dddd", "cccc"}},
+		{10, []string{"dddd", "cccc", "bbbb", "aaaa"}},
+		{0, []string{}},
+	}
+	for _, tt := range tests {
+		db := &DB{connector: &fakeRangeConnector{
+			fakeRecordSizerConnector: fakeRecordSizerConnector{fakeConnector: fc, size: 4},
+		}}
+		recs, err := db.RecentArticles("SYSOP", tt.n)
+		if err != nil {
+			t.Errorf("RecentArticles(%v) error: %v", tt.n, err)
+			continue
+		}
+		filenames := []string{}
+		for _, r := range recs {
+			filenames = append(filenames, r.Filename())
+		}
+		if !reflect.DeepEqual(filenames, tt.expected) {
+			t.Errorf("RecentArticles(%v) expected: %v, got: %v", tt.n, tt.expected, filenames)
+		}
+		// only the returned records are read from file.
+		if bytes := db.Stats().ArticleBytesRead; bytes != uint64(4*len(tt.expected)) {
+			t.Errorf("RecentArticles(%v) should read %v bytes, got: %v", tt.n, 4*len(tt.expected), bytes)
+		}
+	}
+}
+
+func TestArticleAtIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "range_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ".DIR")
+	if err := ioutil.WriteFile(path, []byte("aaaabbbbccccdddd"), 0644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+	fc := &fakeConnector{
+		fakeGetBoardArticleRecordsPath: func() (string, error) { return path, nil },
+		fakeReadArticleRecordsFile: func() ([]ArticleRecord, error) {
+			return []ArticleRecord{
+				&fakeArticleRecord{filename: "aaaa"}, &fakeArticleRecord{filename: "bbbb"},
+				&fakeArticleRecord{filename: "cccc"}, &fakeArticleRecord{filename: "dddd"},
+			}, nil
+		},
+	}
+
+	tests := []struct {
+		index    int
+		expected string
+	}{
+		{0, "aaaa"},
+		{2, "cccc"},
+		{-1, "dddd"},
+		{-4, "aaaa"},
+		{4, ""},
+		{-5, ""},
+	}
+	dbs := map[string]*DB{
+		"range": {connector: &fakeRangeConnector{
+			fakeRecordSizerConnector: fakeRecordSizerConnector{fakeConnector: fc, size: 4},
+		}},
+		"read all": {connector: fc},
+	}
+	for name, db := range dbs {
+		for _, tt := range tests {
+			r, err := db.ArticleAtIndex("SYSOP", tt.index)
+			if tt.expected == "" {
+				if !errors.Is(err, ErrRecordNotFound) {
+					t.Errorf("%v: ArticleAtIndex(%v) should return ErrRecordNotFound, got: %v", name, tt.index, err)
+				}
+				continue
+			}
+			if err != nil || r.Filename() != tt.expected {
+				t.Errorf("%v: ArticleAtIndex(%v) expected: %v, <nil>, got: %v, %v", name, tt.index, tt.expected, r, err)
+			}
+		}
+	}
+
+	// only the returned record is read from file.
+	db := &DB{connector: &fakeRangeConnector{
+		fakeRecordSizerConnector: fakeRecordSizerConnector{fakeConnector: fc, size: 4},
+	}}
+	if _, err := db.ArticleAtIndex("SYSOP", -2); err != nil {
+		t.Fatalf("ArticleAtIndex error: %v", err)
+	}
+	if bytes := db.Stats().ArticleBytesRead; bytes != 4 {
+		t.Errorf("ArticleAtIndex should read 4 bytes, got: %v", bytes)
+	}
+}
+
+func TestSliceRange(t *testing.T) {
+	tests := []struct {
+		n, offset, limit int
+		from, to         int
+	}{
+		{5, 0, 0, 0, 5},
+		{5, 1, 2, 1, 3},
+		{5, 4, 2, 4, 5},
+		{5, 7, 2, 5, 5},
+	}
+	for _, tt := range tests {
+		from, to, err := sliceRange(tt.n, tt.offset, tt.limit)
+		if err != nil || from != tt.from || to != tt.to {
+			t.Errorf("sliceRange(%v, %v, %v) expected: %v, %v, got: %v, %v, %v", tt.n, tt.offset, tt.limit, tt.from, tt.to, from, to, err)
+		}
+	}
+}
+
+type fakeStrictRangeConnector struct {
+	fakeRangeConnector
+}
+
+// checkRecord rejects records with NUL, such as a truncated record padded
+// with zero.
+func checkRecord(data []byte) error {
+	if i := strings.IndexByte(string(data), 0); i >= 0 {
+		return fmt.Errorf("unexpected NUL at %d", i)
+	}
+	return nil
+}
+
+func (c *fakeStrictRangeConnector) DecodeUserRecord(data []byte) (UserRecord, error) {
+	if err := checkRecord(data); err != nil {
+		return nil, err
+	}
+	return c.fakeRangeConnector.DecodeUserRecord(data)
+}
+
+func (c *fakeStrictRangeConnector) DecodeBoardRecord(data []byte) (BoardRecord, error) {
+	if err := checkRecord(data); err != nil {
+		return nil, err
+	}
+	return c.fakeRangeConnector.DecodeBoardRecord(data)
+}
+
+func (c *fakeStrictRangeConnector) DecodeArticleRecord(data []byte) (ArticleRecord, error) {
+	if err := checkRecord(data); err != nil {
+		return nil, err
+	}
+	return c.fakeRangeConnector.DecodeArticleRecord(data)
+}
+
+func TestReadRangeDecodeError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "range_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// 2 records of 4 bytes and a truncated final record.
+	path := filepath.Join(dir, "records")
+	if err := ioutil.WriteFile(path, []byte("aaaabbbbcc"), 0644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+	fc := &fakeConnector{
+		fakeGetBoardRecordsPath:        func() (string, error) { return path, nil },
+		fakeGetBoardArticleRecordsPath: func() (string, error) { return path, nil },
+	}
+	db := &DB{connector: &fakeStrictRangeConnector{fakeRangeConnector{
+		fakeRecordSizerConnector: fakeRecordSizerConnector{fakeConnector: fc, size: 4},
+		path:                     path,
+	}}}
+
+	reads := map[string]func() error{
+		"user": func() error {
+			_, err := db.ReadUserRecordsRange(1, 0)
+			return err
+		},
+		"board": func() error {
+			_, err := db.ReadBoardRecordsRange(0, 0)
+			return err
+		},
+		"article": func() error {
+			_, err := db.ReadBoardArticleRecordsRange("SYSOP", 0, 0)
+			return err
+		},
+	}
+	for name, read := range reads {
+		err := read()
+		var de *DecodeError
+		if !errors.As(err, &de) {
+			t.Errorf("%v: error should be DecodeError, got: %v", name, err)
+			continue
+		}
+		if de.Path != path || de.Index != 2 || de.Offset != 8 || de.Err == nil {
+			t.Errorf("%v: DecodeError expected: %v, 2, 8, got: %+v", name, path, de)
+		}
+		if !strings.Contains(err.Error(), "record 2 (offset 8)") {
+			t.Errorf("%v: error message should locate the record, got: %v", name, err)
+		}
+	}
+
+	if recs, err := db.ReadBoardRecordsRange(0, 2); err != nil || len(recs) != 2 {
+		t.Errorf("records before the corrupt one expected: 2, <nil>, got: %v, %v", len(recs), err)
+	}
+}
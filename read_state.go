@@ -0,0 +1,48 @@
+package bbs
+
+import (
+	"fmt"
+	"log"
+	"sort"
+)
+
+// ReadStateConnector is a connector for bbs which tracks the read state of
+// boards for each user, it powers the "有新文章" markers of board list.
+type ReadStateConnector interface {
+
+	// ReadUserBoardReadState should return a map from board id to whether
+	// the board has unread articles for user, boards not in the map have no
+	// unread articles.
+	ReadUserBoardReadState(userID string) (map[string]bool, error)
+}
+
+// UserUnreadBoards returns the sorted ids of boards which have unread
+// articles for userID. It returns ErrNotSupported if connector does not
+// implement ReadStateConnector.
+func (db *DB) UserUnreadBoards(userID string) ([]string, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("%w: empty user id", ErrInvalidName)
+	}
+	if err := checkNames(userID); err != nil {
+		return nil, err
+	}
+	var rc ReadStateConnector
+	if !db.connectorAs(&rc) {
+		return nil, ErrNotSupported
+	}
+
+	state, err := rc.ReadUserBoardReadState(userID)
+	if err != nil {
+		log.Println("bbs: ReadUserBoardReadState error:", err)
+		return nil, err
+	}
+
+	ret := []string{}
+	for boardID, unread := range state {
+		if unread {
+			ret = append(ret, boardID)
+		}
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
@@ -0,0 +1,48 @@
+package bbs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type fakeReadStateConnector struct {
+	fakeConnector
+	state map[string]map[string]bool
+}
+
+func (c *fakeReadStateConnector) ReadUserBoardReadState(userID string) (map[string]bool, error) {
+	return c.state[userID], nil
+}
+
+func TestUserUnreadBoards(t *testing.T) {
+	db := &DB{connector: &fakeReadStateConnector{
+		state: map[string]map[string]bool{
+			"pichu": {"Test": true, "SYSOP": true, "junk": false},
+		},
+	}}
+
+	actual, err := db.UserUnreadBoards("pichu")
+	if err != nil {
+		t.Fatalf("UserUnreadBoards error: %v", err)
+	}
+	if expected := []string{"SYSOP", "Test"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("UserUnreadBoards expected: %v, got: %v", expected, actual)
+	}
+
+	actual, err = db.UserUnreadBoards("nobody")
+	if err != nil || len(actual) != 0 {
+		t.Errorf("UserUnreadBoards of user without state expected: [], <nil>, got: %v, %v", actual, err)
+	}
+
+	for _, id := range []string{"", "../pichu"} {
+		if _, err := db.UserUnreadBoards(id); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("UserUnreadBoards(%q) should return ErrInvalidName, got: %v", id, err)
+		}
+	}
+
+	db = &DB{connector: &fakeConnector{}}
+	if _, err := db.UserUnreadBoards("pichu"); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("UserUnreadBoards should return ErrNotSupported, got: %v", err)
+	}
+}
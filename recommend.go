@@ -0,0 +1,43 @@
+package bbs
+
+import "strconv"
+
+// MaxRecommendDisplay is the score shown as 爆 or XX, see MAX_RECOMMENDS in
+// pttbbs.
+const MaxRecommendDisplay = 100
+
+// ScoredArticleRecord return ArticleRecord interface which support the
+// recommend (推/噓) score as shown in bbs.
+type ScoredArticleRecord interface {
+	// RecommendDisplay return the token shown in article list, see
+	// RecommendDisplay.
+	RecommendDisplay() string
+	// RecommendValue return the net score of 推 minus 噓.
+	RecommendValue() int
+}
+
+// RecommendDisplay returns the token of score n shown in article list of
+// pttbbs: "爆" for n >= 100, the number for 1 <= n <= 99, "X1" to "X9" for
+// -99 <= n <= -10 by tens, "XX" for n <= -100, and empty string otherwise.
+func RecommendDisplay(n int) string {
+	switch {
+	case n >= MaxRecommendDisplay:
+		return "爆"
+	case n > 0:
+		return strconv.Itoa(n)
+	case n <= -MaxRecommendDisplay:
+		return "XX"
+	case n <= -10:
+		return "X" + strconv.Itoa(-n/10)
+	}
+	return ""
+}
+
+// ArticleRecommendDisplay returns the recommend token of r, it uses
+// ScoredArticleRecord and falls back to RecommendDisplay of Recommend.
+func ArticleRecommendDisplay(r ArticleRecord) string {
+	if sr, ok := r.(ScoredArticleRecord); ok {
+		return sr.RecommendDisplay()
+	}
+	return RecommendDisplay(r.Recommend())
+}
@@ -0,0 +1,34 @@
+package bbs
+
+import "testing"
+
+func TestRecommendDisplay(t *testing.T) {
+	testCases := []struct {
+		n        int
+		expected string
+	}{
+		{n: 127, expected: "爆"},
+		{n: 100, expected: "爆"},
+		{n: 99, expected: "99"},
+		{n: 10, expected: "10"},
+		{n: 9, expected: "9"},
+		{n: 1, expected: "1"},
+		{n: 0, expected: ""},
+		{n: -1, expected: ""},
+		{n: -9, expected: ""},
+		{n: -10, expected: "X1"},
+		{n: -19, expected: "X1"},
+		{n: -35, expected: "X3"},
+		{n: -99, expected: "X9"},
+		{n: -100, expected: "XX"},
+		{n: -128, expected: "XX"},
+	}
+	for _, c := range testCases {
+		if actual := RecommendDisplay(c.n); actual != c.expected {
+			t.Errorf("RecommendDisplay(%d) expected: %q, got: %q", c.n, c.expected, actual)
+		}
+		if actual := ArticleRecommendDisplay(&fakeArticleRecord{recommend: c.n}); actual != c.expected {
+			t.Errorf("ArticleRecommendDisplay of %d expected: %q, got: %q", c.n, c.expected, actual)
+		}
+	}
+}
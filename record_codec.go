@@ -0,0 +1,286 @@
+package bbs
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Field names of user record in RecordLayout, strings are NUL terminated
+// Big5, integers and times (unix time) are little endian of field size.
+const (
+	UserFieldUserID       = "userid"
+	UserFieldRealName     = "realname"
+	UserFieldNickname     = "nickname"
+	UserFieldPassword     = "passwd"
+	UserFieldUserFlag     = "uflag"
+	UserFieldNumLoginDays = "numlogindays"
+	UserFieldNumPosts     = "numposts"
+	UserFieldLastLogin    = "lastlogin"
+	UserFieldLastHost     = "lasthost"
+	UserFieldMoney        = "money"
+
+	// UserFieldSex is the sex byte decoded by MapleGender, and
+	// UserFieldMarital is the integer of marital status. Records of layout
+	// without them return GenderUnknown and MaritalStatusUnknown.
+	UserFieldSex     = "sex"
+	UserFieldMarital = "marital"
+)
+
+// RecordField is the position of a field in a fixed size record.
+type RecordField struct {
+	Offset int
+	Size   int
+}
+
+// RecordLayout is the size of a fixed size record and the positions of its
+// fields by name, fields not in Fields are treated as zero.
+type RecordLayout struct {
+	Size   int
+	Fields map[string]RecordField
+}
+
+// field returns the bytes of field name in data, ok is false if layout has
+// no such field.
+func (l RecordLayout) field(data []byte, name string) (b []byte, ok bool) {
+	f, ok := l.Fields[name]
+	if !ok || f.Offset < 0 || f.Size <= 0 || f.Offset+f.Size > len(data) {
+		return nil, false
+	}
+	return data[f.Offset : f.Offset+f.Size], true
+}
+
+// cstr returns the bytes of field name in data until NUL.
+func (l RecordLayout) cstr(data []byte, name string) []byte {
+	b, _ := l.field(data, name)
+	return CstrToBytes(b)
+}
+
+func (l RecordLayout) uint(data []byte, name string) uint64 {
+	b, _ := l.field(data, name)
+	switch len(b) {
+	case 1:
+		return uint64(b[0])
+	case 2:
+		return uint64(binary.LittleEndian.Uint16(b))
+	case 4:
+		return uint64(binary.LittleEndian.Uint32(b))
+	case 8:
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+// RecordCodec is the record layouts of a bbs fork, such as pttbbs or SOB,
+// which share a driver but differ in struct layouts. Drivers register
+// codecs with RegisterRecordCodec, and Open selects one by the "fork"
+// argument of dataSourceName, eg: `file:///home/bbs?fork=pttbbs`.
+type RecordCodec struct {
+	// Fork is the name of fork used in dataSourceName.
+	Fork string
+	// User is the layout of user record (userec).
+	User RecordLayout
+	// Native is true if User is the layout which driver decodes itself, such
+	// as fork pttbbs of driver pttbbs. DB reads user records by connector
+	// then, so records keep the optional interfaces, password verification
+	// and string encoding of driver.
+	Native bool
+	// DecodeString decodes string fields trimmed at NUL, nil means
+	// Big5ToUtf8.
+	DecodeString func([]byte) string
+}
+
+// DecodeUserRecord decodes one user record from data by User layout. The
+// returned UserRecord can not verify passwords itself, its VerifyPassword
+// returns ErrNotSupported. User records read by DB verify passwords with
+// the scheme set by SetPasswordScheme.
+func (c *RecordCodec) DecodeUserRecord(data []byte) (UserRecord, error) {
+	return c.decodeUserRecord(data, nil, nil)
+}
+
+// decodeUserRecord decodes one user record from data by User layout, the
+// returned UserRecord verifies passwords with scheme if it is not nil, and
+// returns times in loc, nil means time.Local.
+func (c *RecordCodec) decodeUserRecord(data []byte, scheme PasswordScheme, loc *time.Location) (UserRecord, error) {
+	if c.User.Size <= 0 || len(data) < c.User.Size {
+		return nil, fmt.Errorf("%w: user record of fork %q, size %d", ErrUnknownFormat, c.Fork, len(data))
+	}
+	l := c.User
+	decodeString := c.DecodeString
+	if decodeString == nil {
+		decodeString = Big5ToUtf8
+	}
+	str := func(name string) string { return decodeString(l.cstr(data, name)) }
+	gender, maritalStatus := GenderUnknown, MaritalStatusUnknown
+	if b, ok := l.field(data, UserFieldSex); ok {
+		gender = MapleGender(b[0])
+	}
+	if _, ok := l.field(data, UserFieldMarital); ok {
+		maritalStatus = int(l.uint(data, UserFieldMarital))
+	}
+	lastLogin := time.Unix(int64(l.uint(data, UserFieldLastLogin)), 0)
+	if loc != nil {
+		lastLogin = lastLogin.In(loc)
+	}
+	return &codecUserRecord{
+		fork:           c.Fork,
+		scheme:         scheme,
+		userID:         str(UserFieldUserID),
+		realName:       str(UserFieldRealName),
+		nickname:       str(UserFieldNickname),
+		hashedPassword: string(l.cstr(data, UserFieldPassword)),
+		userFlag:       uint32(l.uint(data, UserFieldUserFlag)),
+		numLoginDays:   int(l.uint(data, UserFieldNumLoginDays)),
+		numPosts:       int(l.uint(data, UserFieldNumPosts)),
+		lastLogin:      lastLogin,
+		lastHost:       str(UserFieldLastHost),
+		money:          int(int32(l.uint(data, UserFieldMoney))),
+		gender:         gender,
+		maritalStatus:  maritalStatus,
+	}, nil
+}
+
+type codecUserRecord struct {
+	fork           string
+	scheme         PasswordScheme
+	userID         string
+	realName       string
+	nickname       string
+	hashedPassword string
+	userFlag       uint32
+	numLoginDays   int
+	numPosts       int
+	lastLogin      time.Time
+	lastHost       string
+	money          int
+	gender         Gender
+	maritalStatus  int
+}
+
+func (u *codecUserRecord) UserID() string         { return u.userID }
+func (u *codecUserRecord) HashedPassword() string { return u.hashedPassword }
+func (u *codecUserRecord) Nickname() string       { return u.nickname }
+func (u *codecUserRecord) RealName() string       { return u.realName }
+func (u *codecUserRecord) NumLoginDays() int      { return u.numLoginDays }
+func (u *codecUserRecord) NumPosts() int          { return u.numPosts }
+func (u *codecUserRecord) Money() int             { return u.money }
+func (u *codecUserRecord) LastLogin() time.Time   { return u.lastLogin }
+func (u *codecUserRecord) LastHost() string       { return u.lastHost }
+func (u *codecUserRecord) UserFlag() uint32       { return u.userFlag }
+func (u *codecUserRecord) Gender() Gender         { return u.gender }
+func (u *codecUserRecord) MaritalStatus() int     { return u.maritalStatus }
+
+var _ ProfileUserRecord = &codecUserRecord{}
+
+func (u *codecUserRecord) VerifyPassword(password string) error {
+	if u.scheme != nil {
+		return u.scheme.Verify(password, u.hashedPassword)
+	}
+	return fmt.Errorf("%w: verify password of fork %q, use SetPasswordScheme", ErrNotSupported, u.fork)
+}
+
+var (
+	recordCodecsMu sync.RWMutex
+	recordCodecs   = make(map[string]map[string]*RecordCodec)
+)
+
+// RegisterRecordCodec registers codec of a fork for driver drivername, a
+// codec registered with the same fork replaces the previous one.
+func RegisterRecordCodec(drivername string, codec *RecordCodec) {
+	recordCodecsMu.Lock()
+	defer recordCodecsMu.Unlock()
+	if recordCodecs[drivername] == nil {
+		recordCodecs[drivername] = make(map[string]*RecordCodec)
+	}
+	recordCodecs[drivername][codec.Fork] = codec
+}
+
+// recordCodec returns the codec of fork registered for drivername.
+func recordCodec(drivername, fork string) (*RecordCodec, bool) {
+	recordCodecsMu.RLock()
+	defer recordCodecsMu.RUnlock()
+	c, ok := recordCodecs[drivername][fork]
+	return c, ok
+}
+
+// dataSourceFork returns the "fork" argument of dataSourceName, eg: pttbbs
+// of `file:///home/bbs?fork=pttbbs`.
+func dataSourceFork(dataSourceName string) string {
+	i := strings.Index(dataSourceName, "?")
+	if i < 0 {
+		return ""
+	}
+	q, err := url.ParseQuery(dataSourceName[i+1:])
+	if err != nil {
+		return ""
+	}
+	return q.Get("fork")
+}
+
+// RecordCodec returns the codec selected by the "fork" argument of
+// dataSourceName in Open, it is nil if no fork is selected.
+func (db *DB) RecordCodec() *RecordCodec {
+	return db.recordCodec
+}
+
+// userCodec returns the codec which decodes user records instead of
+// connector, it is nil if no fork is selected or the codec is Native.
+func (db *DB) userCodec() *RecordCodec {
+	if db.recordCodec == nil || db.recordCodec.Native {
+		return nil
+	}
+	return db.recordCodec
+}
+
+// readUserRecordsWithCodec reads at most limit user records starting from
+// offset and decodes them by userCodec, limit 0 means no limit.
+func (db *DB) readUserRecordsWithCodec(offset, limit int) ([]UserRecord, error) {
+	path, err := db.userRecordsPath()
+	if err != nil {
+		log.Println("bbs: open file error:", err)
+		return nil, err
+	}
+	codec := db.userCodec()
+	size := codec.User.Size
+	recs, err := db.readRange(path, size, offset, limit, func(data []byte) (interface{}, error) {
+		return codec.decodeUserRecord(data, db.passwordScheme, db.Location())
+	})
+	if err != nil {
+		log.Println("bbs: readRange error:", err)
+		return nil, err
+	}
+	db.countRead(userRecordKind, len(recs)*size)
+	ret := make([]UserRecord, len(recs))
+	for i, r := range recs {
+		ret[i] = r.(UserRecord)
+	}
+	return ret, nil
+}
+
+// codecBatchSize is the number of user records read at a time by
+// eachUserRecordWithCodec.
+const codecBatchSize = 256
+
+// eachUserRecordWithCodec calls fn with each user record decoded by
+// userCodec and stops when fn returns false, records are read in batches of
+// codecBatchSize.
+func (db *DB) eachUserRecordWithCodec(fn func(UserRecord) bool) error {
+	for offset := 0; ; offset += codecBatchSize {
+		recs, err := db.readUserRecordsWithCodec(offset, codecBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, r := range recs {
+			if !fn(r) {
+				return nil
+			}
+		}
+		if len(recs) < codecBatchSize {
+			return nil
+		}
+	}
+}
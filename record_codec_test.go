@@ -0,0 +1,215 @@
+package bbs
+
+import (
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Ptt-official-app/go-bbs/crypt"
+)
+
+// testForkA and testForkB are two userec layouts which place the same
+// fields at different offsets, like the forks of Maple.
+var (
+	testForkA = &RecordCodec{
+		Fork: "a",
+		User: RecordLayout{
+			Size: 64,
+			Fields: map[string]RecordField{
+				UserFieldUserID:       {Offset: 4, Size: 13},
+				UserFieldNickname:     {Offset: 17, Size: 24},
+				UserFieldNumLoginDays: {Offset: 44, Size: 4},
+				UserFieldNumPosts:     {Offset: 48, Size: 2},
+				UserFieldLastLogin:    {Offset: 52, Size: 4},
+				UserFieldMoney:        {Offset: 56, Size: 4},
+			},
+		},
+	}
+	testForkB = &RecordCodec{
+		Fork: "b",
+		User: RecordLayout{
+			Size: 48,
+			Fields: map[string]RecordField{
+				UserFieldMoney:        {Offset: 0, Size: 4},
+				UserFieldUserID:       {Offset: 4, Size: 13},
+				UserFieldRealName:     {Offset: 17, Size: 20},
+				UserFieldNumLoginDays: {Offset: 37, Size: 1},
+				UserFieldLastLogin:    {Offset: 40, Size: 4},
+			},
+		},
+	}
+)
+
+func encodeTestForkUser(c *RecordCodec, userID, name string, logins, money int, lastLogin time.Time) []byte {
+	data := make([]byte, c.User.Size)
+	put := func(field string, v uint64) {
+		f, ok := c.User.Fields[field]
+		if !ok {
+			return
+		}
+		switch f.Size {
+		case 1:
+			data[f.Offset] = byte(v)
+		case 2:
+			binary.LittleEndian.PutUint16(data[f.Offset:], uint16(v))
+		case 4:
+			binary.LittleEndian.PutUint32(data[f.Offset:], uint32(v))
+		}
+	}
+	copy(data[c.User.Fields[UserFieldUserID].Offset:], userID)
+	if f, ok := c.User.Fields[UserFieldNickname]; ok {
+		copy(data[f.Offset:f.Offset+f.Size-1], Utf8ToBig5(name))
+	}
+	if f, ok := c.User.Fields[UserFieldRealName]; ok {
+		copy(data[f.Offset:f.Offset+f.Size-1], Utf8ToBig5(name))
+	}
+	put(UserFieldNumLoginDays, uint64(logins))
+	put(UserFieldNumPosts, 7)
+	put(UserFieldLastLogin, uint64(lastLogin.Unix()))
+	put(UserFieldMoney, uint64(uint32(int32(money))))
+	return data
+}
+
+func TestRecordCodecDecodeUserRecord(t *testing.T) {
+	lastLogin := time.Unix(1600681288, 0)
+	for _, c := range []*RecordCodec{testForkA, testForkB} {
+		u, err := c.DecodeUserRecord(encodeTestForkUser(c, "SYSOP", "站長", 200, -10, lastLogin))
+		if err != nil {
+			t.Fatalf("fork %v DecodeUserRecord error: %v", c.Fork, err)
+		}
+		if u.UserID() != "SYSOP" || u.NumLoginDays() != 200 || u.Money() != -10 || !u.LastLogin().Equal(lastLogin) {
+			t.Errorf("fork %v user not match, got: %v %v %v %v", c.Fork, u.UserID(), u.NumLoginDays(), u.Money(), u.LastLogin())
+		}
+		if _, ok := c.User.Fields[UserFieldNickname]; ok && u.Nickname() != "站長" {
+			t.Errorf("fork %v nickname expected: 站長, got: %v", c.Fork, u.Nickname())
+		}
+		if _, ok := c.User.Fields[UserFieldRealName]; ok && u.RealName() != "站長" {
+			t.Errorf("fork %v real name expected: 站長, got: %v", c.Fork, u.RealName())
+		}
+	}
+
+	u, _ := testForkA.DecodeUserRecord(encodeTestForkUser(testForkA, "SYSOP", "", 1, 0, lastLogin))
+	if u.NumPosts() != 7 || u.RealName() != "" {
+		t.Errorf("fork a num posts expected: 7 and empty real name, got: %v %q", u.NumPosts(), u.RealName())
+	}
+	if err := u.VerifyPassword("123456"); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("VerifyPassword should return ErrNotSupported, got: %v", err)
+	}
+
+	if _, err := testForkA.DecodeUserRecord(make([]byte, 10)); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("DecodeUserRecord of short data should return ErrUnknownFormat, got: %v", err)
+	}
+}
+
+func TestOpenWithRecordCodec(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".PASSWDS")
+	data := append(encodeTestForkUser(testForkB, "SYSOP", "站長", 1, 100, time.Unix(0, 0)),
+		encodeTestForkUser(testForkB, "pichu", "皮丘", 2, 10, time.Unix(0, 0))...)
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+
+	Register("fakefork", &fakeConnector{
+		fakeOpen:               func() error { return nil },
+		fakeGetUserRecordsPath: func() (string, error) { return path, nil },
+	})
+	defer Unregister("fakefork")
+	RegisterRecordCodec("fakefork", testForkA)
+	RegisterRecordCodec("fakefork", testForkB)
+
+	db, err := Open("fakefork", "file://"+dir+"?fork=b")
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	if db.RecordCodec() != testForkB {
+		t.Errorf("RecordCodec expected: fork b, got: %v", db.RecordCodec())
+	}
+	recs, err := db.ReadUserRecords()
+	if err != nil {
+		t.Fatalf("ReadUserRecords error: %v", err)
+	}
+	if len(recs) != 2 || recs[1].UserID() != "pichu" || recs[1].RealName() != "皮丘" {
+		t.Errorf("ReadUserRecords with fork b not match, got: %v", recs)
+	}
+	if ok, err := db.UsersExist([]string{"PICHU"}); err != nil || !ok["PICHU"] {
+		t.Errorf("UsersExist with fork b expected: true, got: %v, %v", ok, err)
+	}
+	recs, err = db.ReadUserRecordsRange(1, 1)
+	if err != nil || len(recs) != 1 || recs[0].UserID() != "pichu" {
+		t.Errorf("ReadUserRecordsRange with fork b not match, got: %v, %v", recs, err)
+	}
+	db.SetPasswordScheme(crypt.DES{})
+	recs, err = db.ReadUserRecords()
+	if err != nil {
+		t.Fatalf("ReadUserRecords error: %v", err)
+	}
+	if err := recs[0].VerifyPassword("123456"); !errors.Is(err, crypt.ErrPasswordMismatch) {
+		t.Errorf("VerifyPassword should use password scheme of DB, got: %v", err)
+	}
+	loc := time.FixedZone("CST", 8*60*60)
+	db.SetLocation(loc)
+	recs, err = db.ReadUserRecords()
+	if err != nil {
+		t.Fatalf("ReadUserRecords error: %v", err)
+	}
+	if got := recs[0].LastLogin(); got.Location() != loc || got.Unix() != 0 {
+		t.Errorf("LastLogin should be in location of DB %v, got: %v", loc, got)
+	}
+
+	var ids []string
+	err = db.EachUserRecord(func(u UserRecord) (bool, error) {
+		ids = append(ids, u.UserID())
+		return false, nil
+	})
+	if err != nil || len(ids) != 2 || ids[1] != "pichu" {
+		t.Errorf("EachUserRecord with fork b not match, got: %v, %v", ids, err)
+	}
+
+	db, err = Open("fakefork", dir)
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	if db.RecordCodec() != nil {
+		t.Errorf("RecordCodec should be nil without fork")
+	}
+
+	if _, err := Open("fakefork", dir+"?fork=sob"); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Open with unknown fork should return ErrNotSupported, got: %v", err)
+	}
+}
+
+func TestRecordCodecDecodeProfile(t *testing.T) {
+	// userec of a Maple fork: userid, then birthday (month, day, year), sex
+	// and marital status.
+	c := &RecordCodec{
+		Fork: "maple",
+		User: RecordLayout{
+			Size: 24,
+			Fields: map[string]RecordField{
+				UserFieldUserID:  {Offset: 0, Size: 13},
+				UserFieldSex:     {Offset: 16, Size: 1},
+				UserFieldMarital: {Offset: 17, Size: 1},
+			},
+		},
+	}
+	data := []byte("pichu\x00\x00\x00\x00\x00\x00\x00\x00\x0c\x19\x50\x03\x01\x00\x00\x00\x00\x00\x00")
+	u, err := c.DecodeUserRecord(data)
+	if err != nil {
+		t.Fatalf("DecodeUserRecord error: %v", err)
+	}
+	if u.UserID() != "pichu" || UserGender(u) != GenderFemale || UserMaritalStatus(u) != MaritalStatusMarried {
+		t.Errorf("profile expected: pichu, female, married, got: %v, %v, %v", u.UserID(), UserGender(u), UserMaritalStatus(u))
+	}
+
+	u, err = testForkA.DecodeUserRecord(encodeTestForkUser(testForkA, "SYSOP", "站長", 1, 0, time.Unix(0, 0)))
+	if err != nil {
+		t.Fatalf("DecodeUserRecord error: %v", err)
+	}
+	if UserGender(u) != GenderUnknown || UserMaritalStatus(u) != MaritalStatusUnknown {
+		t.Errorf("profile of layout without profile fields expected: unknown, -1, got: %v, %v", UserGender(u), UserMaritalStatus(u))
+	}
+}
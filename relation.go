@@ -0,0 +1,96 @@
+package bbs
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"log"
+	"strings"
+)
+
+// RelationConnector is a connector for bbs which supports user relation
+// lists, 好友名單 (friends) and 壞人名單 (rejects).
+type RelationConnector interface {
+
+	// GetUserFriendsPath should return the friend list file path of user,
+	// eg: BBSHome/home/{{u}}/{{userID}}/overrides
+	GetUserFriendsPath(userID string) (string, error)
+
+	// GetUserRejectsPath should return the reject list file path of user,
+	// eg: BBSHome/home/{{u}}/{{userID}}/reject
+	GetUserRejectsPath(userID string) (string, error)
+}
+
+// ReadUserFriends returns the user ids in friend list of userID. It returns
+// an empty slice if user has no friend list file.
+func (db *DB) ReadUserFriends(userID string) ([]string, error) {
+	return db.readUserRelation(userID, func(rc RelationConnector) (string, error) {
+		return db.userFriendsPath(rc, userID)
+	})
+}
+
+// ReadUserRejects returns the user ids in reject list of userID. It returns
+// an empty slice if user has no reject list file.
+func (db *DB) ReadUserRejects(userID string) ([]string, error) {
+	return db.readUserRelation(userID, func(rc RelationConnector) (string, error) {
+		return db.userRejectsPath(rc, userID)
+	})
+}
+
+func (db *DB) readUserRelation(userID string, getPath func(rc RelationConnector) (string, error)) ([]string, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("%w: empty user id", ErrInvalidName)
+	}
+	if err := checkNames(userID); err != nil {
+		return nil, err
+	}
+	var rc RelationConnector
+	if !db.connectorAs(&rc) {
+		return nil, ErrNotSupported
+	}
+
+	path, err := getPath(rc)
+	if err != nil {
+		log.Println("bbs: get user relation path error:", err)
+		return nil, err
+	}
+
+	f, err := db.openFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		log.Println("bbs: open user relation file error:", err)
+		return nil, err
+	}
+	defer f.Close()
+
+	buf, err := ioutil.ReadAll(f)
+	if err != nil {
+		log.Println("bbs: read user relation file error:", err)
+		return nil, err
+	}
+	return parseUserRelation(buf)
+}
+
+// parseUserRelation parses relation list file, each line is a user id
+// optionally followed by whitespace and a description in Big5, which is
+// ignored.
+func parseUserRelation(data []byte) ([]string, error) {
+	ret := []string{}
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		ret = append(ret, fields[0])
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
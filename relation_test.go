@@ -0,0 +1,66 @@
+package bbs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+type fakeRelationConnector struct {
+	fakeConnector
+}
+
+var _ RelationConnector = &fakeRelationConnector{}
+
+func (c *fakeRelationConnector) GetUserFriendsPath(userID string) (string, error) {
+	return "home/" + userID + "/overrides", nil
+}
+
+func (c *fakeRelationConnector) GetUserRejectsPath(userID string) (string, error) {
+	return "home/" + userID + "/reject", nil
+}
+
+func TestReadUserRelation(t *testing.T) {
+	db := &DB{
+		connector: &fakeRelationConnector{},
+		fsys: fstest.MapFS{
+			"home/pichu/overrides": &fstest.MapFile{Data: append([]byte("SYSOP   "), Utf8ToBig5("站長\n\nKahou\n")...)},
+			"home/pichu/reject":    &fstest.MapFile{Data: []byte("troll   spam\r\n")},
+		},
+	}
+
+	friends, err := db.ReadUserFriends("pichu")
+	if err != nil {
+		t.Fatalf("ReadUserFriends error: %v", err)
+	}
+	if expected := []string{"SYSOP", "Kahou"}; !reflect.DeepEqual(friends, expected) {
+		t.Errorf("friends expected: %v, got: %v", expected, friends)
+	}
+	rejects, err := db.ReadUserRejects("pichu")
+	if err != nil {
+		t.Fatalf("ReadUserRejects error: %v", err)
+	}
+	if expected := []string{"troll"}; !reflect.DeepEqual(rejects, expected) {
+		t.Errorf("rejects expected: %v, got: %v", expected, rejects)
+	}
+
+	friends, err = db.ReadUserFriends("SYSOP")
+	if err != nil || friends == nil || len(friends) != 0 {
+		t.Errorf("ReadUserFriends without file expected: [], <nil>, got: %v, %v", friends, err)
+	}
+
+	for _, userID := range []string{"", "../pichu", "a/b"} {
+		if _, err := db.ReadUserFriends(userID); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("ReadUserFriends(%q) should return ErrInvalidName, got: %v", userID, err)
+		}
+		if _, err := db.ReadUserRejects(userID); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("ReadUserRejects(%q) should return ErrInvalidName, got: %v", userID, err)
+		}
+	}
+
+	db = &DB{connector: &fakeConnector{}}
+	if _, err := db.ReadUserFriends("pichu"); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("ReadUserFriends should return ErrNotSupported, got: %v", err)
+	}
+}
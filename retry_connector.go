@@ -0,0 +1,163 @@
+package bbs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"syscall"
+	"time"
+)
+
+// ContextConnector is implemented by connector which carries a context, such
+// as a connector bound to a request. WithRetry stops retrying when the
+// context is done.
+type ContextConnector interface {
+	// Context returns the context of connector.
+	Context() context.Context
+}
+
+// retryConnector retries read methods of Connector on transient errors.
+type retryConnector struct {
+	Connector
+	attempts int
+	backoff  time.Duration
+}
+
+// WithRetry returns a connector which retries the read methods of c, such
+// as ReadUserRecordsFile and ReadBoardArticleFile, at most attempts times in
+// total when they fail. It waits backoff before the first retry and doubles
+// it for each following retry. Only transient errors are retried, which are
+// EAGAIN, EINTR, EBUSY and timeouts, other errors such as os.ErrNotExist and
+// *DecodeError are returned immediately. If c or a connector wrapped by c
+// implements ContextConnector, nothing is read once its context is done.
+// Write methods are not retried.
+func WithRetry(c Connector, attempts int, backoff time.Duration) Connector {
+	if attempts < 1 {
+		attempts = 1
+	}
+	return &retryConnector{
+		Connector: c,
+		attempts:  attempts,
+		backoff:   backoff,
+	}
+}
+
+// Unwrap returns the connector wrapped by retry.
+func (c *retryConnector) Unwrap() Connector {
+	return c.Connector
+}
+
+// isTransientError returns true if err may go away by retrying, such as an
+// interrupted or busy system call and a timeout.
+func isTransientError(err error) bool {
+	if errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EINTR) || errors.Is(err, syscall.EBUSY) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+	var te interface{ Timeout() bool }
+	return errors.As(err, &te) && te.Timeout()
+}
+
+// retry calls fn until it succeeds, returns non-transient error or runs out
+// of attempts. It returns the error of context without calling fn if the
+// context is already done.
+func (c *retryConnector) retry(fn func() error) error {
+	ctx := context.Background()
+	var cc ContextConnector
+	if connectorAs(c.Connector, &cc) {
+		ctx = cc.Context()
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	backoff := c.backoff
+	var err error
+	for i := 0; i < c.attempts; i++ {
+		if i > 0 {
+			t := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return err
+			case <-t.C:
+			}
+			backoff *= 2
+		}
+
+		err = fn()
+		if err == nil || !isTransientError(err) {
+			return err
+		}
+	}
+	return err
+}
+
+func (c *retryConnector) ReadUserRecordsFile(name string) (recs []UserRecord, err error) {
+	err = c.retry(func() error {
+		recs, err = c.Connector.ReadUserRecordsFile(name)
+		return err
+	})
+	return recs, err
+}
+
+func (c *retryConnector) ReadUserFavoriteRecordsFile(name string) (recs []FavoriteRecord, err error) {
+	err = c.retry(func() error {
+		recs, err = c.Connector.ReadUserFavoriteRecordsFile(name)
+		return err
+	})
+	return recs, err
+}
+
+func (c *retryConnector) ReadBoardRecordsFile(name string) (recs []BoardRecord, err error) {
+	err = c.retry(func() error {
+		recs, err = c.Connector.ReadBoardRecordsFile(name)
+		return err
+	})
+	return recs, err
+}
+
+func (c *retryConnector) ReadArticleRecordsFile(name string) (recs []ArticleRecord, err error) {
+	err = c.retry(func() error {
+		recs, err = c.Connector.ReadArticleRecordsFile(name)
+		return err
+	})
+	return recs, err
+}
+
+// ReadRecordFileRange retries RecordRangeConnector of the wrapped connector,
+// it returns ErrNotSupported if the wrapped connector does not implement it.
+func (c *retryConnector) ReadRecordFileRange(name string, offset int64, size int) (buf []byte, err error) {
+	var rc RecordRangeConnector
+	if !connectorAs(c.Connector, &rc) {
+		return nil, ErrNotSupported
+	}
+	err = c.retry(func() error {
+		buf, err = rc.ReadRecordFileRange(name, offset, size)
+		return err
+	})
+	return buf, err
+}
+
+func (c *retryConnector) ReadBoardArticleFile(name string) (buf []byte, err error) {
+	err = c.retry(func() error {
+		buf, err = c.Connector.ReadBoardArticleFile(name)
+		return err
+	})
+	return buf, err
+}
+
+// OpenBoardArticleFile retries OpenArticleFileConnector of the wrapped
+// connector, it returns ErrNotSupported if the wrapped connector does not
+// implement it. Reading the opened file is not retried.
+func (c *retryConnector) OpenBoardArticleFile(name string) (f io.ReadCloser, err error) {
+	var oc OpenArticleFileConnector
+	if !connectorAs(c.Connector, &oc) {
+		return nil, ErrNotSupported
+	}
+	err = c.retry(func() error {
+		f, err = oc.OpenBoardArticleFile(name)
+		return err
+	})
+	return f, err
+}
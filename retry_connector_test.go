@@ -0,0 +1,92 @@
+package bbs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type fakeContextConnector struct {
+	fakeConnector
+	ctx context.Context
+}
+
+func (c *fakeContextConnector) Context() context.Context { return c.ctx }
+
+func TestWithRetry(t *testing.T) {
+	numCalls := 0
+	errs := []error{syscall.EBUSY, syscall.EBUSY, nil}
+	c := &fakeConnector{
+		fakeReadBoardRecordsFile: func() ([]BoardRecord, error) {
+			err := errs[numCalls]
+			numCalls++
+			if err != nil {
+				return nil, err
+			}
+			return testBoardRecords, nil
+		},
+	}
+
+	recs, err := WithRetry(c, 3, time.Millisecond).ReadBoardRecordsFile(".BRD")
+	if err != nil {
+		t.Fatalf("ReadBoardRecordsFile error: %v", err)
+	}
+	if numCalls != 3 || len(recs) != len(testBoardRecords) {
+		t.Errorf("ReadBoardRecordsFile should succeed after 3 calls, got: %v calls", numCalls)
+	}
+
+	numCalls = 0
+	_, err = WithRetry(c, 2, time.Millisecond).ReadBoardRecordsFile(".BRD")
+	if err == nil || numCalls != 2 {
+		t.Errorf("ReadBoardRecordsFile should fail after 2 calls, got: %v calls, err: %v", numCalls, err)
+	}
+
+	numCalls = 0
+	errs = []error{fmt.Errorf("open .BRD: %w", os.ErrNotExist)}
+	_, err = WithRetry(c, 3, time.Millisecond).ReadBoardRecordsFile(".BRD")
+	if !errors.Is(err, os.ErrNotExist) || numCalls != 1 {
+		t.Errorf("not exist error should not be retried, got: %v calls, err: %v", numCalls, err)
+	}
+
+	for _, e := range []error{
+		&DecodeError{Path: ".BRD", Index: 1, Offset: 256, Err: io.ErrUnexpectedEOF},
+		fmt.Errorf("open .BRD: %w", fs.ErrPermission),
+		errors.New("unknown"),
+	} {
+		numCalls = 0
+		errs = []error{e}
+		_, err = WithRetry(c, 3, time.Millisecond).ReadBoardRecordsFile(".BRD")
+		if err != e || numCalls != 1 {
+			t.Errorf("%v should not be retried, got: %v calls, err: %v", e, numCalls, err)
+		}
+	}
+}
+
+func TestWithRetryContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	numCalls := 0
+	c := &fakeContextConnector{ctx: ctx}
+	c.fakeReadBoardArticleFile = func() ([]byte, error) {
+		numCalls++
+		return nil, syscall.EBUSY
+	}
+
+	_, err := WithRetry(c, 3, time.Hour).ReadBoardArticleFile("M.1.A.001")
+	if !errors.Is(err, context.Canceled) || numCalls != 0 {
+		t.Errorf("nothing should be read when context is done, got: %v calls, err: %v", numCalls, err)
+	}
+
+	// ContextConnector is found through wrappers.
+	_, err = WithRetry(WithCache(c, time.Hour), 3, time.Hour).ReadBoardArticleFile("M.1.A.001")
+	if !errors.Is(err, context.Canceled) || numCalls != 0 {
+		t.Errorf("wrapped ContextConnector should be used, got: %v calls, err: %v", numCalls, err)
+	}
+}
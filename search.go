@@ -0,0 +1,92 @@
+package bbs
+
+import (
+	"log"
+	"strings"
+	"unicode/utf8"
+)
+
+// SearchOptions controls how search methods match query.
+type SearchOptions struct {
+	// CaseSensitive matches query with case sensitive, default is case-insensitive.
+	CaseSensitive bool
+	// Prefix matches only when the field starts with query, default is substring.
+	Prefix bool
+	// Limit is the max number of results, 0 means no limit.
+	Limit int
+	// BoardIDCaseInsensitive matches board ids case-insensitively even if
+	// CaseSensitive is set, board ids are case-insensitive in most bbs.
+	BoardIDCaseInsensitive bool
+}
+
+// normalizeQuery returns query in UTF-8, query which is not valid UTF-8 is
+// treated as Big5 since records are decoded into UTF-8 by drivers.
+func normalizeQuery(query string) string {
+	if utf8.ValidString(query) {
+		return query
+	}
+	return Big5ToUtf8([]byte(query))
+}
+
+func (opts SearchOptions) match(s, query string) bool {
+	if !opts.CaseSensitive {
+		s = strings.ToLower(s)
+		query = strings.ToLower(query)
+	}
+	if opts.Prefix {
+		return strings.HasPrefix(s, query)
+	}
+	return strings.Contains(s, query)
+}
+
+func (opts SearchOptions) reachLimit(n int) bool {
+	return opts.Limit > 0 && n >= opts.Limit
+}
+
+// SearchBoardArticles returns the article records in board whose title
+// matches query, in .DIR order. It is `/` search in article list of bbs.
+func (db *DB) SearchBoardArticles(boardID, query string, opts SearchOptions) ([]ArticleRecord, error) {
+	recs, err := db.ReadBoardArticleRecordsFile(boardID)
+	if err != nil {
+		log.Println("bbs: ReadBoardArticleRecordsFile error:", err)
+		return nil, err
+	}
+
+	query = normalizeQuery(query)
+	ret := []ArticleRecord{}
+	for _, r := range recs {
+		if opts.reachLimit(len(ret)) {
+			break
+		}
+		if opts.match(r.Title(), query) {
+			ret = append(ret, r)
+		}
+	}
+	return ret, nil
+}
+
+// SearchBoards returns the board records whose board id or title matches
+// query, in .BRD order. It is board search in class list of bbs.
+func (db *DB) SearchBoards(query string, opts SearchOptions) ([]BoardRecord, error) {
+	recs, err := db.ReadBoardRecords()
+	if err != nil {
+		log.Println("bbs: ReadBoardRecords error:", err)
+		return nil, err
+	}
+
+	query = normalizeQuery(query)
+	idOpts := opts
+	if opts.BoardIDCaseInsensitive {
+		idOpts.CaseSensitive = false
+	}
+	ret := []BoardRecord{}
+	for _, r := range recs {
+		if opts.reachLimit(len(ret)) {
+			break
+		}
+		if idOpts.match(r.BoardID(), query) || opts.match(r.Title(), query) {
+			ret = append(ret, r)
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,170 @@
+package bbs
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testArticleRecords = []ArticleRecord{
+	&fakeArticleRecord{filename: "M.1599059246.A.CF6", title: "[閒聊] 自己的文章自己寫", owner: "SYSOP"},
+	&fakeArticleRecord{filename: "M.1599059415.A.FBA", title: "[討論] 賞大稻埕煙火遠離人潮！", owner: "pichu"},
+	&fakeArticleRecord{filename: "M.1599059496.A.2BE", title: "Re: [閒聊] 自己的文章自己寫", owner: "Pichu"},
+	&fakeArticleRecord{filename: "M.1599059500.A.123", title: "[問題] Golang test", owner: "SYSOP"},
+}
+
+func newTestArticleRecordsDB(recs []ArticleRecord) *DB {
+	return &DB{
+		connector: &fakeConnector{
+			fakeGetBoardArticleRecordsPath: func() (string, error) {
+				return ".DIR", nil
+			},
+			fakeReadArticleRecordsFile: func() ([]ArticleRecord, error) {
+				return recs, nil
+			},
+		},
+	}
+}
+
+func articleFilenames(recs []ArticleRecord) []string {
+	ret := []string{}
+	for _, r := range recs {
+		ret = append(ret, r.Filename())
+	}
+	return ret
+}
+
+func TestSearchBoardArticles(t *testing.T) {
+	db := newTestArticleRecordsDB(testArticleRecords)
+
+	tests := []struct {
+		name     string
+		query    string
+		opts     SearchOptions
+		expected []string
+	}{
+		{
+			name:     "substring",
+			query:    "[閒聊]",
+			expected: []string{"M.1599059246.A.CF6", "M.1599059496.A.2BE"},
+		},
+		{
+			name:     "prefix",
+			query:    "[閒聊]",
+			opts:     SearchOptions{Prefix: true},
+			expected: []string{"M.1599059246.A.CF6"},
+		},
+		{
+			name:     "limit",
+			query:    "[閒聊]",
+			opts:     SearchOptions{Limit: 1},
+			expected: []string{"M.1599059246.A.CF6"},
+		},
+		{
+			name:     "case-insensitive",
+			query:    "golang",
+			expected: []string{"M.1599059500.A.123"},
+		},
+		{
+			name:     "case sensitive",
+			query:    "golang",
+			opts:     SearchOptions{CaseSensitive: true},
+			expected: []string{},
+		},
+		{
+			name:     "big5 query",
+			query:    string(Utf8ToBig5("煙火")),
+			expected: []string{"M.1599059415.A.FBA"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recs, err := db.SearchBoardArticles("SYSOP", tt.query, tt.opts)
+			if err != nil {
+				t.Errorf("SearchBoardArticles error: %v", err)
+			}
+			if actual := articleFilenames(recs); !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("search result not match, expected: %v, got: %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSearchBoards(t *testing.T) {
+	db := &DB{
+		connector: &fakeConnector{
+			fakeGetBoardRecordsPath: func() (string, error) { return ".BRD", nil },
+			fakeReadBoardRecordsFile: func() ([]BoardRecord, error) {
+				return []BoardRecord{
+					&fakeBoardRecord{boardID: "SYSOP", title: "嘰哩 ◎站長好!"},
+					&fakeBoardRecord{boardID: "Gossiping", title: "綜合 ◎八卦版"},
+					&fakeBoardRecord{boardID: "Baseball", title: "棒球 ◎棒球版"},
+					&fakeBoardRecord{boardID: "sysop_test", title: "測試 ◎sysop test"},
+				}, nil
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		opts     SearchOptions
+		expected []string
+	}{
+		{
+			name:     "board id or title",
+			query:    "sysop",
+			expected: []string{"SYSOP", "sysop_test"},
+		},
+		{
+			name:     "chinese title",
+			query:    "棒球",
+			expected: []string{"Baseball"},
+		},
+		{
+			name:     "big5 query",
+			query:    string(Utf8ToBig5("八卦")),
+			expected: []string{"Gossiping"},
+		},
+		{
+			name:     "limit",
+			query:    "◎",
+			opts:     SearchOptions{Limit: 2},
+			expected: []string{"SYSOP", "Gossiping"},
+		},
+		{
+			name:     "case sensitive",
+			query:    "SYSOP",
+			opts:     SearchOptions{CaseSensitive: true},
+			expected: []string{"SYSOP"},
+		},
+		{
+			name:     "case-insensitive board id",
+			query:    "SYSOP",
+			opts:     SearchOptions{CaseSensitive: true, BoardIDCaseInsensitive: true},
+			expected: []string{"SYSOP", "sysop_test"},
+		},
+		{
+			name:     "prefix",
+			query:    "bas",
+			opts:     SearchOptions{Prefix: true},
+			expected: []string{"Baseball"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recs, err := db.SearchBoards(tt.query, tt.opts)
+			if err != nil {
+				t.Errorf("SearchBoards error: %v", err)
+			}
+			actual := []string{}
+			for _, r := range recs {
+				actual = append(actual, r.BoardID())
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("search result not match, expected: %v, got: %v", tt.expected, actual)
+			}
+		})
+	}
+}
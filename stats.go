@@ -0,0 +1,96 @@
+package bbs
+
+import "sync/atomic"
+
+// DBStats is a snapshot of the read and write counters of DB.
+type DBStats struct {
+	// UserReads, BoardReads and ArticleReads are the number of read
+	// operations of user records, board records and article records or
+	// files.
+	UserReads    uint64
+	BoardReads   uint64
+	ArticleReads uint64
+
+	// UserBytesRead, BoardBytesRead and ArticleBytesRead are the cumulative
+	// bytes read. Bytes of records are counted only if connector implements
+	// RecordSizer, bytes of article files are the size of raw file.
+	UserBytesRead    uint64
+	BoardBytesRead   uint64
+	ArticleBytesRead uint64
+
+	// UserWrites, BoardWrites and ArticleWrites are the number of
+	// successful write operations.
+	UserWrites    uint64
+	BoardWrites   uint64
+	ArticleWrites uint64
+}
+
+type recordKind int
+
+const (
+	userRecordKind recordKind = iota
+	boardRecordKind
+	articleRecordKind
+	numRecordKinds
+)
+
+// dbStats is the counters of DB, they are updated atomically.
+type dbStats struct {
+	reads     [numRecordKinds]uint64
+	bytesRead [numRecordKinds]uint64
+	writes    [numRecordKinds]uint64
+}
+
+// Stats returns a snapshot of read and write counters since DB is opened,
+// it is safe to call concurrently with reads and writes.
+func (db *DB) Stats() DBStats {
+	s := &db.stats
+	return DBStats{
+		UserReads:        atomic.LoadUint64(&s.reads[userRecordKind]),
+		BoardReads:       atomic.LoadUint64(&s.reads[boardRecordKind]),
+		ArticleReads:     atomic.LoadUint64(&s.reads[articleRecordKind]),
+		UserBytesRead:    atomic.LoadUint64(&s.bytesRead[userRecordKind]),
+		BoardBytesRead:   atomic.LoadUint64(&s.bytesRead[boardRecordKind]),
+		ArticleBytesRead: atomic.LoadUint64(&s.bytesRead[articleRecordKind]),
+		UserWrites:       atomic.LoadUint64(&s.writes[userRecordKind]),
+		BoardWrites:      atomic.LoadUint64(&s.writes[boardRecordKind]),
+		ArticleWrites:    atomic.LoadUint64(&s.writes[articleRecordKind]),
+	}
+}
+
+// countRead counts a read of kind with bytes read.
+func (db *DB) countRead(kind recordKind, bytes int) {
+	atomic.AddUint64(&db.stats.reads[kind], 1)
+	if bytes > 0 {
+		atomic.AddUint64(&db.stats.bytesRead[kind], uint64(bytes))
+	}
+}
+
+// countRecordsRead counts a read of n records of kind, bytes are counted if
+// connector implements RecordSizer.
+func (db *DB) countRecordsRead(kind recordKind, n int) {
+	db.countRead(kind, n*db.recordSize(kind))
+}
+
+// countWrite counts a successful write of kind.
+func (db *DB) countWrite(kind recordKind) {
+	atomic.AddUint64(&db.stats.writes[kind], 1)
+}
+
+// recordSize returns the record size of kind, or 0 if connector does not
+// implement RecordSizer.
+func (db *DB) recordSize(kind recordKind) int {
+	var rs RecordSizer
+	if !db.connectorAs(&rs) {
+		return 0
+	}
+	switch kind {
+	case userRecordKind:
+		return rs.UserRecordSize()
+	case boardRecordKind:
+		return rs.BoardRecordSize()
+	case articleRecordKind:
+		return rs.ArticleRecordSize()
+	}
+	return 0
+}
@@ -0,0 +1,46 @@
+package bbs
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	fc := &fakeConnector{
+		fakeGetBoardRecordsPath: func() (string, error) { return ".BRD", nil },
+		fakeReadBoardRecordsFile: func() ([]BoardRecord, error) {
+			return testBoardRecords, nil
+		},
+		fakeGetBoardArticleFilePath: func() (string, error) { return "M.1.A.001", nil },
+		fakeReadBoardArticleFile: func() ([]byte, error) {
+			return []byte("hello"), nil
+		},
+	}
+	db := &DB{connector: &fakeRecordSizerConnector{fakeConnector: fc, size: 256}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := db.ReadBoardRecords(); err != nil {
+				t.Errorf("ReadBoardRecords error: %v", err)
+			}
+			db.Stats()
+		}()
+	}
+	wg.Wait()
+	if _, err := db.ReadBoardArticleFile("SYSOP", "M.1.A.001"); err != nil {
+		t.Fatalf("ReadBoardArticleFile error: %v", err)
+	}
+
+	expected := DBStats{
+		BoardReads:       10,
+		BoardBytesRead:   uint64(10 * len(testBoardRecords) * 256),
+		ArticleReads:     1,
+		ArticleBytesRead: 5,
+	}
+	if s := db.Stats(); s != expected {
+		t.Errorf("Stats expected: %+v, got: %+v", expected, s)
+	}
+}
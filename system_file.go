@@ -0,0 +1,75 @@
+package bbs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"strings"
+)
+
+// MOTDFileName is the path of message of the day file relative to the etc
+// directory, read by ReadMOTD.
+const MOTDFileName = "motd"
+
+// SystemFileConnector is a connector for bbs which supports reading system
+// files under BBSHOME/etc, such as login screen and message of the day.
+type SystemFileConnector interface {
+
+	// ReadSystemFile should return the content of file relPath relative to
+	// the etc directory, eg: BBSHome/etc/{{relPath}}, and must not read any
+	// file outside of the etc directory.
+	ReadSystemFile(relPath string) ([]byte, error)
+}
+
+// CleanSystemFilePath returns the cleaned slash-separated relPath, it returns
+// ErrInvalidName if relPath is empty, absolute, contains backslash or NUL, or
+// has any ".." element, so the returned path is always inside the etc
+// directory.
+func CleanSystemFilePath(relPath string) (string, error) {
+	if relPath == "" || strings.ContainsAny(relPath, "\\\x00") || strings.HasPrefix(relPath, "/") {
+		return "", fmt.Errorf("%w: system file %q", ErrInvalidName, relPath)
+	}
+	for _, elem := range strings.Split(relPath, "/") {
+		if elem == ".." {
+			return "", fmt.Errorf("%w: system file %q", ErrInvalidName, relPath)
+		}
+	}
+	p := path.Clean(relPath)
+	if p == "." || !fs.ValidPath(p) {
+		return "", fmt.Errorf("%w: system file %q", ErrInvalidName, relPath)
+	}
+	return p, nil
+}
+
+// ReadSystemFile returns the content of file relPath relative to the etc
+// directory, it returns empty bytes if there is no such file. It returns
+// ErrInvalidName if relPath may point outside of the etc directory, and
+// ErrNotSupported if connector does not implement SystemFileConnector.
+func (db *DB) ReadSystemFile(relPath string) ([]byte, error) {
+	p, err := CleanSystemFilePath(relPath)
+	if err != nil {
+		return nil, err
+	}
+	var sfc SystemFileConnector
+	if !db.connectorAs(&sfc) {
+		return nil, ErrNotSupported
+	}
+
+	buf, err := sfc.ReadSystemFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []byte{}, nil
+	}
+	if err != nil {
+		log.Println("bbs: ReadSystemFile error:", err)
+		return nil, err
+	}
+	return buf, nil
+}
+
+// ReadMOTD returns the message of the day shown on connect, it returns empty
+// bytes if there is no such file.
+func (db *DB) ReadMOTD() ([]byte, error) {
+	return db.ReadSystemFile(MOTDFileName)
+}
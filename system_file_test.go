@@ -0,0 +1,49 @@
+package bbs
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+type fakeSystemFileConnector struct {
+	fakeConnector
+	fsys fstest.MapFS
+}
+
+var _ SystemFileConnector = &fakeSystemFileConnector{}
+
+func (c *fakeSystemFileConnector) ReadSystemFile(relPath string) ([]byte, error) {
+	return fs.ReadFile(c.fsys, "etc/"+relPath)
+}
+
+func TestReadSystemFile(t *testing.T) {
+	db := &DB{connector: &fakeSystemFileConnector{fsys: fstest.MapFS{
+		"etc/motd":          &fstest.MapFile{Data: []byte("welcome")},
+		"etc/Welcome":       &fstest.MapFile{Data: []byte("login")},
+		"etc/sub/note":      &fstest.MapFile{Data: []byte("note")},
+		"home/S/SYSOP/.fav": &fstest.MapFile{Data: []byte("secret")},
+	}}}
+
+	if buf, err := db.ReadMOTD(); err != nil || string(buf) != "welcome" {
+		t.Errorf("ReadMOTD expected: welcome, <nil>, got: %q, %v", buf, err)
+	}
+	if buf, err := db.ReadSystemFile("sub/./note"); err != nil || string(buf) != "note" {
+		t.Errorf("ReadSystemFile expected: note, <nil>, got: %q, %v", buf, err)
+	}
+	if buf, err := db.ReadSystemFile("notexist"); err != nil || buf == nil || len(buf) != 0 {
+		t.Errorf("ReadSystemFile of missing file expected: [], <nil>, got: %q, %v", buf, err)
+	}
+
+	for _, p := range []string{"", ".", "/etc/motd", "../home/S/SYSOP/.fav", "sub/../../home/S/SYSOP/.fav", "sub\\..\\motd", "motd\x00"} {
+		if _, err := db.ReadSystemFile(p); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("ReadSystemFile(%q) should return ErrInvalidName, got: %v", p, err)
+		}
+	}
+
+	db = &DB{connector: &fakeConnector{}}
+	if _, err := db.ReadMOTD(); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("ReadMOTD should return ErrNotSupported, got: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package bbs
+
+import "bytes"
+
+// DefaultWrapWidth is the columns of line which editors of bbs hard-wrap
+// article text at.
+const DefaultWrapWidth = 78
+
+// TextOptions configures NormalizeArticleText.
+type TextOptions struct {
+	// StripANSI removes ANSI escape sequences.
+	StripANSI bool
+	// JoinWrapped joins lines soft-wrapped by editor, a line of at least
+	// WrapWidth columns is joined with the next non-empty line without any
+	// separator, since editors wrap at the column even in the middle of a
+	// word.
+	JoinWrapped bool
+	// WrapWidth is the columns of soft-wrapped lines, 0 means
+	// DefaultWrapWidth. Columns are counted in bytes without ANSI escape
+	// sequences, which is the display width of Big5 text.
+	WrapWidth int
+	// LineEnding is written at the end of each line, such as "\n" or "\r\n",
+	// empty keeps the line ending of raw.
+	LineEnding string
+}
+
+// NormalizeArticleText returns the article text raw normalized by opts for
+// plain text uses such as search indexing, raw is not modified. Lines end
+// with "\n" or "\r\n" in raw.
+func NormalizeArticleText(raw []byte, opts TextOptions) []byte {
+	width := opts.WrapWidth
+	if width <= 0 {
+		width = DefaultWrapWidth
+	}
+
+	lines := bytes.Split(raw, []byte("\n"))
+	ret := make([]byte, 0, len(raw))
+	for i := 0; i < len(lines); i++ {
+		line, ending := splitLineEnding(lines[i], i == len(lines)-1)
+		if opts.StripANSI {
+			line = re.ReplaceAll(line, nil)
+		}
+		ret = append(ret, line...)
+
+		if opts.JoinWrapped && i+1 < len(lines) && textWidth(line) >= width {
+			next, _ := splitLineEnding(lines[i+1], i+1 == len(lines)-1)
+			if len(bytes.TrimSpace(re.ReplaceAll(next, nil))) > 0 {
+				continue
+			}
+		}
+		if ending == "" {
+			continue
+		}
+		if opts.LineEnding != "" {
+			ending = opts.LineEnding
+		}
+		ret = append(ret, ending...)
+	}
+	return ret
+}
+
+// splitLineEnding returns line without trailing "\r" and its line ending,
+// the last line of split text has no line ending.
+func splitLineEnding(line []byte, last bool) ([]byte, string) {
+	if last {
+		return line, ""
+	}
+	if bytes.HasSuffix(line, []byte("\r")) {
+		return line[:len(line)-1], "\r\n"
+	}
+	return line, "\n"
+}
+
+// textWidth returns the display columns of Big5 line.
+func textWidth(line []byte) int {
+	return len(re.ReplaceAll(line, nil))
+}
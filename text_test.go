@@ -0,0 +1,52 @@
+package bbs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeArticleText(t *testing.T) {
+	long := strings.Repeat("a", 78)
+	raw := "\x1b[1;33mtitle\x1b[m\r\n" + long + "\r\nbc\r\n\r\n" + long + "\r\n\r\nend"
+
+	testCases := []struct {
+		name     string
+		opts     TextOptions
+		expected string
+	}{
+		{
+			name:     "keep",
+			expected: raw,
+		},
+		{
+			name:     "strip ansi",
+			opts:     TextOptions{StripANSI: true},
+			expected: "title\r\n" + long + "\r\nbc\r\n\r\n" + long + "\r\n\r\nend",
+		},
+		{
+			name:     "line ending",
+			opts:     TextOptions{LineEnding: "\n"},
+			expected: "\x1b[1;33mtitle\x1b[m\n" + long + "\nbc\n\n" + long + "\n\nend",
+		},
+		{
+			name:     "join wrapped",
+			opts:     TextOptions{StripANSI: true, JoinWrapped: true, LineEnding: "\n"},
+			expected: "title\n" + long + "bc\n\n" + long + "\n\nend",
+		},
+		{
+			name:     "wrap width",
+			opts:     TextOptions{JoinWrapped: true, WrapWidth: 5},
+			expected: "\x1b[1;33mtitle\x1b[m" + long + "bc\r\n\r\n" + long + "\r\n\r\nend",
+		},
+	}
+	for _, c := range testCases {
+		input := []byte(raw)
+		actual := NormalizeArticleText(input, c.opts)
+		if string(actual) != c.expected {
+			t.Errorf("%s: expected: %q, got: %q", c.name, c.expected, actual)
+		}
+		if string(input) != raw {
+			t.Errorf("%s: raw should not be modified, got: %q", c.name, input)
+		}
+	}
+}
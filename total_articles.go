@@ -0,0 +1,105 @@
+package bbs
+
+import (
+	"context"
+	"log"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// SetTotalArticlesSkip sets the boards skipped by TotalArticles, such as
+// boards which aggregate posts of other boards. Board ids are compared
+// case-insensitively. Default is AllPostBoardID only, calling it without any
+// board id skips nothing.
+func (db *DB) SetTotalArticlesSkip(boardIDs ...string) {
+	db.totalArticlesSkip = map[string]bool{}
+	for _, id := range boardIDs {
+		db.totalArticlesSkip[strings.ToLower(id)] = true
+	}
+}
+
+func (db *DB) skipTotalArticles(boardID string) bool {
+	if db.totalArticlesSkip == nil {
+		return strings.EqualFold(boardID, AllPostBoardID)
+	}
+	return db.totalArticlesSkip[strings.ToLower(boardID)]
+}
+
+// TotalArticles returns the number of article records of all boards except
+// classes and the boards set by SetTotalArticlesSkip, see
+// TotalArticlesContext.
+func (db *DB) TotalArticles() (int64, error) {
+	return db.TotalArticlesContext(context.Background())
+}
+
+// TotalArticlesContext is TotalArticles which stops and returns the error of
+// ctx when ctx is done. Boards are counted by NumBoardArticles in parallel,
+// so records are not read if connector implements RecordSizer.
+func (db *DB) TotalArticlesContext(ctx context.Context) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	recs, err := db.ReadBoardRecords()
+	if err != nil {
+		log.Println("bbs: ReadBoardRecords error:", err)
+		return 0, err
+	}
+	boardIDs := []string{}
+	for _, r := range recs {
+		if !r.IsClass() && !db.skipTotalArticles(r.BoardID()) {
+			boardIDs = append(boardIDs, r.BoardID())
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan string)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+		total    int64
+	)
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for boardID := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				n, err := db.NumBoardArticles(boardID)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				atomic.AddInt64(&total, int64(n))
+			}
+		}()
+	}
+
+send:
+	for _, boardID := range boardIDs {
+		select {
+		case jobs <- boardID:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return 0, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
@@ -0,0 +1,62 @@
+package bbs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+type fakeTotalArticlesConnector struct {
+	fakeRecordSizerConnector
+}
+
+func (c *fakeTotalArticlesConnector) GetBoardArticleRecordsPath(boardID string) (string, error) {
+	return "boards/" + boardID + "/.DIR", nil
+}
+
+func TestTotalArticles(t *testing.T) {
+	recs := []BoardRecord{
+		&fakeBoardRecord{boardID: "1...........", isClass: true},
+		&fakeBoardRecord{boardID: "SYSOP"},
+		&fakeBoardRecord{boardID: "Test"},
+		&fakeBoardRecord{boardID: "Empty"},
+		&fakeBoardRecord{boardID: "ALLPOST"},
+	}
+	fc := &fakeConnector{
+		fakeGetBoardRecordsPath:  func() (string, error) { return ".BRD", nil },
+		fakeReadBoardRecordsFile: func() ([]BoardRecord, error) { return recs, nil },
+		fakeReadArticleRecordsFile: func() ([]ArticleRecord, error) {
+			t.Fatalf("records should not be read")
+			return nil, nil
+		},
+	}
+	db := &DB{
+		connector: &fakeTotalArticlesConnector{fakeRecordSizerConnector{fakeConnector: fc, size: 128}},
+		fsys: fstest.MapFS{
+			"boards/SYSOP/.DIR":   &fstest.MapFile{Data: make([]byte, 128*3)},
+			"boards/Test/.DIR":    &fstest.MapFile{Data: make([]byte, 128*4)},
+			"boards/ALLPOST/.DIR": &fstest.MapFile{Data: make([]byte, 128*7)},
+		},
+	}
+
+	if n, err := db.TotalArticles(); err != nil || n != 7 {
+		t.Errorf("TotalArticles expected: 7, <nil>, got: %v, %v", n, err)
+	}
+
+	db.SetTotalArticlesSkip("allpost", "test")
+	if n, err := db.TotalArticles(); err != nil || n != 3 {
+		t.Errorf("TotalArticles with skip list expected: 3, <nil>, got: %v, %v", n, err)
+	}
+
+	db.SetTotalArticlesSkip()
+	if n, err := db.TotalArticles(); err != nil || n != 14 {
+		t.Errorf("TotalArticles without skip list expected: 14, <nil>, got: %v, %v", n, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := db.TotalArticlesContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("TotalArticlesContext should return context.Canceled, got: %v", err)
+	}
+}
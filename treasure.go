@@ -0,0 +1,125 @@
+package bbs
+
+import (
+	"fmt"
+	"log"
+)
+
+// maxTreasureDepth limits the depth of WalkBoardTreasure in case of loops
+// made by symbolic links.
+const maxTreasureDepth = 32
+
+// WalkBoardTreasure walks the treasure (精華區) of board in depth-first
+// order, and calls fn with the treasure id of the directory containing r
+// and whether r is a directory. Directories are passed to fn before their
+// entries. An entry is a directory if its records file exists. It returns
+// nil if board has no treasure.
+func (db *DB) WalkBoardTreasure(boardID string, fn func(treasureID []string, r ArticleRecord, isDir bool) error) error {
+	return db.walkBoardTreasure(boardID, []string{}, fn)
+}
+
+func (db *DB) walkBoardTreasure(boardID string, treasureID []string, fn func([]string, ArticleRecord, bool) error) error {
+	if len(treasureID) > maxTreasureDepth {
+		return fmt.Errorf("bbs: treasure of %v is deeper than %v", boardID, maxTreasureDepth)
+	}
+	if !db.isBoardTreasureDir(boardID, treasureID) {
+		return nil
+	}
+
+	recs, err := db.ReadBoardTreasureRecordsFile(boardID, treasureID)
+	if err != nil {
+		log.Println("bbs: ReadBoardTreasureRecordsFile error:", err)
+		return err
+	}
+	for _, r := range recs {
+		if r.Filename() == "" {
+			continue
+		}
+		if err := checkNames(r.Filename()); err != nil {
+			log.Println("bbs: skip treasure entry:", err)
+			continue
+		}
+		sub := append(append([]string{}, treasureID...), r.Filename())
+		isDir := db.isBoardTreasureDir(boardID, sub)
+		if err := fn(treasureID, r, isDir); err != nil {
+			return err
+		}
+		if isDir {
+			if err := db.walkBoardTreasure(boardID, sub, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isBoardTreasureDir returns true if the records file of treasureID exists.
+func (db *DB) isBoardTreasureDir(boardID string, treasureID []string) bool {
+	path, err := db.boardTreasureRecordsPath(boardID, treasureID)
+	if err != nil {
+		return false
+	}
+	_, err = db.statFile(path)
+	return err == nil
+}
+
+// TreasureEntryRecord return ArticleRecord interface which support
+// IsTreasureDir, it is implemented by records returned by ListTreasureTree.
+type TreasureEntryRecord interface {
+	// IsTreasureDir return true if the entry is a folder of treasure.
+	IsTreasureDir() bool
+	// Unwrap return the ArticleRecord of driver, which may implement other
+	// optional interfaces.
+	Unwrap() ArticleRecord
+}
+
+// treasureEntryRecord annotates ArticleRecord with whether it is a folder.
+type treasureEntryRecord struct {
+	ArticleRecord
+	isDir bool
+}
+
+func (r *treasureEntryRecord) IsTreasureDir() bool   { return r.isDir }
+func (r *treasureEntryRecord) Unwrap() ArticleRecord { return r.ArticleRecord }
+
+// ListTreasureTree returns the entries of treasure folder treasureID of
+// board, one level only, empty treasureID means the root folder. Returned
+// records implement TreasureEntryRecord, whether an entry is a folder is
+// decided by the existence of its records file, and no article file is
+// read. It returns an empty slice if the folder does not exist.
+func (db *DB) ListTreasureTree(boardID string, treasureID []string) ([]ArticleRecord, error) {
+	if _, err := db.normalizeBoardID(boardID); err != nil {
+		return nil, err
+	}
+	if treasureID == nil {
+		treasureID = []string{}
+	}
+	if err := checkNames(treasureID...); err != nil {
+		return nil, err
+	}
+	if len(treasureID) > maxTreasureDepth {
+		return nil, fmt.Errorf("bbs: treasure of %v is deeper than %v", boardID, maxTreasureDepth)
+	}
+	if !db.isBoardTreasureDir(boardID, treasureID) {
+		return []ArticleRecord{}, nil
+	}
+
+	recs, err := db.ReadBoardTreasureRecordsFile(boardID, treasureID)
+	if err != nil {
+		log.Println("bbs: ReadBoardTreasureRecordsFile error:", err)
+		return nil, err
+	}
+	ret := make([]ArticleRecord, 0, len(recs))
+	for _, r := range recs {
+		if r.Filename() == "" {
+			continue
+		}
+		if err := checkNames(r.Filename()); err != nil {
+			log.Println("bbs: skip treasure entry:", err)
+			continue
+		}
+		sub := append(append([]string{}, treasureID...), r.Filename())
+		ret = append(ret, &treasureEntryRecord{ArticleRecord: r, isDir: db.isBoardTreasureDir(boardID, sub)})
+	}
+	return ret, nil
+}
@@ -0,0 +1,153 @@
+package bbs
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeBoardHomeConnector stores boards in home, records files are lists of
+// filenames separated by newline.
+type fakeBoardHomeConnector struct {
+	fakeConnector
+	home string
+}
+
+func (c *fakeBoardHomeConnector) GetBoardArticleRecordsPath(boardID string) (string, error) {
+	return filepath.Join(c.home, "boards", boardID, ".DIR"), nil
+}
+
+func (c *fakeBoardHomeConnector) GetBoardArticleFilePath(boardID string, filename string) (string, error) {
+	return filepath.Join(c.home, "boards", boardID, filename), nil
+}
+
+func (c *fakeBoardHomeConnector) GetBoardTreasureRecordsPath(boardID string, treasureID []string) (string, error) {
+	return filepath.Join(append(append([]string{c.home, "man", boardID}, treasureID...), ".DIR")...), nil
+}
+
+func (c *fakeBoardHomeConnector) GetBoardTreasureFilePath(boardID string, treasureID []string, name string) (string, error) {
+	return filepath.Join(append(append([]string{c.home, "man", boardID}, treasureID...), name)...), nil
+}
+
+func (c *fakeBoardHomeConnector) ReadArticleRecordsFile(name string) ([]ArticleRecord, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	recs := []ArticleRecord{}
+	for _, filename := range strings.Fields(string(data)) {
+		recs = append(recs, &fakeArticleRecord{filename: filename})
+	}
+	return recs, nil
+}
+
+// writeTestFiles writes files of content keyed by slash-separated path
+// relative to dir.
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("mkdir error: %v", err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatalf("write file error: %v", err)
+		}
+	}
+}
+
+func TestWalkBoardTreasure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "treasure_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"man/SYSOP/.DIR":          "D1\nM.1.A.001\n",
+		"man/SYSOP/M.1.A.001":     "hello",
+		"man/SYSOP/D1/.DIR":       "M.2.A.002\n",
+		"man/SYSOP/D1/M.2.A.002":  "world",
+		"man/SYSOP/D2/M.3.A.003x": "unreferenced",
+	})
+	db := &DB{connector: &fakeBoardHomeConnector{home: dir}}
+
+	var visited []string
+	err = db.WalkBoardTreasure("SYSOP", func(treasureID []string, r ArticleRecord, isDir bool) error {
+		name := strings.Join(append(append([]string{}, treasureID...), r.Filename()), "/")
+		if isDir {
+			name += "/"
+		}
+		visited = append(visited, name)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkBoardTreasure error: %v", err)
+	}
+	expected := []string{"D1/", "D1/M.2.A.002", "M.1.A.001"}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("visited expected: %v, got: %v", expected, visited)
+	}
+
+	visited = nil
+	err = db.WalkBoardTreasure("Empty", func(treasureID []string, r ArticleRecord, isDir bool) error {
+		visited = append(visited, r.Filename())
+		return nil
+	})
+	if err != nil || len(visited) != 0 {
+		t.Errorf("board without treasure expected no entries, got: %v, %v", visited, err)
+	}
+}
+
+func TestListTreasureTree(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"man/SYSOP/.DIR":         "D1\nM.1.A.001\n",
+		"man/SYSOP/M.1.A.001":    "hello",
+		"man/SYSOP/D1/.DIR":      "M.2.A.002\n../x\n",
+		"man/SYSOP/D1/M.2.A.002": "world",
+	})
+	db := &DB{connector: &fakeBoardHomeConnector{home: dir}}
+
+	entries := func(recs []ArticleRecord) []string {
+		ret := []string{}
+		for _, r := range recs {
+			name := r.Filename()
+			if r.(TreasureEntryRecord).IsTreasureDir() {
+				name += "/"
+			}
+			ret = append(ret, name)
+		}
+		return ret
+	}
+
+	testCases := []struct {
+		treasureID []string
+		expected   []string
+	}{
+		{treasureID: nil, expected: []string{"D1/", "M.1.A.001"}},
+		{treasureID: []string{}, expected: []string{"D1/", "M.1.A.001"}},
+		{treasureID: []string{"D1"}, expected: []string{"M.2.A.002"}},
+		{treasureID: []string{"D2"}, expected: []string{}},
+	}
+	for _, c := range testCases {
+		recs, err := db.ListTreasureTree("SYSOP", c.treasureID)
+		if err != nil {
+			t.Fatalf("ListTreasureTree(%v) error: %v", c.treasureID, err)
+		}
+		if actual := entries(recs); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("ListTreasureTree(%v) expected: %v, got: %v", c.treasureID, c.expected, actual)
+		}
+	}
+
+	if _, err := db.ListTreasureTree("SYSOP", []string{".."}); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("ListTreasureTree should return ErrInvalidName, got: %v", err)
+	}
+	if _, err := db.ListTreasureTree("", nil); !errors.Is(err, ErrInvalidBoardID) {
+		t.Errorf("ListTreasureTree should return ErrInvalidBoardID, got: %v", err)
+	}
+}
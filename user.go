@@ -0,0 +1,245 @@
+package bbs
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// GetUserRecord returns the UserRecord of userID, userID is matched
+// case-insensitively. It returns ErrRecordNotFound if there is no such user.
+func (db *DB) GetUserRecord(userID string) (UserRecord, error) {
+	var ret UserRecord
+	err := db.eachUserRecord(func(u UserRecord) bool {
+		if strings.EqualFold(u.UserID(), userID) {
+			ret = u
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if ret == nil || userID == "" {
+		return nil, fmt.Errorf("%w: user %q", ErrRecordNotFound, userID)
+	}
+	return ret, nil
+}
+
+// UsersExist returns a map from each of userIDs to whether the user exists,
+// user ids are matched case-insensitively. Unlike calling GetUserRecord for
+// each id, user records are scanned only once, and the scan stops when all
+// ids are found.
+func (db *DB) UsersExist(userIDs []string) (map[string]bool, error) {
+	ret := make(map[string]bool, len(userIDs))
+	wanted := map[string][]string{}
+	for _, id := range userIDs {
+		ret[id] = false
+		if id == "" {
+			continue
+		}
+		key := strings.ToLower(id)
+		wanted[key] = append(wanted[key], id)
+	}
+	if len(wanted) == 0 {
+		return ret, nil
+	}
+
+	err := db.eachUserRecord(func(u UserRecord) bool {
+		key := strings.ToLower(u.UserID())
+		ids, ok := wanted[key]
+		if !ok {
+			return true
+		}
+		for _, id := range ids {
+			ret[id] = true
+		}
+		delete(wanted, key)
+		return len(wanted) != 0
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// CreateUser creates a user record with args and adds it to the user records
+// file. args should have "user_id" and "password", other arguments depend on
+// driver. user_id should be letters and digits starting with a letter, or
+// ErrInvalidName is returned. It returns ErrUserExists if user id is used,
+// which driver checks again under its write lock, and ErrNotSupported if
+// connector does not implement WriteUserConnector.
+func (db *DB) CreateUser(args map[string]interface{}) (UserRecord, error) {
+	var wuc WriteUserConnector
+	if !db.connectorAs(&wuc) {
+		return nil, ErrNotSupported
+	}
+
+	userID, ok := args["user_id"].(string)
+	if !ok || userID == "" {
+		return nil, fmt.Errorf("CreateUser: user_id must not be empty")
+	}
+	if msg := checkUserID(userID); msg != "" {
+		return nil, fmt.Errorf("CreateUser: %w: %s", ErrInvalidName, msg)
+	}
+	_, err := db.GetUserRecord(userID)
+	if err == nil {
+		return nil, fmt.Errorf("%w: %v", ErrUserExists, userID)
+	} else if !errors.Is(err, ErrRecordNotFound) && !errors.Is(err, os.ErrNotExist) {
+		log.Println("bbs: GetUserRecord error:", err)
+		return nil, err
+	}
+
+	u, err := wuc.NewUserRecord(args)
+	if err != nil {
+		log.Println("bbs: NewUserRecord error:", err)
+		return nil, err
+	}
+
+	path, err := db.userRecordsPath()
+	if err != nil {
+		log.Println("bbs: open file error:", err)
+		return nil, err
+	}
+	log.Println("path:", path)
+
+	err = wuc.AddUserRecordFileRecord(path, u)
+	db.invalidateCache(userRecordsCacheKey(path))
+	if err != nil {
+		log.Println("bbs: AddUserRecordFileRecord error:", err)
+		return nil, err
+	}
+	db.countWrite(userRecordKind)
+	return u, nil
+}
+
+// AdjustUserMoney adds delta, which may be negative, to the money of userID
+// and returns the new balance. The record is read and written back under the
+// write lock of driver, so concurrent adjustments are not lost. It returns
+// ErrMoneyOverflow if the new balance overflows, ErrRecordNotFound if there
+// is no such user, and ErrNotSupported if connector does not implement
+// MoneyConnector.
+func (db *DB) AdjustUserMoney(userID string, delta int) (newBalance int, err error) {
+	var mc MoneyConnector
+	if !db.connectorAs(&mc) {
+		return 0, ErrNotSupported
+	}
+
+	path, err := db.userRecordsPath()
+	if err != nil {
+		log.Println("bbs: open file error:", err)
+		return 0, err
+	}
+
+	newBalance, err = mc.AdjustUserRecordFileMoney(path, userID, delta)
+	if err != nil {
+		log.Println("bbs: AdjustUserRecordFileMoney error:", err)
+		return 0, err
+	}
+	db.invalidateCache(userRecordsCacheKey(path))
+	db.countWrite(userRecordKind)
+	return newBalance, nil
+}
+
+// SetNumBadPosts sets the number of bad posts of userID to n, negative n is
+// set as 0. It returns ErrRecordNotFound if there is no such user, and
+// ErrNotSupported if connector does not implement BadPostConnector.
+func (db *DB) SetNumBadPosts(userID string, n int) error {
+	var bc BadPostConnector
+	if !db.connectorAs(&bc) {
+		return ErrNotSupported
+	}
+
+	path, err := db.userRecordsPath()
+	if err != nil {
+		log.Println("bbs: open file error:", err)
+		return err
+	}
+
+	if err := bc.SetUserRecordFileNumBadPosts(path, userID, n); err != nil {
+		log.Println("bbs: SetUserRecordFileNumBadPosts error:", err)
+		return err
+	}
+	db.invalidateCache(userRecordsCacheKey(path))
+	db.countWrite(userRecordKind)
+	return nil
+}
+
+// AdjustBadPosts adds delta to the number of bad posts of userID, positive
+// delta issues penalties and negative delta revokes them. The number is
+// clamped at 0. It returns ErrRecordNotFound if there is no such user, and
+// ErrNotSupported if connector does not implement BadPostConnector.
+func (db *DB) AdjustBadPosts(userID string, delta int) error {
+	var bc BadPostConnector
+	if !db.connectorAs(&bc) {
+		return ErrNotSupported
+	}
+
+	path, err := db.userRecordsPath()
+	if err != nil {
+		log.Println("bbs: open file error:", err)
+		return err
+	}
+
+	if _, err := bc.AdjustUserRecordFileNumBadPosts(path, userID, delta); err != nil {
+		log.Println("bbs: AdjustUserRecordFileNumBadPosts error:", err)
+		return err
+	}
+	db.invalidateCache(userRecordsCacheKey(path))
+	db.countWrite(userRecordKind)
+	return nil
+}
+
+// RecountUserPosts returns the number of posts stored in the record of
+// userID and the actual number of articles owned by userID, which are
+// enumerated by scanning .DIR of all boards. The per-user article index of
+// GetUserArticleRecordFile is not used as it may be stale. It does not
+// change anything, use FixUserPosts to write the actual number back. It
+// returns ErrRecordNotFound if there is no such user.
+func (db *DB) RecountUserPosts(userID string) (stored int, actual int, err error) {
+	u, err := db.GetUserRecord(userID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	recs, err := db.scanUserArticleRecords(u.UserID())
+	if err != nil {
+		log.Println("bbs: scanUserArticleRecords error:", err)
+		return 0, 0, err
+	}
+	return u.NumPosts(), len(recs), nil
+}
+
+// FixUserPosts recounts the posts of userID as RecountUserPosts, and writes
+// the actual number back if it differs from the stored one. It returns the
+// actual number, and ErrNotSupported if connector does not implement
+// NumPostsConnector.
+func (db *DB) FixUserPosts(userID string) (int, error) {
+	var nc NumPostsConnector
+	if !db.connectorAs(&nc) {
+		return 0, ErrNotSupported
+	}
+
+	stored, actual, err := db.RecountUserPosts(userID)
+	if err != nil {
+		return 0, err
+	}
+	if stored == actual {
+		return actual, nil
+	}
+
+	path, err := db.userRecordsPath()
+	if err != nil {
+		log.Println("bbs: open file error:", err)
+		return 0, err
+	}
+	if err := nc.SetUserRecordFileNumPosts(path, userID, actual); err != nil {
+		log.Println("bbs: SetUserRecordFileNumPosts error:", err)
+		return 0, err
+	}
+	db.invalidateCache(userRecordsCacheKey(path))
+	db.countWrite(userRecordKind)
+	return actual, nil
+}
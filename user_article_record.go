@@ -1,23 +1,195 @@
 package bbs
 
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+)
+
+// UserArticleRecord is an article posted by user, it is the record of user
+// article index returned by GetUserArticleRecordFile.
 type UserArticleRecord interface {
+	// BoardID return the board id which article is posted to.
 	BoardID() string
+	// Title return the title of article.
 	Title() string
+	// Owner return the user id of author.
 	Owner() string
+	// ArticleID return the filename of article in board.
 	ArticleID() string
 }
 
-type userArticleRecord map[string]string
+// NewUserArticleRecord returns a UserArticleRecord, drivers may use it to
+// build records read from user article index.
+func NewUserArticleRecord(boardID, title, owner, articleID string) UserArticleRecord {
+	return &userArticleRecord{
+		boardID:   boardID,
+		title:     title,
+		owner:     owner,
+		articleID: articleID,
+	}
+}
 
-func (r userArticleRecord) BoardID() string {
-	return r["board_id"]
+type userArticleRecord struct {
+	boardID   string
+	title     string
+	owner     string
+	articleID string
+}
+
+func (r *userArticleRecord) BoardID() string {
+	return r.boardID
+}
+func (r *userArticleRecord) Title() string {
+	return r.title
 }
-func (r userArticleRecord) Title() string {
-	return r["title"]
+func (r *userArticleRecord) Owner() string {
+	return r.owner
 }
-func (r userArticleRecord) Owner() string {
-	return r["owner"]
+func (r *userArticleRecord) ArticleID() string {
+	return r.articleID
+}
+
+// ValidateUserArticleRecord returns an error naming the first empty field of
+// r which is required in user article index, board_id, owner and
+// article_id.
+func ValidateUserArticleRecord(r UserArticleRecord) error {
+	if r == nil {
+		return fmt.Errorf("bbs: user article record must not be nil")
+	}
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"board_id", r.BoardID()},
+		{"owner", r.Owner()},
+		{"article_id", r.ArticleID()},
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			return fmt.Errorf("bbs: user article record missing %s", f.name)
+		}
+	}
+	return nil
+}
+
+// AppendUserArticleRecord appends r to user article index of userID, r is
+// validated by ValidateUserArticleRecord before writing.
+func (db *DB) AppendUserArticleRecord(userID string, r UserArticleRecord) error {
+	if err := ValidateUserArticleRecord(r); err != nil {
+		return err
+	}
+	var uac UserArticleConnector
+	if !db.connectorAs(&uac) {
+		return ErrNotSupported
+	}
+
+	path, err := db.userArticleRecordsPath(uac, userID)
+	if err != nil {
+		log.Println("bbs: GetUserArticleRecordsPath error:", err)
+		return err
+	}
+	if err := uac.AppendUserArticleRecordFile(path, r); err != nil {
+		log.Println("bbs: AppendUserArticleRecordFile error:", err)
+		return err
+	}
+	return nil
 }
-func (r userArticleRecord) ArticleID() string {
-	return r["article_id"]
+
+// WriteUserArticleRecords replaces user article index of userID with recs,
+// nothing is written if any of recs is invalid.
+func (db *DB) WriteUserArticleRecords(userID string, recs []UserArticleRecord) error {
+	for i, r := range recs {
+		if err := ValidateUserArticleRecord(r); err != nil {
+			return fmt.Errorf("record %d: %w", i, err)
+		}
+	}
+	var uac UserArticleConnector
+	if !db.connectorAs(&uac) {
+		return ErrNotSupported
+	}
+
+	path, err := db.userArticleRecordsPath(uac, userID)
+	if err != nil {
+		log.Println("bbs: GetUserArticleRecordsPath error:", err)
+		return err
+	}
+	if err := uac.WriteUserArticleRecordFile(path, recs); err != nil {
+		log.Println("bbs: WriteUserArticleRecordFile error:", err)
+		return err
+	}
+	return nil
+}
+
+// RebuildUserArticleIndex replaces user article index of userID with the
+// articles found by scanning all boards, see GetUserArticleRecordFile.
+// Records are sorted by board id, then by the timestamp in filename, and
+// duplicated or invalid records are dropped, so rebuilding an unchanged bbs
+// writes the same index. It returns ErrNotSupported if connector does not
+// implement UserArticleConnector.
+func (db *DB) RebuildUserArticleIndex(userID string) error {
+	var uac UserArticleConnector
+	if !db.connectorAs(&uac) {
+		return ErrNotSupported
+	}
+
+	recs, err := db.scanUserArticleRecords(userID)
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+	ret := []UserArticleRecord{}
+	for _, r := range recs {
+		key := r.BoardID() + "/" + r.ArticleID()
+		if seen[key] || ValidateUserArticleRecord(r) != nil {
+			continue
+		}
+		seen[key] = true
+		ret = append(ret, r)
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].BoardID() != ret[j].BoardID() {
+			return ret[i].BoardID() < ret[j].BoardID()
+		}
+		ti, _ := articleFilenameTime(ret[i].ArticleID())
+		tj, _ := articleFilenameTime(ret[j].ArticleID())
+		if ti != tj {
+			return ti < tj
+		}
+		return ret[i].ArticleID() < ret[j].ArticleID()
+	})
+	return db.WriteUserArticleRecords(userID, ret)
+}
+
+// UserArticleBytes returns the total size in bytes of article files posted
+// by userID, articles are enumerated by GetUserArticleRecordFile. Article
+// files which no longer exist are skipped.
+func (db *DB) UserArticleBytes(userID string) (int64, error) {
+	recs, err := db.GetUserArticleRecordFile(userID)
+	if err != nil {
+		log.Println("bbs: GetUserArticleRecordFile error:", err)
+		return 0, err
+	}
+
+	var total int64
+	for _, r := range recs {
+		path, err := db.boardArticleFilePath(r.BoardID(), r.ArticleID())
+		if err != nil {
+			log.Println("bbs: GetBoardArticleFilePath error:", err)
+			return 0, err
+		}
+		stat, err := db.statFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			log.Println("bbs: skip missing article file:", path)
+			continue
+		} else if err != nil {
+			log.Println("bbs: stat article file error:", err)
+			return 0, err
+		}
+		total += stat.Size()
+	}
+	return total, nil
 }
@@ -0,0 +1,243 @@
+package bbs
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type fakeUserArticleConnector struct {
+	fakeConnector
+	records []UserArticleRecord
+}
+
+var _ UserArticleConnector = &fakeUserArticleConnector{}
+
+func (c *fakeUserArticleConnector) GetUserArticleRecordsPath(userID string) (string, error) {
+	return "home/" + userID + "/articles", nil
+}
+
+func (c *fakeUserArticleConnector) ReadUserArticleRecordFile(name string) ([]UserArticleRecord, error) {
+	return c.records, nil
+}
+
+func (c *fakeUserArticleConnector) WriteUserArticleRecordFile(name string, records []UserArticleRecord) error {
+	c.records = records
+	return nil
+}
+
+func (c *fakeUserArticleConnector) AppendUserArticleRecordFile(name string, record UserArticleRecord) error {
+	c.records = append(c.records, record)
+	return nil
+}
+
+func userArticleRecordStrings(recs []UserArticleRecord) []string {
+	ret := []string{}
+	for _, r := range recs {
+		ret = append(ret, r.BoardID()+" "+r.ArticleID()+" "+r.Owner()+" "+r.Title())
+	}
+	return ret
+}
+
+func TestGetUserArticleRecordFile(t *testing.T) {
+	c := &fakeUserArticleConnector{
+		fakeConnector: fakeConnector{
+			fakeGetBoardRecordsPath: func() (string, error) { return ".BRD", nil },
+			fakeReadBoardRecordsFile: func() ([]BoardRecord, error) {
+				return []BoardRecord{
+					&fakeBoardRecord{boardID: "SYSOP"},
+					&fakeBoardRecord{boardID: AllPostBoardID},
+				}, nil
+			},
+			fakeGetBoardArticleRecordsPath: func() (string, error) { return ".DIR", nil },
+			fakeReadArticleRecordsFile: func() ([]ArticleRecord, error) {
+				return testArticleRecords, nil
+			},
+		},
+	}
+	db := &DB{connector: c}
+
+	// scan boards when user article index is empty, ALLPOST is skipped.
+	recs, err := db.GetUserArticleRecordFile("SYSOP")
+	if err != nil {
+		t.Fatalf("GetUserArticleRecordFile error: %v", err)
+	}
+	expected := []string{
+		"SYSOP M.1599059246.A.CF6 SYSOP [閒聊] 自己的文章自己寫",
+		"SYSOP M.1599059500.A.123 SYSOP [問題] Golang test",
+	}
+	if actual := userArticleRecordStrings(recs); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("scanned records expected: %v, got: %v", expected, actual)
+	}
+
+	// use user article index when it has records.
+	c.records = []UserArticleRecord{
+		NewUserArticleRecord("Test", "[公告] 測試", "SYSOP", "M.1599059600.A.456"),
+	}
+	recs, err = db.GetUserArticleRecordFile("SYSOP")
+	if err != nil {
+		t.Fatalf("GetUserArticleRecordFile error: %v", err)
+	}
+	expected = []string{"Test M.1599059600.A.456 SYSOP [公告] 測試"}
+	if actual := userArticleRecordStrings(recs); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("indexed records expected: %v, got: %v", expected, actual)
+	}
+}
+
+func TestValidateUserArticleRecord(t *testing.T) {
+	tests := []struct {
+		input   UserArticleRecord
+		missing string
+	}{
+		{NewUserArticleRecord("SYSOP", "", "pichu", "M.1599059246.A.CF6"), ""},
+		{NewUserArticleRecord("", "title", "pichu", "M.1599059246.A.CF6"), "board_id"},
+		{NewUserArticleRecord("SYSOP", "title", "", "M.1599059246.A.CF6"), "owner"},
+		{NewUserArticleRecord("SYSOP", "title", "pichu", ""), "article_id"},
+	}
+	for _, tt := range tests {
+		err := ValidateUserArticleRecord(tt.input)
+		if tt.missing == "" {
+			if err != nil {
+				t.Errorf("ValidateUserArticleRecord error: %v", err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.missing) {
+			t.Errorf("ValidateUserArticleRecord should report missing %s, got: %v", tt.missing, err)
+		}
+	}
+}
+
+func TestWriteUserArticleRecords(t *testing.T) {
+	c := &fakeUserArticleConnector{}
+	db := &DB{connector: c}
+
+	valid := NewUserArticleRecord("SYSOP", "title", "pichu", "M.1599059246.A.CF6")
+	invalid := NewUserArticleRecord("SYSOP", "title", "pichu", "")
+	if err := db.AppendUserArticleRecord("pichu", valid); err != nil {
+		t.Fatalf("AppendUserArticleRecord error: %v", err)
+	}
+	if err := db.AppendUserArticleRecord("pichu", invalid); err == nil {
+		t.Errorf("AppendUserArticleRecord should return error for invalid record")
+	}
+	if err := db.WriteUserArticleRecords("pichu", []UserArticleRecord{valid, invalid}); err == nil {
+		t.Errorf("WriteUserArticleRecords should return error for invalid record")
+	}
+	if len(c.records) != 1 || c.records[0] != valid {
+		t.Errorf("invalid records should not be written, got: %v", userArticleRecordStrings(c.records))
+	}
+	if err := db.WriteUserArticleRecords("pichu", nil); err != nil || len(c.records) != 0 {
+		t.Errorf("WriteUserArticleRecords expected: [], <nil>, got: %v, %v", userArticleRecordStrings(c.records), err)
+	}
+
+	db = &DB{connector: &fakeConnector{}}
+	if err := db.AppendUserArticleRecord("pichu", valid); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("AppendUserArticleRecord should return ErrNotSupported, got: %v", err)
+	}
+}
+
+type fakeUserArticleFileConnector struct {
+	*fakeUserArticleConnector
+}
+
+func (c *fakeUserArticleFileConnector) GetBoardArticleFilePath(boardID string, filename string) (string, error) {
+	return "boards/" + boardID + "/" + filename, nil
+}
+
+func TestUserArticleBytes(t *testing.T) {
+	c := &fakeUserArticleFileConnector{
+		fakeUserArticleConnector: &fakeUserArticleConnector{
+			records: []UserArticleRecord{
+				NewUserArticleRecord("SYSOP", "title", "pichu", "M.1599059246.A.CF6"),
+				NewUserArticleRecord("Test", "title", "pichu", "M.1599059500.A.123"),
+				NewUserArticleRecord("Test", "title", "pichu", "M.1599059600.A.456"),
+			},
+		},
+	}
+	db := &DB{
+		connector: c,
+		fsys: fstest.MapFS{
+			"boards/SYSOP/M.1599059246.A.CF6": &fstest.MapFile{Data: make([]byte, 100)},
+			"boards/Test/M.1599059500.A.123":  &fstest.MapFile{Data: make([]byte, 23)},
+		},
+	}
+
+	actual, err := db.UserArticleBytes("pichu")
+	if err != nil {
+		t.Fatalf("UserArticleBytes error: %v", err)
+	}
+	if actual != 123 {
+		t.Errorf("UserArticleBytes expected: 123, got: %v", actual)
+	}
+}
+
+type fakeUserArticleFileBytesConnector struct {
+	*fakeUserArticleConnector
+	files map[string][]byte
+}
+
+func (c *fakeUserArticleFileBytesConnector) WriteUserArticleRecordFile(name string, records []UserArticleRecord) error {
+	c.files[name] = []byte(strings.Join(userArticleRecordStrings(records), "\n"))
+	return c.fakeUserArticleConnector.WriteUserArticleRecordFile(name, records)
+}
+
+func TestRebuildUserArticleIndex(t *testing.T) {
+	recs := []ArticleRecord{
+		&fakeArticleRecord{filename: "M.1599059500.A.123", title: "[問題] Golang test", owner: "SYSOP"},
+		&fakeArticleRecord{filename: "M.1599059415.A.FBA", title: "[討論] 賞大稻埕煙火遠離人潮！", owner: "pichu"},
+		&fakeArticleRecord{filename: "M.1599059246.A.CF6", title: "[閒聊] 自己的文章自己寫", owner: "SYSOP"},
+		&fakeArticleRecord{filename: "", title: "(本文已被刪除)", owner: "SYSOP"},
+	}
+	c := &fakeUserArticleFileBytesConnector{
+		fakeUserArticleConnector: &fakeUserArticleConnector{
+			fakeConnector: fakeConnector{
+				fakeGetBoardRecordsPath: func() (string, error) { return ".BRD", nil },
+				fakeReadBoardRecordsFile: func() ([]BoardRecord, error) {
+					return []BoardRecord{
+						&fakeBoardRecord{boardID: "Test"},
+						&fakeBoardRecord{boardID: "SYSOP"},
+						&fakeBoardRecord{boardID: AllPostBoardID},
+						&fakeBoardRecord{boardID: "Test"},
+					}, nil
+				},
+				fakeGetBoardArticleRecordsPath: func() (string, error) { return ".DIR", nil },
+				fakeReadArticleRecordsFile: func() ([]ArticleRecord, error) {
+					return recs, nil
+				},
+			},
+			records: []UserArticleRecord{
+				NewUserArticleRecord("Old", "[公告] 舊的", "SYSOP", "M.1500000000.A.000"),
+			},
+		},
+		files: map[string][]byte{},
+	}
+	db := &DB{connector: c}
+
+	if err := db.RebuildUserArticleIndex("SYSOP"); err != nil {
+		t.Fatalf("RebuildUserArticleIndex error: %v", err)
+	}
+	expected := []string{
+		"SYSOP M.1599059246.A.CF6 SYSOP [閒聊] 自己的文章自己寫",
+		"SYSOP M.1599059500.A.123 SYSOP [問題] Golang test",
+		"Test M.1599059246.A.CF6 SYSOP [閒聊] 自己的文章自己寫",
+		"Test M.1599059500.A.123 SYSOP [問題] Golang test",
+	}
+	if actual := userArticleRecordStrings(c.records); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("rebuilt records expected: %v, got: %v", expected, actual)
+	}
+
+	first := c.files["home/SYSOP/articles"]
+	if err := db.RebuildUserArticleIndex("SYSOP"); err != nil {
+		t.Fatalf("RebuildUserArticleIndex error: %v", err)
+	}
+	if second := c.files["home/SYSOP/articles"]; string(first) != string(second) {
+		t.Errorf("rebuilding twice should write identical bytes, first: %q, second: %q", first, second)
+	}
+
+	db = &DB{connector: &fakeConnector{}}
+	if err := db.RebuildUserArticleIndex("SYSOP"); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("RebuildUserArticleIndex should return ErrNotSupported, got: %v", err)
+	}
+}
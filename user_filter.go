@@ -0,0 +1,107 @@
+package bbs
+
+import (
+	"log"
+	"sort"
+	"time"
+)
+
+// FilterUserRecords returns the UserRecords which pred returns true.
+// If connector implements StreamUserRecordsConnector, records are streamed
+// from the user records file and only the matched ones are kept, pred runs
+// while the file is held open, so it should not take long.
+func (db *DB) FilterUserRecords(pred func(UserRecord) bool) ([]UserRecord, error) {
+	ret := []UserRecord{}
+	err := db.eachUserRecord(func(u UserRecord) bool {
+		if pred(u) {
+			ret = append(ret, u)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// EachUserRecord calls fn with each UserRecord in file order until fn
+// returns stop or an error, the error of fn is returned. Unlike
+// FilterUserRecords it keeps no records, so finding the first matched user
+// stops reading there. If connector implements StreamUserRecordsConnector,
+// the user records file is held open during iteration, fn should not take
+// long or write user records.
+func (db *DB) EachUserRecord(fn func(UserRecord) (stop bool, err error)) error {
+	var fnErr error
+	err := db.eachUserRecord(func(u UserRecord) bool {
+		stop, err := fn(u)
+		if err != nil {
+			fnErr = err
+			return false
+		}
+		return !stop
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	return err
+}
+
+// UsersByLastLogin returns the UserRecords whose LastLogin is after since,
+// sorted by LastLogin descending. Users never logged in, whose LastLogin is
+// zero or unix time 0, are excluded.
+func (db *DB) UsersByLastLogin(since time.Time) ([]UserRecord, error) {
+	recs, err := db.FilterUserRecords(func(u UserRecord) bool {
+		t := u.LastLogin()
+		return !t.IsZero() && t.Unix() != 0 && t.After(since)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(recs, func(i, j int) bool {
+		return recs[i].LastLogin().After(recs[j].LastLogin())
+	})
+	return recs, nil
+}
+
+// eachUserRecord calls fn with each UserRecord and stops when fn returns
+// false. It streams records if connector implements
+// StreamUserRecordsConnector or a RecordCodec decodes user records,
+// otherwise it reads all records first.
+func (db *DB) eachUserRecord(fn func(UserRecord) bool) error {
+	if db.userCodec() != nil {
+		return db.eachUserRecordWithCodec(fn)
+	}
+	var sc StreamUserRecordsConnector
+	if !db.connectorAs(&sc) {
+		recs, err := db.ReadUserRecords()
+		if err != nil {
+			return err
+		}
+		for _, r := range recs {
+			if !fn(r) {
+				break
+			}
+		}
+		return nil
+	}
+
+	path, err := db.userRecordsPath()
+	if err != nil {
+		log.Println("bbs: open file error:", err)
+		return err
+	}
+	log.Println("path:", path)
+
+	n := 0
+	err = sc.EachUserRecordsFile(path, func(u UserRecord) bool {
+		n++
+		return fn(u)
+	})
+	db.countRecordsRead(userRecordKind, n)
+	if err != nil {
+		log.Println("bbs: EachUserRecordsFile error:", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package bbs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testUserRecords = []UserRecord{
+	&fakeUserRecord{userID: "SYSOP", money: 100},
+	&fakeUserRecord{userID: "pichu", money: -10},
+	&fakeUserRecord{userID: "Kahou", money: -1},
+}
+
+type fakeStreamUserRecordsConnector struct {
+	fakeConnector
+}
+
+var _ StreamUserRecordsConnector = &fakeStreamUserRecordsConnector{}
+
+func (c *fakeStreamUserRecordsConnector) GetUserRecordsPath() (string, error) {
+	return ".PASSWDS", nil
+}
+
+func (c *fakeStreamUserRecordsConnector) EachUserRecordsFile(name string, fn func(UserRecord) bool) error {
+	for _, u := range testUserRecords {
+		if !fn(u) {
+			break
+		}
+	}
+	return nil
+}
+
+func TestFilterUserRecords(t *testing.T) {
+	negative := func(u UserRecord) bool { return u.Money() < 0 }
+
+	dbs := map[string]*DB{
+		"stream": {connector: &fakeStreamUserRecordsConnector{}},
+		"read all": {connector: &fakeConnector{
+			fakeGetUserRecordsPath:  func() (string, error) { return ".PASSWDS", nil },
+			fakeReadUserRecordsFile: func() ([]UserRecord, error) { return testUserRecords, nil },
+		}},
+	}
+	for name, db := range dbs {
+		recs, err := db.FilterUserRecords(negative)
+		if err != nil {
+			t.Fatalf("%v: FilterUserRecords error: %v", name, err)
+		}
+		if len(recs) != 2 || recs[0].UserID() != "pichu" || recs[1].UserID() != "Kahou" {
+			t.Errorf("%v: FilterUserRecords expected: [pichu Kahou], got: %v", name, recs)
+		}
+	}
+}
+
+func TestEachUserRecord(t *testing.T) {
+	dbs := map[string]*DB{
+		"stream": {connector: &fakeStreamUserRecordsConnector{}},
+		"read all": {connector: &fakeConnector{
+			fakeGetUserRecordsPath:  func() (string, error) { return ".PASSWDS", nil },
+			fakeReadUserRecordsFile: func() ([]UserRecord, error) { return testUserRecords, nil },
+		}},
+	}
+	errStop := errors.New("stop")
+	for name, db := range dbs {
+		visited := []string{}
+		err := db.EachUserRecord(func(u UserRecord) (bool, error) {
+			visited = append(visited, u.UserID())
+			return u.Money() < 0, nil
+		})
+		if err != nil || !reflect.DeepEqual(visited, []string{"SYSOP", "pichu"}) {
+			t.Errorf("%v: EachUserRecord expected: [SYSOP pichu], <nil>, got: %v, %v", name, visited, err)
+		}
+
+		visited = []string{}
+		err = db.EachUserRecord(func(u UserRecord) (bool, error) {
+			visited = append(visited, u.UserID())
+			return false, nil
+		})
+		if err != nil || len(visited) != len(testUserRecords) {
+			t.Errorf("%v: EachUserRecord should visit all records, got: %v, %v", name, visited, err)
+		}
+
+		visited = []string{}
+		err = db.EachUserRecord(func(u UserRecord) (bool, error) {
+			visited = append(visited, u.UserID())
+			return false, errStop
+		})
+		if !errors.Is(err, errStop) || len(visited) != 1 {
+			t.Errorf("%v: EachUserRecord should stop with error of fn, got: %v, %v", name, visited, err)
+		}
+	}
+}
+
+func TestUsersByLastLogin(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	recs := []UserRecord{
+		&fakeUserRecord{userID: "old", lastLogin: now.AddDate(0, -1, 0)},
+		&fakeUserRecord{userID: "never"},
+		&fakeUserRecord{userID: "epoch", lastLogin: time.Unix(0, 0)},
+		&fakeUserRecord{userID: "yesterday", lastLogin: now.AddDate(0, 0, -1)},
+		&fakeUserRecord{userID: "today", lastLogin: now},
+	}
+	db := &DB{connector: &fakeConnector{
+		fakeGetUserRecordsPath:  func() (string, error) { return ".PASSWDS", nil },
+		fakeReadUserRecordsFile: func() ([]UserRecord, error) { return recs, nil },
+	}}
+
+	actual, err := db.UsersByLastLogin(now.AddDate(0, 0, -7))
+	if err != nil {
+		t.Fatalf("UsersByLastLogin error: %v", err)
+	}
+	ids := []string{}
+	for _, u := range actual {
+		ids = append(ids, u.UserID())
+	}
+	if expected := []string{"today", "yesterday"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("UsersByLastLogin expected: %v, got: %v", expected, ids)
+	}
+
+	actual, err = db.UsersByLastLogin(time.Time{})
+	if err != nil {
+		t.Fatalf("UsersByLastLogin error: %v", err)
+	}
+	if len(actual) != 3 {
+		t.Errorf("UsersByLastLogin should exclude users never logged in, got: %v", len(actual))
+	}
+}
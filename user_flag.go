@@ -0,0 +1,49 @@
+package bbs
+
+// UserFlag is the bitmask of user permissions, each driver maps its own
+// permission bits to UserFlag.
+type UserFlag uint32
+
+const (
+	// UserFlagBasic is for user who can login.
+	UserFlagBasic UserFlag = 1 << iota
+	// UserFlagPost is for user who can post articles.
+	UserFlagPost
+	// UserFlagRegistered is for user who has passed registration.
+	UserFlagRegistered
+	// UserFlagBM is for board manager (板主).
+	UserFlagBM
+	// UserFlagAccountAdmin is for admin who manages user accounts.
+	UserFlagAccountAdmin
+	// UserFlagBoardAdmin is for admin who manages boards.
+	UserFlagBoardAdmin
+	// UserFlagSysop is for system operator (站長).
+	UserFlagSysop
+)
+
+// FlaggedUserRecord return UserRecord interface which support UserFlags
+type FlaggedUserRecord interface {
+	// UserFlags return the permission bitmask of this user.
+	UserFlags() UserFlag
+}
+
+// Has returns true if all bits of flag are set.
+func (f UserFlag) Has(flag UserFlag) bool { return f&flag == flag }
+
+// IsSysop returns true if user is a system operator.
+func (f UserFlag) IsSysop() bool { return f.Has(UserFlagSysop) }
+
+// IsRegistered returns true if user has passed registration.
+func (f UserFlag) IsRegistered() bool { return f.Has(UserFlagRegistered) }
+
+// IsBM returns true if user is a board manager.
+func (f UserFlag) IsBM() bool { return f.Has(UserFlagBM) }
+
+// UserFlags returns the permissions of u, it returns 0 if u does not
+// implement FlaggedUserRecord.
+func UserFlags(u UserRecord) UserFlag {
+	if fu, ok := u.(FlaggedUserRecord); ok {
+		return fu.UserFlags()
+	}
+	return 0
+}
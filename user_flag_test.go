@@ -0,0 +1,26 @@
+package bbs
+
+import (
+	"testing"
+)
+
+type fakeFlaggedUserRecord struct {
+	fakeUserRecord
+	flags UserFlag
+}
+
+func (u *fakeFlaggedUserRecord) UserFlags() UserFlag { return u.flags }
+
+func TestUserFlags(t *testing.T) {
+	f := UserFlags(&fakeFlaggedUserRecord{flags: UserFlagBasic | UserFlagRegistered | UserFlagSysop})
+	if !f.IsSysop() || !f.IsRegistered() {
+		t.Errorf("flags should be sysop and registered, got: %b", f)
+	}
+	if f.IsBM() {
+		t.Errorf("flags should not be BM, got: %b", f)
+	}
+
+	if f := UserFlags(&fakeUserRecord{}); f != 0 {
+		t.Errorf("flags of record without support should be 0, got: %b", f)
+	}
+}
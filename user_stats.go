@@ -0,0 +1,54 @@
+package bbs
+
+import (
+	"log"
+	"time"
+)
+
+// UserStats is the scalar fields of user shown on profile pages.
+type UserStats struct {
+	UserID       string
+	Money        int
+	NumPosts     int
+	NumLoginDays int
+	// NumBadPosts is nil if user record does not implement
+	// BadPostUserRecord.
+	NumBadPosts *int
+	LastLogin   time.Time
+	LastHost    string
+	// LastLoginCountry is empty if user record does not implement
+	// LastCountryUserRecord.
+	LastLoginCountry string
+}
+
+// NewUserStats returns the UserStats of u, optional fields are populated
+// only if u implements the relevant interface.
+func NewUserStats(u UserRecord) UserStats {
+	stats := UserStats{
+		UserID:       u.UserID(),
+		Money:        u.Money(),
+		NumPosts:     u.NumPosts(),
+		NumLoginDays: u.NumLoginDays(),
+		LastLogin:    u.LastLogin(),
+		LastHost:     u.LastHost(),
+	}
+	if bu, ok := u.(BadPostUserRecord); ok {
+		n := bu.NumBadPosts()
+		stats.NumBadPosts = &n
+	}
+	if cu, ok := u.(LastCountryUserRecord); ok {
+		stats.LastLoginCountry = cu.LastLoginCountry()
+	}
+	return stats
+}
+
+// UserStats returns the UserStats of userID, see GetUserRecord and
+// NewUserStats.
+func (db *DB) UserStats(userID string) (UserStats, error) {
+	u, err := db.GetUserRecord(userID)
+	if err != nil {
+		log.Println("bbs: GetUserRecord error:", err)
+		return UserStats{}, err
+	}
+	return NewUserStats(u), nil
+}
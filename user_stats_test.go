@@ -0,0 +1,50 @@
+package bbs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeBadPostUserRecord struct {
+	fakeUserRecord
+	badPosts int
+}
+
+func (u *fakeBadPostUserRecord) NumBadPosts() int { return u.badPosts }
+
+func TestUserStats(t *testing.T) {
+	lastLogin := time.Unix(1606145447, 0)
+	recs := []UserRecord{
+		&fakeUserRecord{userID: "SYSOP", money: 100, numPosts: 3, numLoginDays: 7, lastLogin: lastLogin, lastHost: "127.0.0.1"},
+		&fakeBadPostUserRecord{fakeUserRecord: fakeUserRecord{userID: "pichu", money: -10}, badPosts: 2},
+	}
+	db := &DB{
+		connector: &fakeConnector{
+			fakeGetUserRecordsPath:  func() (string, error) { return ".PASSWDS", nil },
+			fakeReadUserRecordsFile: func() ([]UserRecord, error) { return recs, nil },
+		},
+	}
+
+	actual, err := db.UserStats("sysop")
+	if err != nil {
+		t.Fatalf("UserStats error: %v", err)
+	}
+	expected := UserStats{UserID: "SYSOP", Money: 100, NumPosts: 3, NumLoginDays: 7, LastLogin: lastLogin, LastHost: "127.0.0.1"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("UserStats expected: %+v, got: %+v", expected, actual)
+	}
+
+	actual, err = db.UserStats("pichu")
+	if err != nil {
+		t.Fatalf("UserStats error: %v", err)
+	}
+	if actual.NumBadPosts == nil || *actual.NumBadPosts != 2 || actual.Money != -10 {
+		t.Errorf("UserStats of pichu expected 2 bad posts and money -10, got: %+v", actual)
+	}
+
+	if _, err := db.UserStats("nobody"); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("UserStats should return ErrRecordNotFound, got: %v", err)
+	}
+}
@@ -0,0 +1,302 @@
+package bbs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeWriteUserConnector struct {
+	fakeConnector
+	users []UserRecord
+}
+
+var _ WriteUserConnector = &fakeWriteUserConnector{}
+
+func (c *fakeWriteUserConnector) GetUserRecordsPath() (string, error) {
+	return ".PASSWDS", nil
+}
+
+func (c *fakeWriteUserConnector) ReadUserRecordsFile(name string) ([]UserRecord, error) {
+	return c.users, nil
+}
+
+func (c *fakeWriteUserConnector) NewUserRecord(args map[string]interface{}) (UserRecord, error) {
+	return &fakeUserRecord{userID: args["user_id"].(string)}, nil
+}
+
+func (c *fakeWriteUserConnector) AddUserRecordFileRecord(name string, u UserRecord) error {
+	c.users = append(c.users, u)
+	return nil
+}
+
+func TestGetUserRecord(t *testing.T) {
+	db := &DB{connector: &fakeWriteUserConnector{users: testUserRecords}}
+
+	u, err := db.GetUserRecord("sysop")
+	if err != nil {
+		t.Fatalf("GetUserRecord error: %v", err)
+	}
+	if u.UserID() != "SYSOP" {
+		t.Errorf("user id expected: SYSOP, got: %v", u.UserID())
+	}
+
+	if _, err := db.GetUserRecord("nobody"); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("GetUserRecord should return ErrRecordNotFound, got: %v", err)
+	}
+}
+
+func TestUsersExist(t *testing.T) {
+	db := &DB{connector: &fakeStreamUserRecordsConnector{}}
+
+	actual, err := db.UsersExist([]string{"sysop", "PICHU", "nobody", "", "SYSOP"})
+	if err != nil {
+		t.Fatalf("UsersExist error: %v", err)
+	}
+	expected := map[string]bool{"sysop": true, "PICHU": true, "nobody": false, "": false, "SYSOP": true}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("UsersExist expected: %v, got: %v", expected, actual)
+	}
+
+	actual, err = db.UsersExist(nil)
+	if err != nil || len(actual) != 0 {
+		t.Errorf("UsersExist(nil) expected: map[], <nil>, got: %v, %v", actual, err)
+	}
+}
+
+func benchmarkUsersDB(n int) *DB {
+	users := make([]UserRecord, n)
+	for i := range users {
+		users[i] = &fakeUserRecord{userID: fmt.Sprintf("user%d", i)}
+	}
+	return &DB{connector: &fakeWriteUserConnector{users: users}}
+}
+
+func benchmarkUserIDs(n, step int) []string {
+	ids := []string{}
+	for i := 0; i < n; i += step {
+		ids = append(ids, fmt.Sprintf("USER%d", i))
+	}
+	return append(ids, "nobody")
+}
+
+func BenchmarkUsersExist(b *testing.B) {
+	db := benchmarkUsersDB(10000)
+	ids := benchmarkUserIDs(10000, 100)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = db.UsersExist(ids)
+	}
+}
+
+func BenchmarkUsersExistByGetUserRecord(b *testing.B) {
+	db := benchmarkUsersDB(10000)
+	ids := benchmarkUserIDs(10000, 100)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, id := range ids {
+			_, _ = db.GetUserRecord(id)
+		}
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	c := &fakeWriteUserConnector{}
+	db := &DB{connector: c}
+
+	u, err := db.CreateUser(map[string]interface{}{"user_id": "pichu", "password": "123456"})
+	if err != nil {
+		t.Fatalf("CreateUser error: %v", err)
+	}
+	if u.UserID() != "pichu" || len(c.users) != 1 {
+		t.Errorf("user should be added, got: %v", c.users)
+	}
+
+	_, err = db.CreateUser(map[string]interface{}{"user_id": "PICHU", "password": "123456"})
+	if !errors.Is(err, ErrUserExists) {
+		t.Errorf("CreateUser should return ErrUserExists, got: %v", err)
+	}
+
+	for _, id := range []string{"1pichu", "pi chu", "../pichu", "皮丘"} {
+		if _, err := db.CreateUser(map[string]interface{}{"user_id": id, "password": "123456"}); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("CreateUser %q should return ErrInvalidName, got: %v", id, err)
+		}
+	}
+
+	db = &DB{connector: &fakeConnector{}}
+	if _, err := db.CreateUser(map[string]interface{}{"user_id": "pichu"}); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("CreateUser should return ErrNotSupported, got: %v", err)
+	}
+}
+
+func TestCreateUserInvalidatesCache(t *testing.T) {
+	c := &fakeWriteUserConnector{}
+	db := &DB{connector: WithCache(c, time.Hour)}
+
+	if _, err := db.ReadUserRecords(); err != nil {
+		t.Fatalf("ReadUserRecords error: %v", err)
+	}
+	if _, err := db.CreateUser(map[string]interface{}{"user_id": "pichu"}); err != nil {
+		t.Fatalf("CreateUser error: %v", err)
+	}
+	recs, err := db.ReadUserRecords()
+	if err != nil {
+		t.Fatalf("ReadUserRecords error: %v", err)
+	}
+	if len(recs) != 1 {
+		t.Errorf("cached user records should be invalidated, got: %v", recs)
+	}
+}
+
+type fakeMoneyConnector struct {
+	fakeWriteUserConnector
+	money map[string]int
+}
+
+func (c *fakeMoneyConnector) AdjustUserRecordFileMoney(name string, userID string, delta int) (int, error) {
+	c.money[userID] += delta
+	return c.money[userID], nil
+}
+
+func TestAdjustUserMoney(t *testing.T) {
+	db := &DB{connector: &fakeMoneyConnector{money: map[string]int{"pichu": 10}}}
+	money, err := db.AdjustUserMoney("pichu", 5)
+	if err != nil || money != 15 {
+		t.Errorf("AdjustUserMoney expected: 15, <nil>, got: %v, %v", money, err)
+	}
+
+	db = &DB{connector: &fakeWriteUserConnector{}}
+	if _, err := db.AdjustUserMoney("pichu", 5); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("AdjustUserMoney should return ErrNotSupported, got: %v", err)
+	}
+}
+
+type fakeBadPostConnector struct {
+	fakeWriteUserConnector
+	badPosts map[string]int
+}
+
+func (c *fakeBadPostConnector) SetUserRecordFileNumBadPosts(name string, userID string, n int) error {
+	if n < 0 {
+		n = 0
+	}
+	c.badPosts[userID] = n
+	return nil
+}
+
+func (c *fakeBadPostConnector) AdjustUserRecordFileNumBadPosts(name string, userID string, delta int) (int, error) {
+	err := c.SetUserRecordFileNumBadPosts(name, userID, c.badPosts[userID]+delta)
+	return c.badPosts[userID], err
+}
+
+func TestAdjustBadPosts(t *testing.T) {
+	c := &fakeBadPostConnector{badPosts: map[string]int{}}
+	db := &DB{connector: c}
+	if err := db.SetNumBadPosts("pichu", 2); err != nil {
+		t.Fatalf("SetNumBadPosts error: %v", err)
+	}
+	if err := db.AdjustBadPosts("pichu", -1); err != nil || c.badPosts["pichu"] != 1 {
+		t.Errorf("AdjustBadPosts expected: 1, <nil>, got: %v, %v", c.badPosts["pichu"], err)
+	}
+
+	db = &DB{connector: &fakeWriteUserConnector{}}
+	if err := db.AdjustBadPosts("pichu", 1); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("AdjustBadPosts should return ErrNotSupported, got: %v", err)
+	}
+	if err := db.SetNumBadPosts("pichu", 1); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("SetNumBadPosts should return ErrNotSupported, got: %v", err)
+	}
+}
+
+type fakeNumPostsConnector struct {
+	fakeWriteUserConnector
+}
+
+func (c *fakeNumPostsConnector) SetUserRecordFileNumPosts(name string, userID string, n int) error {
+	for _, u := range c.users {
+		if u.UserID() == userID {
+			u.(*fakeUserRecord).numPosts = n
+			return nil
+		}
+	}
+	return ErrRecordNotFound
+}
+
+type fakeIndexedNumPostsConnector struct {
+	*fakeNumPostsConnector
+	index []UserArticleRecord
+}
+
+func (c *fakeIndexedNumPostsConnector) GetUserArticleRecordsPath(userID string) (string, error) {
+	return "home/" + userID + "/articles", nil
+}
+
+func (c *fakeIndexedNumPostsConnector) ReadUserArticleRecordFile(name string) ([]UserArticleRecord, error) {
+	return c.index, nil
+}
+
+func (c *fakeIndexedNumPostsConnector) WriteUserArticleRecordFile(name string, records []UserArticleRecord) error {
+	c.index = records
+	return nil
+}
+
+func (c *fakeIndexedNumPostsConnector) AppendUserArticleRecordFile(name string, record UserArticleRecord) error {
+	c.index = append(c.index, record)
+	return nil
+}
+
+func TestRecountUserPosts(t *testing.T) {
+	c := &fakeNumPostsConnector{
+		fakeWriteUserConnector: fakeWriteUserConnector{
+			fakeConnector: fakeConnector{
+				fakeGetBoardRecordsPath: func() (string, error) { return ".BRD", nil },
+				fakeReadBoardRecordsFile: func() ([]BoardRecord, error) {
+					return []BoardRecord{&fakeBoardRecord{boardID: "SYSOP"}}, nil
+				},
+				fakeGetBoardArticleRecordsPath: func() (string, error) { return ".DIR", nil },
+				fakeReadArticleRecordsFile: func() ([]ArticleRecord, error) {
+					return testArticleRecords, nil
+				},
+			},
+			users: []UserRecord{&fakeUserRecord{userID: "SYSOP", numPosts: 10}},
+		},
+	}
+	db := &DB{connector: c}
+
+	stored, actual, err := db.RecountUserPosts("sysop")
+	if err != nil || stored != 10 || actual != 2 {
+		t.Errorf("RecountUserPosts expected: 10, 2, <nil>, got: %v, %v, %v", stored, actual, err)
+	}
+	if n := c.users[0].NumPosts(); n != 10 {
+		t.Errorf("RecountUserPosts should not change stored count, got: %v", n)
+	}
+
+	n, err := db.FixUserPosts("SYSOP")
+	if err != nil || n != 2 || c.users[0].NumPosts() != 2 {
+		t.Errorf("FixUserPosts expected: 2, <nil>, got: %v, %v, stored: %v", n, err, c.users[0].NumPosts())
+	}
+
+	if _, _, err := db.RecountUserPosts("nobody"); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("RecountUserPosts should return ErrRecordNotFound, got: %v", err)
+	}
+
+	// The stale per-user article index should not be counted.
+	ic := &fakeIndexedNumPostsConnector{
+		fakeNumPostsConnector: c,
+		index:                 []UserArticleRecord{NewUserArticleRecord("SYSOP", "stale", "SYSOP", "M.1.A.000")},
+	}
+	db = &DB{connector: ic}
+	if _, actual, err := db.RecountUserPosts("SYSOP"); err != nil || actual != 2 {
+		t.Errorf("RecountUserPosts with stale index expected: 2, <nil>, got: %v, %v", actual, err)
+	}
+	db = &DB{connector: &c.fakeWriteUserConnector}
+	if _, err := db.FixUserPosts("SYSOP"); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("FixUserPosts should return ErrNotSupported, got: %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package bbs
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+// UtmpRecord is an online session of user, it maps to `userinfo_t` in
+// pttbbs.
+type UtmpRecord interface {
+	// UserID return the user id of this session.
+	UserID() string
+	// From return the host where user logged in from.
+	From() string
+	// LoginTime return the time when this session logged in.
+	LoginTime() time.Time
+	// Activity return what user is doing now, eg: "閱讀文章".
+	Activity() string
+	// BoardID return the board user is reading now, return empty string if
+	// user is not in any board.
+	BoardID() string
+}
+
+// WhoIsOnline returns the UtmpRecords of online users (誰在線上), sessions
+// without user id are skipped. It returns ErrNotSupported if connector
+// does not implement UtmpConnector.
+func (db *DB) WhoIsOnline() ([]UtmpRecord, error) {
+	var uc UtmpConnector
+	if !db.connectorAs(&uc) {
+		return nil, ErrNotSupported
+	}
+
+	recs, err := uc.ReadUtmp()
+	if err != nil {
+		log.Println("bbs: ReadUtmp error:", err)
+		return nil, err
+	}
+
+	ret := make([]UtmpRecord, 0, len(recs))
+	for _, r := range recs {
+		if r.UserID() == "" {
+			continue
+		}
+		ret = append(ret, r)
+	}
+	return ret, nil
+}
+
+// BoardReaders returns the UtmpRecords of online users reading boardID,
+// board ids are matched case-insensitively. It returns an empty slice if
+// connector does not implement UtmpConnector.
+func (db *DB) BoardReaders(boardID string) ([]UtmpRecord, error) {
+	boardID, err := db.normalizeBoardID(boardID)
+	if err != nil {
+		return nil, err
+	}
+	recs, err := db.WhoIsOnline()
+	if errors.Is(err, ErrNotSupported) {
+		return []UtmpRecord{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	ret := []UtmpRecord{}
+	for _, r := range recs {
+		if strings.EqualFold(r.BoardID(), boardID) {
+			ret = append(ret, r)
+		}
+	}
+	return ret, nil
+}
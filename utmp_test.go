@@ -0,0 +1,91 @@
+package bbs
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeUtmpRecord struct {
+	userID    string
+	from      string
+	loginTime time.Time
+	activity  string
+	boardID   string
+}
+
+func (u *fakeUtmpRecord) UserID() string       { return u.userID }
+func (u *fakeUtmpRecord) From() string         { return u.from }
+func (u *fakeUtmpRecord) LoginTime() time.Time { return u.loginTime }
+func (u *fakeUtmpRecord) Activity() string     { return u.activity }
+func (u *fakeUtmpRecord) BoardID() string      { return u.boardID }
+
+type fakeUtmpConnector struct {
+	fakeConnector
+	fakeReadUtmp func() ([]UtmpRecord, error)
+}
+
+var _ UtmpConnector = &fakeUtmpConnector{}
+
+func (c *fakeUtmpConnector) ReadUtmp() ([]UtmpRecord, error) {
+	return c.fakeReadUtmp()
+}
+
+func TestWhoIsOnline(t *testing.T) {
+	db := &DB{connector: &fakeUtmpConnector{
+		fakeReadUtmp: func() ([]UtmpRecord, error) {
+			return []UtmpRecord{
+				&fakeUtmpRecord{userID: "SYSOP", from: "127.0.0.1", boardID: "SYSOP"},
+				&fakeUtmpRecord{},
+				&fakeUtmpRecord{userID: "pichu", activity: "閱讀文章"},
+			}, nil
+		},
+	}}
+
+	recs, err := db.WhoIsOnline()
+	if err != nil {
+		t.Fatalf("WhoIsOnline error: %v", err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("len(recs) expected: 2, got: %v", len(recs))
+	}
+	if recs[1].UserID() != "pichu" {
+		t.Errorf("user id expected: pichu, got: %v", recs[1].UserID())
+	}
+
+	db = &DB{connector: &fakeConnector{}}
+	if _, err := db.WhoIsOnline(); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("WhoIsOnline should return ErrNotSupported, got: %v", err)
+	}
+}
+
+func TestBoardReaders(t *testing.T) {
+	db := &DB{connector: &fakeUtmpConnector{
+		fakeReadUtmp: func() ([]UtmpRecord, error) {
+			return []UtmpRecord{
+				&fakeUtmpRecord{userID: "SYSOP", boardID: "SYSOP"},
+				&fakeUtmpRecord{userID: "pichu", boardID: "sysop"},
+				&fakeUtmpRecord{boardID: "SYSOP"},
+				&fakeUtmpRecord{userID: "Kahou", boardID: "Test"},
+				&fakeUtmpRecord{userID: "guest"},
+			}, nil
+		},
+	}}
+
+	recs, err := db.BoardReaders("SYSOP")
+	if err != nil {
+		t.Fatalf("BoardReaders error: %v", err)
+	}
+	if len(recs) != 2 || recs[0].UserID() != "SYSOP" || recs[1].UserID() != "pichu" {
+		t.Errorf("readers expected: [SYSOP pichu], got: %v", recs)
+	}
+	if _, err := db.BoardReaders(""); !errors.Is(err, ErrInvalidBoardID) {
+		t.Errorf("BoardReaders should return ErrInvalidBoardID, got: %v", err)
+	}
+
+	db = &DB{connector: &fakeConnector{}}
+	recs, err = db.BoardReaders("SYSOP")
+	if err != nil || recs == nil || len(recs) != 0 {
+		t.Errorf("BoardReaders without utmp expected: [], <nil>, got: %v, %v", recs, err)
+	}
+}
@@ -0,0 +1,233 @@
+package bbs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+)
+
+// ValidationProblem is a problem found in a record file.
+type ValidationProblem struct {
+	// Index is the 0-based index of the bad record.
+	Index int
+	// Offset is the byte offset of the bad record in file, it is -1 when
+	// connector does not implement RecordSizer.
+	Offset int64
+	// Problem describes what is wrong with the record.
+	Problem string
+}
+
+func (p ValidationProblem) String() string {
+	return fmt.Sprintf("record %d (offset %d): %s", p.Index, p.Offset, p.Problem)
+}
+
+// ValidationReport is the result of verifying a record file.
+type ValidationReport struct {
+	// Path is the record file path.
+	Path string
+	// NumRecords is the number of records read from file.
+	NumRecords int
+	// Problems lists the bad records ordered by index.
+	Problems []ValidationProblem
+}
+
+// OK returns true if no problem is found.
+func (r ValidationReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// VerifyUserRecords checks the user records file, the file size should be
+// a multiple of the user record size and user ids should be well-formed.
+// Empty user id is treated as an unused slot. Records are read by the
+// connector wrapped by WithCache or other wrappers, so the file is checked
+// instead of cached records.
+func (db *DB) VerifyUserRecords() (ValidationReport, error) {
+	path, err := db.userRecordsPath()
+	if err != nil {
+		log.Println("bbs: get user records path error:", err)
+		return ValidationReport{}, err
+	}
+	recs, err := db.baseConnector().ReadUserRecordsFile(path)
+	if err != nil && !isTruncatedRecord(err) {
+		log.Println("bbs: ReadUserRecordsFile error:", err)
+		return ValidationReport{}, err
+	}
+
+	ids := make([]string, len(recs))
+	for i, r := range recs {
+		ids[i] = r.UserID()
+	}
+	var size int
+	var rs RecordSizer
+	if db.connectorAs(&rs) {
+		size = rs.UserRecordSize()
+	}
+	return db.verifyRecordFile(path, size, ids, checkUserID)
+}
+
+// VerifyBoardRecords checks the board records file, the file size should be
+// a multiple of the board record size and board ids should be well-formed.
+// Empty board id is treated as an unused slot.
+func (db *DB) VerifyBoardRecords() (ValidationReport, error) {
+	path, err := db.boardRecordsPath()
+	if err != nil {
+		log.Println("bbs: get board records path error:", err)
+		return ValidationReport{}, err
+	}
+	recs, err := db.baseConnector().ReadBoardRecordsFile(path)
+	if err != nil && !isTruncatedRecord(err) {
+		log.Println("bbs: ReadBoardRecordsFile error:", err)
+		return ValidationReport{}, err
+	}
+
+	ids := make([]string, len(recs))
+	for i, r := range recs {
+		ids[i] = r.BoardID()
+	}
+	var size int
+	var rs RecordSizer
+	if db.connectorAs(&rs) {
+		size = rs.BoardRecordSize()
+	}
+	return db.verifyRecordFile(path, size, ids, checkBoardID)
+}
+
+// VerifyBoardArticleRecords checks the article records file of board, the
+// file size should be a multiple of the article record size and filenames
+// should be well-formed. Empty filename is treated as a deleted slot.
+func (db *DB) VerifyBoardArticleRecords(boardID string) (ValidationReport, error) {
+	path, err := db.boardArticleRecordsPath(boardID)
+	if err != nil {
+		log.Println("bbs: get board article records path error:", err)
+		return ValidationReport{}, err
+	}
+	recs, err := db.baseConnector().ReadArticleRecordsFile(path)
+	if err != nil && !isTruncatedRecord(err) {
+		log.Println("bbs: ReadArticleRecordsFile error:", err)
+		return ValidationReport{}, err
+	}
+
+	ids := make([]string, len(recs))
+	for i, r := range recs {
+		ids[i] = r.Filename()
+	}
+	var size int
+	var rs RecordSizer
+	if db.connectorAs(&rs) {
+		size = rs.ArticleRecordSize()
+	}
+	return db.verifyRecordFile(path, size, ids, checkArticleFilename)
+}
+
+// isTruncatedRecord returns true if err is DecodeError of a partial record
+// at the end of file, connector returns the records before it with err, and
+// verifyRecordFile reports the partial record from the file size.
+func isTruncatedRecord(err error) bool {
+	var de *DecodeError
+	return errors.As(err, &de) && errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// verifyRecordFile checks ids of records in path by check, which returns the
+// problem or empty string. File size is checked only when size is not 0.
+func (db *DB) verifyRecordFile(path string, size int, ids []string, check func(string) string) (ValidationReport, error) {
+	report := ValidationReport{
+		Path:       path,
+		NumRecords: len(ids),
+	}
+
+	offset := func(index int) int64 {
+		if size == 0 {
+			return -1
+		}
+		return int64(index) * int64(size)
+	}
+
+	for i, id := range ids {
+		if p := check(id); p != "" {
+			report.Problems = append(report.Problems, ValidationProblem{
+				Index:   i,
+				Offset:  offset(i),
+				Problem: p,
+			})
+		}
+	}
+
+	if size == 0 {
+		return report, nil
+	}
+	stat, err := db.statFile(path)
+	if err != nil {
+		log.Println("bbs: stat record file error:", err)
+		return report, err
+	}
+	if remain := stat.Size() % int64(size); remain != 0 {
+		index := int(stat.Size() / int64(size))
+		p := ValidationProblem{
+			Index:   index,
+			Offset:  offset(index),
+			Problem: fmt.Sprintf("truncated record: %d of %d bytes", remain, size),
+		}
+		// partial record may also be read as a record with bad id.
+		if n := len(report.Problems); n > 0 && report.Problems[n-1].Index == index {
+			report.Problems[n-1] = p
+		} else {
+			report.Problems = append(report.Problems, p)
+		}
+	}
+	return report, nil
+}
+
+func checkUserID(id string) string {
+	if id == "" {
+		return ""
+	}
+	for i, c := range id {
+		if !isASCIILetter(c) && (i == 0 || !isASCIIDigit(c)) {
+			return fmt.Sprintf("malformed user id %q", id)
+		}
+	}
+	return ""
+}
+
+func checkBoardID(id string) string {
+	if id == "" {
+		return ""
+	}
+	// class boards in pttbbs are named like "1...........".
+	for i, c := range id {
+		if isASCIILetter(c) || isASCIIDigit(c) {
+			continue
+		}
+		if i > 0 && (c == '_' || c == '-' || c == '.') {
+			continue
+		}
+		return fmt.Sprintf("malformed board id %q", id)
+	}
+	return ""
+}
+
+func checkArticleFilename(filename string) string {
+	// all-zero record is a cleared slot.
+	if strings.TrimSpace(filename) == "" {
+		return ""
+	}
+	if filename == "." || filename == ".." || strings.ContainsAny(filename, "/\\") {
+		return fmt.Sprintf("malformed filename %q", filename)
+	}
+	for _, c := range filename {
+		if c < 0x20 || c == 0x7f {
+			return fmt.Sprintf("malformed filename %q", filename)
+		}
+	}
+	return ""
+}
+
+func isASCIILetter(c rune) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isASCIIDigit(c rune) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,126 @@
+package bbs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeRecordSizerConnector struct {
+	*fakeConnector
+	size int
+}
+
+var _ RecordSizer = &fakeRecordSizerConnector{}
+
+func (c *fakeRecordSizerConnector) UserRecordSize() int    { return c.size }
+func (c *fakeRecordSizerConnector) BoardRecordSize() int   { return c.size }
+func (c *fakeRecordSizerConnector) ArticleRecordSize() int { return c.size }
+
+func TestVerifyBoardRecords(t *testing.T) {
+	dir, err := ioutil.TempDir("", "verify_test_*")
+	if err != nil {
+		t.Fatalf("create tmp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	brd := filepath.Join(dir, ".BRD")
+	// 3 records and a partial record of 2 bytes.
+	if err := ioutil.WriteFile(brd, make([]byte, 4*3+2), 0644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+
+	fc := &fakeConnector{
+		fakeGetBoardRecordsPath: func() (string, error) { return brd, nil },
+		fakeReadBoardRecordsFile: func() ([]BoardRecord, error) {
+			return []BoardRecord{
+				&fakeBoardRecord{boardID: "SYSOP"},
+				&fakeBoardRecord{boardID: "bad/id"},
+				&fakeBoardRecord{boardID: ""},
+				&fakeBoardRecord{boardID: "\x01"},
+			}, nil
+		},
+	}
+
+	db := &DB{connector: &fakeRecordSizerConnector{fakeConnector: fc, size: 4}}
+	report, err := db.VerifyBoardRecords()
+	if err != nil {
+		t.Fatalf("VerifyBoardRecords error: %v", err)
+	}
+	expected := []ValidationProblem{
+		{Index: 1, Offset: 4, Problem: `malformed board id "bad/id"`},
+		{Index: 3, Offset: 12, Problem: "truncated record: 2 of 4 bytes"},
+	}
+	if !reflect.DeepEqual(report.Problems, expected) {
+		t.Errorf("problems not match, expected: %v, got: %v", expected, report.Problems)
+	}
+	if report.OK() {
+		t.Errorf("report should not be OK")
+	}
+
+	db = &DB{connector: fc}
+	report, err = db.VerifyBoardRecords()
+	if err != nil {
+		t.Fatalf("VerifyBoardRecords error: %v", err)
+	}
+	expected = []ValidationProblem{
+		{Index: 1, Offset: -1, Problem: `malformed board id "bad/id"`},
+		{Index: 3, Offset: -1, Problem: `malformed board id "\x01"`},
+	}
+	if !reflect.DeepEqual(report.Problems, expected) {
+		t.Errorf("problems not match, expected: %v, got: %v", expected, report.Problems)
+	}
+}
+
+func TestVerifyBoardRecordsWithCache(t *testing.T) {
+	recs := []BoardRecord{&fakeBoardRecord{boardID: "SYSOP"}}
+	fc := &fakeConnector{
+		fakeGetBoardRecordsPath:  func() (string, error) { return ".BRD", nil },
+		fakeReadBoardRecordsFile: func() ([]BoardRecord, error) { return recs, nil },
+	}
+	db := &DB{connector: WithCache(fc, time.Hour)}
+	if _, err := db.ReadBoardRecords(); err != nil {
+		t.Fatalf("ReadBoardRecords error: %v", err)
+	}
+
+	recs = append(recs, &fakeBoardRecord{boardID: "bad/id"})
+	report, err := db.VerifyBoardRecords()
+	if err != nil {
+		t.Fatalf("VerifyBoardRecords error: %v", err)
+	}
+	expected := []ValidationProblem{
+		{Index: 1, Offset: -1, Problem: `malformed board id "bad/id"`},
+	}
+	if !reflect.DeepEqual(report.Problems, expected) {
+		t.Errorf("records should not be read from cache, expected: %v, got: %v", expected, report.Problems)
+	}
+}
+
+func TestCheckRecordIDs(t *testing.T) {
+	tests := []struct {
+		check func(string) string
+		id    string
+		ok    bool
+	}{
+		{checkUserID, "SYSOP", true},
+		{checkUserID, "pichu2", true},
+		{checkUserID, "", true},
+		{checkUserID, "2pichu", false},
+		{checkUserID, "pi_chu", false},
+		{checkBoardID, "Gossiping", true},
+		{checkBoardID, "C_Chat", true},
+		{checkBoardID, "1...........", true},
+		{checkBoardID, "_Chat", false},
+		{checkArticleFilename, "M.1609459200.A.123", true},
+		{checkArticleFilename, "", true},
+		{checkArticleFilename, "../.PASSWDS", false},
+	}
+	for _, tt := range tests {
+		if got := tt.check(tt.id) == ""; got != tt.ok {
+			t.Errorf("check %q expected ok: %v, got: %v", tt.id, tt.ok, got)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package bbs
+
+// Operations passed to WriteHook.
+const (
+	WriteOpAdd    = "add"
+	WriteOpUpdate = "update"
+	WriteOpRemove = "remove"
+	WriteOpMove   = "move"
+	WriteOpImport = "import"
+)
+
+// WriteHook is called after a record is written. op is one of WriteOpAdd,
+// WriteOpUpdate, WriteOpRemove, WriteOpMove and WriteOpImport, path is the
+// record file, index is the 0-based index of the record and rec is the
+// written record. For WriteOpAdd, index is where the record is appended as
+// returned by the driver. rec is nil for WriteOpRemove and WriteOpMove. For
+// WriteOpMove, index is the new index, the old index is passed to MoveHook.
+// For WriteOpImport, path is the article records file of the imported
+// board, index is 0 and rec is nil.
+type WriteHook func(op string, path string, index uint, rec interface{})
+
+// MoveHook is called after a record in path is moved from index from to
+// index to.
+type MoveHook func(path string, from, to uint)
+
+// SetWriteHook sets fn to be called after AddBoardRecord, UpdateBoardRecord,
+// RemoveBoardRecord, MoveBoardRecord and ImportBoard succeed, such as for
+// audit logging. fn is called
+// synchronously before the write method returns, and never on failed
+// writes. Passing nil removes the hook.
+func (db *DB) SetWriteHook(fn func(op string, path string, index uint, rec interface{})) {
+	db.writeHook = fn
+}
+
+// SetMoveHook sets fn to be called after MoveBoardRecord succeeds, after the
+// write hook. Like SetWriteHook, fn is called synchronously and never on
+// failed writes. Passing nil removes the hook.
+func (db *DB) SetMoveHook(fn func(path string, from, to uint)) {
+	db.moveHook = fn
+}
+
+func (db *DB) callWriteHook(op string, path string, index uint, rec interface{}) {
+	if db.writeHook != nil {
+		db.writeHook(op, path, index, rec)
+	}
+}
+
+func (db *DB) callMoveHook(path string, from, to uint) {
+	if db.moveHook != nil {
+		db.moveHook(path, from, to)
+	}
+}
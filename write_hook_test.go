@@ -0,0 +1,86 @@
+package bbs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type failWriteBoardConnector struct {
+	*fakeWriteBoardConnector
+}
+
+func (c *failWriteBoardConnector) AddBoardRecordFileRecord(name string, brd BoardRecord) error {
+	return errors.New("disk full")
+}
+
+func (c *failWriteBoardConnector) AddBoardRecordFileRecords(name string, brds []BoardRecord) (uint, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestSetWriteHook(t *testing.T) {
+	reads := 0
+	db := &DB{connector: newFakeWriteBoardConnector(&reads)}
+
+	var events []string
+	db.SetWriteHook(func(op string, path string, index uint, rec interface{}) {
+		id := ""
+		if brd, ok := rec.(BoardRecord); ok {
+			id = brd.BoardID()
+		}
+		events = append(events, fmt.Sprintf("%s %s %d %s", op, path, index, id))
+	})
+
+	for _, id := range []string{"SYSOP", "Test"} {
+		brd, _ := db.NewBoardRecord(map[string]interface{}{"board_id": id})
+		if err := db.AddBoardRecord(brd); err != nil {
+			t.Fatalf("AddBoardRecord error: %v", err)
+		}
+	}
+	brd, _ := db.NewBoardRecord(map[string]interface{}{"board_id": "Note"})
+	if err := db.UpdateBoardRecord(1, &brd); err != nil {
+		t.Fatalf("UpdateBoardRecord error: %v", err)
+	}
+	if err := db.RemoveBoardRecord(0); err != nil {
+		t.Fatalf("RemoveBoardRecord error: %v", err)
+	}
+	var brds []BoardRecord
+	for _, id := range []string{"A0", "A1"} {
+		brd, _ := db.NewBoardRecord(map[string]interface{}{"board_id": id})
+		brds = append(brds, brd)
+	}
+	if err := db.AddBoardRecords(brds); err != nil {
+		t.Fatalf("AddBoardRecords error: %v", err)
+	}
+
+	expected := []string{
+		"add .BRD 0 SYSOP",
+		"add .BRD 1 Test",
+		"update .BRD 1 Note",
+		"remove .BRD 0 ",
+		"add .BRD 1 A0",
+		"add .BRD 2 A1",
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("events not match, expected: %q, got: %q", expected, events)
+	}
+}
+
+func TestSetWriteHookFailedWrite(t *testing.T) {
+	reads := 0
+	db := &DB{connector: &failWriteBoardConnector{newFakeWriteBoardConnector(&reads)}}
+
+	called := false
+	db.SetWriteHook(func(op string, path string, index uint, rec interface{}) {
+		called = true
+	})
+
+	brd, _ := db.NewBoardRecord(map[string]interface{}{"board_id": "SYSOP"})
+	if err := db.AddBoardRecord(brd); err == nil {
+		t.Errorf("AddBoardRecord should fail")
+	}
+	if called {
+		t.Errorf("write hook should not be called on failed write")
+	}
+}